@@ -0,0 +1,15 @@
+data "mssql_all_permissions" "all" {
+  include_databases = true
+  database_names    = ["mydb"]
+}
+
+# Generate import blocks for the permissions, e.g. with terraform console
+output "import_blocks" {
+  value = join("\n", [for p in data.mssql_all_permissions.all.permissions : <<-EOT
+    import {
+      to = ${p.resource_type}.${replace(lower("${p.principal_name}_${p.permission}"), "/[^a-z0-9_]/", "_")}
+      id = "${p.import_id}"
+    }
+  EOT
+  ])
+}
@@ -0,0 +1,13 @@
+data "mssql_database_imports" "app" {
+  database_name = "mydb"
+}
+
+output "import_blocks" {
+  value = join("\n", [for i in data.mssql_database_imports.app.imports : <<-EOT
+    import {
+      to = ${i.resource_type}.${replace(lower(i.import_id), "/[^a-z0-9_]/", "_")}
+      id = "${i.import_id}"
+    }
+  EOT
+  ])
+}
@@ -0,0 +1,7 @@
+data "mssql_database_owner" "example" {
+  database_name = "my_database"
+}
+
+output "owner" {
+  value = data.mssql_database_owner.example.owner_name
+}
@@ -0,0 +1,4 @@
+data "mssql_database_role_permissions" "example" {
+  database_name = "example_db"
+  role_name     = "app_readers"
+}
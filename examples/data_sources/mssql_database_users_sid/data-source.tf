@@ -0,0 +1,8 @@
+data "mssql_database_users_sid" "primary" {
+  database_name = "mydb"
+}
+
+# Contained users to create with the same SID on the other replicas
+output "contained_users" {
+  value = { for u in data.mssql_database_users_sid.primary.users : u.name => u.sid if u.authentication_type == "DATABASE" }
+}
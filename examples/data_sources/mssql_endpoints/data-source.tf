@@ -0,0 +1,5 @@
+data "mssql_endpoints" "all" {}
+
+output "mirroring_endpoint" {
+  value = one([for endpoint in data.mssql_endpoints.all.endpoints : endpoint.name if endpoint.type_desc == "DATABASE_MIRRORING"])
+}
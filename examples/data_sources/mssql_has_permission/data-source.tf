@@ -0,0 +1,11 @@
+data "mssql_has_permission" "reporting_select" {
+  database_name   = "example_db"
+  principal_name  = "reporting_user"
+  securable       = "sales.orders"
+  securable_class = "OBJECT"
+  permission      = "SELECT"
+}
+
+output "reporting_can_read_orders" {
+  value = data.mssql_has_permission.reporting_select.has_permission
+}
@@ -0,0 +1,7 @@
+data "mssql_principal_memberships" "example" {
+  principal_name = "example_login"
+}
+
+output "server_roles" {
+  value = data.mssql_principal_memberships.example.server_roles
+}
@@ -0,0 +1,5 @@
+data "mssql_provider_stats" "current" {}
+
+output "connections_in_use" {
+  value = sum([for pool in data.mssql_provider_stats.current.pools : pool.in_use])
+}
@@ -6,3 +6,10 @@ data "mssql_query" "example" {
 output "server_version" {
   value = data.mssql_query.example.result[0].values.version
 }
+
+# Route a reporting query to a readable secondary replica
+data "mssql_query" "report" {
+  database_name = "my_database"
+  query         = "SELECT COUNT(*) AS orders FROM dbo.orders"
+  read_only     = true
+}
@@ -1,3 +1,8 @@
 data "mssql_schemas" "example" {
   database_name = "example_db"
 }
+
+data "mssql_schemas" "dbo_owned" {
+  database_name = "example_db"
+  owner_name    = "dbo"
+}
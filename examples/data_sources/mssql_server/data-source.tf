@@ -0,0 +1,5 @@
+data "mssql_server" "current" {}
+
+output "server_version" {
+  value = data.mssql_server.current.version
+}
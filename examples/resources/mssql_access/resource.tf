@@ -0,0 +1,11 @@
+resource "mssql_access" "reporting" {
+  database_name        = mssql_database.example.name
+  principal_name       = mssql_sql_user.reporting.name
+  server_roles         = ["dbcreator"]
+  database_roles       = ["db_datareader"]
+  database_permissions = ["VIEW DEFINITION", "SHOWPLAN"]
+  schema_permissions = {
+    app     = ["SELECT", "EXECUTE"]
+    reports = ["SELECT"]
+  }
+}
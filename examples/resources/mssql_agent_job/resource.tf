@@ -0,0 +1,21 @@
+resource "mssql_database" "example" {
+  name = "example_db"
+}
+
+# Rebuild the indexes and update the statistics of the database
+resource "mssql_agent_job" "maintenance" {
+  name = "example_db maintenance"
+
+  steps = [
+    {
+      name          = "rebuild indexes"
+      command       = "EXEC sp_MSforeachtable 'ALTER INDEX ALL ON ? REBUILD'"
+      database_name = mssql_database.example.name
+    },
+    {
+      name          = "update statistics"
+      command       = "EXEC sp_updatestats"
+      database_name = mssql_database.example.name
+    },
+  ]
+}
@@ -0,0 +1,22 @@
+resource "mssql_agent_job" "maintenance" {
+  name = "maintenance"
+
+  steps = [
+    {
+      name    = "update statistics"
+      command = "EXEC sp_updatestats"
+    },
+  ]
+}
+
+resource "mssql_agent_schedule" "weekly" {
+  name       = "weekly maintenance"
+  frequency  = "WEEKLY"
+  weekdays   = ["SUNDAY"]
+  start_time = "02:00:00"
+}
+
+resource "mssql_agent_job_schedule" "maintenance_weekly" {
+  job_id      = mssql_agent_job.maintenance.id
+  schedule_id = mssql_agent_schedule.weekly.id
+}
@@ -0,0 +1,17 @@
+# Every Sunday at 02:00
+resource "mssql_agent_schedule" "weekly" {
+  name       = "weekly maintenance"
+  frequency  = "WEEKLY"
+  weekdays   = ["SUNDAY"]
+  start_time = "02:00:00"
+}
+
+# Every 15 minutes during business hours
+resource "mssql_agent_schedule" "business_hours" {
+  name            = "business hours"
+  frequency       = "DAILY"
+  subday_unit     = "MINUTES"
+  subday_interval = 15
+  start_time      = "08:00:00"
+  end_time        = "18:00:00"
+}
@@ -0,0 +1,9 @@
+resource "mssql_database" "example" {
+  name = "example_db"
+}
+
+# The certificate must exist in master, e.g. created with mssql_script
+resource "mssql_database_encryption" "example" {
+  database_name = mssql_database.example.name
+  encryptor     = "TDECert"
+}
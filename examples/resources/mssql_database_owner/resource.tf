@@ -0,0 +1,14 @@
+resource "mssql_database" "example" {
+  name = "example_db"
+}
+
+# A login without a user in the database, used only as its owner
+resource "mssql_sql_login" "owner" {
+  name     = "example_db_owner"
+  password = "SecurePassword123!"
+}
+
+resource "mssql_database_owner" "example" {
+  database_name = mssql_database.example.name
+  owner_name    = mssql_sql_login.owner.name
+}
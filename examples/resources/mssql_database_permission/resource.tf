@@ -18,3 +18,12 @@ resource "mssql_database_permission" "example" {
   principal_name = mssql_sql_user.example.name
   permission     = "CONNECT"
 }
+
+# Permission on a certificate created in the database, e.g. for module signing
+resource "mssql_database_permission" "certificate" {
+  database_name  = mssql_database.example.name
+  principal_name = mssql_sql_user.example.name
+  permission     = "CONTROL"
+  securable_type = "CERTIFICATE"
+  securable_name = "signing_cert"
+}
@@ -6,3 +6,10 @@ resource "mssql_database_role" "example" {
   name          = "example_role"
   database_name = mssql_database.example.name
 }
+
+resource "mssql_database_role" "auditors" {
+  name          = "auditors"
+  database_name = mssql_database.example.name
+  permissions   = ["VIEW DEFINITION", "SHOWPLAN"]
+  members       = ["audit_user"]
+}
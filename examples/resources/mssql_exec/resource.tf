@@ -0,0 +1,9 @@
+resource "mssql_exec" "shrink_log" {
+  database_name = "example_db"
+  statement     = "DBCC SHRINKFILE (2, 64)"
+
+  # Shrink the log again whenever the maintenance window changes
+  triggers = {
+    window = "2024-06"
+  }
+}
@@ -0,0 +1,9 @@
+resource "mssql_database" "example" {
+  name = "example_db"
+}
+
+# Disable the guest user (CIS benchmark)
+resource "mssql_guest_user" "example" {
+  database_name = mssql_database.example.name
+  enabled       = false
+}
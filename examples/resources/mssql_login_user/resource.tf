@@ -0,0 +1,10 @@
+resource "mssql_database" "example" {
+  name = "example_db"
+}
+
+resource "mssql_login_user" "example" {
+  login_name    = "example_app"
+  password      = "SecretPassword123!"
+  database_name = mssql_database.example.name
+  roles         = ["db_datareader"]
+}
@@ -0,0 +1,27 @@
+resource "mssql_database" "example" {
+  name = "example_db"
+}
+
+resource "mssql_database_role" "reporting" {
+  name          = "reporting"
+  database_name = mssql_database.example.name
+}
+
+# SELECT on a single table
+resource "mssql_permission" "orders_select" {
+  database_name  = mssql_database.example.name
+  class          = "OBJECT"
+  securable_name = "dbo.orders"
+  principal_name = mssql_database_role.reporting.name
+  permission     = "SELECT"
+}
+
+# Keep the role from using a user-defined type
+resource "mssql_permission" "deny_type" {
+  database_name  = mssql_database.example.name
+  class          = "TYPE"
+  securable_name = "dbo.order_list"
+  principal_name = mssql_database_role.reporting.name
+  permission     = "EXECUTE"
+  state          = "DENY"
+}
@@ -0,0 +1,11 @@
+resource "mssql_database" "example" {
+  name = "example_db"
+}
+
+resource "mssql_query_store" "example" {
+  database_name              = mssql_database.example.name
+  operation_mode             = "READ_WRITE"
+  max_storage_size_mb        = 1024
+  query_capture_mode         = "AUTO"
+  stale_query_threshold_days = 30
+}
@@ -0,0 +1,26 @@
+resource "mssql_database" "example" {
+  name = "example_db"
+}
+
+resource "mssql_sql_login" "example" {
+  name     = "example_user_login"
+  password = "SecretPassword123!"
+}
+
+resource "mssql_sql_user" "example" {
+  name          = "example_user"
+  database_name = mssql_database.example.name
+  login_name    = mssql_sql_login.example.name
+}
+
+resource "mssql_schema" "example" {
+  name          = "example_schema"
+  database_name = mssql_database.example.name
+}
+
+resource "mssql_schema_permissions" "example" {
+  database_name  = mssql_database.example.name
+  schema_name    = mssql_schema.example.name
+  principal_name = mssql_sql_user.example.name
+  permissions    = ["SELECT", "INSERT", "UPDATE", "DELETE", "EXECUTE"]
+}
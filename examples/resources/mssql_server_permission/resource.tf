@@ -7,3 +7,11 @@ resource "mssql_server_permission" "example" {
   principal_name = mssql_sql_login.example.name
   permission     = "CONNECT SQL"
 }
+
+# Allow the login to connect to the Always On / mirroring endpoint
+resource "mssql_server_permission" "hadr_connect" {
+  principal_name = mssql_sql_login.example.name
+  permission     = "CONNECT"
+  securable_type = "ENDPOINT"
+  securable_name = "Hadr_endpoint"
+}
@@ -8,8 +8,9 @@ terraform {
 }
 
 provider "mssql" {
-  hostname = var.sql_hostname
-  port     = var.sql_port
+  hostname                  = var.sql_hostname
+  port                      = var.sql_port
+  default_with_grant_option = var.test_default_grant_option
 
   sql_auth {
     username = var.sql_username
@@ -22,11 +23,29 @@ resource "mssql_database" "app" {
   name = "application_db"
 }
 
+# A copy of the application database, only created to test that copies are
+# refused outside of Azure SQL Database
+resource "mssql_database" "copy" {
+  count = var.test_copy_source == null ? 0 : 1
+
+  name            = "application_db_copy"
+  source_database = var.test_copy_source
+}
+
+# A database only created to test renaming in place
+resource "mssql_database" "rename" {
+  count = var.test_rename_database == null ? 0 : 1
+
+  name = var.test_rename_database
+}
+
 # Create a login for the application
 resource "mssql_sql_login" "app" {
-  name             = "app_login"
-  password         = var.app_password
-  default_database = mssql_database.app.name
+  name                          = "app_login"
+  password                      = var.app_password
+  default_database              = mssql_database.app.name
+  unlock                        = true
+  check_default_database_access = true
 }
 
 # Create a role for read-only access (must exist before user with inline roles)
@@ -43,8 +62,9 @@ resource "mssql_sql_user" "app" {
   name           = "app_user"
   login_name     = mssql_sql_login.app.name
   default_schema = "app"
-  # OPTION 1: Inline roles - role assignment is managed within the user resource
-  roles = [mssql_database_role.readers.name]
+  # OPTION 1: Inline roles - role assignment is managed within the user resource.
+  # The role is given by principal ID, so renaming it doesn't affect the user.
+  roles = ["id:${split("/", mssql_database_role.readers.id)[1]}"]
 }
 
 # Create a schema for the application
@@ -54,11 +74,31 @@ resource "mssql_schema" "app" {
   owner_name    = mssql_sql_user.app.name
 }
 
+# Create a schema whose name needs quoting, granting access in the same batch
+resource "mssql_schema" "reports" {
+  database_name = mssql_database.app.name
+  name          = "report [archive]"
+
+  create_objects = [
+    "GRANT SELECT ON SCHEMA::[report [archive]]] TO [${mssql_database_role.readers.name}]",
+  ]
+}
+
 # Grant SELECT permission to the role
 resource "mssql_database_permission" "readers_select" {
   database_name  = mssql_database.app.name
   principal_name = mssql_database_role.readers.name
   permission     = "SELECT"
+  # Granted WITH GRANT OPTION by the provider default in one of the tests
+  cascade = true
+}
+
+# Grant CONNECT to the built-in public role
+resource "mssql_database_permission" "public_connect" {
+  database_name     = mssql_database.app.name
+  principal_name    = "public"
+  permission        = "CONNECT"
+  with_grant_option = false
 }
 
 # Grant EXECUTE permission on the schema
@@ -80,10 +120,56 @@ resource "mssql_database_role" "writers" {
   name          = "app_writers"
 }
 
+# Delegate login administration to the application login
+resource "mssql_server_permission" "app_alter_any_login" {
+  principal_name = mssql_sql_login.app.name
+  permission     = "ALTER ANY LOGIN"
+}
+
+# Keep the guest user disabled in the application database
+resource "mssql_guest_user" "app" {
+  database_name = mssql_database.app.name
+}
+
+# A login without a user in the application database that owns it
+resource "mssql_sql_login" "app_owner" {
+  name     = "app_db_owner"
+  password = var.app_password
+}
+
+# A service login created disabled, to be enabled once the service goes live
+resource "mssql_sql_login" "pending" {
+  name        = "pending_service_login"
+  password    = var.app_password
+  is_disabled = true
+}
+
+resource "mssql_database_owner" "app" {
+  database_name = mssql_database.app.name
+  owner_name    = mssql_sql_login.app_owner.name
+}
+
+# A reporting login with its user, managed as one resource
+resource "mssql_login_user" "reporting" {
+  login_name       = "report_login"
+  password         = var.app_password
+  default_database = mssql_database.app.name
+  database_name    = mssql_database.app.name
+  roles            = ["db_datareader"]
+}
+
+# SIDs of the application database users, e.g. to replicate them
+data "mssql_database_users_sid" "app" {
+  database_name = mssql_database.app.name
+
+  depends_on = [mssql_login_user.reporting]
+}
+
 # Create a second login for testing
 resource "mssql_sql_login" "test" {
-  name             = "test_login"
+  name             = var.test_login_name
   password         = var.app_password
+  password_version = var.test_password_version
   default_database = mssql_database.app.name
 }
 
@@ -109,11 +195,250 @@ resource "mssql_database_role_member" "test_writer" {
   member_name   = mssql_sql_user.test.name
 }
 
+# A misspelled member, only added to test the role member lookup
+resource "mssql_database_role_member" "test_missing" {
+  count = var.test_missing_role_member == null ? 0 : 1
+
+  database_name = mssql_database.app.name
+  role_name     = mssql_database_role.readers.name
+  member_name   = var.test_missing_role_member
+}
+
+# The only db_owner member besides dbo, removed to test the last member warning
+resource "mssql_database_role_member" "test_owner" {
+  count = var.test_db_owner ? 1 : 0
+
+  database_name = mssql_database.app.name
+  role_name     = "db_owner"
+  member_name   = mssql_sql_user.test.name
+}
+
+# OPTION 3: Authoritative role - permissions and members managed on the role
+resource "mssql_database_role" "auditors" {
+  database_name = mssql_database.app.name
+  name          = "app_auditors"
+  permissions   = ["VIEW DEFINITION", "SHOWPLAN"]
+  members       = [mssql_sql_user.test.name]
+}
+
+# OPTION 4: All access of a principal managed in one resource
+resource "mssql_sql_login" "audit" {
+  name     = "audit_login"
+  password = var.app_password
+}
+
+resource "mssql_sql_user" "audit" {
+  database_name = mssql_database.app.name
+  name          = "audit_user"
+  login_name    = mssql_sql_login.audit.name
+}
+
+resource "mssql_access" "audit" {
+  database_name        = mssql_database.app.name
+  principal_name       = mssql_sql_user.audit.name
+  server_roles         = ["dbcreator"]
+  database_roles       = [mssql_database_role.readers.name]
+  database_permissions = ["VIEW DEFINITION"]
+  schema_permissions = {
+    (mssql_schema.app.name) = ["SELECT", "EXECUTE"]
+  }
+}
+
+# Schema permissions test_user inherits from its roles
+data "mssql_schema_permissions" "test_effective" {
+  database_name  = mssql_database.app.name
+  schema_name    = mssql_schema.reports.name
+  principal_name = mssql_sql_user.test.name
+  effective      = true
+
+  depends_on = [mssql_database_role_member.test_reader]
+}
+
 # Grant SELECT permission on the schema to test_user (non-owner)
 resource "mssql_schema_permission" "test_select" {
   database_name     = mssql_database.app.name
   schema_name       = mssql_schema.app.name
   principal_name    = mssql_sql_user.test.name
   permission        = "SELECT"
-  with_grant_option = true
+  with_grant_option = var.test_select_grant_option
+  cascade           = var.test_select_cascade
+}
+
+# =============================================================================
+# Custom DDL that requires specific SET options (filtered index)
+# =============================================================================
+resource "mssql_script" "filtered_index" {
+  database_name = mssql_database.app.name
+  set_options = [
+    "ANSI_NULLS ON",
+    "ANSI_PADDING ON",
+    "ANSI_WARNINGS ON",
+    "ARITHABORT ON",
+    "CONCAT_NULL_YIELDS_NULL ON",
+    "QUOTED_IDENTIFIER ON",
+    "NUMERIC_ROUNDABORT OFF",
+  ]
+
+  create_script = <<-SQL
+    CREATE TABLE dbo.orders (id INT PRIMARY KEY, shipped_at DATETIME2 NULL);
+    CREATE INDEX ix_orders_open ON dbo.orders (id) WHERE shipped_at IS NULL;
+  SQL
+
+  read_script = <<-SQL
+    SELECT name AS index_name, has_filter FROM sys.indexes WHERE name = 'ix_orders_open'
+  SQL
+
+  delete_script = <<-SQL
+    DROP TABLE IF EXISTS dbo.orders
+  SQL
+}
+
+# Index created with statement options appended to the create script
+resource "mssql_script" "shipped_index" {
+  database_name     = mssql_database.app.name
+  statement_options = ["SORT_IN_TEMPDB = ON", "MAXDOP = 1"]
+
+  create_script = <<-SQL
+    CREATE INDEX ix_orders_shipped ON dbo.orders (shipped_at);
+  SQL
+
+  delete_script = <<-SQL
+    DROP INDEX IF EXISTS ix_orders_shipped ON dbo.orders
+  SQL
+
+  depends_on = [mssql_script.filtered_index]
+}
+
+# Seed data for the orders table, run once as there is nothing to undo
+resource "mssql_exec" "seed_orders" {
+  database_name = mssql_database.app.name
+  statement     = "IF NOT EXISTS (SELECT 1 FROM dbo.orders WHERE id = 1) INSERT INTO dbo.orders (id) VALUES (1)"
+
+  triggers = {
+    table = mssql_script.filtered_index.id
+  }
+}
+
+# Adds an order each time it runs, only created to test run_on
+resource "mssql_exec" "add_order" {
+  count = var.test_exec_run_on == null ? 0 : 1
+
+  database_name = mssql_database.app.name
+  statement     = "INSERT INTO dbo.orders (id) SELECT MAX(id) + 1 FROM dbo.orders"
+  run_on        = var.test_exec_run_on
+
+  depends_on = [mssql_exec.seed_orders]
+}
+
+# Permissions on a table through the class-based mssql_permission resource
+resource "mssql_permission" "readers_orders_select" {
+  database_name  = mssql_database.app.name
+  class          = "OBJECT"
+  securable_name = "dbo.orders"
+  principal_name = mssql_database_role.readers.name
+  permission     = "SELECT"
+
+  depends_on = [mssql_script.filtered_index]
+}
+
+resource "mssql_permission" "readers_orders_deny_delete" {
+  database_name  = mssql_database.app.name
+  class          = "OBJECT"
+  securable_name = "dbo.orders"
+  principal_name = mssql_database_role.readers.name
+  permission     = "DELETE"
+  state          = "DENY"
+
+  depends_on = [mssql_script.filtered_index]
+}
+
+# =============================================================================
+# Permission on a certificate used for module signing
+# =============================================================================
+resource "mssql_script" "signing_certificate" {
+  database_name = mssql_database.app.name
+
+  create_script = <<-SQL
+    CREATE CERTIFICATE signing_cert ENCRYPTION BY PASSWORD = '${var.app_password}' WITH SUBJECT = 'Module signing'
+  SQL
+
+  read_script = <<-SQL
+    SELECT name FROM sys.certificates WHERE name = 'signing_cert'
+  SQL
+
+  delete_script = <<-SQL
+    DROP CERTIFICATE signing_cert
+  SQL
+}
+
+resource "mssql_database_permission" "readers_certificate_control" {
+  database_name  = mssql_database.app.name
+  principal_name = mssql_database_role.readers.name
+  permission     = "CONTROL"
+  securable_type = "CERTIFICATE"
+  securable_name = "signing_cert"
+
+  depends_on = [mssql_script.signing_certificate]
+}
+
+# User mapped to the signing certificate, which signed modules run as
+resource "mssql_sql_user" "signing" {
+  database_name    = mssql_database.app.name
+  name             = "signing_user"
+  certificate_name = "signing_cert"
+  roles            = [mssql_database_role.readers.name]
+
+  depends_on = [mssql_script.signing_certificate]
+}
+
+# Delegated management of a single user (class 4)
+resource "mssql_database_permission" "writers_user_alter" {
+  database_name  = mssql_database.app.name
+  principal_name = mssql_database_role.writers.name
+  permission     = "ALTER"
+  securable_type = "USER"
+  securable_name = mssql_sql_user.test.name
+}
+
+# Permission naming the database explicitly (ON DATABASE::[application_db])
+resource "mssql_database_permission" "writers_create_view" {
+  database_name  = mssql_database.app.name
+  principal_name = mssql_database_role.writers.name
+  permission     = "CREATE VIEW"
+  securable_type = "DATABASE"
+  securable_name = mssql_database.app.name
+}
+
+# =============================================================================
+# SQL Server Agent job
+# =============================================================================
+resource "mssql_agent_job" "maintenance" {
+  name             = "application_db maintenance"
+  owner_login_name = "sa"
+
+  steps = [
+    {
+      name          = "update statistics"
+      command       = "EXEC sp_updatestats"
+      database_name = mssql_database.app.name
+    },
+    {
+      name    = "log completion"
+      command = "PRINT 'done'"
+    },
+  ]
+}
+
+resource "mssql_agent_schedule" "maintenance" {
+  name            = "application_db maintenance"
+  frequency       = "WEEKLY"
+  weekdays        = ["SATURDAY", "SUNDAY"]
+  subday_unit     = "HOURS"
+  subday_interval = 6
+  start_time      = "01:30:00"
+}
+
+resource "mssql_agent_job_schedule" "maintenance" {
+  job_id      = mssql_agent_job.maintenance.id
+  schedule_id = mssql_agent_schedule.maintenance.id
 }
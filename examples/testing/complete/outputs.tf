@@ -12,3 +12,13 @@ output "user_name" {
   description = "The user name"
   value       = mssql_sql_user.app.name
 }
+
+output "test_effective_report_permissions" {
+  description = "The schema permissions test_user inherits on the reports schema"
+  value       = join(",", [for p in data.mssql_schema_permissions.test_effective.permissions : "${p.permission}:${p.grantee_name}"])
+}
+
+output "report_user_sids" {
+  description = "The SID of report_login's user from the resource and from mssql_database_users_sid"
+  value       = "${mssql_login_user.reporting.sid},${one([for u in data.mssql_database_users_sid.app.users : u.sid if u.name == mssql_login_user.reporting.user_name])}"
+}
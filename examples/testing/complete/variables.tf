@@ -27,3 +27,63 @@ variable "app_password" {
   type        = string
   sensitive   = true
 }
+
+variable "test_select_grant_option" {
+  description = "Whether test_user may grant SELECT on the app schema onwards"
+  type        = bool
+  default     = true
+}
+
+variable "test_select_cascade" {
+  description = "Whether revoking test_user's SELECT grant option cascades to onward grants"
+  type        = bool
+  default     = true
+}
+
+variable "test_password_version" {
+  description = "Password version of the second login, changed to test resetting its password"
+  type        = string
+  default     = "1"
+}
+
+variable "test_db_owner" {
+  description = "Whether the second user is a member of db_owner, disabled to test removing the last member"
+  type        = bool
+  default     = true
+}
+
+variable "test_missing_role_member" {
+  description = "Name of a member that does not exist in the database, set to test the role member lookup"
+  type        = string
+  default     = null
+}
+
+variable "test_copy_source" {
+  description = "Source of a database copy, set to test that copies are refused outside of Azure SQL Database"
+  type        = string
+  default     = null
+}
+
+variable "test_rename_database" {
+  description = "Name of a database created to test renaming, changed between applies"
+  type        = string
+  default     = null
+}
+
+variable "test_exec_run_on" {
+  description = "run_on of an mssql_exec resource that adds an order, set to test when its statement runs"
+  type        = string
+  default     = null
+}
+
+variable "test_default_grant_option" {
+  description = "Provider default for with_grant_option, enabled to test permissions that leave it unset"
+  type        = bool
+  default     = false
+}
+
+variable "test_login_name" {
+  description = "Name of the second login, changed to test renaming it in place"
+  type        = string
+  default     = "test_login"
+}
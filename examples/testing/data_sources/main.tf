@@ -11,6 +11,9 @@ provider "mssql" {
   hostname = "localhost"
   port     = 1433
 
+  # Logical server name used in IDs instead of localhost
+  server_name = "sqlserver-e2e"
+
   sql_auth {
     username = "sa"
     password = "P@ssw0rd123!"
@@ -30,12 +33,96 @@ data "mssql_query" "version" {
   query = "SELECT @@VERSION as version"
 }
 
+# Execute a query on a read-only connection
+data "mssql_query" "read_only" {
+  database_name = "master"
+  query         = "SELECT DATABASEPROPERTYEX('master', 'Updateability') AS updateability"
+  read_only     = true
+}
+
+# Execute a query in several databases; the missing one is skipped with a warning
+data "mssql_query" "across_databases" {
+  databases = ["master", "application_db", "missing_db"]
+  query     = "SELECT DB_NAME() AS current_db"
+}
+
 # List all logins
 data "mssql_sql_logins" "all" {}
 
+# Get the password policy state of the sa login
+data "mssql_sql_login" "sa" {
+  name = "sa"
+}
+
 # Get server roles
 data "mssql_server_roles" "all" {}
 
+data "mssql_endpoints" "all" {}
+
+data "mssql_provider_stats" "current" {}
+
+# Import IDs of the app_readers role of the complete example
+data "mssql_database_imports" "app_readers" {
+  database_name  = "application_db"
+  principal_name = "app_readers"
+}
+
+# The app_readers role of the complete example with its members and permissions
+data "mssql_database_role" "app_readers" {
+  database_name       = "application_db"
+  name                = "app_readers"
+  include_members     = true
+  include_permissions = true
+}
+
+# Access of app_user of the complete example, inherited through app_readers and public
+data "mssql_database_access_report" "app_user" {
+  database_name  = "application_db"
+  principal_name = "app_user"
+}
+
+# Import IDs of the server permissions of public and the master permissions of guest
+data "mssql_all_permissions" "public" {
+  principal_name = "public"
+}
+
+data "mssql_all_permissions" "guest" {
+  principal_name    = "guest"
+  include_databases = true
+  database_names    = ["master"]
+}
+
+# Role memberships of sa, which is dbo in master
+data "mssql_principal_memberships" "sa" {
+  principal_name = "sa"
+}
+
+# Check effective permissions of the provider's login and of the guest user
+data "mssql_has_permission" "control_server" {
+  securable_class = "SERVER"
+  permission      = "CONTROL SERVER"
+}
+
+data "mssql_has_permission" "guest_alter_master" {
+  database_name   = "master"
+  principal_name  = "guest"
+  securable_class = "DATABASE"
+  permission      = "ALTER"
+}
+
+# List the schemas owned by sys
+data "mssql_schemas" "sys_owned" {
+  database_name = "master"
+  owner_name    = "sys"
+}
+
+# List the tables in the dbo schema of the complete example's database
+data "mssql_schema_objects" "app_tables" {
+  database_name = "application_db"
+  schema_name   = "dbo"
+  type          = "U"
+}
+
 output "databases" {
   value = [for db in data.mssql_databases.all.databases : db.name]
 }
@@ -44,6 +131,18 @@ output "sql_version" {
   value = data.mssql_query.version.result[0].values["version"]
 }
 
+output "read_only_query" {
+  value = data.mssql_query.read_only.result[0].values["updateability"]
+}
+
+output "query_across_databases" {
+  value = join(",", [for row in data.mssql_query.across_databases.result : "${row.database_name}=${row.values["current_db"]}"])
+}
+
+output "app_user_access" {
+  value = join(",", [for perm in data.mssql_database_access_report.app_user.permissions : "${perm.class}:${perm.permission}:${coalesce(perm.role_name, "-")}"])
+}
+
 output "login_count" {
   value = length(data.mssql_sql_logins.all.logins)
 }
@@ -51,3 +150,58 @@ output "login_count" {
 output "server_roles" {
   value = [for role in data.mssql_server_roles.all.roles : role.name]
 }
+
+output "sysadmin_create_date" {
+  value = [for role in data.mssql_server_roles.all.roles : role.create_date if role.name == "sysadmin"][0]
+}
+
+output "principal_sids" {
+  value = "${data.mssql_sql_login.sa.sid},${[for role in data.mssql_server_roles.all.roles : role.sid if role.name == "sysadmin"][0]}"
+}
+
+output "sys_owned_schemas" {
+  value = join(",", [for schema in data.mssql_schemas.sys_owned.schemas : schema.name])
+}
+
+output "app_tables" {
+  value = join(",", [for object in data.mssql_schema_objects.app_tables.objects : "${object.name}:${object.type_desc}"])
+}
+
+output "sa_memberships" {
+  value = join(",", concat(data.mssql_principal_memberships.sa.server_roles, data.mssql_principal_memberships.sa.database_roles["master"]))
+}
+
+output "sa_password_state" {
+  value = "locked=${data.mssql_sql_login.sa.is_locked},bad_password_count=${data.mssql_sql_login.sa.bad_password_count},password_last_set_time=${data.mssql_sql_login.sa.password_last_set_time}"
+}
+
+output "tsql_endpoint_state" {
+  value = [for endpoint in data.mssql_endpoints.all.endpoints : endpoint.state_desc if endpoint.name == "TSQL Default TCP"][0]
+}
+
+output "server_pool_open_connections" {
+  value = [for pool in data.mssql_provider_stats.current.pools : pool.open_connections if pool.database_name == ""][0]
+}
+
+output "provider_stats_id" {
+  value = data.mssql_provider_stats.current.id
+}
+
+output "app_readers_imports" {
+  value = join(",", [for i in data.mssql_database_imports.app_readers.imports : "${i.resource_type}:${i.import_id}"])
+}
+
+output "app_readers_access" {
+  value = "members=${join(",", data.mssql_database_role.app_readers.members)};permissions=${join(",", [for p in data.mssql_database_role.app_readers.permissions : "${p.state}:${p.permission}"])}"
+}
+
+output "all_permissions_import_ids" {
+  value = join(",", concat(
+    [for p in data.mssql_all_permissions.public.permissions : p.import_id],
+    [for p in data.mssql_all_permissions.guest.permissions : "${p.resource_type}:${p.import_id}"],
+  ))
+}
+
+output "permission_checks" {
+  value = "${data.mssql_has_permission.control_server.has_permission},${data.mssql_has_permission.guest_alter_master.has_permission}"
+}
@@ -0,0 +1,53 @@
+terraform {
+  required_providers {
+    mssql = {
+      source  = "muecahit94/mssql"
+      version = "~> 1.0"
+    }
+  }
+}
+
+provider "mssql" {
+  hostname = "localhost"
+  port     = 1433
+
+  sql_auth {
+    username = "sa"
+    password = "P@ssw0rd123!"
+  }
+}
+
+# The fixed securityadmin role, managed after
+# `terraform import mssql_server_role.securityadmin securityadmin`
+resource "mssql_server_role" "securityadmin" {
+  name = "securityadmin"
+}
+
+resource "mssql_sql_login" "security_operator" {
+  name     = "security_operator"
+  password = "SecurityOp@123!"
+}
+
+resource "mssql_server_role_member" "security_operator" {
+  role_name   = mssql_server_role.securityadmin.name
+  member_name = mssql_sql_login.security_operator.name
+}
+
+# User-defined server roles nested in a fixed and in a user-defined role
+resource "mssql_server_role" "security_operators" {
+  name = "security_operators"
+}
+
+resource "mssql_server_role" "security_auditors" {
+  name = "security_auditors"
+}
+
+resource "mssql_server_role_member" "security_operators" {
+  role_name   = mssql_server_role.securityadmin.name
+  member_name = mssql_server_role.security_operators.name
+}
+
+resource "mssql_server_role_member" "security_auditors" {
+  role_name   = mssql_server_role.security_operators.name
+  member_name = mssql_server_role.security_auditors.name
+}
@@ -20,13 +20,31 @@ provider "mssql" {
 
 # Create a database
 resource "mssql_database" "example" {
-  name = "example_db"
+  name              = "example_db"
+  filegroups        = ["example_data"]
+  default_filegroup = "example_data"
+  auto_shrink       = true
+  auto_close        = false
+}
+
+variable "login_default_database" {
+  description = "The default database of the example login"
+  type        = string
+  default     = "master"
+}
+
+variable "login_default_language" {
+  description = "The default language of the example login"
+  type        = string
+  default     = null
 }
 
 # Create a login
 resource "mssql_sql_login" "example" {
-  name     = "example_login"
-  password = "SecurePassword123!"
+  name             = "example_login"
+  password         = "SecurePassword123!"
+  default_database = var.login_default_database
+  default_language = var.login_default_language
 }
 
 # Create a user in the database
@@ -0,0 +1,32 @@
+terraform {
+  required_providers {
+    mssql = {
+      source  = "muecahit94/mssql"
+      version = "~> 1.0"
+    }
+  }
+}
+
+provider "mssql" {
+  hostname = "localhost"
+  port     = 1433
+
+  sql_auth {
+    username = "sa"
+    password = "P@ssw0rd123!"
+  }
+}
+
+variable "sa_disabled" {
+  description = "Whether to disable the sa login"
+  type        = bool
+  default     = false
+}
+
+# The built-in sa login (principal_id 1), managed after
+# `terraform import mssql_sql_login.sa sa`
+resource "mssql_sql_login" "sa" {
+  name        = "sa"
+  password    = "P@ssw0rd123!"
+  is_disabled = var.sa_disabled
+}
@@ -0,0 +1,562 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Actions of a job step on success or failure, as stored in
+// msdb.dbo.sysjobsteps.on_success_action and on_fail_action.
+const (
+	AgentStepActionQuitWithSuccess = "QUIT_WITH_SUCCESS"
+	AgentStepActionQuitWithFailure = "QUIT_WITH_FAILURE"
+	AgentStepActionGoToNextStep    = "GO_TO_NEXT_STEP"
+	AgentStepActionGoToStep        = "GO_TO_STEP"
+)
+
+var agentStepActions = map[string]int{
+	AgentStepActionQuitWithSuccess: 1,
+	AgentStepActionQuitWithFailure: 2,
+	AgentStepActionGoToNextStep:    3,
+	AgentStepActionGoToStep:        4,
+}
+
+// AgentJob represents a SQL Server Agent job from msdb.dbo.sysjobs.
+type AgentJob struct {
+	JobID          string
+	Name           string
+	Enabled        bool
+	OwnerLoginName string
+	Steps          []AgentJobStep
+}
+
+// AgentJobStep represents a Transact-SQL step of an Agent job.
+type AgentJobStep struct {
+	Name            string
+	Command         string
+	DatabaseName    string
+	OnSuccessAction string
+	OnFailAction    string
+}
+
+// CheckAgentAvailable returns ErrAgentUnavailable if the server has no SQL
+// Server Agent, which is the case in Azure SQL Database and Synapse.
+func (c *Client) CheckAgentAvailable(ctx context.Context) error {
+	query := `
+		SELECT
+			CAST(SERVERPROPERTY('EngineEdition') AS INT),
+			CASE WHEN OBJECT_ID('msdb.dbo.sysjobs') IS NULL THEN 0 ELSE 1 END`
+
+	var engineEdition int
+	var hasMsdb bool
+	if err := c.QueryRowContext(ctx, query).Scan(&engineEdition, &hasMsdb); err != nil {
+		return fmt.Errorf("failed to check for SQL Server Agent: %w", err)
+	}
+	// EngineEdition 5 = Azure SQL Database, 6 = Azure Synapse
+	if engineEdition == 5 || engineEdition == 6 {
+		return fmt.Errorf("%w: Agent jobs are not supported in Azure SQL Database, use elastic jobs instead", ErrAgentUnavailable)
+	}
+	if !hasMsdb {
+		return fmt.Errorf("%w: msdb.dbo.sysjobs not found", ErrAgentUnavailable)
+	}
+
+	return nil
+}
+
+// GetAgentJob retrieves an Agent job and its steps by job ID.
+func (c *Client) GetAgentJob(ctx context.Context, jobID string) (*AgentJob, error) {
+	return c.getAgentJob(ctx, "j.job_id = @p1", jobID)
+}
+
+// GetAgentJobByName retrieves an Agent job and its steps by name.
+func (c *Client) GetAgentJobByName(ctx context.Context, name string) (*AgentJob, error) {
+	return c.getAgentJob(ctx, "j.name = @p1", name)
+}
+
+func (c *Client) getAgentJob(ctx context.Context, where string, arg string) (*AgentJob, error) {
+	query := fmt.Sprintf(`
+		SELECT CAST(j.job_id AS NVARCHAR(36)), j.name, j.enabled, ISNULL(SUSER_SNAME(j.owner_sid), '')
+		FROM msdb.dbo.sysjobs j
+		WHERE %s`, where)
+	row := c.QueryRowContext(ctx, query, arg)
+
+	var job AgentJob
+	err := row.Scan(&job.JobID, &job.Name, &job.Enabled, &job.OwnerLoginName)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent job: %w", err)
+	}
+
+	steps, err := c.listAgentJobSteps(ctx, job.JobID)
+	if err != nil {
+		return nil, err
+	}
+	job.Steps = steps
+
+	return &job, nil
+}
+
+func (c *Client) listAgentJobSteps(ctx context.Context, jobID string) ([]AgentJobStep, error) {
+	query := `
+		SELECT step_name, ISNULL(command, ''), ISNULL(database_name, ''), on_success_action, on_fail_action
+		FROM msdb.dbo.sysjobsteps
+		WHERE job_id = @p1
+		ORDER BY step_id`
+	rows, err := c.QueryContext(ctx, query, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agent job steps: %w", err)
+	}
+	defer rows.Close()
+
+	var steps []AgentJobStep
+	for rows.Next() {
+		var step AgentJobStep
+		var onSuccess, onFail int
+		if err := rows.Scan(&step.Name, &step.Command, &step.DatabaseName, &onSuccess, &onFail); err != nil {
+			return nil, fmt.Errorf("failed to scan agent job step: %w", err)
+		}
+		step.OnSuccessAction = mapKeyOf(agentStepActions, onSuccess)
+		step.OnFailAction = mapKeyOf(agentStepActions, onFail)
+		steps = append(steps, step)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list agent job steps: %w", err)
+	}
+
+	return steps, nil
+}
+
+// CreateAgentJobOptions contains options for creating an Agent job.
+type CreateAgentJobOptions struct {
+	Name           string
+	Enabled        bool
+	OwnerLoginName string // defaults to the connected login
+	Steps          []AgentJobStep
+}
+
+// CreateAgentJob creates an Agent job on the local server with sp_add_job and
+// sp_add_jobserver, and adds its steps. If adding the steps fails, the job is
+// returned along with the error, since it exists from then on.
+func (c *Client) CreateAgentJob(ctx context.Context, opts CreateAgentJobOptions) (*AgentJob, error) {
+	if err := c.CheckAgentAvailable(ctx); err != nil {
+		return nil, err
+	}
+
+	var owner interface{}
+	if opts.OwnerLoginName != "" {
+		owner = opts.OwnerLoginName
+	}
+	query := `
+		DECLARE @job_id UNIQUEIDENTIFIER;
+		EXEC msdb.dbo.sp_add_job @job_name = @p1, @enabled = @p2, @owner_login_name = @p3, @job_id = @job_id OUTPUT;
+		EXEC msdb.dbo.sp_add_jobserver @job_id = @job_id;
+		SELECT CAST(@job_id AS NVARCHAR(36));`
+
+	var jobID string
+	if err := c.QueryRowContext(ctx, query, opts.Name, opts.Enabled, owner).Scan(&jobID); err != nil {
+		return nil, fmt.Errorf("failed to create agent job: %w", wrapSQLError(err))
+	}
+
+	if err := c.setAgentJobSteps(ctx, jobID, opts.Steps); err != nil {
+		return &AgentJob{JobID: jobID, Name: opts.Name}, err
+	}
+
+	return c.GetAgentJob(ctx, jobID)
+}
+
+// UpdateAgentJobOptions contains options for updating an Agent job. Steps are
+// only replaced if ReplaceSteps is set.
+type UpdateAgentJobOptions struct {
+	JobID          string
+	Name           string
+	Enabled        bool
+	OwnerLoginName string // unchanged if empty
+	ReplaceSteps   bool
+	Steps          []AgentJobStep
+}
+
+// UpdateAgentJob renames, enables or disables and reassigns an Agent job with
+// sp_update_job, and replaces its steps if requested.
+func (c *Client) UpdateAgentJob(ctx context.Context, opts UpdateAgentJobOptions) (*AgentJob, error) {
+	var owner interface{}
+	if opts.OwnerLoginName != "" {
+		owner = opts.OwnerLoginName
+	}
+	query := `EXEC msdb.dbo.sp_update_job @job_id = @p1, @new_name = @p2, @enabled = @p3, @owner_login_name = @p4`
+	if _, err := c.ExecContext(ctx, query, opts.JobID, opts.Name, opts.Enabled, owner); err != nil {
+		return nil, fmt.Errorf("failed to update agent job: %w", err)
+	}
+
+	if opts.ReplaceSteps {
+		if err := c.setAgentJobSteps(ctx, opts.JobID, opts.Steps); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.GetAgentJob(ctx, opts.JobID)
+}
+
+// setAgentJobSteps replaces the steps of a job. Steps refer to each other by
+// position, so they are all dropped and added again in order.
+func (c *Client) setAgentJobSteps(ctx context.Context, jobID string, steps []AgentJobStep) error {
+	// step_id 0 deletes all steps of the job
+	if _, err := c.ExecContext(ctx, "EXEC msdb.dbo.sp_delete_jobstep @job_id = @p1, @step_id = 0", jobID); err != nil {
+		return fmt.Errorf("failed to delete agent job steps: %w", err)
+	}
+
+	query := `
+		EXEC msdb.dbo.sp_add_jobstep
+			@job_id = @p1,
+			@step_id = @p2,
+			@step_name = @p3,
+			@subsystem = N'TSQL',
+			@command = @p4,
+			@database_name = @p5,
+			@on_success_action = @p6,
+			@on_fail_action = @p7`
+	for i, step := range steps {
+		onSuccess, ok := agentStepActions[strings.ToUpper(step.OnSuccessAction)]
+		if !ok || onSuccess == agentStepActions[AgentStepActionGoToStep] {
+			return fmt.Errorf("unsupported on_success action '%s' for step '%s'", step.OnSuccessAction, step.Name)
+		}
+		onFail, ok := agentStepActions[strings.ToUpper(step.OnFailAction)]
+		if !ok || onFail == agentStepActions[AgentStepActionGoToStep] {
+			return fmt.Errorf("unsupported on_fail action '%s' for step '%s'", step.OnFailAction, step.Name)
+		}
+		if _, err := c.ExecContext(ctx, query, jobID, i+1, step.Name, step.Command, step.DatabaseName, onSuccess, onFail); err != nil {
+			return fmt.Errorf("failed to add agent job step '%s': %w", step.Name, err)
+		}
+	}
+
+	if len(steps) > 0 {
+		if _, err := c.ExecContext(ctx, "EXEC msdb.dbo.sp_update_job @job_id = @p1, @start_step_id = 1", jobID); err != nil {
+			return fmt.Errorf("failed to set agent job start step: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// DeleteAgentJob deletes an Agent job with its steps and history.
+func (c *Client) DeleteAgentJob(ctx context.Context, jobID string) error {
+	if _, err := c.ExecContext(ctx, "EXEC msdb.dbo.sp_delete_job @job_id = @p1", jobID); err != nil {
+		return fmt.Errorf("failed to delete agent job: %w", err)
+	}
+
+	return nil
+}
+
+// Frequencies of an Agent schedule, as stored in msdb.dbo.sysschedules.freq_type.
+const (
+	AgentScheduleFrequencyDaily  = "DAILY"
+	AgentScheduleFrequencyWeekly = "WEEKLY"
+)
+
+var agentScheduleFrequencies = map[string]int{
+	"ONCE":                       1,
+	AgentScheduleFrequencyDaily:  4,
+	AgentScheduleFrequencyWeekly: 8,
+	"MONTHLY":                    16,
+	"MONTHLY_RELATIVE":           32,
+	"AGENT_START":                64,
+	"IDLE":                       128,
+}
+
+// Units of the interval at which a schedule repeats within a day, as stored
+// in msdb.dbo.sysschedules.freq_subday_type. ONCE runs at the start time only.
+const (
+	AgentScheduleSubdayOnce    = "ONCE"
+	AgentScheduleSubdaySeconds = "SECONDS"
+	AgentScheduleSubdayMinutes = "MINUTES"
+	AgentScheduleSubdayHours   = "HOURS"
+)
+
+var agentScheduleSubdayUnits = map[string]int{
+	AgentScheduleSubdayOnce:    1,
+	AgentScheduleSubdaySeconds: 2,
+	AgentScheduleSubdayMinutes: 4,
+	AgentScheduleSubdayHours:   8,
+}
+
+// agentScheduleWeekdays maps the days of a weekly schedule to the bits of
+// msdb.dbo.sysschedules.freq_interval, in the order of the week.
+var agentScheduleWeekdays = []struct {
+	name string
+	bit  int
+}{
+	{"SUNDAY", 1},
+	{"MONDAY", 2},
+	{"TUESDAY", 4},
+	{"WEDNESDAY", 8},
+	{"THURSDAY", 16},
+	{"FRIDAY", 32},
+	{"SATURDAY", 64},
+}
+
+// AgentSchedule represents a SQL Server Agent schedule from
+// msdb.dbo.sysschedules. Interval is the number of days between runs of a
+// daily schedule and the number of weeks between runs of a weekly schedule.
+type AgentSchedule struct {
+	ScheduleID     int
+	Name           string
+	Enabled        bool
+	Frequency      string
+	Interval       int
+	Weekdays       []string
+	SubdayUnit     string
+	SubdayInterval int
+	StartTime      string // HH:MM:SS
+	EndTime        string // HH:MM:SS
+}
+
+// GetAgentSchedule retrieves an Agent schedule by ID.
+func (c *Client) GetAgentSchedule(ctx context.Context, scheduleID int) (*AgentSchedule, error) {
+	schedules, err := c.listAgentSchedules(ctx, "schedule_id = @p1", scheduleID)
+	if err != nil || len(schedules) == 0 {
+		return nil, err
+	}
+	return &schedules[0], nil
+}
+
+// GetAgentScheduleByName retrieves an Agent schedule by name. Schedule names
+// are not unique in msdb, so an error is returned if several schedules have
+// the name.
+func (c *Client) GetAgentScheduleByName(ctx context.Context, name string) (*AgentSchedule, error) {
+	schedules, err := c.listAgentSchedules(ctx, "name = @p1", name)
+	if err != nil || len(schedules) == 0 {
+		return nil, err
+	}
+	if len(schedules) > 1 {
+		return nil, fmt.Errorf("%d agent schedules are named '%s'", len(schedules), name)
+	}
+	return &schedules[0], nil
+}
+
+func (c *Client) listAgentSchedules(ctx context.Context, where string, arg interface{}) ([]AgentSchedule, error) {
+	query := fmt.Sprintf(`
+		SELECT schedule_id, name, enabled, freq_type, freq_interval, freq_subday_type, freq_subday_interval,
+			freq_recurrence_factor, active_start_time, active_end_time
+		FROM msdb.dbo.sysschedules
+		WHERE %s`, where)
+	rows, err := c.QueryContext(ctx, query, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent schedule: %w", err)
+	}
+	defer rows.Close()
+
+	var schedules []AgentSchedule
+	for rows.Next() {
+		var schedule AgentSchedule
+		var freqType, freqInterval, subdayType, recurrenceFactor, startTime, endTime int
+		if err := rows.Scan(&schedule.ScheduleID, &schedule.Name, &schedule.Enabled, &freqType, &freqInterval,
+			&subdayType, &schedule.SubdayInterval, &recurrenceFactor, &startTime, &endTime); err != nil {
+			return nil, fmt.Errorf("failed to scan agent schedule: %w", err)
+		}
+
+		schedule.Frequency = mapKeyOf(agentScheduleFrequencies, freqType)
+		switch freqType {
+		case agentScheduleFrequencies[AgentScheduleFrequencyDaily]:
+			schedule.Interval = freqInterval
+		case agentScheduleFrequencies[AgentScheduleFrequencyWeekly]:
+			schedule.Interval = recurrenceFactor
+			for _, day := range agentScheduleWeekdays {
+				if freqInterval&day.bit != 0 {
+					schedule.Weekdays = append(schedule.Weekdays, day.name)
+				}
+			}
+		}
+		schedule.SubdayUnit = mapKeyOf(agentScheduleSubdayUnits, subdayType)
+		schedule.StartTime = agentTimeString(startTime)
+		schedule.EndTime = agentTimeString(endTime)
+		schedules = append(schedules, schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get agent schedule: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// CreateAgentSchedule creates an Agent schedule with sp_add_schedule.
+func (c *Client) CreateAgentSchedule(ctx context.Context, schedule AgentSchedule) (*AgentSchedule, error) {
+	if err := c.CheckAgentAvailable(ctx); err != nil {
+		return nil, err
+	}
+
+	args, err := agentScheduleArgs(schedule)
+	if err != nil {
+		return nil, err
+	}
+	query := `
+		DECLARE @schedule_id INT;
+		EXEC msdb.dbo.sp_add_schedule
+			@schedule_name = @p1,
+			@enabled = @p2,
+			@freq_type = @p3,
+			@freq_interval = @p4,
+			@freq_subday_type = @p5,
+			@freq_subday_interval = @p6,
+			@freq_recurrence_factor = @p7,
+			@active_start_time = @p8,
+			@active_end_time = @p9,
+			@schedule_id = @schedule_id OUTPUT;
+		SELECT @schedule_id;`
+
+	var scheduleID int
+	if err := c.QueryRowContext(ctx, query, args...).Scan(&scheduleID); err != nil {
+		return nil, fmt.Errorf("failed to create agent schedule: %w", wrapSQLError(err))
+	}
+
+	return c.GetAgentSchedule(ctx, scheduleID)
+}
+
+// UpdateAgentSchedule updates an Agent schedule with sp_update_schedule. The
+// jobs the schedule is attached to keep it.
+func (c *Client) UpdateAgentSchedule(ctx context.Context, schedule AgentSchedule) (*AgentSchedule, error) {
+	args, err := agentScheduleArgs(schedule)
+	if err != nil {
+		return nil, err
+	}
+	query := `
+		EXEC msdb.dbo.sp_update_schedule
+			@schedule_id = @p10,
+			@new_name = @p1,
+			@enabled = @p2,
+			@freq_type = @p3,
+			@freq_interval = @p4,
+			@freq_subday_type = @p5,
+			@freq_subday_interval = @p6,
+			@freq_recurrence_factor = @p7,
+			@active_start_time = @p8,
+			@active_end_time = @p9`
+	if _, err := c.ExecContext(ctx, query, append(args, schedule.ScheduleID)...); err != nil {
+		return nil, fmt.Errorf("failed to update agent schedule: %w", err)
+	}
+
+	return c.GetAgentSchedule(ctx, schedule.ScheduleID)
+}
+
+// agentScheduleArgs converts a schedule to the parameters of sp_add_schedule
+// and sp_update_schedule, in order.
+func agentScheduleArgs(schedule AgentSchedule) ([]interface{}, error) {
+	var freqType, freqInterval, recurrenceFactor int
+	switch strings.ToUpper(schedule.Frequency) {
+	case AgentScheduleFrequencyDaily:
+		freqType = agentScheduleFrequencies[AgentScheduleFrequencyDaily]
+		freqInterval = schedule.Interval
+	case AgentScheduleFrequencyWeekly:
+		freqType = agentScheduleFrequencies[AgentScheduleFrequencyWeekly]
+		recurrenceFactor = schedule.Interval
+		for _, name := range schedule.Weekdays {
+			found := false
+			for _, day := range agentScheduleWeekdays {
+				if strings.EqualFold(name, day.name) {
+					freqInterval |= day.bit
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("unknown weekday '%s'", name)
+			}
+		}
+		if freqInterval == 0 {
+			return nil, fmt.Errorf("a weekly schedule needs at least one weekday")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported schedule frequency '%s', must be DAILY or WEEKLY", schedule.Frequency)
+	}
+
+	subdayType, ok := agentScheduleSubdayUnits[strings.ToUpper(schedule.SubdayUnit)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported subday unit '%s', must be ONCE, SECONDS, MINUTES or HOURS", schedule.SubdayUnit)
+	}
+	startTime, err := agentTimeInt(schedule.StartTime)
+	if err != nil {
+		return nil, err
+	}
+	endTime, err := agentTimeInt(schedule.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return []interface{}{
+		schedule.Name, schedule.Enabled, freqType, freqInterval, subdayType, schedule.SubdayInterval,
+		recurrenceFactor, startTime, endTime,
+	}, nil
+}
+
+// DeleteAgentSchedule deletes an Agent schedule. It fails while the schedule
+// is attached to a job.
+func (c *Client) DeleteAgentSchedule(ctx context.Context, scheduleID int) error {
+	if _, err := c.ExecContext(ctx, "EXEC msdb.dbo.sp_delete_schedule @schedule_id = @p1", scheduleID); err != nil {
+		return fmt.Errorf("failed to delete agent schedule: %w", err)
+	}
+
+	return nil
+}
+
+// AgentJobHasSchedule reports whether a schedule is attached to a job.
+func (c *Client) AgentJobHasSchedule(ctx context.Context, jobID string, scheduleID int) (bool, error) {
+	query := `SELECT 1 FROM msdb.dbo.sysjobschedules WHERE job_id = @p1 AND schedule_id = @p2`
+
+	var found int
+	err := c.QueryRowContext(ctx, query, jobID, scheduleID).Scan(&found)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get agent job schedule: %w", err)
+	}
+
+	return true, nil
+}
+
+// AttachAgentSchedule attaches a schedule to a job with sp_attach_schedule.
+func (c *Client) AttachAgentSchedule(ctx context.Context, jobID string, scheduleID int) error {
+	if _, err := c.ExecContext(ctx, "EXEC msdb.dbo.sp_attach_schedule @job_id = @p1, @schedule_id = @p2", jobID, scheduleID); err != nil {
+		return fmt.Errorf("failed to attach agent schedule: %w", err)
+	}
+
+	return nil
+}
+
+// DetachAgentSchedule detaches a schedule from a job, keeping the schedule.
+func (c *Client) DetachAgentSchedule(ctx context.Context, jobID string, scheduleID int) error {
+	if _, err := c.ExecContext(ctx, "EXEC msdb.dbo.sp_detach_schedule @job_id = @p1, @schedule_id = @p2, @delete_unused_schedule = 0", jobID, scheduleID); err != nil {
+		return fmt.Errorf("failed to detach agent schedule: %w", err)
+	}
+
+	return nil
+}
+
+// agentTimeString formats a time stored as HHMMSS in msdb as HH:MM:SS.
+func agentTimeString(t int) string {
+	return fmt.Sprintf("%02d:%02d:%02d", t/10000, t/100%100, t%100)
+}
+
+// agentTimeInt parses a time given as HH:MM:SS into the HHMMSS form of msdb.
+func agentTimeInt(s string) (int, error) {
+	var h, m, sec int
+	if _, err := fmt.Sscanf(s, "%d:%d:%d", &h, &m, &sec); err != nil || h > 23 || m > 59 || sec > 59 || h < 0 || m < 0 || sec < 0 {
+		return 0, fmt.Errorf("invalid time '%s', must be HH:MM:SS", s)
+	}
+	return h*10000 + m*100 + sec, nil
+}
+
+// mapKeyOf returns the name of a value in a map of names to msdb codes, or
+// the code itself if it has no name.
+func mapKeyOf(names map[string]int, value int) string {
+	for name, v := range names {
+		if v == value {
+			return name
+		}
+	}
+	return fmt.Sprintf("%d", value)
+}
@@ -5,25 +5,83 @@
 package mssql
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/pem"
 	"fmt"
 	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	mssqldb "github.com/microsoft/go-mssqldb"
+	"github.com/microsoft/go-mssqldb/msdsn"
 )
 
+// serverDatabase is the database server-scoped statements run in.
+const serverDatabase = "master"
+
 // Client represents a connection to a SQL Server instance.
 type Client struct {
+	// db is the server-level connection pool. Its connections are opened in
+	// master and switched back to master whenever they are reused, so
+	// server-scoped statements such as CREATE LOGIN always run in master, even
+	// on a connection that was previously switched to another database with USE.
 	db       *sql.DB
 	hostname string
 	port     int
 	config   *Config // Store config for creating database-specific connections
+
+	// azureTokens provides the Azure AD tokens of all connection pools with
+	// Azure AD authentication, so that the credential is created and a token
+	// is requested only once rather than per pool or connection.
+	azureTokens *azureTokenSource
+
+	// databases holds one connection pool per database and application
+	// intent. Every connection in such a pool is opened with the database as
+	// its initial catalog, so no USE statement is needed. At most
+	// maxDatabasePools are kept; see databaseConnection.
+	databasesMu sync.Mutex
+	databases   map[databaseKey]*databasePool
+
+	// languages caches the languages of the server, which don't change
+	// while the provider runs.
+	languagesMu sync.Mutex
+	languages   []Language
+
+	// caseSensitive is set if the server collation compares names
+	// case-sensitively, e.g. Latin1_General_CS_AS or a binary collation.
+	caseSensitive bool
+}
+
+// databaseKey identifies a database-scoped connection pool. The name is the
+// NameKey of the database name, so that spellings the server considers the
+// same database share a pool.
+type databaseKey struct {
+	name     string
+	readOnly bool
+}
+
+// databasePool is a cached database-scoped connection pool.
+type databasePool struct {
+	db *sql.DB
+	// databaseName is the spelling the pool was opened with.
+	databaseName string
+	lastUsed     time.Time
 }
 
 // Config holds the configuration for connecting to SQL Server.
@@ -31,6 +89,41 @@ type Config struct {
 	Hostname string
 	Port     int
 
+	// ServerName replaces Hostname in resource IDs, e.g. the logical server
+	// name when connecting through a load balancer or listener whose host
+	// differs between environments. Defaults to Hostname.
+	ServerName string
+
+	// FailoverPartner is the host of the database mirroring partner, which is
+	// connected to on the same port when Hostname cannot be reached.
+	FailoverPartner string
+	// MultiSubnetFailover connects to all IP addresses of an availability group
+	// listener in parallel. The driver enables it if it is nil.
+	MultiSubnetFailover *bool
+
+	// Encrypt encrypts the whole connection instead of only the login. The
+	// driver default applies if it is nil.
+	Encrypt *bool
+	// TrustServerCertificate accepts the server certificate without
+	// validating it. If it is nil, the certificate is trusted unless
+	// Certificate or CertificateThumbprint is set.
+	TrustServerCertificate *bool
+	// Certificate is the path to a .pem or .der file with the certificate the
+	// server certificate is validated against, e.g. a private CA.
+	Certificate string
+	// CertificateThumbprint pins the server certificate by its SHA-1 or
+	// SHA-256 thumbprint as a hex string. Only a server presenting exactly
+	// this certificate is accepted; the chain is not validated.
+	CertificateThumbprint string
+
+	// ValidateConnection pings a pooled connection before each operation, so
+	// that connections broken while idle are replaced before they are used.
+	ValidateConnection bool
+
+	// DefaultWithGrantOption is the with_grant_option applied to permission
+	// resources that leave it unset.
+	DefaultWithGrantOption bool
+
 	// SQL Authentication
 	SQLAuth *SQLAuthConfig
 
@@ -49,8 +142,57 @@ type AzureAuthConfig struct {
 	ClientID     string
 	ClientSecret string
 	TenantID     string
+	Environment  string // One of the AzureEnvironment constants, defaults to AzureEnvironmentPublic
+
+	// OIDCToken or OIDCTokenFile authenticate the client ID with workload
+	// identity federation, e.g. with the ID token of a CI pipeline. The file is
+	// read again whenever a new Azure AD token is needed, so it may be rotated.
+	OIDCToken     string
+	OIDCTokenFile string
+
+	// TokenRefreshMargin is how long before its expiry the Azure AD token is
+	// replaced by a new one. Defaults to DefaultTokenRefreshMargin.
+	TokenRefreshMargin time.Duration
+}
+
+// Azure clouds that Azure AD authentication can be used with. The names match
+// the ARM_ENVIRONMENT values of the azurerm provider.
+const (
+	AzureEnvironmentPublic       = "public"
+	AzureEnvironmentUSGovernment = "usgovernment"
+	AzureEnvironmentChina        = "china"
+)
+
+// azureEnvironment holds the Azure AD authority and the Azure SQL token scope
+// of an Azure cloud.
+type azureEnvironment struct {
+	cloud    cloud.Configuration
+	sqlScope string
 }
 
+var azureEnvironments = map[string]azureEnvironment{
+	AzureEnvironmentPublic:       {cloud: cloud.AzurePublic, sqlScope: "https://database.windows.net/.default"},
+	AzureEnvironmentUSGovernment: {cloud: cloud.AzureGovernment, sqlScope: "https://database.usgovcloudapi.net/.default"},
+	AzureEnvironmentChina:        {cloud: cloud.AzureChina, sqlScope: "https://database.chinacloudapi.cn/.default"},
+}
+
+// IsAzureEnvironment reports whether name is a supported Azure cloud.
+func IsAzureEnvironment(name string) bool {
+	_, ok := azureEnvironments[strings.ToLower(name)]
+	return ok
+}
+
+// connMaxIdleTime closes pooled connections that have been idle for longer,
+// since firewalls and load balancers, e.g. of Azure SQL, silently drop idle
+// connections and the next operation on them would fail.
+const connMaxIdleTime = 5 * time.Minute
+
+// maxDatabasePools bounds the number of cached database-scoped connection
+// pools. Data sources that read every database of the server would otherwise
+// leave a pool with idle connections per database open until the provider
+// exits.
+const maxDatabasePools = 32
+
 // NewClient creates a new SQL Server client with the given configuration.
 func NewClient(ctx context.Context, cfg *Config) (*Client, error) {
 	if cfg.Hostname == "" {
@@ -69,10 +211,14 @@ func NewClient(ctx context.Context, cfg *Config) (*Client, error) {
 	}
 
 	var db *sql.DB
+	var azureTokens *azureTokenSource
 	var err error
 
 	if cfg.AzureAuth != nil {
-		db, err = connectWithAzureAuth(ctx, cfg)
+		azureTokens, err = newAzureTokenSource(cfg.AzureAuth)
+		if err == nil {
+			db, err = connectWithAzureAuth(ctx, cfg, azureTokens)
+		}
 	} else if cfg.SQLAuth != nil {
 		db, err = connectWithSQLAuth(cfg)
 	} else {
@@ -82,6 +228,7 @@ func NewClient(ctx context.Context, cfg *Config) (*Client, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to SQL Server: %w", err)
 	}
+	db.SetConnMaxIdleTime(connMaxIdleTime)
 
 	// Verify connection
 	if err := db.PingContext(ctx); err != nil {
@@ -89,18 +236,155 @@ func NewClient(ctx context.Context, cfg *Config) (*Client, error) {
 		return nil, fmt.Errorf("failed to ping SQL Server: %w", err)
 	}
 
+	// Names are compared the way the server compares them
+	var collation string
+	if err := db.QueryRowContext(ctx, "SELECT CAST(SERVERPROPERTY('Collation') AS NVARCHAR(128))").Scan(&collation); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to get server collation: %w", err)
+	}
+
 	return &Client{
-		db:       db,
-		hostname: cfg.Hostname,
-		port:     cfg.Port,
-		config:   cfg,
+		db:            db,
+		hostname:      cfg.Hostname,
+		port:          cfg.Port,
+		config:        cfg,
+		azureTokens:   azureTokens,
+		databases:     make(map[databaseKey]*databasePool),
+		caseSensitive: isCaseSensitiveCollation(collation),
 	}, nil
 }
 
-// connectWithSQLAuth establishes a connection using SQL authentication.
-func connectWithSQLAuth(cfg *Config) (*sql.DB, error) {
+// isCaseSensitiveCollation reports whether a collation compares strings
+// case-sensitively: a CS collation or a binary collation.
+func isCaseSensitiveCollation(collation string) bool {
+	for _, part := range strings.Split(strings.ToUpper(collation), "_") {
+		if part == "CS" || part == "BIN" || part == "BIN2" {
+			return true
+		}
+	}
+	return false
+}
+
+// connectionQuery returns the connection string options shared by all
+// connections of the client.
+func connectionQuery(cfg *Config) url.Values {
 	query := url.Values{}
 	query.Add("app name", "terraform-provider-mssql")
+	if cfg.FailoverPartner != "" {
+		query.Add("failoverpartner", cfg.FailoverPartner)
+	}
+	if cfg.MultiSubnetFailover != nil {
+		query.Add("multisubnetfailover", strconv.FormatBool(*cfg.MultiSubnetFailover))
+	}
+	if cfg.Encrypt != nil {
+		query.Add("encrypt", strconv.FormatBool(*cfg.Encrypt))
+	}
+	if cfg.TrustServerCertificate != nil {
+		query.Add("trustservercertificate", strconv.FormatBool(*cfg.TrustServerCertificate))
+	} else if cfg.Certificate != "" || cfg.CertificateThumbprint != "" {
+		query.Add("trustservercertificate", "false")
+	}
+	if cfg.Certificate != "" {
+		query.Add("certificate", cfg.Certificate)
+	}
+	return query
+}
+
+// parseDSN parses a connection string and pins the server certificate to
+// CertificateThumbprint if it is set.
+func parseDSN(cfg *Config, dsn string) (msdsn.Config, error) {
+	params, err := msdsn.Parse(dsn)
+	if err != nil {
+		return params, err
+	}
+	if cfg.CertificateThumbprint == "" {
+		return params, nil
+	}
+
+	thumbprint, err := ParseCertificateThumbprint(cfg.CertificateThumbprint)
+	if err != nil {
+		return params, err
+	}
+	if params.TLSConfig == nil {
+		return params, fmt.Errorf("certificate_thumbprint requires TLS, but encryption is disabled")
+	}
+	// The pinned certificate replaces the validation of the chain and host name
+	params.TLSConfig.InsecureSkipVerify = true
+	params.TLSConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("server presented no certificate")
+		}
+		var actual []byte
+		if len(thumbprint) == sha1.Size {
+			sum := sha1.Sum(rawCerts[0])
+			actual = sum[:]
+		} else {
+			sum := sha256.Sum256(rawCerts[0])
+			actual = sum[:]
+		}
+		if !bytes.Equal(actual, thumbprint) {
+			return fmt.Errorf("server certificate thumbprint %s does not match the pinned thumbprint", strings.ToUpper(hex.EncodeToString(actual)))
+		}
+		return nil
+	}
+	return params, nil
+}
+
+// ParseCertificateThumbprint parses a SHA-1 or SHA-256 certificate thumbprint
+// given as a hex string. Spaces and colons between the bytes are ignored, as
+// tools print thumbprints in different forms.
+func ParseCertificateThumbprint(thumbprint string) ([]byte, error) {
+	cleaned := strings.NewReplacer(" ", "", ":", "").Replace(thumbprint)
+	decoded, err := hex.DecodeString(cleaned)
+	if err != nil || (len(decoded) != sha1.Size && len(decoded) != sha256.Size) {
+		return nil, fmt.Errorf("certificate thumbprint must be a SHA-1 or SHA-256 hash as a hex string, got: %s", thumbprint)
+	}
+	return decoded, nil
+}
+
+// CheckCertificateFile checks that a file holds a certificate the driver can
+// validate the server certificate against: a .pem file with at least one
+// certificate, or a .der file.
+func CheckCertificateFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read certificate: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".pem":
+		found := false
+		for {
+			var block *pem.Block
+			block, data = pem.Decode(data)
+			if block == nil {
+				break
+			}
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+				return fmt.Errorf("failed to parse certificate in %s: %w", path, err)
+			}
+			found = true
+		}
+		if !found {
+			return fmt.Errorf("no certificate found in %s", path)
+		}
+	case ".der":
+		if _, err := x509.ParseCertificate(data); err != nil {
+			return fmt.Errorf("failed to parse certificate in %s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("certificate must be a .pem or .der file, got: %s", path)
+	}
+	return nil
+}
+
+// connectWithSQLAuth establishes a connection using SQL authentication.
+func connectWithSQLAuth(cfg *Config) (*sql.DB, error) {
+	query := connectionQuery(cfg)
+	query.Add("database", serverDatabase)
 
 	u := &url.URL{
 		Scheme:   "sqlserver",
@@ -109,23 +393,37 @@ func connectWithSQLAuth(cfg *Config) (*sql.DB, error) {
 		RawQuery: query.Encode(),
 	}
 
-	db, err := sql.Open("sqlserver", u.String())
+	params, err := parseDSN(cfg, u.String())
 	if err != nil {
 		return nil, err
 	}
+	connector := mssqldb.NewConnectorConfig(params)
 
-	return db, nil
+	return openDB(connector, serverDatabase), nil
 }
 
-// connectWithAzureAuth establishes a connection using Azure AD authentication.
-func connectWithAzureAuth(ctx context.Context, cfg *Config) (*sql.DB, error) {
-	var cred azcore.TokenCredential
-	var err error
+// openDB opens a connection pool for a database. Connections are reset before
+// they are reused from the pool; SessionInitSQL then switches them back to the
+// database in case a previous operation, e.g. a script, ran USE on them. An
+// empty database name leaves the database of reused connections as is.
+func openDB(connector driver.Connector, databaseName string) *sql.DB {
+	if c, ok := connector.(*mssqldb.Connector); ok && databaseName != "" {
+		c.SessionInitSQL = "USE " + quoteIdentifier(databaseName)
+	}
+	return sql.OpenDB(connector)
+}
 
+// newAzureCredential creates the credential for Azure AD authentication and
+// returns it together with the Azure SQL token scope of the configured cloud.
+// Unset options are read from the ARM_* environment variables.
+func newAzureCredential(cfg *AzureAuthConfig) (azcore.TokenCredential, string, error) {
 	// Check for environment variable override
-	clientID := cfg.AzureAuth.ClientID
-	clientSecret := cfg.AzureAuth.ClientSecret
-	tenantID := cfg.AzureAuth.TenantID
+	clientID := cfg.ClientID
+	clientSecret := cfg.ClientSecret
+	tenantID := cfg.TenantID
+	environment := cfg.Environment
+	oidcToken := cfg.OIDCToken
+	oidcTokenFile := cfg.OIDCTokenFile
 
 	if clientID == "" {
 		clientID = os.Getenv("ARM_CLIENT_ID")
@@ -136,48 +434,169 @@ func connectWithAzureAuth(ctx context.Context, cfg *Config) (*sql.DB, error) {
 	if tenantID == "" {
 		tenantID = os.Getenv("ARM_TENANT_ID")
 	}
+	if environment == "" {
+		environment = os.Getenv("ARM_ENVIRONMENT")
+	}
+	if oidcToken == "" {
+		oidcToken = os.Getenv("ARM_OIDC_TOKEN")
+	}
+	if oidcTokenFile == "" {
+		oidcTokenFile = os.Getenv("ARM_OIDC_TOKEN_FILE_PATH")
+	}
+	if environment == "" {
+		environment = AzureEnvironmentPublic
+	}
+
+	env, ok := azureEnvironments[strings.ToLower(environment)]
+	if !ok {
+		return nil, "", fmt.Errorf("unsupported Azure environment: %s", environment)
+	}
+	clientOptions := azcore.ClientOptions{Cloud: env.cloud}
 
 	if clientID != "" && clientSecret != "" && tenantID != "" {
 		// Use Service Principal authentication
-		cred, err = azidentity.NewClientSecretCredential(tenantID, clientID, clientSecret, nil)
+		cred, err := azidentity.NewClientSecretCredential(tenantID, clientID, clientSecret, &azidentity.ClientSecretCredentialOptions{
+			ClientOptions: clientOptions,
+		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to create client secret credential: %w", err)
+			return nil, "", fmt.Errorf("failed to create client secret credential: %w", err)
 		}
-	} else {
-		// Use default Azure credential chain
-		cred, err = azidentity.NewDefaultAzureCredential(nil)
+		return cred, env.sqlScope, nil
+	}
+
+	if clientID != "" && tenantID != "" && (oidcToken != "" || oidcTokenFile != "") {
+		// Use workload identity federation with the OIDC token as client assertion
+		getAssertion := func(context.Context) (string, error) {
+			if oidcToken != "" {
+				return oidcToken, nil
+			}
+			token, err := os.ReadFile(oidcTokenFile)
+			if err != nil {
+				return "", fmt.Errorf("failed to read OIDC token file: %w", err)
+			}
+			return strings.TrimSpace(string(token)), nil
+		}
+		cred, err := azidentity.NewClientAssertionCredential(tenantID, clientID, getAssertion, &azidentity.ClientAssertionCredentialOptions{
+			ClientOptions: clientOptions,
+		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to create default Azure credential: %w", err)
+			return nil, "", fmt.Errorf("failed to create client assertion credential: %w", err)
 		}
+		return cred, env.sqlScope, nil
 	}
 
-	// Get token for Azure SQL
-	token, err := cred.GetToken(ctx, policy.TokenRequestOptions{
-		Scopes: []string{"https://database.windows.net/.default"},
+	// Use default Azure credential chain
+	cred, err := azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{
+		ClientOptions: clientOptions,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get Azure AD token: %w", err)
+		return nil, "", fmt.Errorf("failed to create default Azure credential: %w", err)
 	}
+	return cred, env.sqlScope, nil
+}
 
-	connector, err := mssqldb.NewAccessTokenConnector(
-		fmt.Sprintf("sqlserver://%s:%d?database=master&app+name=terraform-provider-mssql", cfg.Hostname, cfg.Port),
-		func() (string, error) {
-			return token.Token, nil
-		},
-	)
+// DefaultTokenRefreshMargin is how long before its expiry a cached Azure AD
+// token is replaced by default, so that a connection is not opened with a
+// token that expires while the login is in progress.
+const DefaultTokenRefreshMargin = 5 * time.Minute
+
+// azureTokenSource caches the Azure AD token for Azure SQL and requests a new
+// one from the credential only when the cached token is about to expire. It is
+// shared by all connection pools of a client, since running the credential
+// chain for each of them is slow and can be throttled by Azure AD during
+// large applies.
+type azureTokenSource struct {
+	cred          azcore.TokenCredential
+	scope         string
+	refreshMargin time.Duration
+
+	mu    sync.Mutex
+	token azcore.AccessToken
+}
+
+// newAzureTokenSource creates the credential for Azure AD authentication.
+// No token is requested until the first connection is opened.
+func newAzureTokenSource(cfg *AzureAuthConfig) (*azureTokenSource, error) {
+	cred, scope, err := newAzureCredential(cfg)
+	if err != nil {
+		return nil, err
+	}
+	refreshMargin := cfg.TokenRefreshMargin
+	if refreshMargin <= 0 {
+		refreshMargin = DefaultTokenRefreshMargin
+	}
+	return &azureTokenSource{cred: cred, scope: scope, refreshMargin: refreshMargin}, nil
+}
+
+// Token returns the cached token, or a new one if it expires within the
+// refresh margin. The token is refreshed ahead of its expiry rather than after
+// a failed login, so connections opened late in a long apply do not fail.
+func (s *azureTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.Token != "" && time.Until(s.token.ExpiresOn) > s.refreshMargin {
+		return s.token.Token, nil
+	}
+	token, err := s.cred.GetToken(ctx, policy.TokenRequestOptions{
+		Scopes: []string{s.scope},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get Azure AD token: %w", err)
+	}
+	s.token = token
+	return token.Token, nil
+}
+
+// newAccessTokenConnector creates a connector that authenticates each new
+// connection with a token of tokens.
+func newAccessTokenConnector(cfg *Config, dsn string, tokens *azureTokenSource) (driver.Connector, error) {
+	params, err := parseDSN(cfg, dsn)
+	if err != nil {
+		return nil, err
+	}
+	connector, err := mssqldb.NewSecurityTokenConnector(params, tokens.Token)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create access token connector: %w", err)
 	}
+	return connector, nil
+}
 
-	db := sql.OpenDB(connector)
-	return db, nil
+// connectWithAzureAuth establishes a connection using Azure AD authentication.
+func connectWithAzureAuth(ctx context.Context, cfg *Config, tokens *azureTokenSource) (*sql.DB, error) {
+	// Request the first token here, so that authentication errors are
+	// reported as such rather than as a failed ping
+	if _, err := tokens.Token(ctx); err != nil {
+		return nil, err
+	}
+
+	query := connectionQuery(cfg)
+	query.Add("database", serverDatabase)
+	u := &url.URL{
+		Scheme:   "sqlserver",
+		Host:     fmt.Sprintf("%s:%d", cfg.Hostname, cfg.Port),
+		RawQuery: query.Encode(),
+	}
+
+	connector, err := newAccessTokenConnector(cfg, u.String(), tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	return openDB(connector, serverDatabase), nil
 }
 
 // connectWithSQLAuthToDatabase establishes a connection to a specific database using SQL authentication.
-func connectWithSQLAuthToDatabase(cfg *Config, databaseName string) (*sql.DB, error) {
-	query := url.Values{}
-	query.Add("app name", "terraform-provider-mssql")
-	query.Add("database", databaseName)
+// With readOnly set, the connection declares a read-only application intent so
+// that it can be routed to a readable secondary replica.
+func connectWithSQLAuthToDatabase(cfg *Config, databaseName string, readOnly bool) (*sql.DB, error) {
+	query := connectionQuery(cfg)
+	if databaseName != "" {
+		query.Add("database", databaseName)
+	}
+	if readOnly {
+		query.Add("ApplicationIntent", "ReadOnly")
+	}
 
 	u := &url.URL{
 		Scheme:   "sqlserver",
@@ -186,84 +605,148 @@ func connectWithSQLAuthToDatabase(cfg *Config, databaseName string) (*sql.DB, er
 		RawQuery: query.Encode(),
 	}
 
-	db, err := sql.Open("sqlserver", u.String())
+	params, err := parseDSN(cfg, u.String())
 	if err != nil {
 		return nil, err
 	}
+	connector := mssqldb.NewConnectorConfig(params)
 
-	return db, nil
+	return openDB(connector, databaseName), nil
 }
 
 // connectWithAzureAuthToDatabase establishes a connection to a specific database using Azure AD authentication.
-func connectWithAzureAuthToDatabase(ctx context.Context, cfg *Config, databaseName string) (*sql.DB, error) {
-	var cred azcore.TokenCredential
-	var err error
-
-	// Check for environment variable override
-	clientID := cfg.AzureAuth.ClientID
-	clientSecret := cfg.AzureAuth.ClientSecret
-	tenantID := cfg.AzureAuth.TenantID
+// With readOnly set, the connection declares a read-only application intent so
+// that it can be routed to a readable secondary replica.
+// An empty database name connects to the default database of the login, as
+// with SQL authentication.
+func connectWithAzureAuthToDatabase(ctx context.Context, cfg *Config, tokens *azureTokenSource, databaseName string, readOnly bool) (*sql.DB, error) {
+	if _, err := tokens.Token(ctx); err != nil {
+		return nil, err
+	}
 
-	if clientID == "" {
-		clientID = os.Getenv("ARM_CLIENT_ID")
+	query := connectionQuery(cfg)
+	if databaseName != "" {
+		query.Add("database", databaseName)
 	}
-	if clientSecret == "" {
-		clientSecret = os.Getenv("ARM_CLIENT_SECRET")
+	if readOnly {
+		query.Add("ApplicationIntent", "ReadOnly")
 	}
-	if tenantID == "" {
-		tenantID = os.Getenv("ARM_TENANT_ID")
+	u := &url.URL{
+		Scheme:   "sqlserver",
+		Host:     fmt.Sprintf("%s:%d", cfg.Hostname, cfg.Port),
+		RawQuery: query.Encode(),
 	}
 
-	if clientID != "" && clientSecret != "" && tenantID != "" {
-		// Use Service Principal authentication
-		cred, err = azidentity.NewClientSecretCredential(tenantID, clientID, clientSecret, nil)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create client secret credential: %w", err)
-		}
-	} else {
-		// Use default Azure credential chain
-		cred, err = azidentity.NewDefaultAzureCredential(nil)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create default Azure credential: %w", err)
-		}
+	// The pool is reused for the lifetime of the client, so the token is
+	// requested for every new connection; tokens refreshes it when needed.
+	connector, err := newAccessTokenConnector(cfg, u.String(), tokens)
+	if err != nil {
+		return nil, err
 	}
 
-	// Get token for Azure SQL
-	token, err := cred.GetToken(ctx, policy.TokenRequestOptions{
-		Scopes: []string{"https://database.windows.net/.default"},
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get Azure AD token: %w", err)
+	return openDB(connector, databaseName), nil
+}
+
+// GetDatabaseConnection returns the connection pool scoped to a specific
+// database. This is needed for Azure SQL Database which doesn't support the
+// USE statement, and avoids switching the database of connections shared by
+// concurrently running resources.
+// Pools are created on first use and reused afterwards. They are owned by the
+// client and closed by Close, so callers must not close them.
+func (c *Client) GetDatabaseConnection(ctx context.Context, databaseName string) (*sql.DB, error) {
+	return c.databaseConnection(ctx, databaseName, false)
+}
+
+// GetReadOnlyDatabaseConnection returns a connection pool scoped to a specific
+// database whose connections declare ApplicationIntent=ReadOnly. On an
+// availability group listener with read-only routing, or on Azure SQL with
+// read scale-out, they are served by a readable secondary replica. An empty
+// database name connects to the default database of the login.
+// The pool is owned by the client and must not be closed by callers.
+func (c *Client) GetReadOnlyDatabaseConnection(ctx context.Context, databaseName string) (*sql.DB, error) {
+	return c.databaseConnection(ctx, databaseName, true)
+}
+
+// databaseConnection returns the cached connection pool for key, creating it
+// on first use. Once maxDatabasePools pools are cached, the least recently
+// used one is closed to make room. Callers use a pool right after getting it,
+// so an evicted pool has normally finished its queries; Close waits for those
+// still running.
+func (c *Client) databaseConnection(ctx context.Context, databaseName string, readOnly bool) (*sql.DB, error) {
+	if c.config == nil {
+		return nil, fmt.Errorf("client config not available")
 	}
+	key := databaseKey{name: c.NameKey(databaseName), readOnly: readOnly}
 
-	connector, err := mssqldb.NewAccessTokenConnector(
-		fmt.Sprintf("sqlserver://%s:%d?database=%s&app+name=terraform-provider-mssql", cfg.Hostname, cfg.Port, url.QueryEscape(databaseName)),
-		func() (string, error) {
-			return token.Token, nil
-		},
-	)
+	c.databasesMu.Lock()
+	pool, ok := c.databases[key]
+	if ok {
+		pool.lastUsed = time.Now()
+	}
+	c.databasesMu.Unlock()
+	if ok {
+		c.validateConnection(ctx, pool.db)
+		return pool.db, nil
+	}
+
+	db, err := c.openDatabaseConnection(ctx, databaseName, readOnly)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create access token connector: %w", err)
+		return nil, err
 	}
 
-	db := sql.OpenDB(connector)
+	c.databasesMu.Lock()
+	defer c.databasesMu.Unlock()
+	// Another caller may have connected to the same database in the meantime
+	if existing, ok := c.databases[key]; ok {
+		db.Close()
+		existing.lastUsed = time.Now()
+		return existing.db, nil
+	}
+	if len(c.databases) >= maxDatabasePools {
+		c.evictDatabaseConnection()
+	}
+	c.databases[key] = &databasePool{db: db, databaseName: databaseName, lastUsed: time.Now()}
 	return db, nil
 }
 
-// GetDatabaseConnection creates a new connection to a specific database.
-// This is needed for Azure SQL Database which doesn't support the USE statement.
-func (c *Client) GetDatabaseConnection(ctx context.Context, databaseName string) (*sql.DB, error) {
-	if c.config == nil {
-		return nil, fmt.Errorf("client config not available")
+// evictDatabaseConnection closes and forgets the least recently used
+// database-scoped connection pool. The caller must hold databasesMu.
+func (c *Client) evictDatabaseConnection() {
+	var oldest databaseKey
+	var oldestPool *databasePool
+	for key, pool := range c.databases {
+		if oldestPool == nil || pool.lastUsed.Before(oldestPool.lastUsed) {
+			oldest, oldestPool = key, pool
+		}
 	}
+	if oldestPool != nil {
+		oldestPool.db.Close()
+		delete(c.databases, oldest)
+	}
+}
 
+// closeDatabaseConnection closes and forgets the connection pools scoped to a
+// database, whichever spelling of its name they were opened with.
+func (c *Client) closeDatabaseConnection(databaseName string) {
+	c.databasesMu.Lock()
+	defer c.databasesMu.Unlock()
+	for key, pool := range c.databases {
+		if c.NamesEqual(pool.databaseName, databaseName) {
+			pool.db.Close()
+			delete(c.databases, key)
+		}
+	}
+}
+
+// openDatabaseConnection creates a new connection pool to a specific database.
+func (c *Client) openDatabaseConnection(ctx context.Context, databaseName string, readOnly bool) (*sql.DB, error) {
 	var db *sql.DB
 	var err error
 
 	if c.config.AzureAuth != nil {
-		db, err = connectWithAzureAuthToDatabase(ctx, c.config, databaseName)
+		db, err = connectWithAzureAuthToDatabase(ctx, c.config, c.azureTokens, databaseName, readOnly)
 	} else if c.config.SQLAuth != nil {
-		db, err = connectWithSQLAuthToDatabase(c.config, databaseName)
+		db, err = connectWithSQLAuthToDatabase(c.config, databaseName, readOnly)
 	} else {
 		return nil, fmt.Errorf("no authentication method configured")
 	}
@@ -271,6 +754,7 @@ func (c *Client) GetDatabaseConnection(ctx context.Context, databaseName string)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database %s: %w", databaseName, err)
 	}
+	db.SetConnMaxIdleTime(connMaxIdleTime)
 
 	// Verify connection
 	if err := db.PingContext(ctx); err != nil {
@@ -281,53 +765,159 @@ func (c *Client) GetDatabaseConnection(ctx context.Context, databaseName string)
 	return db, nil
 }
 
-// Close closes the database connection.
+// Close closes the database connection and all database-scoped connection pools.
 func (c *Client) Close() error {
+	c.databasesMu.Lock()
+	for key, pool := range c.databases {
+		pool.db.Close()
+		delete(c.databases, key)
+	}
+	c.databasesMu.Unlock()
+
 	if c.db != nil {
 		return c.db.Close()
 	}
 	return nil
 }
 
+// PoolStats holds the statistics of a connection pool of the client.
+type PoolStats struct {
+	// DatabaseName is empty for the server-level pool.
+	DatabaseName string
+	ReadOnly     bool
+	sql.DBStats
+}
+
+// PoolStats returns the statistics of the server-level connection pool,
+// followed by those of the database-scoped pools ordered by database, e.g. to
+// diagnose pool exhaustion during large applies.
+func (c *Client) PoolStats() []PoolStats {
+	stats := []PoolStats{{DBStats: c.db.Stats()}}
+
+	c.databasesMu.Lock()
+	defer c.databasesMu.Unlock()
+	var databaseStats []PoolStats
+	for key, pool := range c.databases {
+		databaseStats = append(databaseStats, PoolStats{DatabaseName: pool.databaseName, ReadOnly: key.readOnly, DBStats: pool.db.Stats()})
+	}
+	sort.Slice(databaseStats, func(i, j int) bool {
+		if databaseStats[i].DatabaseName != databaseStats[j].DatabaseName {
+			return databaseStats[i].DatabaseName < databaseStats[j].DatabaseName
+		}
+		return !databaseStats[i].ReadOnly
+	})
+
+	return append(stats, databaseStats...)
+}
+
 // DB returns the underlying database connection for advanced queries.
 func (c *Client) DB() *sql.DB {
 	return c.db
 }
 
-// Hostname returns the connected server hostname.
+// Hostname returns the server hostname used in resource IDs: the configured
+// ServerName, or else the hostname connected to.
 func (c *Client) Hostname() string {
+	if c.config != nil && c.config.ServerName != "" {
+		return c.config.ServerName
+	}
 	return c.hostname
 }
 
+// CaseSensitive reports whether the server collation compares names, such as
+// database, login and user names, case-sensitively.
+func (c *Client) CaseSensitive() bool {
+	return c.caseSensitive
+}
+
+// NameKey returns the key under which the server considers a name unique:
+// the name itself on a case-sensitive collation, or else its upper case form.
+// Names in a database are compared under the server collation too, which
+// assumes that the database collation agrees with it on case sensitivity. A
+// nil client, e.g. in ValidateConfig before the provider is configured,
+// compares names case-insensitively.
+func (c *Client) NameKey(name string) string {
+	if c != nil && c.caseSensitive {
+		return name
+	}
+	return strings.ToUpper(name)
+}
+
+// NamesEqual reports whether two names refer to the same object under the
+// server collation.
+func (c *Client) NamesEqual(a, b string) bool {
+	if c != nil && c.caseSensitive {
+		return a == b
+	}
+	return strings.EqualFold(a, b)
+}
+
 // Port returns the connected server port.
 func (c *Client) Port() int {
 	return c.port
 }
 
+// DefaultWithGrantOption returns whether permissions are granted WITH GRANT
+// OPTION when a permission resource leaves with_grant_option unset.
+func (c *Client) DefaultWithGrantOption() bool {
+	return c.config != nil && c.config.DefaultWithGrantOption
+}
+
+// validateConnection pings a connection of the pool if ValidateConnection is
+// set. A connection that turns out to be broken is discarded by database/sql,
+// which retries on a new one, so the following operation gets a working
+// connection. Errors are left to that operation to report.
+func (c *Client) validateConnection(ctx context.Context, db *sql.DB) {
+	if c.config == nil || !c.config.ValidateConnection {
+		return
+	}
+	_ = db.PingContext(ctx)
+}
+
 // ExecContext executes a query without returning any rows.
 func (c *Client) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
-	return c.db.ExecContext(ctx, query, args...)
+	c.validateConnection(ctx, c.db)
+	result, err := c.db.ExecContext(ctx, query, args...)
+	return result, wrapSQLError(err)
 }
 
 // QueryContext executes a query that returns rows.
 func (c *Client) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
-	return c.db.QueryContext(ctx, query, args...)
+	c.validateConnection(ctx, c.db)
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	return rows, wrapSQLError(err)
+}
+
+// execSQL executes a statement on a database-scoped connection and classifies its error.
+func execSQL(ctx context.Context, db *sql.DB, query string) error {
+	_, err := db.ExecContext(ctx, query)
+	return wrapSQLError(err)
 }
 
 // QueryRowContext executes a query that is expected to return at most one row.
 func (c *Client) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	c.validateConnection(ctx, c.db)
 	return c.db.QueryRowContext(ctx, query, args...)
 }
 
-// UseDatabase switches the connection to use the specified database.
-func (c *Client) UseDatabase(ctx context.Context, databaseName string) error {
-	_, err := c.db.ExecContext(ctx, fmt.Sprintf("USE [%s]", databaseName))
-	return err
+// quoteIdentifier quotes a name as a delimited identifier, escaping any
+// closing brackets it contains.
+func quoteIdentifier(name string) string {
+	return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+}
+
+// quoteString quotes a value as a Unicode string literal, escaping any single
+// quotes it contains.
+func quoteString(value string) string {
+	return "N'" + strings.ReplaceAll(value, "'", "''") + "'"
 }
 
 // ExecInDatabaseContext executes a query in the context of a specific database.
 // This uses a dedicated connection to ensure the USE statement persists for the query.
+// It is the fallback for callers that could not get the connection pool of the
+// database; the connection is switched back to master before it is reused, see openDB.
 func (c *Client) ExecInDatabaseContext(ctx context.Context, databaseName, query string) error {
+	c.validateConnection(ctx, c.db)
 	// Get a dedicated connection from the pool
 	conn, err := c.db.Conn(ctx)
 	if err != nil {
@@ -337,12 +927,12 @@ func (c *Client) ExecInDatabaseContext(ctx context.Context, databaseName, query
 
 	// Switch to the target database
 	if _, err := conn.ExecContext(ctx, fmt.Sprintf("USE [%s]", databaseName)); err != nil {
-		return fmt.Errorf("failed to switch database context: %w", err)
+		return fmt.Errorf("failed to switch database context: %w", wrapSQLError(err))
 	}
 
 	// Execute the query in the correct context
 	if _, err := conn.ExecContext(ctx, query); err != nil {
-		return err
+		return wrapSQLError(err)
 	}
 
 	return nil
@@ -351,6 +941,7 @@ func (c *Client) ExecInDatabaseContext(ctx context.Context, databaseName, query
 // QueryRowInDatabaseContext executes a query in the context of a specific database and returns a row.
 // This uses a dedicated connection to ensure the USE statement persists for the query.
 func (c *Client) QueryRowInDatabaseContext(ctx context.Context, databaseName, query string, args ...interface{}) (*sql.Row, error) {
+	c.validateConnection(ctx, c.db)
 	// Get a dedicated connection from the pool
 	conn, err := c.db.Conn(ctx)
 	if err != nil {
@@ -362,7 +953,7 @@ func (c *Client) QueryRowInDatabaseContext(ctx context.Context, databaseName, qu
 	// Switch to the target database
 	if _, err := conn.ExecContext(ctx, fmt.Sprintf("USE [%s]", databaseName)); err != nil {
 		conn.Close()
-		return nil, fmt.Errorf("failed to switch database context: %w", err)
+		return nil, fmt.Errorf("failed to switch database context: %w", wrapSQLError(err))
 	}
 
 	// Execute the query in the correct context
@@ -7,6 +7,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 )
 
 // Database represents a SQL Server database.
@@ -32,6 +34,22 @@ func (c *Client) GetDatabase(ctx context.Context, name string) (*Database, error
 	return &db, nil
 }
 
+// IsContainedDatabase reports whether a database is partially contained.
+func (c *Client) IsContainedDatabase(ctx context.Context, name string) (bool, error) {
+	query := `SELECT containment FROM sys.databases WHERE name = @p1`
+
+	var containment int
+	err := c.QueryRowContext(ctx, query, name).Scan(&containment)
+	if err == sql.ErrNoRows {
+		return false, fmt.Errorf("database '%s' not found", name)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get database containment: %w", err)
+	}
+
+	return containment != 0, nil
+}
+
 // GetDatabaseByID retrieves a database by ID.
 func (c *Client) GetDatabaseByID(ctx context.Context, id int) (*Database, error) {
 	query := `SELECT database_id, name FROM sys.databases WHERE database_id = @p1`
@@ -82,17 +100,326 @@ func (c *Client) CreateDatabase(ctx context.Context, name string) (*Database, er
 	return c.GetDatabase(ctx, name)
 }
 
+// databaseCopyPollInterval is the delay between checks of a database copy.
+const databaseCopyPollInterval = 10 * time.Second
+
+// CopyDatabase creates a database as a transactionally consistent copy of a
+// source database with CREATE DATABASE ... AS COPY OF, which is only supported
+// in Azure SQL Database. The copy runs asynchronously, so CopyDatabase waits
+// until the database is ONLINE or ctx is done. Once the copy has started, the
+// database is returned along with any error, since it exists from then on.
+func (c *Client) CopyDatabase(ctx context.Context, name, sourceName string) (*Database, error) {
+	props, err := c.GetServerProperties(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// EngineEdition 5 = Azure SQL Database
+	if props.EngineEdition != 5 {
+		return nil, fmt.Errorf("database copies are only supported in Azure SQL Database, the server has engine edition %d", props.EngineEdition)
+	}
+
+	query := fmt.Sprintf("CREATE DATABASE %s AS COPY OF %s", quoteIdentifier(name), quoteIdentifier(sourceName))
+	if _, err := c.ExecContext(ctx, query); err != nil {
+		return nil, fmt.Errorf("failed to copy database: %w", err)
+	}
+
+	db, err := c.GetDatabase(ctx, name)
+	if err != nil || db == nil {
+		return nil, err
+	}
+
+	return db, c.waitForDatabaseCopy(ctx, name)
+}
+
+// waitForDatabaseCopy polls the state of a database being copied until it is
+// ONLINE. A failed copy is removed by Azure, which is reported with the error
+// last seen in sys.dm_database_copies.
+func (c *Client) waitForDatabaseCopy(ctx context.Context, name string) error {
+	query := `
+		SELECT d.state_desc, ISNULL(dc.percent_complete, 0), ISNULL(dc.error_desc, '')
+		FROM sys.databases d
+		LEFT JOIN sys.dm_database_copies dc ON dc.database_id = d.database_id
+		WHERE d.name = @p1`
+
+	var lastError string
+	for {
+		var state, errorDesc string
+		var percent float64
+		err := c.QueryRowContext(ctx, query, name).Scan(&state, &percent, &errorDesc)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("copy of database '%s' failed: %s", name, lastError)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get database copy state: %w", err)
+		}
+		if state == "ONLINE" {
+			return nil
+		}
+		if errorDesc != "" {
+			lastError = errorDesc
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("database '%s' still %s (%.0f%% copied): %w", name, state, percent, ctx.Err())
+		case <-time.After(databaseCopyPollInterval):
+		}
+	}
+}
+
 // DropDatabase drops a database.
 func (c *Client) DropDatabase(ctx context.Context, name string) error {
-	// Set to single user mode to force close all connections
-	alterQuery := fmt.Sprintf("ALTER DATABASE [%s] SET SINGLE_USER WITH ROLLBACK IMMEDIATE", name)
-	_, _ = c.ExecContext(ctx, alterQuery) // Ignore error if database doesn't exist or is already in single user mode
+	// Release the pooled connections this client holds to the database
+	c.closeDatabaseConnection(name)
 
-	query := fmt.Sprintf("DROP DATABASE IF EXISTS [%s]", name)
-	_, err := c.ExecContext(ctx, query)
+	// A database that was just used may be in transition for a short time.
+	// Each attempt closes the connections again, since new ones may have been
+	// opened while waiting.
+	err := retryWhileDatabaseBusy(ctx, func() error {
+		// Set to single user mode to force close all connections
+		alterQuery := fmt.Sprintf("ALTER DATABASE [%s] SET SINGLE_USER WITH ROLLBACK IMMEDIATE", name)
+		_, _ = c.ExecContext(ctx, alterQuery) // Ignore error if database doesn't exist or is already in single user mode
+
+		query := fmt.Sprintf("DROP DATABASE IF EXISTS [%s]", name)
+		_, err := c.ExecContext(ctx, query)
+		return wrapSQLError(err)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to drop database: %w", err)
 	}
 
 	return nil
 }
+
+// RenameDatabase renames the database with the given ID with ALTER DATABASE
+// ... MODIFY NAME, keeping its data. The rename needs an exclusive lock on the
+// database, so other connections are closed by switching the database to
+// single-user mode for the rename, where supported. It returns nil if the
+// database doesn't exist.
+func (c *Client) RenameDatabase(ctx context.Context, id int, newName string) (*Database, error) {
+	db, err := c.GetDatabaseByID(ctx, id)
+	if err != nil || db == nil {
+		return nil, err
+	}
+	if db.Name == newName {
+		return db, nil
+	}
+
+	var userAccess string
+	err = c.QueryRowContext(ctx, `SELECT user_access_desc FROM sys.databases WHERE database_id = @p1`, id).Scan(&userAccess)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database user access: %w", err)
+	}
+
+	// Release the pooled connections this client holds to the database
+	c.closeDatabaseConnection(db.Name)
+
+	name := db.Name
+	singleUser := false
+	if userAccess == "MULTI_USER" {
+		// SINGLE_USER is not supported in Azure SQL Database, where the rename
+		// only waits for other connections
+		alterQuery := fmt.Sprintf("ALTER DATABASE %s SET SINGLE_USER WITH ROLLBACK IMMEDIATE", quoteIdentifier(name))
+		_, err := c.ExecContext(ctx, alterQuery)
+		singleUser = err == nil
+	}
+
+	err = retryWhileDatabaseBusy(ctx, func() error {
+		query := fmt.Sprintf("ALTER DATABASE %s MODIFY NAME = %s", quoteIdentifier(name), quoteIdentifier(newName))
+		_, err := c.ExecContext(ctx, query)
+		return wrapSQLError(err)
+	})
+	if err == nil {
+		name = newName
+	}
+
+	if singleUser {
+		alterQuery := fmt.Sprintf("ALTER DATABASE %s SET MULTI_USER", quoteIdentifier(name))
+		if _, resetErr := c.ExecContext(ctx, alterQuery); resetErr != nil && err == nil {
+			err = fmt.Errorf("database renamed, but failed to reset it to multi-user mode: %w", resetErr)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename database: %w", err)
+	}
+
+	return c.GetDatabaseByID(ctx, id)
+}
+
+// DatabaseOwner represents the owner of a database, the login mapped to its
+// dbo user.
+type DatabaseOwner struct {
+	DatabaseID   int
+	DatabaseName string
+	OwnerName    string // Empty if the owner SID doesn't match a login anymore
+	OwnerSID     string // Hex string with 0x prefix
+}
+
+// GetDatabaseOwner retrieves the owner of a database from sys.databases.
+func (c *Client) GetDatabaseOwner(ctx context.Context, name string) (*DatabaseOwner, error) {
+	query := `
+		SELECT database_id, name, ISNULL(SUSER_SNAME(owner_sid), ''), CONVERT(VARCHAR(200), owner_sid, 1)
+		FROM sys.databases
+		WHERE name = @p1`
+	row := c.QueryRowContext(ctx, query, name)
+
+	var owner DatabaseOwner
+	err := row.Scan(&owner.DatabaseID, &owner.DatabaseName, &owner.OwnerName, &owner.OwnerSID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database owner: %w", err)
+	}
+
+	return &owner, nil
+}
+
+// SetDatabaseOwner makes a login the owner of a database. The login must not
+// be mapped to a user in the database already.
+func (c *Client) SetDatabaseOwner(ctx context.Context, name, loginName string) (*DatabaseOwner, error) {
+	query := fmt.Sprintf("ALTER AUTHORIZATION ON DATABASE::%s TO %s", quoteIdentifier(name), quoteIdentifier(loginName))
+	_, err := c.ExecContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set database owner: %w", wrapSQLError(err))
+	}
+
+	return c.GetDatabaseOwner(ctx, name)
+}
+
+// Filegroup represents a rows filegroup of a database.
+type Filegroup struct {
+	Name      string
+	IsDefault bool
+}
+
+// ListFilegroups retrieves the rows filegroups of a database, including PRIMARY.
+func (c *Client) ListFilegroups(ctx context.Context, databaseName string) ([]Filegroup, error) {
+	// sys.filegroups only lists the filegroups of the current database
+	query := `SELECT name, is_default FROM sys.filegroups WHERE type = 'FG' ORDER BY name`
+
+	var rows *sql.Rows
+	if db, err := c.GetDatabaseConnection(ctx, databaseName); err == nil {
+		rows, err = db.QueryContext(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list filegroups: %w", err)
+		}
+	} else {
+		// Get a dedicated connection from the pool
+		conn, err := c.db.Conn(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get database connection: %w", err)
+		}
+		defer conn.Close()
+
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("USE %s", quoteIdentifier(databaseName))); err != nil {
+			return nil, fmt.Errorf("failed to switch database context: %w", wrapSQLError(err))
+		}
+		rows, err = conn.QueryContext(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list filegroups: %w", err)
+		}
+	}
+	defer rows.Close()
+
+	var filegroups []Filegroup
+	for rows.Next() {
+		var filegroup Filegroup
+		if err := rows.Scan(&filegroup.Name, &filegroup.IsDefault); err != nil {
+			return nil, fmt.Errorf("failed to scan filegroup: %w", err)
+		}
+		filegroups = append(filegroups, filegroup)
+	}
+
+	return filegroups, rows.Err()
+}
+
+// AddFilegroup adds a filegroup to a database, together with one data file
+// named <database>_<filegroup>.ndf next to the primary data file. A filegroup
+// without files cannot hold data or be the default filegroup.
+func (c *Client) AddFilegroup(ctx context.Context, databaseName, filegroupName string) error {
+	var primaryPath string
+	query := `SELECT physical_name FROM sys.master_files WHERE database_id = DB_ID(@p1) AND file_id = 1`
+	if err := c.QueryRowContext(ctx, query, databaseName).Scan(&primaryPath); err != nil {
+		return fmt.Errorf("failed to get primary data file of database %s: %w", databaseName, err)
+	}
+	// The path uses the separator of the server's operating system
+	directory := primaryPath[:strings.LastIndexAny(primaryPath, `/\`)+1]
+	fileName := databaseName + "_" + filegroupName
+
+	statements := []string{
+		fmt.Sprintf("ALTER DATABASE %s ADD FILEGROUP %s", quoteIdentifier(databaseName), quoteIdentifier(filegroupName)),
+		fmt.Sprintf("ALTER DATABASE %s ADD FILE (NAME = %s, FILENAME = %s) TO FILEGROUP %s",
+			quoteIdentifier(databaseName), quoteString(fileName), quoteString(directory+fileName+".ndf"), quoteIdentifier(filegroupName)),
+	}
+	for _, statement := range statements {
+		if _, err := c.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to add filegroup %s: %w", filegroupName, wrapSQLError(err))
+		}
+	}
+
+	return nil
+}
+
+// SetDefaultFilegroup makes a filegroup the default filegroup of a database,
+// which new tables and indexes are created in.
+func (c *Client) SetDefaultFilegroup(ctx context.Context, databaseName, filegroupName string) error {
+	query := fmt.Sprintf("ALTER DATABASE %s MODIFY FILEGROUP %s DEFAULT", quoteIdentifier(databaseName), quoteIdentifier(filegroupName))
+	if _, err := c.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to set default filegroup: %w", wrapSQLError(err))
+	}
+
+	return nil
+}
+
+// Database options that are switched ON or OFF with ALTER DATABASE SET.
+const (
+	DatabaseOptionAutoClose            = "AUTO_CLOSE"
+	DatabaseOptionAutoShrink           = "AUTO_SHRINK"
+	DatabaseOptionAutoCreateStatistics = "AUTO_CREATE_STATISTICS"
+	DatabaseOptionAutoUpdateStatistics = "AUTO_UPDATE_STATISTICS"
+)
+
+// DatabaseOptions holds the AUTO_* options of a database.
+type DatabaseOptions struct {
+	AutoClose            bool
+	AutoShrink           bool
+	AutoCreateStatistics bool
+	AutoUpdateStatistics bool
+}
+
+// GetDatabaseOptions retrieves the AUTO_* options of a database from
+// sys.databases.
+func (c *Client) GetDatabaseOptions(ctx context.Context, name string) (*DatabaseOptions, error) {
+	query := `
+		SELECT is_auto_close_on, is_auto_shrink_on, is_auto_create_stats_on, is_auto_update_stats_on
+		FROM sys.databases
+		WHERE name = @p1`
+	row := c.QueryRowContext(ctx, query, name)
+
+	var options DatabaseOptions
+	err := row.Scan(&options.AutoClose, &options.AutoShrink, &options.AutoCreateStatistics, &options.AutoUpdateStatistics)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database options: %w", err)
+	}
+
+	return &options, nil
+}
+
+// SetDatabaseOption switches an option of a database, e.g. AUTO_SHRINK, ON
+// or OFF.
+func (c *Client) SetDatabaseOption(ctx context.Context, name, option string, on bool) error {
+	value := "OFF"
+	if on {
+		value = "ON"
+	}
+	query := fmt.Sprintf("ALTER DATABASE %s SET %s %s", quoteIdentifier(name), option, value)
+	if _, err := c.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to set %s: %w", option, wrapSQLError(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// DatabaseEncryption represents the Transparent Data Encryption (TDE) state of a database.
+type DatabaseEncryption struct {
+	DatabaseName    string
+	Enabled         bool
+	EncryptionState string
+	EncryptorName   string
+	EncryptorType   string
+	ServiceManaged  bool
+}
+
+// encryptionStates maps sys.dm_database_encryption_keys.encryption_state to a description.
+var encryptionStates = map[int]string{
+	0: "NONE",
+	1: "UNENCRYPTED",
+	2: "ENCRYPTION_IN_PROGRESS",
+	3: "ENCRYPTED",
+	4: "KEY_CHANGE_IN_PROGRESS",
+	5: "DECRYPTION_IN_PROGRESS",
+	6: "PROTECTION_CHANGE_IN_PROGRESS",
+}
+
+// GetDatabaseEncryption retrieves the TDE state of a database.
+// On Azure SQL, TDE is service-managed and no encryptor is reported.
+func (c *Client) GetDatabaseEncryption(ctx context.Context, databaseName string) (*DatabaseEncryption, error) {
+	props, err := c.GetServerProperties(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT
+			d.name,
+			d.is_encrypted,
+			ISNULL(k.encryption_state, 0),
+			ISNULL(c.name, ''),
+			ISNULL(k.encryptor_type, '')
+		FROM sys.databases d
+		LEFT JOIN sys.dm_database_encryption_keys k ON k.database_id = d.database_id
+		LEFT JOIN master.sys.certificates c ON c.thumbprint = k.encryptor_thumbprint
+		WHERE d.name = @p1`
+	if props.IsAzure {
+		// master.sys.certificates is not accessible from user databases on Azure SQL
+		query = `
+			SELECT
+				d.name,
+				d.is_encrypted,
+				ISNULL(k.encryption_state, 0),
+				'',
+				ISNULL(k.encryptor_type, '')
+			FROM sys.databases d
+			LEFT JOIN sys.dm_database_encryption_keys k ON k.database_id = d.database_id
+			WHERE d.name = @p1`
+	}
+	row := c.QueryRowContext(ctx, query, databaseName)
+
+	enc := DatabaseEncryption{ServiceManaged: props.IsAzure}
+	var state int
+	err = row.Scan(
+		&enc.DatabaseName,
+		&enc.Enabled,
+		&state,
+		&enc.EncryptorName,
+		&enc.EncryptorType,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database encryption: %w", err)
+	}
+	enc.EncryptionState = encryptionStates[state]
+
+	return &enc, nil
+}
+
+// SetDatabaseEncryptionOptions contains options for turning TDE on or off.
+type SetDatabaseEncryptionOptions struct {
+	DatabaseName string
+	Enabled      bool
+	// EncryptorName is the server certificate protecting the database
+	// encryption key. It is required to enable TDE on SQL Server and ignored
+	// on Azure SQL, where TDE is service-managed.
+	EncryptorName string
+}
+
+// SetDatabaseEncryption turns TDE on or off for a database. When enabling TDE
+// on SQL Server, the database encryption key is created if it does not exist
+// yet, or re-encrypted if it is protected by a different certificate.
+func (c *Client) SetDatabaseEncryption(ctx context.Context, opts SetDatabaseEncryptionOptions) (*DatabaseEncryption, error) {
+	current, err := c.GetDatabaseEncryption(ctx, opts.DatabaseName)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, fmt.Errorf("database '%s' not found", opts.DatabaseName)
+	}
+
+	if opts.Enabled && !current.ServiceManaged && opts.EncryptorName != "" {
+		var keyQuery string
+		switch {
+		case current.EncryptionState == encryptionStates[0]:
+			keyQuery = fmt.Sprintf("CREATE DATABASE ENCRYPTION KEY WITH ALGORITHM = AES_256 ENCRYPTION BY SERVER CERTIFICATE [%s]", opts.EncryptorName)
+		case current.EncryptorName != opts.EncryptorName:
+			keyQuery = fmt.Sprintf("ALTER DATABASE ENCRYPTION KEY ENCRYPTION BY SERVER CERTIFICATE [%s]", opts.EncryptorName)
+		}
+		if keyQuery != "" {
+			if err := c.ExecInDatabaseContext(ctx, opts.DatabaseName, keyQuery); err != nil {
+				return nil, fmt.Errorf("failed to set database encryption key: %w", err)
+			}
+		}
+	}
+
+	if opts.Enabled != current.Enabled {
+		query := fmt.Sprintf("ALTER DATABASE [%s] SET ENCRYPTION %s", opts.DatabaseName, boolToOnOff(opts.Enabled))
+		if _, err := c.ExecContext(ctx, query); err != nil {
+			return nil, fmt.Errorf("failed to set database encryption: %w", err)
+		}
+	}
+
+	return c.GetDatabaseEncryption(ctx, opts.DatabaseName)
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package mssql
+
+import (
+	"errors"
+)
+
+// Sentinel errors for common SQL Server failures. Errors returned by the
+// client match them with errors.Is while still carrying the original
+// SQL Server error message.
+var (
+	// ErrAlreadyExists is returned when the object to create already exists.
+	ErrAlreadyExists = errors.New("object already exists")
+	// ErrNotFound is returned when the referenced object does not exist.
+	ErrNotFound = errors.New("object not found")
+	// ErrPermissionDenied is returned when the login lacks the required permission.
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrAgentUnavailable is returned when SQL Server Agent is not available,
+	// e.g. in Azure SQL Database.
+	ErrAgentUnavailable = errors.New("SQL Server Agent is not available")
+	// ErrDatabaseBusy is returned when a database cannot be changed or dropped
+	// for the moment, e.g. while it is in transition after heavy activity.
+	ErrDatabaseBusy = errors.New("database is busy")
+)
+
+// sqlErrorKinds maps SQL Server error numbers to the sentinel errors above.
+var sqlErrorKinds = map[int32]error{
+	1801:  ErrAlreadyExists, // Database already exists
+	2714:  ErrAlreadyExists, // There is already an object named ... in the database
+	15023: ErrAlreadyExists, // User, group, or role already exists in the current database
+	15025: ErrAlreadyExists, // The server principal already exists
+
+	911:   ErrNotFound, // Database does not exist
+	3701:  ErrNotFound, // Cannot drop the object because it does not exist or you do not have permission
+	15007: ErrNotFound, // Login is not a valid login or you do not have permission
+	15151: ErrNotFound, // Cannot find the object because it does not exist or you do not have permission
+
+	229:   ErrPermissionDenied, // The permission was denied on the object
+	262:   ErrPermissionDenied, // Permission denied in database
+	300:   ErrPermissionDenied, // Server-level permission was denied
+	916:   ErrPermissionDenied, // The server principal is not able to access the database
+	15247: ErrPermissionDenied, // User does not have permission to perform this action
+
+	952:  ErrDatabaseBusy, // Database is in transition
+	3702: ErrDatabaseBusy, // Cannot drop database because it is currently in use
+	5030: ErrDatabaseBusy, // The database could not be exclusively locked to perform the operation
+	5061: ErrDatabaseBusy, // ALTER DATABASE failed because a lock could not be placed on the database
+	5064: ErrDatabaseBusy, // Changes to the state or options of the database cannot be made at this time
+}
+
+// sqlError attaches a sentinel error to an error returned by SQL Server.
+type sqlError struct {
+	kind error
+	err  error
+}
+
+func (e *sqlError) Error() string {
+	return e.err.Error()
+}
+
+func (e *sqlError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// wrapSQLError classifies a SQL Server error by its error number so that it
+// matches ErrAlreadyExists, ErrNotFound, ErrPermissionDenied or
+// ErrDatabaseBusy. Other errors are returned unchanged.
+func wrapSQLError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var sqlErr interface{ SQLErrorNumber() int32 }
+	if !errors.As(err, &sqlErr) {
+		return err
+	}
+	kind, ok := sqlErrorKinds[sqlErr.SQLErrorNumber()]
+	if !ok || errors.Is(err, kind) {
+		return err
+	}
+
+	return &sqlError{kind: kind, err: err}
+}
@@ -7,43 +7,79 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
+)
+
+// SALoginPrincipalID is the principal ID of the built-in sa login. It keeps
+// this ID when it is renamed, and can be disabled but not dropped.
+const SALoginPrincipalID = 1
+
+// Login types, as stored in sys.server_principals.type.
+const (
+	LoginTypeSQL           = "S"
+	LoginTypeWindowsUser   = "U"
+	LoginTypeWindowsGroup  = "G"
+	LoginTypeExternalUser  = "E"
+	LoginTypeExternalGroup = "X"
 )
 
 // SQLLogin represents a SQL Server login.
 type SQLLogin struct {
 	PrincipalID            int
 	Name                   string
+	SID                    string // Hex string with 0x prefix
+	Type                   string // One of the LoginType constants
 	DefaultDatabaseName    string
 	DefaultLanguageName    string
 	CheckExpirationEnabled bool
 	CheckPolicyEnabled     bool
 	IsDisabled             bool
+	IsLocked               bool // Locked out by the password policy, always false for Windows and external logins
+	CredentialName         string
+	CreateDate             time.Time // With the UTC offset of the server
+	ModifyDate             time.Time
 }
 
-// GetSQLLogin retrieves a SQL login by name.
+// GetSQLLogin retrieves a login by name. Besides SQL logins, it finds Windows
+// and external (Azure AD) logins, whose disabled state is read from
+// sys.server_principals as sys.sql_logins only holds SQL logins.
 func (c *Client) GetSQLLogin(ctx context.Context, name string) (*SQLLogin, error) {
 	query := `
 		SELECT
-			principal_id,
-			name,
-			ISNULL(default_database_name, 'master'),
-			ISNULL(default_language_name, ''),
-			ISNULL(is_expiration_checked, 0),
-			ISNULL(is_policy_checked, 0),
-			is_disabled
-		FROM sys.sql_logins
-		WHERE name = @p1`
+			p.principal_id,
+			p.name,
+			ISNULL(CONVERT(varchar(172), p.sid, 1), ''),
+			p.type,
+			ISNULL(p.default_database_name, 'master'),
+			ISNULL(p.default_language_name, ''),
+			ISNULL(l.is_expiration_checked, 0),
+			ISNULL(l.is_policy_checked, 0),
+			p.is_disabled,
+			ISNULL(CAST(LOGINPROPERTY(p.name, 'IsLocked') AS bit), 0),
+			ISNULL(c.name, ''),
+			TODATETIMEOFFSET(p.create_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET())),
+			TODATETIMEOFFSET(p.modify_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET()))
+		FROM sys.server_principals p
+		LEFT JOIN sys.sql_logins l ON p.principal_id = l.principal_id
+		LEFT JOIN sys.credentials c ON p.credential_id = c.credential_id
+		WHERE p.name = @p1 AND p.type IN ('S', 'U', 'G', 'E', 'X')`
 	row := c.QueryRowContext(ctx, query, name)
 
 	var login SQLLogin
 	err := row.Scan(
 		&login.PrincipalID,
 		&login.Name,
+		&login.SID,
+		&login.Type,
 		&login.DefaultDatabaseName,
 		&login.DefaultLanguageName,
 		&login.CheckExpirationEnabled,
 		&login.CheckPolicyEnabled,
 		&login.IsDisabled,
+		&login.IsLocked,
+		&login.CredentialName,
+		&login.CreateDate,
+		&login.ModifyDate,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -55,30 +91,45 @@ func (c *Client) GetSQLLogin(ctx context.Context, name string) (*SQLLogin, error
 	return &login, nil
 }
 
-// GetSQLLoginByID retrieves a SQL login by principal ID.
+// GetSQLLoginByID retrieves a login by principal ID. Like GetSQLLogin, it finds
+// logins of every type.
 func (c *Client) GetSQLLoginByID(ctx context.Context, id int) (*SQLLogin, error) {
 	query := `
 		SELECT
-			principal_id,
-			name,
-			ISNULL(default_database_name, 'master'),
-			ISNULL(default_language_name, ''),
-			ISNULL(is_expiration_checked, 0),
-			ISNULL(is_policy_checked, 0),
-			is_disabled
-		FROM sys.sql_logins
-		WHERE principal_id = @p1`
+			p.principal_id,
+			p.name,
+			ISNULL(CONVERT(varchar(172), p.sid, 1), ''),
+			p.type,
+			ISNULL(p.default_database_name, 'master'),
+			ISNULL(p.default_language_name, ''),
+			ISNULL(l.is_expiration_checked, 0),
+			ISNULL(l.is_policy_checked, 0),
+			p.is_disabled,
+			ISNULL(CAST(LOGINPROPERTY(p.name, 'IsLocked') AS bit), 0),
+			ISNULL(c.name, ''),
+			TODATETIMEOFFSET(p.create_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET())),
+			TODATETIMEOFFSET(p.modify_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET()))
+		FROM sys.server_principals p
+		LEFT JOIN sys.sql_logins l ON p.principal_id = l.principal_id
+		LEFT JOIN sys.credentials c ON p.credential_id = c.credential_id
+		WHERE p.principal_id = @p1 AND p.type IN ('S', 'U', 'G', 'E', 'X')`
 	row := c.QueryRowContext(ctx, query, id)
 
 	var login SQLLogin
 	err := row.Scan(
 		&login.PrincipalID,
 		&login.Name,
+		&login.SID,
+		&login.Type,
 		&login.DefaultDatabaseName,
 		&login.DefaultLanguageName,
 		&login.CheckExpirationEnabled,
 		&login.CheckPolicyEnabled,
 		&login.IsDisabled,
+		&login.IsLocked,
+		&login.CredentialName,
+		&login.CreateDate,
+		&login.ModifyDate,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -90,19 +141,25 @@ func (c *Client) GetSQLLoginByID(ctx context.Context, id int) (*SQLLogin, error)
 	return &login, nil
 }
 
-// ListSQLLogins retrieves all SQL logins.
+// ListSQLLogins retrieves all SQL logins. Windows and external logins are not
+// included.
 func (c *Client) ListSQLLogins(ctx context.Context) ([]SQLLogin, error) {
 	query := `
 		SELECT
-			principal_id,
-			name,
-			ISNULL(default_database_name, 'master'),
-			ISNULL(default_language_name, ''),
-			ISNULL(is_expiration_checked, 0),
-			ISNULL(is_policy_checked, 0),
-			is_disabled
-		FROM sys.sql_logins
-		ORDER BY name`
+			l.principal_id,
+			l.name,
+			ISNULL(CONVERT(varchar(172), l.sid, 1), ''),
+			ISNULL(l.default_database_name, 'master'),
+			ISNULL(l.default_language_name, ''),
+			ISNULL(l.is_expiration_checked, 0),
+			ISNULL(l.is_policy_checked, 0),
+			l.is_disabled,
+			ISNULL(c.name, ''),
+			TODATETIMEOFFSET(l.create_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET())),
+			TODATETIMEOFFSET(l.modify_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET()))
+		FROM sys.sql_logins l
+		LEFT JOIN sys.credentials c ON l.credential_id = c.credential_id
+		ORDER BY l.name`
 	rows, err := c.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list SQL logins: %w", err)
@@ -112,14 +169,19 @@ func (c *Client) ListSQLLogins(ctx context.Context) ([]SQLLogin, error) {
 	var logins []SQLLogin
 	for rows.Next() {
 		var login SQLLogin
+		login.Type = LoginTypeSQL
 		if err := rows.Scan(
 			&login.PrincipalID,
 			&login.Name,
+			&login.SID,
 			&login.DefaultDatabaseName,
 			&login.DefaultLanguageName,
 			&login.CheckExpirationEnabled,
 			&login.CheckPolicyEnabled,
 			&login.IsDisabled,
+			&login.CredentialName,
+			&login.CreateDate,
+			&login.ModifyDate,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan SQL login: %w", err)
 		}
@@ -137,9 +199,15 @@ type CreateSQLLoginOptions struct {
 	DefaultLanguage        string
 	CheckExpirationEnabled bool
 	CheckPolicyEnabled     bool
+	CredentialName         string
+	// IsDisabled creates the login disabled, so that it can't be used before
+	// it is enabled.
+	IsDisabled bool
 }
 
-// CreateSQLLogin creates a new SQL login.
+// CreateSQLLogin creates a new SQL login. A login created with IsDisabled is
+// disabled right after it is created; if that fails, the login is dropped
+// again so that it is never left enabled.
 func (c *Client) CreateSQLLogin(ctx context.Context, opts CreateSQLLoginOptions) (*SQLLogin, error) {
 	defaultDB := opts.DefaultDatabase
 	if defaultDB == "" {
@@ -161,30 +229,72 @@ func (c *Client) CreateSQLLogin(ctx context.Context, opts CreateSQLLoginOptions)
 	if opts.DefaultLanguage != "" {
 		query += fmt.Sprintf(", DEFAULT_LANGUAGE = [%s]", opts.DefaultLanguage)
 	}
+	if opts.CredentialName != "" {
+		query += fmt.Sprintf(", CREDENTIAL = [%s]", opts.CredentialName)
+	}
 
 	_, err := c.ExecContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create SQL login: %w", err)
 	}
 
-	return c.GetSQLLogin(ctx, opts.Name)
+	if opts.IsDisabled {
+		// CREATE LOGIN must be the only statement in its batch in Azure SQL
+		// Database, so the login is disabled in a second statement
+		disableQuery := fmt.Sprintf("ALTER LOGIN %s DISABLE", quoteIdentifier(opts.Name))
+		if _, err := c.ExecContext(ctx, disableQuery); err != nil {
+			_, _ = c.ExecContext(ctx, fmt.Sprintf("DROP LOGIN %s", quoteIdentifier(opts.Name)))
+			return nil, fmt.Errorf("failed to disable SQL login, the login was dropped again: %w", err)
+		}
+	}
+
+	login, err := c.GetSQLLogin(ctx, opts.Name)
+	if err != nil || login == nil {
+		return login, err
+	}
+	if opts.IsDisabled && !login.IsDisabled {
+		return nil, fmt.Errorf("SQL login '%s' was created, but is not disabled", opts.Name)
+	}
+
+	return login, nil
 }
 
 // UpdateSQLLoginOptions contains options for updating a SQL login.
 type UpdateSQLLoginOptions struct {
 	Name                   string
+	NewName                *string // Renames the login before the other options are applied, keeping its SID
 	Password               *string
 	DefaultDatabase        *string
 	DefaultLanguage        *string
 	CheckExpirationEnabled *bool
 	CheckPolicyEnabled     *bool
 	IsDisabled             *bool
+	// Unlock unlocks a login locked out by the password policy. SQL Server only
+	// accepts it together with a password, so Password must be set.
+	Unlock bool
+	// CredentialName maps the login to a credential. An empty string removes
+	// the current mapping.
+	CredentialName *string
 }
 
 // UpdateSQLLogin updates an existing SQL login.
 func (c *Client) UpdateSQLLogin(ctx context.Context, opts UpdateSQLLoginOptions) (*SQLLogin, error) {
+	if opts.NewName != nil && *opts.NewName != opts.Name {
+		query := fmt.Sprintf("ALTER LOGIN %s WITH NAME = %s", quoteIdentifier(opts.Name), quoteIdentifier(*opts.NewName))
+		if _, err := c.ExecContext(ctx, query); err != nil {
+			return nil, fmt.Errorf("failed to rename SQL login: %w", err)
+		}
+		opts.Name = *opts.NewName
+	}
+
+	if opts.Unlock && opts.Password == nil {
+		return nil, fmt.Errorf("failed to unlock SQL login: a password is required")
+	}
 	if opts.Password != nil {
 		query := fmt.Sprintf("ALTER LOGIN [%s] WITH PASSWORD = '%s'", opts.Name, *opts.Password)
+		if opts.Unlock {
+			query += " UNLOCK"
+		}
 		if _, err := c.ExecContext(ctx, query); err != nil {
 			return nil, fmt.Errorf("failed to update SQL login password: %w", err)
 		}
@@ -204,6 +314,13 @@ func (c *Client) UpdateSQLLogin(ctx context.Context, opts UpdateSQLLoginOptions)
 	if opts.CheckPolicyEnabled != nil {
 		alterParts = append(alterParts, fmt.Sprintf("CHECK_POLICY = %s", boolToOnOff(*opts.CheckPolicyEnabled)))
 	}
+	if opts.CredentialName != nil {
+		if *opts.CredentialName == "" {
+			alterParts = append(alterParts, "NO CREDENTIAL")
+		} else {
+			alterParts = append(alterParts, fmt.Sprintf("CREDENTIAL = [%s]", *opts.CredentialName))
+		}
+	}
 
 	if len(alterParts) > 0 {
 		query := fmt.Sprintf("ALTER LOGIN [%s] WITH ", opts.Name)
@@ -233,6 +350,109 @@ func (c *Client) UpdateSQLLogin(ctx context.Context, opts UpdateSQLLoginOptions)
 	return c.GetSQLLogin(ctx, opts.Name)
 }
 
+// LoginProperties holds the password policy state of a SQL login, which is
+// only available through LOGINPROPERTY.
+type LoginProperties struct {
+	IsLocked            bool
+	IsExpired           bool
+	IsMustChange        bool
+	BadPasswordCount    int
+	PasswordLastSetTime sql.NullTime // With the UTC offset of the server; not set for Windows and external logins
+}
+
+// GetLoginProperties retrieves the password policy state of a login. The values
+// are false or zero for Windows and external logins. It returns nil if the login
+// does not exist.
+func (c *Client) GetLoginProperties(ctx context.Context, name string) (*LoginProperties, error) {
+	query := `
+		SELECT
+			ISNULL(CAST(LOGINPROPERTY(p.name, 'IsLocked') AS bit), 0),
+			ISNULL(CAST(LOGINPROPERTY(p.name, 'IsExpired') AS bit), 0),
+			ISNULL(CAST(LOGINPROPERTY(p.name, 'IsMustChange') AS bit), 0),
+			ISNULL(CAST(LOGINPROPERTY(p.name, 'BadPasswordCount') AS int), 0),
+			TODATETIMEOFFSET(CAST(LOGINPROPERTY(p.name, 'PasswordLastSetTime') AS datetime), DATEPART(TZOFFSET, SYSDATETIMEOFFSET()))
+		FROM sys.server_principals p
+		WHERE p.name = @p1 AND p.type IN ('S', 'U', 'G', 'E', 'X')`
+	row := c.QueryRowContext(ctx, query, name)
+
+	var props LoginProperties
+	err := row.Scan(
+		&props.IsLocked,
+		&props.IsExpired,
+		&props.IsMustChange,
+		&props.BadPasswordCount,
+		&props.PasswordLastSetTime,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get login properties: %w", err)
+	}
+
+	return &props, nil
+}
+
+// GetCurrentLoginName returns the name of the login the client is connected as.
+func (c *Client) GetCurrentLoginName(ctx context.Context) (string, error) {
+	var name string
+	if err := c.QueryRowContext(ctx, "SELECT SUSER_SNAME()").Scan(&name); err != nil {
+		return "", fmt.Errorf("failed to get current login: %w", err)
+	}
+	return name, nil
+}
+
+// LoginHasDatabaseAccess reports whether a login can connect to a database: it
+// is a member of sysadmin, it is mapped to a user there that holds CONNECT,
+// e.g. dbo for the owner, or the guest user of the database is enabled. It
+// returns false if the login does not exist. The login's SID is read on the
+// server, so that the user is also found in Azure SQL databases, which do not
+// see the logins of the server.
+func (c *Client) LoginHasDatabaseAccess(ctx context.Context, loginName, databaseName string) (bool, error) {
+	var sid []byte
+	var sysadmin sql.NullInt64
+	err := c.QueryRowContext(ctx, "SELECT sid, IS_SRVROLEMEMBER('sysadmin', name) FROM sys.server_principals WHERE name = @p1", loginName).
+		Scan(&sid, &sysadmin)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get login: %w", wrapSQLError(err))
+	}
+	if sysadmin.Int64 == 1 {
+		return true, nil
+	}
+
+	query := `
+		SELECT CASE WHEN EXISTS (
+			SELECT 1
+			FROM sys.database_permissions perm
+			INNER JOIN sys.database_principals dp ON perm.grantee_principal_id = dp.principal_id
+			WHERE (dp.sid = @p1 OR dp.name = 'guest')
+				AND perm.class = 0
+				AND perm.permission_name = 'CONNECT'
+				AND perm.state IN ('G', 'W')
+		) THEN 1 ELSE 0 END`
+
+	// Try to get a direct connection to the database first (Azure SQL support)
+	var row *sql.Row
+	db, err := c.GetDatabaseConnection(ctx, databaseName)
+	if err == nil {
+		row = db.QueryRowContext(ctx, query, sid)
+	} else {
+		row, err = c.QueryRowInDatabaseContext(ctx, databaseName, query, sid)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	var access bool
+	if err := row.Scan(&access); err != nil {
+		return false, fmt.Errorf("failed to check database access: %w", wrapSQLError(err))
+	}
+	return access, nil
+}
+
 // DropSQLLogin drops a SQL login.
 func (c *Client) DropSQLLogin(ctx context.Context, name string) error {
 	query := fmt.Sprintf("DROP LOGIN [%s]", name)
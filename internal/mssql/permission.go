@@ -6,7 +6,9 @@ package mssql
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -15,7 +17,7 @@ type Permission struct {
 	PrincipalID    int
 	PrincipalName  string
 	PermissionName string
-	StateDesc      string // GRANT, DENY, REVOKE
+	StateDesc      string // GRANT, GRANT_WITH_GRANT_OPTION or DENY
 	ObjectType     string // DATABASE, SCHEMA, TABLE, etc.
 	ObjectName     string
 }
@@ -32,6 +34,7 @@ type DatabasePermission struct {
 
 // GetDatabasePermission retrieves a specific database permission.
 func (c *Client) GetDatabasePermission(ctx context.Context, databaseName, principalName, permission string) (*DatabasePermission, error) {
+	principalName = normalizePrincipalName(principalName)
 	query := `
 		SELECT
 			dp.principal_id,
@@ -47,19 +50,96 @@ func (c *Client) GetDatabasePermission(ctx context.Context, databaseName, princi
 			AND perm.class = 0`
 
 	// Try to get a direct connection to the database first (Azure SQL support)
+	var queryRow func(query string, args ...interface{}) (*sql.Row, error)
 	db, err := c.GetDatabaseConnection(ctx, databaseName)
 	if err == nil {
-		defer db.Close()
-		row := db.QueryRowContext(ctx, query, principalName, strings.ToUpper(permission))
-		return scanDatabasePermission(row)
+		queryRow = func(query string, args ...interface{}) (*sql.Row, error) {
+			return db.QueryRowContext(ctx, query, args...), nil
+		}
+	} else {
+		queryRow = func(query string, args ...interface{}) (*sql.Row, error) {
+			return c.QueryRowInDatabaseContext(ctx, databaseName, query, args...)
+		}
 	}
 
-	row, err := c.QueryRowInDatabaseContext(ctx, databaseName, query, principalName, strings.ToUpper(permission))
+	row, err := queryRow(query, principalName, strings.ToUpper(permission))
 	if err != nil {
 		return nil, err
 	}
+	perm, err := scanDatabasePermission(row)
+	if err != nil || perm != nil {
+		return perm, err
+	}
 
-	return scanDatabasePermission(row)
+	// Permission not granted directly. Check if a covering permission implies it.
+	for _, covering := range coveringPermissions(permission) {
+		row, err := queryRow(query+" AND perm.state IN ('G', 'W')", principalName, covering)
+		if err != nil {
+			return nil, err
+		}
+		coveringPerm, err := scanDatabasePermission(row)
+		if err != nil {
+			return nil, err
+		}
+		if coveringPerm != nil {
+			return createCoveredDatabasePermission(coveringPerm, permission), nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Permission states as reported by the state_desc column of
+// sys.database_permissions and sys.server_permissions. The state column holds
+// G, W and D respectively; only W carries the grant option.
+const (
+	PermissionStateGrant                = "GRANT"
+	PermissionStateGrantWithGrantOption = "GRANT_WITH_GRANT_OPTION"
+	PermissionStateDeny                 = "DENY"
+)
+
+// PermissionStateDesc returns the state_desc of a granted permission.
+func PermissionStateDesc(withGrantOption bool) string {
+	if withGrantOption {
+		return PermissionStateGrantWithGrantOption
+	}
+	return PermissionStateGrant
+}
+
+// publicPrincipal is the name of the fixed role every database user and login
+// belongs to. It exists both as a database role and as a server role.
+const publicPrincipal = "public"
+
+// normalizePrincipalName maps any casing of PUBLIC to the catalog name of the
+// public role, so that lookups against sys.database_principals and
+// sys.server_principals also match on case-sensitive collations.
+func normalizePrincipalName(name string) string {
+	if strings.EqualFold(name, publicPrincipal) {
+		return publicPrincipal
+	}
+	return name
+}
+
+// coveringPermissions returns the permissions that implicitly confer the given
+// permission on the same securable. CONTROL on a database or schema confers
+// every other permission on that securable, so a principal holding CONTROL
+// already has e.g. SELECT, INSERT, ALTER or VIEW DEFINITION there.
+func coveringPermissions(permission string) []string {
+	if strings.ToUpper(permission) == "CONTROL" {
+		return nil
+	}
+	return []string{"CONTROL"}
+}
+
+func createCoveredDatabasePermission(covering *DatabasePermission, permission string) *DatabasePermission {
+	return &DatabasePermission{
+		PrincipalID:     covering.PrincipalID,
+		PrincipalName:   covering.PrincipalName,
+		PermissionName:  strings.ToUpper(permission),
+		StateDesc:       PermissionStateDesc(covering.WithGrantOption), // Implicit grant
+		DatabaseID:      0,                                             // Unknown/Irrelevant for virtual
+		WithGrantOption: covering.WithGrantOption,
+	}
 }
 
 func scanDatabasePermission(row *sql.Row) (*DatabasePermission, error) {
@@ -83,6 +163,7 @@ func scanDatabasePermission(row *sql.Row) (*DatabasePermission, error) {
 
 // ListDatabasePermissions retrieves all database permissions for a principal.
 func (c *Client) ListDatabasePermissions(ctx context.Context, databaseName, principalName string) ([]DatabasePermission, error) {
+	principalName = normalizePrincipalName(principalName)
 	query := `
 		SELECT
 			dp.principal_id,
@@ -99,7 +180,6 @@ func (c *Client) ListDatabasePermissions(ctx context.Context, databaseName, prin
 	// Try to get a direct connection to the database first (Azure SQL support)
 	db, err := c.GetDatabaseConnection(ctx, databaseName)
 	if err == nil {
-		defer db.Close()
 		rows, err := db.QueryContext(ctx, query, principalName)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list database permissions: %w", err)
@@ -118,7 +198,7 @@ func (c *Client) ListDatabasePermissions(ctx context.Context, databaseName, prin
 
 	// Switch to the target database
 	if _, err := conn.ExecContext(ctx, fmt.Sprintf("USE [%s]", databaseName)); err != nil {
-		return nil, fmt.Errorf("failed to switch database context: %w", err)
+		return nil, fmt.Errorf("failed to switch database context: %w", wrapSQLError(err))
 	}
 
 	rows, err := conn.QueryContext(ctx, query, principalName)
@@ -150,8 +230,11 @@ func scanDatabasePermissionsRows(rows *sql.Rows) ([]DatabasePermission, error) {
 	return perms, rows.Err()
 }
 
-// GrantDatabasePermission grants a database-level permission.
+// GrantDatabasePermission grants a database-level permission. If the principal
+// is not found, the grant is retried for a bounded time, since a principal
+// created in the same apply may not be visible on this connection yet.
 func (c *Client) GrantDatabasePermission(ctx context.Context, databaseName, principalName, permission string, withGrantOption bool) error {
+	principalName = normalizePrincipalName(principalName)
 	query := fmt.Sprintf("GRANT %s TO [%s]", strings.ToUpper(permission), principalName)
 	if withGrantOption {
 		query += " WITH GRANT OPTION"
@@ -160,13 +243,15 @@ func (c *Client) GrantDatabasePermission(ctx context.Context, databaseName, prin
 	// Try to get a direct connection to the database first (Azure SQL support)
 	db, err := c.GetDatabaseConnection(ctx, databaseName)
 	if err == nil {
-		defer db.Close()
-		_, err = db.ExecContext(ctx, query)
-		return err
+		return retryWhileNotFound(ctx, func() error {
+			return execSQL(ctx, db, query)
+		})
 	}
 
 	// Fallback to existing logic
-	err = c.ExecInDatabaseContext(ctx, databaseName, query)
+	err = retryWhileNotFound(ctx, func() error {
+		return c.ExecInDatabaseContext(ctx, databaseName, query)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to grant database permission: %w", err)
 	}
@@ -174,15 +259,27 @@ func (c *Client) GrantDatabasePermission(ctx context.Context, databaseName, prin
 	return nil
 }
 
-// RevokeDatabasePermission revokes a database-level permission.
-func (c *Client) RevokeDatabasePermission(ctx context.Context, databaseName, principalName, permission string) error {
+// RevokeDatabasePermission revokes a database-level permission. Cascade
+// behaves as for RevokeSchemaPermission. It does nothing if the principal no
+// longer exists.
+func (c *Client) RevokeDatabasePermission(ctx context.Context, databaseName, principalName, permission string, cascade bool) error {
+	principalName = normalizePrincipalName(principalName)
+	if exists, err := c.DatabasePrincipalExists(ctx, databaseName, principalName); err != nil || !exists {
+		return err
+	}
+	cascade, err := c.revokeCascade(ctx, databaseName, 0, "0", cascade, principalName, permission)
+	if err != nil {
+		return err
+	}
 	query := fmt.Sprintf("REVOKE %s FROM [%s]", strings.ToUpper(permission), principalName)
+	if cascade {
+		query += " CASCADE"
+	}
 
 	// Try to get a direct connection to the database first (Azure SQL support)
 	db, err := c.GetDatabaseConnection(ctx, databaseName)
 	if err == nil {
-		defer db.Close()
-		_, err = db.ExecContext(ctx, query)
+		err = execSQL(ctx, db, query)
 		return err
 	}
 
@@ -195,85 +292,214 @@ func (c *Client) RevokeDatabasePermission(ctx context.Context, databaseName, pri
 	return nil
 }
 
-// SchemaPermission represents a schema-level permission.
-type SchemaPermission struct {
-	PrincipalID     int
-	PrincipalName   string
-	PermissionName  string
-	StateDesc       string
-	SchemaName      string
-	DatabaseID      int
-	WithGrantOption bool
+// Database securable types that permissions can be granted on. DATABASE names
+// the database itself explicitly, as in GRANT ... ON DATABASE::[name].
+const (
+	SecurableTypeDatabase      = "DATABASE"
+	SecurableTypeCertificate   = "CERTIFICATE"
+	SecurableTypeSymmetricKey  = "SYMMETRIC_KEY"
+	SecurableTypeAsymmetricKey = "ASYMMETRIC_KEY"
+	SecurableTypeUser          = "USER"
+	SecurableTypeRole          = "ROLE"
+)
+
+// databaseSecurable describes how permissions on a type of database securable
+// are granted and where they are found in sys.database_permissions.
+type databaseSecurable struct {
+	class       int    // class of sys.database_permissions
+	catalogView string // catalog view holding the securable, joined on major_id
+	idColumn    string
+	keyword     string // securable class of the GRANT statement
+	filter      string // condition on the catalog view, aliased s, if it holds several types
 }
 
-// GetSchemaPermission retrieves a specific schema permission.
-func (c *Client) GetSchemaPermission(ctx context.Context, databaseName, schemaName, principalName, permission string) (*SchemaPermission, error) {
-	// Try to get a direct connection to the database first (Azure SQL support)
-	db, err := c.GetDatabaseConnection(ctx, databaseName)
-	if err == nil {
-		defer db.Close()
-		return c.getSchemaPermissionWithDB(ctx, db, schemaName, principalName, permission)
+var databaseSecurables = map[string]databaseSecurable{
+	// Permissions on the database are class 0 with major_id 0 whether or not
+	// the GRANT names the database, so they are read like implicit ones
+	SecurableTypeDatabase:      {class: 0, keyword: "DATABASE"},
+	SecurableTypeSymmetricKey:  {class: 24, catalogView: "sys.symmetric_keys", idColumn: "symmetric_key_id", keyword: "SYMMETRIC KEY"},
+	SecurableTypeCertificate:   {class: 25, catalogView: "sys.certificates", idColumn: "certificate_id", keyword: "CERTIFICATE"},
+	SecurableTypeAsymmetricKey: {class: 26, catalogView: "sys.asymmetric_keys", idColumn: "asymmetric_key_id", keyword: "ASYMMETRIC KEY"},
+	// Users and roles are database principals (class 4), e.g. ALTER on a user
+	// for delegated user management
+	SecurableTypeUser: {class: 4, catalogView: "sys.database_principals", idColumn: "principal_id", keyword: "USER", filter: "s.type NOT IN ('R', 'A')"},
+	SecurableTypeRole: {class: 4, catalogView: "sys.database_principals", idColumn: "principal_id", keyword: "ROLE", filter: "s.type = 'R'"},
+}
+
+// IsDatabaseSecurableType reports whether permissions can be granted on
+// database securables of the given type.
+func IsDatabaseSecurableType(securableType string) bool {
+	_, ok := databaseSecurables[strings.ToUpper(securableType)]
+	return ok
+}
+
+func lookupDatabaseSecurable(securableType string) (databaseSecurable, error) {
+	securable, ok := databaseSecurables[strings.ToUpper(securableType)]
+	if !ok {
+		return securable, fmt.Errorf("unsupported securable type: %s", securableType)
 	}
+	return securable, nil
+}
 
-	// Fallback to existing logic
-	query := `
+// checkDatabaseSecurableName returns an error if a DATABASE securable names
+// another database than the one the statement runs in. SQL Server only grants
+// permissions on the current database, so a grant on another database cannot
+// be issued from master or any other database.
+func (c *Client) checkDatabaseSecurableName(securable databaseSecurable, databaseName, securableName string) error {
+	if securable.class == 0 && !c.NamesEqual(securableName, databaseName) {
+		return fmt.Errorf("permissions on database '%s' must be granted in that database, not in '%s'", securableName, databaseName)
+	}
+	return nil
+}
+
+// GetDatabaseSecurablePermission retrieves a specific permission on the
+// database, or on a certificate, symmetric key, asymmetric key, user or role of
+// a database.
+func (c *Client) GetDatabaseSecurablePermission(ctx context.Context, databaseName, securableType, securableName, principalName, permission string) (*DatabasePermission, error) {
+	securable, err := lookupDatabaseSecurable(securableType)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkDatabaseSecurableName(securable, databaseName, securableName); err != nil {
+		return nil, err
+	}
+	if securable.class == 0 {
+		return c.GetDatabasePermission(ctx, databaseName, principalName, permission)
+	}
+	principalName = normalizePrincipalName(principalName)
+	filter := ""
+	if securable.filter != "" {
+		filter = "AND " + securable.filter
+	}
+	query := fmt.Sprintf(`
 		SELECT
 			dp.principal_id,
 			dp.name,
 			perm.permission_name,
 			perm.state_desc,
-			s.name,
 			DB_ID(),
 			CASE WHEN perm.state = 'W' THEN 1 ELSE 0 END
 		FROM sys.database_permissions perm
 		INNER JOIN sys.database_principals dp ON perm.grantee_principal_id = dp.principal_id
-		INNER JOIN sys.schemas s ON perm.major_id = s.schema_id
+		INNER JOIN %s s ON perm.major_id = s.%s
 		WHERE dp.name = @p1
 			AND perm.permission_name = @p2
 			AND s.name = @p3
-			AND perm.class = 3`
+			AND perm.class = %d
+			%s`, securable.catalogView, securable.idColumn, securable.class, filter)
 
-	row, err := c.QueryRowInDatabaseContext(ctx, databaseName, query, principalName, strings.ToUpper(permission), schemaName)
+	// Try to get a direct connection to the database first (Azure SQL support)
+	var row *sql.Row
+	db, err := c.GetDatabaseConnection(ctx, databaseName)
+	if err == nil {
+		row = db.QueryRowContext(ctx, query, principalName, strings.ToUpper(permission), securableName)
+	} else {
+		row, err = c.QueryRowInDatabaseContext(ctx, databaseName, query, principalName, strings.ToUpper(permission), securableName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return scanDatabasePermission(row)
+}
+
+// GrantDatabaseSecurablePermission grants a permission on the database, or on
+// a certificate, symmetric key, asymmetric key, user or role, e.g. CONTROL on a
+// certificate used for module signing or ALTER on a user. Like
+// GrantDatabasePermission, it retries while the principal is not found.
+func (c *Client) GrantDatabaseSecurablePermission(ctx context.Context, databaseName, securableType, securableName, principalName, permission string, withGrantOption bool) error {
+	securable, err := lookupDatabaseSecurable(securableType)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if err := c.checkDatabaseSecurableName(securable, databaseName, securableName); err != nil {
+		return err
+	}
+	principalName = normalizePrincipalName(principalName)
+	query := fmt.Sprintf("GRANT %s ON %s::%s TO %s", strings.ToUpper(permission), securable.keyword, quoteIdentifier(securableName), quoteIdentifier(principalName))
+	if withGrantOption {
+		query += " WITH GRANT OPTION"
 	}
 
-	perm, err := scanSchemaPermission(row)
+	// Try to get a direct connection to the database first (Azure SQL support)
+	db, err := c.GetDatabaseConnection(ctx, databaseName)
 	if err == nil {
-		return perm, nil
+		return retryWhileNotFound(ctx, func() error {
+			return execSQL(ctx, db, query)
+		})
 	}
-	if err != sql.ErrNoRows {
-		return nil, err
+
+	err = retryWhileNotFound(ctx, func() error {
+		return c.ExecInDatabaseContext(ctx, databaseName, query)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to grant %s permission: %w", strings.ToLower(securable.keyword), err)
 	}
 
-	// Permission not found. Check if the principal is the owner of the schema.
-	ownerQuery := `
-		SELECT
-			dp.principal_id,
-			dp.name
-		FROM sys.schemas s
-		INNER JOIN sys.database_principals dp ON s.principal_id = dp.principal_id
-		WHERE s.name = @p1 AND dp.name = @p2`
+	return nil
+}
 
-	ownerRow, err := c.QueryRowInDatabaseContext(ctx, databaseName, ownerQuery, schemaName, principalName)
+// RevokeDatabaseSecurablePermission revokes a permission on the database, or
+// on a certificate, symmetric key, asymmetric key, user or role. Cascade
+// behaves as for RevokeSchemaPermission. It does nothing if the principal no
+// longer exists.
+func (c *Client) RevokeDatabaseSecurablePermission(ctx context.Context, databaseName, securableType, securableName, principalName, permission string, cascade bool) error {
+	securable, err := lookupDatabaseSecurable(securableType)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check schema ownership: %w", err)
+		return err
+	}
+	if err := c.checkDatabaseSecurableName(securable, databaseName, securableName); err != nil {
+		return err
+	}
+	principalName = normalizePrincipalName(principalName)
+	if exists, err := c.DatabasePrincipalExists(ctx, databaseName, principalName); err != nil || !exists {
+		return err
+	}
+	majorID := "0"
+	if securable.class != 0 {
+		majorID = catalogID(securable.catalogView+" s", securable.idColumn, securable.filter)
+	}
+	cascade, err = c.revokeCascade(ctx, databaseName, securable.class, majorID, cascade, principalName, permission, securableName)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("REVOKE %s ON %s::%s FROM %s", strings.ToUpper(permission), securable.keyword, quoteIdentifier(securableName), quoteIdentifier(principalName))
+	if cascade {
+		query += " CASCADE"
 	}
 
-	var ownerID int
-	var ownerName string
-	err = ownerRow.Scan(&ownerID, &ownerName)
-	if err == sql.ErrNoRows {
-		return nil, nil
+	// Try to get a direct connection to the database first (Azure SQL support)
+	db, err := c.GetDatabaseConnection(ctx, databaseName)
+	if err == nil {
+		return execSQL(ctx, db, query)
 	}
+
+	err = c.ExecInDatabaseContext(ctx, databaseName, query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to scan schema owner: %w", err)
+		return fmt.Errorf("failed to revoke %s permission: %w", strings.ToLower(securable.keyword), err)
 	}
 
-	return createImplicitSchemaPermission(ownerID, ownerName, permission, schemaName), nil
+	return nil
 }
 
-func (c *Client) getSchemaPermissionWithDB(ctx context.Context, db *sql.DB, schemaName, principalName, permission string) (*SchemaPermission, error) {
+// SchemaPermission represents a schema-level permission.
+type SchemaPermission struct {
+	PrincipalID     int
+	PrincipalName   string
+	PermissionName  string
+	StateDesc       string
+	SchemaName      string
+	DatabaseID      int
+	WithGrantOption bool
+}
+
+// GetSchemaPermission retrieves a specific schema permission. An explicit row
+// in sys.database_permissions always takes precedence, so that its state and
+// grant option are reported as granted. Only if there is none, a covering
+// permission or the ownership of the schema is reported as a virtual
+// permission with DatabaseID 0.
+func (c *Client) GetSchemaPermission(ctx context.Context, databaseName, schemaName, principalName, permission string) (*SchemaPermission, error) {
+	principalName = normalizePrincipalName(principalName)
 	query := `
 		SELECT
 			dp.principal_id,
@@ -291,7 +517,23 @@ func (c *Client) getSchemaPermissionWithDB(ctx context.Context, db *sql.DB, sche
 			AND s.name = @p3
 			AND perm.class = 3`
 
-	row := db.QueryRowContext(ctx, query, principalName, strings.ToUpper(permission), schemaName)
+	// Try to get a direct connection to the database first (Azure SQL support)
+	var queryRow func(query string, args ...interface{}) (*sql.Row, error)
+	db, err := c.GetDatabaseConnection(ctx, databaseName)
+	if err == nil {
+		queryRow = func(query string, args ...interface{}) (*sql.Row, error) {
+			return db.QueryRowContext(ctx, query, args...), nil
+		}
+	} else {
+		queryRow = func(query string, args ...interface{}) (*sql.Row, error) {
+			return c.QueryRowInDatabaseContext(ctx, databaseName, query, args...)
+		}
+	}
+
+	row, err := queryRow(query, principalName, strings.ToUpper(permission), schemaName)
+	if err != nil {
+		return nil, err
+	}
 	perm, err := scanSchemaPermission(row)
 	if err == nil {
 		return perm, nil
@@ -300,6 +542,23 @@ func (c *Client) getSchemaPermissionWithDB(ctx context.Context, db *sql.DB, sche
 		return nil, err
 	}
 
+	// Permission not granted directly. Check if a covering permission implies
+	// it. Only CONTROL on the schema counts, not CONTROL on the database or
+	// db_owner membership, so that the permission is granted explicitly.
+	for _, covering := range coveringPermissions(permission) {
+		row, err := queryRow(query+" AND perm.state IN ('G', 'W')", principalName, covering, schemaName)
+		if err != nil {
+			return nil, err
+		}
+		coveringPerm, err := scanSchemaPermission(row)
+		if err == nil {
+			return createCoveredSchemaPermission(coveringPerm, permission), nil
+		}
+		if err != sql.ErrNoRows {
+			return nil, err
+		}
+	}
+
 	// Permission not found. Check if the principal is the owner of the schema.
 	ownerQuery := `
 		SELECT
@@ -309,7 +568,11 @@ func (c *Client) getSchemaPermissionWithDB(ctx context.Context, db *sql.DB, sche
 		INNER JOIN sys.database_principals dp ON s.principal_id = dp.principal_id
 		WHERE s.name = @p1 AND dp.name = @p2`
 
-	ownerRow := db.QueryRowContext(ctx, ownerQuery, schemaName, principalName)
+	ownerRow, err := queryRow(ownerQuery, schemaName, principalName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check schema ownership: %w", err)
+	}
+
 	var ownerID int
 	var ownerName string
 	err = ownerRow.Scan(&ownerID, &ownerName)
@@ -348,15 +611,28 @@ func createImplicitSchemaPermission(ownerID int, ownerName, permission, schemaNa
 		PrincipalID:     ownerID,
 		PrincipalName:   ownerName,
 		PermissionName:  strings.ToUpper(permission),
-		StateDesc:       "GRANT", // Implicit grant
+		StateDesc:       PermissionStateGrantWithGrantOption, // Implicit grant
 		SchemaName:      schemaName,
 		DatabaseID:      0,    // Unknown/Irrelevant for virtual
 		WithGrantOption: true, // Owners effectively have grant option (CONTROL)
 	}
 }
 
+func createCoveredSchemaPermission(covering *SchemaPermission, permission string) *SchemaPermission {
+	return &SchemaPermission{
+		PrincipalID:     covering.PrincipalID,
+		PrincipalName:   covering.PrincipalName,
+		PermissionName:  strings.ToUpper(permission),
+		StateDesc:       PermissionStateDesc(covering.WithGrantOption), // Implicit grant
+		SchemaName:      covering.SchemaName,
+		DatabaseID:      0, // Unknown/Irrelevant for virtual
+		WithGrantOption: covering.WithGrantOption,
+	}
+}
+
 // ListSchemaPermissions retrieves all schema permissions for a principal.
 func (c *Client) ListSchemaPermissions(ctx context.Context, databaseName, schemaName, principalName string) ([]SchemaPermission, error) {
+	principalName = normalizePrincipalName(principalName)
 	query := `
 		SELECT
 			dp.principal_id,
@@ -375,7 +651,6 @@ func (c *Client) ListSchemaPermissions(ctx context.Context, databaseName, schema
 	// Try to get a direct connection to the database first (Azure SQL support)
 	db, err := c.GetDatabaseConnection(ctx, databaseName)
 	if err == nil {
-		defer db.Close()
 		rows, err := db.QueryContext(ctx, query, principalName, schemaName)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list schema permissions: %w", err)
@@ -394,7 +669,7 @@ func (c *Client) ListSchemaPermissions(ctx context.Context, databaseName, schema
 
 	// Switch to the target database
 	if _, err := conn.ExecContext(ctx, fmt.Sprintf("USE [%s]", databaseName)); err != nil {
-		return nil, fmt.Errorf("failed to switch database context: %w", err)
+		return nil, fmt.Errorf("failed to switch database context: %w", wrapSQLError(err))
 	}
 
 	rows, err := conn.QueryContext(ctx, query, principalName, schemaName)
@@ -406,9 +681,72 @@ func (c *Client) ListSchemaPermissions(ctx context.Context, databaseName, schema
 	return scanSchemaPermissionsRows(rows)
 }
 
+// ListEffectiveSchemaPermissions retrieves the schema permissions a principal
+// holds directly and through its role memberships, including nested roles and
+// the public role. The PrincipalName of each permission is the grantee, i.e.
+// the principal itself or the role it inherits the permission from.
+func (c *Client) ListEffectiveSchemaPermissions(ctx context.Context, databaseName, schemaName, principalName string) ([]SchemaPermission, error) {
+	principalName = normalizePrincipalName(principalName)
+	query := `
+		WITH principals AS (
+			SELECT principal_id FROM sys.database_principals WHERE name = @p1
+			UNION ALL
+			SELECT drm.role_principal_id
+			FROM sys.database_role_members drm
+			INNER JOIN principals p ON drm.member_principal_id = p.principal_id
+		)
+		SELECT
+			dp.principal_id,
+			dp.name,
+			perm.permission_name,
+			perm.state_desc,
+			s.name,
+			DB_ID(),
+			CASE WHEN perm.state = 'W' THEN 1 ELSE 0 END
+		FROM sys.database_permissions perm
+		INNER JOIN sys.database_principals dp ON perm.grantee_principal_id = dp.principal_id
+		INNER JOIN sys.schemas s ON perm.major_id = s.schema_id
+		WHERE s.name = @p2 AND perm.class = 3
+			AND (dp.principal_id IN (SELECT principal_id FROM principals) OR dp.name = 'public')
+		ORDER BY perm.permission_name, dp.name`
+
+	// Try to get a direct connection to the database first (Azure SQL support)
+	db, err := c.GetDatabaseConnection(ctx, databaseName)
+	if err == nil {
+		rows, err := db.QueryContext(ctx, query, principalName, schemaName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list effective schema permissions: %w", err)
+		}
+		defer rows.Close()
+		return scanSchemaPermissionsRows(rows)
+	}
+
+	// Fallback to existing logic
+	// Get a dedicated connection from the pool
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection: %w", err)
+	}
+	defer conn.Close()
+
+	// Switch to the target database
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("USE [%s]", databaseName)); err != nil {
+		return nil, fmt.Errorf("failed to switch database context: %w", wrapSQLError(err))
+	}
+
+	rows, err := conn.QueryContext(ctx, query, principalName, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list effective schema permissions: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSchemaPermissionsRows(rows)
+}
+
 // GrantSchemaPermission grants a schema-level permission.
 func (c *Client) GrantSchemaPermission(ctx context.Context, databaseName, schemaName, principalName, permission string, withGrantOption bool) error {
-	query := fmt.Sprintf("GRANT %s ON SCHEMA::[%s] TO [%s]", strings.ToUpper(permission), schemaName, principalName)
+	principalName = normalizePrincipalName(principalName)
+	query := fmt.Sprintf("GRANT %s ON SCHEMA::%s TO %s", strings.ToUpper(permission), quoteIdentifier(schemaName), quoteIdentifier(principalName))
 	if withGrantOption {
 		query += " WITH GRANT OPTION"
 	}
@@ -416,8 +754,7 @@ func (c *Client) GrantSchemaPermission(ctx context.Context, databaseName, schema
 	// Try to get a direct connection to the database first (Azure SQL support)
 	db, err := c.GetDatabaseConnection(ctx, databaseName)
 	if err == nil {
-		defer db.Close()
-		_, err = db.ExecContext(ctx, query)
+		err = execSQL(ctx, db, query)
 		return err
 	}
 
@@ -431,25 +768,51 @@ func (c *Client) GrantSchemaPermission(ctx context.Context, databaseName, schema
 }
 
 // RevokeSchemaPermission revokes a schema-level permission.
-// CASCADE is used to also revoke any permissions that were granted by this principal.
-func (c *Client) RevokeSchemaPermission(ctx context.Context, databaseName, schemaName, principalName, permission string) error {
-	query := fmt.Sprintf("REVOKE %s ON SCHEMA::[%s] FROM [%s] CASCADE", strings.ToUpper(permission), schemaName, principalName)
+// With cascade, permissions that were granted onwards by this principal are
+// revoked as well. Without it, revoking a permission that was granted onwards
+// fails; see revokeCascade. It does nothing if the principal no longer exists.
+func (c *Client) RevokeSchemaPermission(ctx context.Context, databaseName, schemaName, principalName, permission string, cascade bool) error {
+	query := fmt.Sprintf("REVOKE %s ON SCHEMA::%s FROM %s", strings.ToUpper(permission), quoteIdentifier(schemaName), quoteIdentifier(normalizePrincipalName(principalName)))
+	if err := c.revokeSchemaPermission(ctx, databaseName, schemaName, principalName, permission, query, cascade); err != nil {
+		return fmt.Errorf("failed to revoke schema permission: %w", err)
+	}
+	return nil
+}
+
+// RevokeSchemaPermissionGrantOption revokes only the grant option of a
+// schema-level permission, so the principal keeps the permission itself.
+// Cascade behaves as for RevokeSchemaPermission. It does nothing if the
+// principal no longer exists.
+func (c *Client) RevokeSchemaPermissionGrantOption(ctx context.Context, databaseName, schemaName, principalName, permission string, cascade bool) error {
+	query := fmt.Sprintf("REVOKE GRANT OPTION FOR %s ON SCHEMA::%s FROM %s", NormalizePermissionName(permission), quoteIdentifier(schemaName), quoteIdentifier(normalizePrincipalName(principalName)))
+	if err := c.revokeSchemaPermission(ctx, databaseName, schemaName, principalName, permission, query, cascade); err != nil {
+		return fmt.Errorf("failed to revoke schema permission grant option: %w", err)
+	}
+	return nil
+}
+
+// revokeSchemaPermission runs a schema-level REVOKE statement in the database.
+func (c *Client) revokeSchemaPermission(ctx context.Context, databaseName, schemaName, principalName, permission, query string, cascade bool) error {
+	principalName = normalizePrincipalName(principalName)
+	if exists, err := c.DatabasePrincipalExists(ctx, databaseName, principalName); err != nil || !exists {
+		return err
+	}
+	cascade, err := c.revokeCascade(ctx, databaseName, 3, "SCHEMA_ID(@p3)", cascade, principalName, permission, schemaName)
+	if err != nil {
+		return err
+	}
+	if cascade {
+		query += " CASCADE"
+	}
 
 	// Try to get a direct connection to the database first (Azure SQL support)
 	db, err := c.GetDatabaseConnection(ctx, databaseName)
 	if err == nil {
-		defer db.Close()
-		_, err = db.ExecContext(ctx, query)
-		return err
+		return execSQL(ctx, db, query)
 	}
 
 	// Fallback to existing logic
-	err = c.ExecInDatabaseContext(ctx, databaseName, query)
-	if err != nil {
-		return fmt.Errorf("failed to revoke schema permission: %w", err)
-	}
-
-	return nil
+	return c.ExecInDatabaseContext(ctx, databaseName, query)
 }
 
 func scanSchemaPermissionsRows(rows *sql.Rows) ([]SchemaPermission, error) {
@@ -484,6 +847,7 @@ type ServerPermission struct {
 
 // GetServerPermission retrieves a specific server permission.
 func (c *Client) GetServerPermission(ctx context.Context, principalName, permission string) (*ServerPermission, error) {
+	principalName = normalizePrincipalName(principalName)
 	query := `
 		SELECT
 			sp.principal_id,
@@ -496,7 +860,7 @@ func (c *Client) GetServerPermission(ctx context.Context, principalName, permiss
 		WHERE sp.name = @p1
 			AND perm.permission_name = @p2
 			AND perm.class = 100`
-	row := c.QueryRowContext(ctx, query, principalName, strings.ToUpper(permission))
+	row := c.QueryRowContext(ctx, query, principalName, NormalizePermissionName(permission))
 
 	var perm ServerPermission
 	err := row.Scan(
@@ -518,6 +882,7 @@ func (c *Client) GetServerPermission(ctx context.Context, principalName, permiss
 
 // ListServerPermissions retrieves all server permissions for a principal.
 func (c *Client) ListServerPermissions(ctx context.Context, principalName string) ([]ServerPermission, error) {
+	principalName = normalizePrincipalName(principalName)
 	query := `
 		SELECT
 			sp.principal_id,
@@ -553,9 +918,77 @@ func (c *Client) ListServerPermissions(ctx context.Context, principalName string
 	return perms, rows.Err()
 }
 
+// serverPermissions are the permissions that can be granted on the server
+// itself, as listed by sys.fn_builtin_permissions('SERVER').
+var serverPermissions = map[string]bool{
+	"ADMINISTER BULK OPERATIONS":                    true,
+	"ALTER ANY AVAILABILITY GROUP":                  true,
+	"ALTER ANY CONNECTION":                          true,
+	"ALTER ANY CREDENTIAL":                          true,
+	"ALTER ANY DATABASE":                            true,
+	"ALTER ANY ENDPOINT":                            true,
+	"ALTER ANY EVENT NOTIFICATION":                  true,
+	"ALTER ANY EVENT SESSION":                       true,
+	"ALTER ANY EVENT SESSION ADD EVENT":             true,
+	"ALTER ANY EVENT SESSION ADD TARGET":            true,
+	"ALTER ANY EVENT SESSION DISABLE":               true,
+	"ALTER ANY EVENT SESSION DROP EVENT":            true,
+	"ALTER ANY EVENT SESSION DROP TARGET":           true,
+	"ALTER ANY EVENT SESSION ENABLE":                true,
+	"ALTER ANY EVENT SESSION OPTION":                true,
+	"ALTER ANY LINKED SERVER":                       true,
+	"ALTER ANY LOGIN":                               true,
+	"ALTER ANY SERVER AUDIT":                        true,
+	"ALTER ANY SERVER ROLE":                         true,
+	"ALTER RESOURCES":                               true,
+	"ALTER SERVER STATE":                            true,
+	"ALTER SETTINGS":                                true,
+	"ALTER TRACE":                                   true,
+	"AUTHENTICATE SERVER":                           true,
+	"CONNECT ANY DATABASE":                          true,
+	"CONNECT SQL":                                   true,
+	"CONTROL SERVER":                                true,
+	"CREATE ANY DATABASE":                           true,
+	"CREATE ANY EVENT SESSION":                      true,
+	"CREATE AVAILABILITY GROUP":                     true,
+	"CREATE DDL EVENT NOTIFICATION":                 true,
+	"CREATE ENDPOINT":                               true,
+	"CREATE LOGIN":                                  true,
+	"CREATE SERVER ROLE":                            true,
+	"CREATE TRACE EVENT NOTIFICATION":               true,
+	"DROP ANY EVENT SESSION":                        true,
+	"EXTERNAL ACCESS ASSEMBLY":                      true,
+	"IMPERSONATE ANY LOGIN":                         true,
+	"SELECT ALL USER SECURABLES":                    true,
+	"SHUTDOWN":                                      true,
+	"UNSAFE ASSEMBLY":                               true,
+	"VIEW ANY CRYPTOGRAPHICALLY SECURED DEFINITION": true,
+	"VIEW ANY DATABASE":                             true,
+	"VIEW ANY DEFINITION":                           true,
+	"VIEW ANY ERROR LOG":                            true,
+	"VIEW ANY PERFORMANCE DEFINITION":               true,
+	"VIEW ANY SECURITY DEFINITION":                  true,
+	"VIEW SERVER PERFORMANCE STATE":                 true,
+	"VIEW SERVER SECURITY AUDIT":                    true,
+	"VIEW SERVER SECURITY STATE":                    true,
+	"VIEW SERVER STATE":                             true,
+}
+
+// NormalizePermissionName upper-cases a permission name and collapses the
+// whitespace between its words, e.g. "alter  any login" to "ALTER ANY LOGIN".
+func NormalizePermissionName(permission string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(permission)), " ")
+}
+
+// IsServerPermission reports whether permission can be granted on the server.
+func IsServerPermission(permission string) bool {
+	return serverPermissions[NormalizePermissionName(permission)]
+}
+
 // GrantServerPermission grants a server-level permission.
 func (c *Client) GrantServerPermission(ctx context.Context, principalName, permission string, withGrantOption bool) error {
-	query := fmt.Sprintf("GRANT %s TO [%s]", strings.ToUpper(permission), principalName)
+	principalName = normalizePrincipalName(principalName)
+	query := fmt.Sprintf("GRANT %s TO [%s]", NormalizePermissionName(permission), principalName)
 	if withGrantOption {
 		query += " WITH GRANT OPTION"
 	}
@@ -568,13 +1001,624 @@ func (c *Client) GrantServerPermission(ctx context.Context, principalName, permi
 	return nil
 }
 
-// RevokeServerPermission revokes a server-level permission.
-func (c *Client) RevokeServerPermission(ctx context.Context, principalName, permission string) error {
-	query := fmt.Sprintf("REVOKE %s FROM [%s]", strings.ToUpper(permission), principalName)
-	_, err := c.ExecContext(ctx, query)
+// RevokeServerPermission revokes a server-level permission. Cascade behaves
+// as for RevokeSchemaPermission. It does nothing if the principal no longer
+// exists.
+func (c *Client) RevokeServerPermission(ctx context.Context, principalName, permission string, cascade bool) error {
+	principalName = normalizePrincipalName(principalName)
+	if exists, err := c.ServerPrincipalExists(ctx, principalName); err != nil || !exists {
+		return err
+	}
+	cascade, err := c.revokeCascade(ctx, "", 100, "0", cascade, principalName, permission)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("REVOKE %s FROM [%s]", NormalizePermissionName(permission), principalName)
+	if cascade {
+		query += " CASCADE"
+	}
+	_, err = c.ExecContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("failed to revoke server permission: %w", err)
 	}
 
 	return nil
 }
+
+// Server securable types that permissions can be granted on, in addition to
+// the server itself.
+const (
+	SecurableTypeEndpoint = "ENDPOINT"
+)
+
+// GetEndpointPermission retrieves a specific permission on an endpoint (class 105).
+func (c *Client) GetEndpointPermission(ctx context.Context, endpointName, principalName, permission string) (*ServerPermission, error) {
+	principalName = normalizePrincipalName(principalName)
+	query := `
+		SELECT
+			sp.principal_id,
+			sp.name,
+			perm.permission_name,
+			perm.state_desc,
+			CASE WHEN perm.state = 'W' THEN 1 ELSE 0 END
+		FROM sys.server_permissions perm
+		INNER JOIN sys.server_principals sp ON perm.grantee_principal_id = sp.principal_id
+		INNER JOIN sys.endpoints e ON perm.major_id = e.endpoint_id
+		WHERE sp.name = @p1
+			AND perm.permission_name = @p2
+			AND e.name = @p3
+			AND perm.class = 105`
+	row := c.QueryRowContext(ctx, query, principalName, NormalizePermissionName(permission), endpointName)
+
+	var perm ServerPermission
+	err := row.Scan(
+		&perm.PrincipalID,
+		&perm.PrincipalName,
+		&perm.PermissionName,
+		&perm.StateDesc,
+		&perm.WithGrantOption,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get endpoint permission: %w", err)
+	}
+
+	return &perm, nil
+}
+
+// GrantEndpointPermission grants a permission on an endpoint, e.g. CONNECT on
+// the database mirroring endpoint used by Always On availability groups.
+func (c *Client) GrantEndpointPermission(ctx context.Context, endpointName, principalName, permission string, withGrantOption bool) error {
+	principalName = normalizePrincipalName(principalName)
+	query := fmt.Sprintf("GRANT %s ON ENDPOINT::[%s] TO [%s]", NormalizePermissionName(permission), endpointName, principalName)
+	if withGrantOption {
+		query += " WITH GRANT OPTION"
+	}
+
+	_, err := c.ExecContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to grant endpoint permission: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeEndpointPermission revokes a permission on an endpoint. Cascade
+// behaves as for RevokeSchemaPermission. It does nothing if the principal no
+// longer exists.
+func (c *Client) RevokeEndpointPermission(ctx context.Context, endpointName, principalName, permission string, cascade bool) error {
+	principalName = normalizePrincipalName(principalName)
+	if exists, err := c.ServerPrincipalExists(ctx, principalName); err != nil || !exists {
+		return err
+	}
+	cascade, err := c.revokeCascade(ctx, "", 105, catalogID("sys.endpoints", "endpoint_id", ""), cascade, principalName, permission, endpointName)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("REVOKE %s ON ENDPOINT::[%s] FROM [%s]", NormalizePermissionName(permission), endpointName, principalName)
+	if cascade {
+		query += " CASCADE"
+	}
+	_, err = c.ExecContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to revoke endpoint permission: %w", err)
+	}
+
+	return nil
+}
+
+// HasPermissionOptions contains options for checking a permission.
+type HasPermissionOptions struct {
+	// DatabaseName is the database the check runs in. If empty, the check runs
+	// on the server, as required for server-level securable classes.
+	DatabaseName string
+	// PrincipalName is the user, or the login for a server-level check, whose
+	// permission is checked. If empty, the permission of the provider's login
+	// is checked.
+	PrincipalName string
+	// Securable is empty for the SERVER class and defaults to DatabaseName
+	// for the DATABASE class.
+	Securable      string
+	SecurableClass string
+	Permission     string
+}
+
+// HasPermission reports whether a principal has an effective permission on a
+// securable, as evaluated by HAS_PERMS_BY_NAME. The permissions of another
+// principal are checked by impersonating it with EXECUTE AS on a dedicated
+// connection, which is reverted before the connection is returned to the pool.
+func (c *Client) HasPermission(ctx context.Context, opts HasPermissionOptions) (bool, error) {
+	securableClass := strings.ToUpper(opts.SecurableClass)
+	var securable interface{}
+	if opts.Securable != "" {
+		securable = opts.Securable
+	} else if securableClass == "DATABASE" && opts.DatabaseName != "" {
+		securable = opts.DatabaseName
+	}
+
+	var impersonate string
+	pool := c.db
+	if opts.DatabaseName != "" {
+		db, err := c.GetDatabaseConnection(ctx, opts.DatabaseName)
+		if err != nil {
+			return false, err
+		}
+		pool = db
+		impersonate = "EXECUTE AS USER = %s"
+	} else {
+		impersonate = "EXECUTE AS LOGIN = %s"
+	}
+
+	conn, err := pool.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get database connection: %w", err)
+	}
+	defer conn.Close()
+
+	if opts.PrincipalName != "" {
+		query := fmt.Sprintf(impersonate, quoteString(normalizePrincipalName(opts.PrincipalName)))
+		if _, err := conn.ExecContext(ctx, query); err != nil {
+			return false, fmt.Errorf("failed to impersonate %s: %w", opts.PrincipalName, wrapSQLError(err))
+		}
+		defer func() {
+			// A connection that is still impersonating must not be reused
+			if _, err := conn.ExecContext(context.Background(), "REVERT"); err != nil {
+				_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+			}
+		}()
+	}
+
+	var has sql.NullInt64
+	err = conn.QueryRowContext(ctx, "SELECT HAS_PERMS_BY_NAME(@p1, @p2, @p3)",
+		securable, securableClass, NormalizePermissionName(opts.Permission)).Scan(&has)
+	if err != nil {
+		return false, fmt.Errorf("failed to check permission: %w", wrapSQLError(err))
+	}
+	if !has.Valid {
+		return false, fmt.Errorf("failed to check permission %s on securable class %s: the securable class or permission is not valid", opts.Permission, securableClass)
+	}
+	return has.Int64 == 1, nil
+}
+
+// PermissionGrant is a permission granted to a principal on the server or in
+// a database, as listed by ListAllPermissions.
+type PermissionGrant struct {
+	// DatabaseName is empty for server and endpoint permissions.
+	DatabaseName string
+	// SecurableType is SERVER, ENDPOINT, DATABASE, SCHEMA or one of the
+	// database securable types, e.g. CERTIFICATE.
+	SecurableType string
+	// SecurableName is empty for permissions on the server or the database.
+	SecurableName   string
+	PrincipalName   string
+	PermissionName  string
+	WithGrantOption bool
+}
+
+// ListAllPermissionsOptions bounds the permissions listed by
+// ListAllPermissions.
+type ListAllPermissionsOptions struct {
+	// IncludeDatabases lists the permissions in databases in addition to the
+	// server permissions.
+	IncludeDatabases bool
+	// DatabaseNames limits the databases listed. If empty, every online
+	// database the client has access to is listed.
+	DatabaseNames []string
+	// PrincipalName limits the permissions to those granted to a principal.
+	PrincipalName string
+}
+
+// ListAllPermissions retrieves the permissions granted on the server and its
+// endpoints and, if opts.IncludeDatabases is set, the permissions granted on
+// databases, schemas, certificates and keys. DENY permissions and the
+// permissions of system principals are left out.
+func (c *Client) ListAllPermissions(ctx context.Context, opts ListAllPermissionsOptions) ([]PermissionGrant, error) {
+	principalName := opts.PrincipalName
+	if principalName != "" {
+		principalName = normalizePrincipalName(principalName)
+	}
+
+	grants, err := c.listServerGrants(ctx, principalName)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.IncludeDatabases {
+		return grants, nil
+	}
+
+	databases := opts.DatabaseNames
+	if len(databases) == 0 {
+		databases, err = c.listAccessibleDatabases(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, databaseName := range databases {
+		databaseGrants, err := c.listDatabaseGrants(ctx, databaseName, principalName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list permissions in database '%s': %w", databaseName, err)
+		}
+		grants = append(grants, databaseGrants...)
+	}
+
+	return grants, nil
+}
+
+// listServerGrants retrieves the permissions granted on the server and its
+// endpoints, optionally to a single principal.
+func (c *Client) listServerGrants(ctx context.Context, principalName string) ([]PermissionGrant, error) {
+	query := `
+		SELECT
+			CASE perm.class WHEN 105 THEN 'ENDPOINT' ELSE 'SERVER' END,
+			ISNULL(e.name, ''),
+			sp.name,
+			perm.permission_name,
+			CASE WHEN perm.state = 'W' THEN 1 ELSE 0 END
+		FROM sys.server_permissions perm
+		INNER JOIN sys.server_principals sp ON perm.grantee_principal_id = sp.principal_id
+		LEFT JOIN sys.endpoints e ON perm.class = 105 AND perm.major_id = e.endpoint_id
+		WHERE perm.class IN (100, 105)
+			AND perm.state IN ('G', 'W')
+			AND sp.name NOT LIKE '##%'
+			AND (@p1 = '' OR sp.name = @p1)
+		ORDER BY sp.name, perm.class, e.name, perm.permission_name`
+	rows, err := c.QueryContext(ctx, query, principalName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list server permissions: %w", err)
+	}
+	defer rows.Close()
+
+	return scanPermissionGrantRows(rows, "")
+}
+
+// listDatabaseGrants retrieves the permissions granted on a database and its
+// schemas, users, roles, certificates and keys, optionally to a single principal. The
+// implicit permissions of dbo are left out.
+func (c *Client) listDatabaseGrants(ctx context.Context, databaseName, principalName string) ([]PermissionGrant, error) {
+	query := `
+		SELECT
+			CASE perm.class
+				WHEN 0 THEN 'DATABASE'
+				WHEN 3 THEN 'SCHEMA'
+				WHEN 4 THEN CASE WHEN tp.type = 'R' THEN 'ROLE' ELSE 'USER' END
+				WHEN 24 THEN 'SYMMETRIC_KEY'
+				WHEN 25 THEN 'CERTIFICATE'
+				ELSE 'ASYMMETRIC_KEY'
+			END,
+			COALESCE(s.name, tp.name, sk.name, cer.name, ak.name, ''),
+			dp.name,
+			perm.permission_name,
+			CASE WHEN perm.state = 'W' THEN 1 ELSE 0 END
+		FROM sys.database_permissions perm
+		INNER JOIN sys.database_principals dp ON perm.grantee_principal_id = dp.principal_id
+		LEFT JOIN sys.schemas s ON perm.class = 3 AND perm.major_id = s.schema_id
+		LEFT JOIN sys.database_principals tp ON perm.class = 4 AND perm.major_id = tp.principal_id
+		LEFT JOIN sys.symmetric_keys sk ON perm.class = 24 AND perm.major_id = sk.symmetric_key_id
+		LEFT JOIN sys.certificates cer ON perm.class = 25 AND perm.major_id = cer.certificate_id
+		LEFT JOIN sys.asymmetric_keys ak ON perm.class = 26 AND perm.major_id = ak.asymmetric_key_id
+		WHERE perm.class IN (0, 3, 4, 24, 25, 26)
+			AND (perm.class <> 4 OR tp.type <> 'A')
+			AND perm.state IN ('G', 'W')
+			AND dp.name <> 'dbo'
+			AND dp.name NOT LIKE '##%'
+			AND (@p1 = '' OR dp.name = @p1)
+		ORDER BY dp.name, perm.class, perm.major_id, perm.permission_name`
+
+	var rows *sql.Rows
+	// Try to get a direct connection to the database first (Azure SQL support)
+	db, err := c.GetDatabaseConnection(ctx, databaseName)
+	if err == nil {
+		rows, err = db.QueryContext(ctx, query, principalName)
+	} else {
+		// Fallback to existing logic
+		conn, connErr := c.db.Conn(ctx)
+		if connErr != nil {
+			return nil, fmt.Errorf("failed to get database connection: %w", connErr)
+		}
+		defer conn.Close()
+
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("USE %s", quoteIdentifier(databaseName))); err != nil {
+			return nil, fmt.Errorf("failed to switch database context: %w", wrapSQLError(err))
+		}
+		rows, err = conn.QueryContext(ctx, query, principalName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list database permissions: %w", err)
+	}
+	defer rows.Close()
+
+	return scanPermissionGrantRows(rows, databaseName)
+}
+
+func scanPermissionGrantRows(rows *sql.Rows, databaseName string) ([]PermissionGrant, error) {
+	var grants []PermissionGrant
+	for rows.Next() {
+		grant := PermissionGrant{DatabaseName: databaseName}
+		if err := rows.Scan(
+			&grant.SecurableType,
+			&grant.SecurableName,
+			&grant.PrincipalName,
+			&grant.PermissionName,
+			&grant.WithGrantOption,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan permission: %w", err)
+		}
+		grants = append(grants, grant)
+	}
+
+	return grants, rows.Err()
+}
+
+// permissionClass describes a securable class of a database for the generic
+// permission methods: the keyword of the GRANT statement, the class of
+// sys.database_permissions and how a securable name is resolved to major_id.
+type permissionClass struct {
+	class     int    // class of sys.database_permissions
+	keyword   string // securable class of the GRANT statement
+	idExpr    string // expression resolving the securable name in @p3 to major_id
+	qualified bool   // names may be schema-qualified, as in schema.name
+}
+
+// catalogID returns an expression that looks up the ID of a securable by
+// name in a catalog view, optionally restricted by filter.
+func catalogID(catalogView, idColumn, filter string) string {
+	if filter != "" {
+		filter = " AND " + filter
+	}
+	return fmt.Sprintf("(SELECT %s FROM %s WHERE name = @p3%s)", idColumn, catalogView, filter)
+}
+
+// permissionClasses are the securable classes of a database that
+// GrantPermission, DenyPermission, RevokePermission and GetPermission support,
+// keyed by the class name with spaces replaced by underscores.
+var permissionClasses = map[string]permissionClass{
+	"DATABASE":                   {class: 0, keyword: "DATABASE", idExpr: "0"},
+	"OBJECT":                     {class: 1, keyword: "OBJECT", idExpr: "OBJECT_ID(@p3)", qualified: true},
+	"SCHEMA":                     {class: 3, keyword: "SCHEMA", idExpr: "SCHEMA_ID(@p3)"},
+	"USER":                       {class: 4, keyword: "USER", idExpr: catalogID("sys.database_principals", "principal_id", "type NOT IN ('R', 'A')")},
+	"ROLE":                       {class: 4, keyword: "ROLE", idExpr: catalogID("sys.database_principals", "principal_id", "type = 'R'")},
+	"APPLICATION_ROLE":           {class: 4, keyword: "APPLICATION ROLE", idExpr: catalogID("sys.database_principals", "principal_id", "type = 'A'")},
+	"ASSEMBLY":                   {class: 5, keyword: "ASSEMBLY", idExpr: catalogID("sys.assemblies", "assembly_id", "")},
+	"TYPE":                       {class: 6, keyword: "TYPE", idExpr: "TYPE_ID(@p3)", qualified: true},
+	"XML_SCHEMA_COLLECTION":      {class: 10, keyword: "XML SCHEMA COLLECTION", idExpr: catalogID("sys.xml_schema_collections", "xml_collection_id", "schema_id = ISNULL(SCHEMA_ID(PARSENAME(@p3, 2)), SCHEMA_ID()) AND name = PARSENAME(@p3, 1)"), qualified: true},
+	"MESSAGE_TYPE":               {class: 15, keyword: "MESSAGE TYPE", idExpr: catalogID("sys.service_message_types", "message_type_id", "")},
+	"CONTRACT":                   {class: 16, keyword: "CONTRACT", idExpr: catalogID("sys.service_contracts", "service_contract_id", "")},
+	"SERVICE":                    {class: 17, keyword: "SERVICE", idExpr: catalogID("sys.services", "service_id", "")},
+	"REMOTE_SERVICE_BINDING":     {class: 18, keyword: "REMOTE SERVICE BINDING", idExpr: catalogID("sys.remote_service_bindings", "remote_service_binding_id", "")},
+	"ROUTE":                      {class: 19, keyword: "ROUTE", idExpr: catalogID("sys.routes", "route_id", "")},
+	"FULLTEXT_CATALOG":           {class: 23, keyword: "FULLTEXT CATALOG", idExpr: catalogID("sys.fulltext_catalogs", "fulltext_catalog_id", "")},
+	"SYMMETRIC_KEY":              {class: 24, keyword: "SYMMETRIC KEY", idExpr: catalogID("sys.symmetric_keys", "symmetric_key_id", "")},
+	"CERTIFICATE":                {class: 25, keyword: "CERTIFICATE", idExpr: catalogID("sys.certificates", "certificate_id", "")},
+	"ASYMMETRIC_KEY":             {class: 26, keyword: "ASYMMETRIC KEY", idExpr: catalogID("sys.asymmetric_keys", "asymmetric_key_id", "")},
+	"DATABASE_SCOPED_CREDENTIAL": {class: 28, keyword: "DATABASE SCOPED CREDENTIAL", idExpr: catalogID("sys.database_scoped_credentials", "credential_id", "")},
+	"FULLTEXT_STOPLIST":          {class: 29, keyword: "FULLTEXT STOPLIST", idExpr: catalogID("sys.fulltext_stoplists", "stoplist_id", "")},
+	"SEARCH_PROPERTY_LIST":       {class: 31, keyword: "SEARCH PROPERTY LIST", idExpr: catalogID("sys.registered_search_property_lists", "property_list_id", "")},
+}
+
+// PermissionClassName normalizes a securable class name, so that e.g.
+// "xml schema collection" and XML_SCHEMA_COLLECTION name the same class.
+func PermissionClassName(class string) string {
+	return strings.ReplaceAll(strings.ToUpper(strings.TrimSpace(class)), " ", "_")
+}
+
+// PermissionClassNames returns the supported securable class names, sorted.
+func PermissionClassNames() []string {
+	names := make([]string, 0, len(permissionClasses))
+	for name := range permissionClasses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsPermissionClass reports whether the generic permission methods support
+// the given securable class.
+func IsPermissionClass(class string) bool {
+	_, ok := permissionClasses[PermissionClassName(class)]
+	return ok
+}
+
+func lookupPermissionClass(class string) (permissionClass, error) {
+	pc, ok := permissionClasses[PermissionClassName(class)]
+	if !ok {
+		return pc, fmt.Errorf("unsupported securable class: %s", class)
+	}
+	return pc, nil
+}
+
+// securableClause returns the ON clause of a GRANT, DENY or REVOKE statement
+// for a securable. Schema-qualified names are quoted part by part.
+func (pc permissionClass) securableClause(securableName string) string {
+	name := quoteIdentifier(securableName)
+	if pc.qualified {
+		if schema, object, ok := strings.Cut(securableName, "."); ok {
+			name = quoteIdentifier(schema) + "." + quoteIdentifier(object)
+		}
+	}
+	return fmt.Sprintf("ON %s::%s", pc.keyword, name)
+}
+
+// checkPermissionClassName validates the securable name of a class like
+// checkDatabaseSecurableName does for the DATABASE class.
+func (c *Client) checkPermissionClassName(pc permissionClass, databaseName, securableName string) error {
+	if securableName == "" {
+		return fmt.Errorf("a securable name is required for securable class %s", pc.keyword)
+	}
+	return c.checkDatabaseSecurableName(databaseSecurable{class: pc.class}, databaseName, securableName)
+}
+
+// GetPermission retrieves a permission granted or denied to a principal on a
+// securable of any supported class, e.g. OBJECT, TYPE or
+// XML_SCHEMA_COLLECTION. Unlike GetDatabasePermission, only the explicit
+// permission is read; permissions covered by CONTROL or ownership are not
+// reported. Column permissions are left out.
+func (c *Client) GetPermission(ctx context.Context, databaseName, class, securableName, principalName, permission string) (*DatabasePermission, error) {
+	pc, err := lookupPermissionClass(class)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkPermissionClassName(pc, databaseName, securableName); err != nil {
+		return nil, err
+	}
+	principalName = normalizePrincipalName(principalName)
+	query := fmt.Sprintf(`
+		SELECT
+			dp.principal_id,
+			dp.name,
+			perm.permission_name,
+			perm.state_desc,
+			DB_ID(),
+			CASE WHEN perm.state = 'W' THEN 1 ELSE 0 END
+		FROM sys.database_permissions perm
+		INNER JOIN sys.database_principals dp ON perm.grantee_principal_id = dp.principal_id
+		WHERE dp.name = @p1
+			AND perm.permission_name = @p2
+			AND perm.class = %d
+			AND perm.major_id = %s
+			AND perm.minor_id = 0`, pc.class, pc.idExpr)
+
+	// Try to get a direct connection to the database first (Azure SQL support)
+	var row *sql.Row
+	db, err := c.GetDatabaseConnection(ctx, databaseName)
+	if err == nil {
+		row = db.QueryRowContext(ctx, query, principalName, NormalizePermissionName(permission), securableName)
+	} else {
+		row, err = c.QueryRowInDatabaseContext(ctx, databaseName, query, principalName, NormalizePermissionName(permission), securableName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return scanDatabasePermission(row)
+}
+
+// GrantPermission grants a permission on a securable of any supported class,
+// building the ON <class>::<name> clause from the class. Like
+// GrantDatabasePermission, it retries while the principal is not found.
+func (c *Client) GrantPermission(ctx context.Context, databaseName, class, securableName, principalName, permission string, withGrantOption bool) error {
+	query := "GRANT %s %s TO %s"
+	if withGrantOption {
+		query += " WITH GRANT OPTION"
+	}
+	return c.execPermissionStatement(ctx, "grant", query, databaseName, class, securableName, principalName, permission)
+}
+
+// DenyPermission denies a permission on a securable of any supported class.
+// It retries while the principal is not found.
+func (c *Client) DenyPermission(ctx context.Context, databaseName, class, securableName, principalName, permission string) error {
+	return c.execPermissionStatement(ctx, "deny", "DENY %s %s TO %s", databaseName, class, securableName, principalName, permission)
+}
+
+// RevokePermission revokes a permission granted or denied on a securable of
+// any supported class. Cascade behaves as for RevokeSchemaPermission. It does
+// nothing if the permission is no longer found, e.g. because the principal or
+// the securable was dropped and took the permission with it.
+func (c *Client) RevokePermission(ctx context.Context, databaseName, class, securableName, principalName, permission string, cascade bool) error {
+	perm, err := c.GetPermission(ctx, databaseName, class, securableName, principalName, permission)
+	if err != nil || perm == nil {
+		return err
+	}
+	if perm.WithGrantOption {
+		pc, err := lookupPermissionClass(class)
+		if err != nil {
+			return err
+		}
+		cascade, err = c.revokeCascade(ctx, databaseName, pc.class, pc.idExpr, cascade, principalName, permission, securableName)
+		if err != nil {
+			return err
+		}
+	}
+	query := "REVOKE %s %s FROM %s"
+	if cascade {
+		query += " CASCADE"
+	}
+	return c.execPermissionStatement(ctx, "revoke", query, databaseName, class, securableName, principalName, permission)
+}
+
+// execPermissionStatement runs a GRANT, DENY or REVOKE statement whose format
+// takes the permission, the ON clause and the principal, in that order. It is
+// retried while the principal is not found.
+func (c *Client) execPermissionStatement(ctx context.Context, action, format, databaseName, class, securableName, principalName, permission string) error {
+	pc, err := lookupPermissionClass(class)
+	if err != nil {
+		return err
+	}
+	if err := c.checkPermissionClassName(pc, databaseName, securableName); err != nil {
+		return err
+	}
+	principalName = normalizePrincipalName(principalName)
+	query := fmt.Sprintf(format, NormalizePermissionName(permission), pc.securableClause(securableName), quoteIdentifier(principalName))
+
+	err = retryWhileNotFound(ctx, func() error {
+		// Try to get a direct connection to the database first (Azure SQL support)
+		if db, err := c.GetDatabaseConnection(ctx, databaseName); err == nil {
+			return execSQL(ctx, db, query)
+		}
+		return c.ExecInDatabaseContext(ctx, databaseName, query)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to %s %s permission: %w", action, strings.ToLower(pc.keyword), err)
+	}
+	return nil
+}
+
+// revokeCascade reports whether a REVOKE of a permission needs CASCADE. SQL
+// Server refuses to revoke a permission held WITH GRANT OPTION without
+// CASCADE, even if it was never granted onwards. If the principal holds the
+// grant option but has not granted the permission to anyone, CASCADE has
+// nothing to revoke and is added; if it has, the revoke fails unless cascade
+// is requested, so that onward grants are not removed silently.
+//
+// The securable is given by its class and an expression for its major_id,
+// which may refer to securableName as @p3. An empty databaseName checks
+// sys.server_permissions instead of the permissions of the database.
+func (c *Client) revokeCascade(ctx context.Context, databaseName string, class int, majorID string, cascade bool, principalName, permission string, securableName ...string) (bool, error) {
+	if cascade {
+		return true, nil
+	}
+
+	permissionsView, principalsView := "sys.database_permissions", "sys.database_principals"
+	if databaseName == "" {
+		permissionsView, principalsView = "sys.server_permissions", "sys.server_principals"
+	}
+	query := fmt.Sprintf(`
+		SELECT
+			ISNULL(MAX(CASE WHEN perm.grantee_principal_id = p.principal_id AND perm.state = 'W' THEN 1 ELSE 0 END), 0),
+			ISNULL(SUM(CASE WHEN perm.grantor_principal_id = p.principal_id AND perm.grantee_principal_id <> p.principal_id THEN 1 ELSE 0 END), 0)
+		FROM %s perm
+		INNER JOIN %s p ON p.name = @p1
+		WHERE perm.class = %d
+			AND perm.major_id = %s
+			AND perm.permission_name = @p2`, permissionsView, principalsView, class, majorID)
+	args := []interface{}{normalizePrincipalName(principalName), NormalizePermissionName(permission)}
+	for _, name := range securableName {
+		args = append(args, name)
+	}
+
+	var row *sql.Row
+	if databaseName == "" {
+		row = c.QueryRowContext(ctx, query, args...)
+	} else if db, err := c.GetDatabaseConnection(ctx, databaseName); err == nil {
+		row = db.QueryRowContext(ctx, query, args...)
+	} else {
+		row, err = c.QueryRowInDatabaseContext(ctx, databaseName, query, args...)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	var withGrantOption bool
+	var grantedOnwards int
+	if err := row.Scan(&withGrantOption, &grantedOnwards); err != nil {
+		return false, fmt.Errorf("failed to check grant option: %w", wrapSQLError(err))
+	}
+	if !withGrantOption {
+		return false, nil
+	}
+	if grantedOnwards > 0 {
+		return false, fmt.Errorf("'%s' granted %s onwards to %d other principal(s); revoke those grants first, or revoke with cascade to remove them as well", principalName, NormalizePermissionName(permission), grantedOnwards)
+	}
+	return true, nil
+}
@@ -0,0 +1,349 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+)
+
+// DatabasePrincipalExists reports whether a user or role exists in a database.
+// A database that does not exist has no principals.
+//
+// Permissions and role memberships of a principal are removed together with
+// the principal, so revoke paths use this guard to treat them as already gone
+// instead of failing when the principal or its database was dropped first.
+func (c *Client) DatabasePrincipalExists(ctx context.Context, databaseName, principalName string) (bool, error) {
+	database, err := c.GetDatabase(ctx, databaseName)
+	if err != nil || database == nil {
+		return false, err
+	}
+
+	principalName = normalizePrincipalName(principalName)
+	query := `SELECT 1 FROM sys.database_principals WHERE name = @p1`
+
+	var row *sql.Row
+	// Try to get a direct connection to the database first (Azure SQL support)
+	db, err := c.GetDatabaseConnection(ctx, databaseName)
+	if err == nil {
+		row = db.QueryRowContext(ctx, query, principalName)
+	} else {
+		row, err = c.QueryRowInDatabaseContext(ctx, databaseName, query, principalName)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return scanExists(row, "database principal")
+}
+
+// ServerPrincipalExists reports whether a login or server role exists. It
+// guards the server-level revoke paths like DatabasePrincipalExists.
+func (c *Client) ServerPrincipalExists(ctx context.Context, principalName string) (bool, error) {
+	principalName = normalizePrincipalName(principalName)
+	row := c.QueryRowContext(ctx, `SELECT 1 FROM sys.server_principals WHERE name = @p1`, principalName)
+	return scanExists(row, "server principal")
+}
+
+func scanExists(row *sql.Row, kind string) (bool, error) {
+	var exists int
+	err := row.Scan(&exists)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check %s: %w", kind, err)
+	}
+	return true, nil
+}
+
+// GetCurrentUserName retrieves the name of the database user the client is
+// connected as in a database, e.g. dbo for the owner or a sysadmin.
+func (c *Client) GetCurrentUserName(ctx context.Context, databaseName string) (string, error) {
+	query := "SELECT USER_NAME()"
+
+	var row *sql.Row
+	// Try to get a direct connection to the database first (Azure SQL support)
+	db, err := c.GetDatabaseConnection(ctx, databaseName)
+	if err == nil {
+		row = db.QueryRowContext(ctx, query)
+	} else {
+		row, err = c.QueryRowInDatabaseContext(ctx, databaseName, query)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var name string
+	if err := row.Scan(&name); err != nil {
+		return "", fmt.Errorf("failed to get current user: %w", err)
+	}
+	return name, nil
+}
+
+// AccessReport is the access a database principal holds, directly and through
+// its roles, as returned by GetDatabaseAccessReport.
+type AccessReport struct {
+	PrincipalName string
+	// Roles are the roles the principal belongs to, directly or through other
+	// roles, including public for users.
+	Roles []AccessReportRole
+	// Permissions are the permissions of the principal and of each of its
+	// roles, sorted by class, securable and permission.
+	Permissions []AccessReportPermission
+}
+
+// AccessReportRole is a role a principal belongs to.
+type AccessReportRole struct {
+	Name        string
+	IsFixedRole bool
+	// Path is the chain of roles from the principal to this role, ending with
+	// the role itself. A role held through several chains is reported with
+	// the shortest one.
+	Path []string
+}
+
+// AccessReportPermission is a permission a principal holds, directly or
+// through one of its roles.
+type AccessReportPermission struct {
+	// Class is the securable class as accepted by GrantPermission, e.g.
+	// DATABASE, SCHEMA or OBJECT.
+	Class string
+	// SecurableName is empty for the database. Objects, types and XML schema
+	// collections are schema-qualified.
+	SecurableName string
+	// ColumnName is set for permissions on a single column of an object.
+	ColumnName      string
+	PermissionName  string
+	State           string // GRANT or DENY
+	WithGrantOption bool
+	// RoleName is the role the permission is held through, or empty if it is
+	// granted to the principal itself.
+	RoleName string
+	// Implied is set for the permissions that fixed roles hold without them
+	// being listed in sys.database_permissions.
+	Implied bool
+}
+
+// fixedRolePermissions are the database permissions implied by membership in
+// the fixed database roles. Permissions of db_denydatareader and
+// db_denydatawriter are denied rather than granted.
+var fixedRolePermissions = map[string][]string{
+	"db_owner":          {"CONTROL"},
+	"db_accessadmin":    {"ALTER ANY USER", "CONNECT", "CREATE SCHEMA"},
+	"db_securityadmin":  {"ALTER ANY APPLICATION ROLE", "ALTER ANY ROLE", "CREATE SCHEMA", "VIEW DEFINITION"},
+	"db_backupoperator": {"BACKUP DATABASE", "BACKUP LOG", "CHECKPOINT"},
+	"db_datareader":     {"SELECT"},
+	"db_datawriter":     {"DELETE", "INSERT", "UPDATE"},
+	"db_ddladmin": {
+		"ALTER ANY ASSEMBLY", "ALTER ANY ASYMMETRIC KEY", "ALTER ANY CERTIFICATE", "ALTER ANY CONTRACT",
+		"ALTER ANY DATABASE DDL TRIGGER", "ALTER ANY DATABASE EVENT NOTIFICATION", "ALTER ANY DATASPACE",
+		"ALTER ANY FULLTEXT CATALOG", "ALTER ANY MESSAGE TYPE", "ALTER ANY REMOTE SERVICE BINDING",
+		"ALTER ANY ROUTE", "ALTER ANY SCHEMA", "ALTER ANY SERVICE", "ALTER ANY SYMMETRIC KEY", "CHECKPOINT",
+		"CREATE AGGREGATE", "CREATE DEFAULT", "CREATE FUNCTION", "CREATE PROCEDURE", "CREATE QUEUE",
+		"CREATE RULE", "CREATE SYNONYM", "CREATE TABLE", "CREATE TYPE", "CREATE VIEW",
+		"CREATE XML SCHEMA COLLECTION", "REFERENCES",
+	},
+	"db_denydatareader": {"SELECT"},
+	"db_denydatawriter": {"DELETE", "INSERT", "UPDATE"},
+}
+
+// accessReportQuery walks the role memberships of the principal in @p1
+// recursively and lists every principal reached together with its
+// permissions. Users are members of public without a row in
+// sys.database_role_members, so public is added for them explicitly.
+const accessReportQuery = `
+	WITH memberships AS (
+		SELECT dp.principal_id, CAST(NULL AS int) AS via_id, 0 AS depth
+		FROM sys.database_principals dp
+		WHERE dp.name = @p1
+		UNION ALL
+		SELECT 0, dp.principal_id, 1
+		FROM sys.database_principals dp
+		WHERE dp.name = @p1 AND dp.type <> 'R' AND dp.principal_id <> 0
+		UNION ALL
+		SELECT drm.role_principal_id, m.principal_id, m.depth + 1
+		FROM memberships m
+		INNER JOIN sys.database_role_members drm ON drm.member_principal_id = m.principal_id
+		WHERE m.depth < 32
+	)
+	SELECT
+		m.principal_id,
+		ISNULL(m.via_id, -1),
+		m.depth,
+		p.name,
+		ISNULL(p.is_fixed_role, 0),
+		CASE perm.class
+			WHEN 1 THEN 'OBJECT'
+			WHEN 4 THEN CASE tp.type WHEN 'R' THEN 'ROLE' WHEN 'A' THEN 'APPLICATION_ROLE' ELSE 'USER' END
+			WHEN 16 THEN 'CONTRACT'
+			WHEN 24 THEN 'SYMMETRIC_KEY'
+			ELSE perm.class_desc
+		END,
+		ISNULL(CASE perm.class
+			WHEN 1 THEN OBJECT_SCHEMA_NAME(perm.major_id) + '.' + OBJECT_NAME(perm.major_id)
+			WHEN 3 THEN SCHEMA_NAME(perm.major_id)
+			WHEN 4 THEN tp.name
+			WHEN 5 THEN (SELECT name FROM sys.assemblies WHERE assembly_id = perm.major_id)
+			WHEN 6 THEN (SELECT SCHEMA_NAME(schema_id) + '.' + name FROM sys.types WHERE user_type_id = perm.major_id)
+			WHEN 10 THEN (SELECT SCHEMA_NAME(schema_id) + '.' + name FROM sys.xml_schema_collections WHERE xml_collection_id = perm.major_id)
+			WHEN 15 THEN (SELECT name FROM sys.service_message_types WHERE message_type_id = perm.major_id)
+			WHEN 16 THEN (SELECT name FROM sys.service_contracts WHERE service_contract_id = perm.major_id)
+			WHEN 17 THEN (SELECT name FROM sys.services WHERE service_id = perm.major_id)
+			WHEN 18 THEN (SELECT name FROM sys.remote_service_bindings WHERE remote_service_binding_id = perm.major_id)
+			WHEN 19 THEN (SELECT name FROM sys.routes WHERE route_id = perm.major_id)
+			WHEN 23 THEN (SELECT name FROM sys.fulltext_catalogs WHERE fulltext_catalog_id = perm.major_id)
+			WHEN 24 THEN (SELECT name FROM sys.symmetric_keys WHERE symmetric_key_id = perm.major_id)
+			WHEN 25 THEN (SELECT name FROM sys.certificates WHERE certificate_id = perm.major_id)
+			WHEN 26 THEN (SELECT name FROM sys.asymmetric_keys WHERE asymmetric_key_id = perm.major_id)
+			WHEN 29 THEN (SELECT name FROM sys.fulltext_stoplists WHERE stoplist_id = perm.major_id)
+			WHEN 31 THEN (SELECT name FROM sys.registered_search_property_lists WHERE property_list_id = perm.major_id)
+		END, ''),
+		ISNULL(CASE WHEN perm.class = 1 AND perm.minor_id > 0 THEN COL_NAME(perm.major_id, perm.minor_id) END, ''),
+		perm.permission_name,
+		perm.state
+	FROM memberships m
+	INNER JOIN sys.database_principals p ON p.principal_id = m.principal_id
+	LEFT JOIN sys.database_permissions perm ON perm.grantee_principal_id = m.principal_id
+	LEFT JOIN sys.database_principals tp ON perm.class = 4 AND perm.major_id = tp.principal_id
+	ORDER BY m.depth, p.name`
+
+// GetDatabaseAccessReport retrieves the roles a user or role belongs to in a
+// database, following nested roles, and the permissions held directly and
+// through each of them. The permissions of the fixed roles are reported as
+// implied. Permissions conferred by ownership, and server-level access of
+// the login, are not covered. It returns nil if the principal does not exist.
+func (c *Client) GetDatabaseAccessReport(ctx context.Context, databaseName, principalName string) (*AccessReport, error) {
+	principalName = normalizePrincipalName(principalName)
+
+	var rows *sql.Rows
+	// Try to get a direct connection to the database first (Azure SQL support)
+	db, err := c.GetDatabaseConnection(ctx, databaseName)
+	if err == nil {
+		rows, err = db.QueryContext(ctx, accessReportQuery, principalName)
+	} else {
+		// Fallback to USE statement for on-premises SQL Server
+		conn, connErr := c.db.Conn(ctx)
+		if connErr != nil {
+			return nil, fmt.Errorf("failed to get database connection: %w", connErr)
+		}
+		defer conn.Close()
+
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("USE %s", quoteIdentifier(databaseName))); err != nil {
+			return nil, fmt.Errorf("failed to switch database context: %w", wrapSQLError(err))
+		}
+		rows, err = conn.QueryContext(ctx, accessReportQuery, principalName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read access report: %w", wrapSQLError(err))
+	}
+	defer rows.Close()
+
+	// Principals are ordered by depth, so the first row of each principal
+	// carries its shortest chain of roles
+	type reached struct {
+		name  string
+		path  []string
+		fixed bool
+		depth int
+	}
+	principals := make(map[int]*reached)
+	var report *AccessReport
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var (
+			principalID, viaID, depth int
+			name                      string
+			fixed                     bool
+			class, securable, column  sql.NullString
+			permission, state         sql.NullString
+		)
+		if err := rows.Scan(&principalID, &viaID, &depth, &name, &fixed, &class, &securable, &column, &permission, &state); err != nil {
+			return nil, fmt.Errorf("failed to scan access report: %w", err)
+		}
+
+		p, ok := principals[principalID]
+		if !ok {
+			p = &reached{name: name, fixed: fixed, depth: depth}
+			if via, ok := principals[viaID]; ok {
+				p.path = append(append([]string(nil), via.path...), name)
+			}
+			principals[principalID] = p
+			if depth == 0 {
+				report = &AccessReport{PrincipalName: name}
+			} else if report != nil {
+				report.Roles = append(report.Roles, AccessReportRole{Name: name, IsFixedRole: fixed, Path: p.path})
+			}
+		} else if depth != p.depth {
+			// Permissions of a role reached through several chains are
+			// listed with the shortest one only
+			continue
+		}
+		if !permission.Valid || report == nil {
+			continue
+		}
+
+		perm := AccessReportPermission{
+			Class:           class.String,
+			SecurableName:   securable.String,
+			ColumnName:      column.String,
+			PermissionName:  permission.String,
+			State:           PermissionStateGrant,
+			WithGrantOption: state.String == "W",
+		}
+		if state.String == "D" {
+			perm.State = PermissionStateDeny
+		}
+		if depth > 0 {
+			perm.RoleName = name
+		}
+		key := fmt.Sprintf("%d/%s/%s/%s/%s", principalID, perm.Class, perm.SecurableName, perm.ColumnName, perm.PermissionName)
+		if !seen[key] {
+			seen[key] = true
+			report.Permissions = append(report.Permissions, perm)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read access report: %w", err)
+	}
+	if report == nil {
+		return nil, nil
+	}
+
+	for _, role := range report.Roles {
+		if !role.IsFixedRole {
+			continue
+		}
+		state := PermissionStateGrant
+		if role.Name == "db_denydatareader" || role.Name == "db_denydatawriter" {
+			state = PermissionStateDeny
+		}
+		for _, permission := range fixedRolePermissions[role.Name] {
+			report.Permissions = append(report.Permissions, AccessReportPermission{
+				Class:          "DATABASE",
+				PermissionName: permission,
+				State:          state,
+				RoleName:       role.Name,
+				Implied:        true,
+			})
+		}
+	}
+
+	sort.SliceStable(report.Permissions, func(i, j int) bool {
+		a, b := report.Permissions[i], report.Permissions[j]
+		if a.Class != b.Class {
+			return a.Class < b.Class
+		}
+		if a.SecurableName != b.SecurableName {
+			return a.SecurableName < b.SecurableName
+		}
+		if a.ColumnName != b.ColumnName {
+			return a.ColumnName < b.ColumnName
+		}
+		return a.PermissionName < b.PermissionName
+	})
+	return report, nil
+}
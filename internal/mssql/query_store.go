@@ -0,0 +1,116 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// QueryStoreOptions represents the Query Store configuration of a database.
+type QueryStoreOptions struct {
+	DatabaseName            string
+	Enabled                 bool
+	OperationMode           string // READ_WRITE, READ_ONLY
+	MaxStorageSizeMB        int64
+	QueryCaptureMode        string // ALL, AUTO, NONE, CUSTOM
+	StaleQueryThresholdDays int64
+}
+
+// QueryStoreOperationModes are the valid Query Store operation modes.
+var QueryStoreOperationModes = []string{"READ_WRITE", "READ_ONLY"}
+
+// QueryStoreCaptureModes are the valid Query Store query capture modes.
+var QueryStoreCaptureModes = []string{"ALL", "AUTO", "NONE", "CUSTOM"}
+
+// GetQueryStoreOptions retrieves the Query Store configuration of a database.
+func (c *Client) GetQueryStoreOptions(ctx context.Context, databaseName string) (*QueryStoreOptions, error) {
+	query := `
+		SELECT
+			CASE WHEN desired_state_desc = 'OFF' THEN 0 ELSE 1 END,
+			CASE WHEN desired_state_desc = 'OFF' THEN 'READ_WRITE' ELSE desired_state_desc END,
+			max_storage_size_mb,
+			query_capture_mode_desc,
+			stale_query_threshold_days
+		FROM sys.database_query_store_options`
+
+	var row *sql.Row
+	// Try to get a direct connection to the database first (Azure SQL support)
+	db, err := c.GetDatabaseConnection(ctx, databaseName)
+	if err == nil {
+		row = db.QueryRowContext(ctx, query)
+	} else {
+		row, err = c.QueryRowInDatabaseContext(ctx, databaseName, query)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	opts := QueryStoreOptions{DatabaseName: databaseName}
+	err = row.Scan(
+		&opts.Enabled,
+		&opts.OperationMode,
+		&opts.MaxStorageSizeMB,
+		&opts.QueryCaptureMode,
+		&opts.StaleQueryThresholdDays,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get query store options: %w", err)
+	}
+
+	return &opts, nil
+}
+
+// SetQueryStoreOptionsOptions contains options for configuring Query Store.
+type SetQueryStoreOptionsOptions struct {
+	DatabaseName            string
+	Enabled                 bool
+	OperationMode           *string
+	MaxStorageSizeMB        *int64
+	QueryCaptureMode        *string
+	StaleQueryThresholdDays *int64
+}
+
+// SetQueryStoreOptions turns Query Store on or off for a database and applies
+// the given options. Options are ignored when Query Store is turned off.
+func (c *Client) SetQueryStoreOptions(ctx context.Context, opts SetQueryStoreOptionsOptions) (*QueryStoreOptions, error) {
+	query := fmt.Sprintf("ALTER DATABASE [%s] SET QUERY_STORE = OFF", opts.DatabaseName)
+	if opts.Enabled {
+		query = fmt.Sprintf("ALTER DATABASE [%s] SET QUERY_STORE = ON", opts.DatabaseName)
+
+		var parts []string
+		if opts.OperationMode != nil {
+			parts = append(parts, fmt.Sprintf("OPERATION_MODE = %s", strings.ToUpper(*opts.OperationMode)))
+		}
+		if opts.MaxStorageSizeMB != nil {
+			parts = append(parts, fmt.Sprintf("MAX_STORAGE_SIZE_MB = %d", *opts.MaxStorageSizeMB))
+		}
+		if opts.QueryCaptureMode != nil {
+			parts = append(parts, fmt.Sprintf("QUERY_CAPTURE_MODE = %s", strings.ToUpper(*opts.QueryCaptureMode)))
+		}
+		if opts.StaleQueryThresholdDays != nil {
+			parts = append(parts, fmt.Sprintf("CLEANUP_POLICY = (STALE_QUERY_THRESHOLD_DAYS = %d)", *opts.StaleQueryThresholdDays))
+		}
+		if len(parts) > 0 {
+			query += " (" + strings.Join(parts, ", ") + ")"
+		}
+	}
+
+	// Try to get a direct connection to the database first (Azure SQL support)
+	db, err := c.GetDatabaseConnection(ctx, opts.DatabaseName)
+	if err == nil {
+		if err := execSQL(ctx, db, query); err != nil {
+			return nil, fmt.Errorf("failed to set query store options: %w", err)
+		}
+	} else if _, err := c.ExecContext(ctx, query); err != nil {
+		return nil, fmt.Errorf("failed to set query store options: %w", err)
+	}
+
+	return c.GetQueryStoreOptions(ctx, opts.DatabaseName)
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package mssql
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	// principalWaitTimeout bounds how long to wait for a newly created
+	// principal to become visible.
+	principalWaitTimeout = 10 * time.Second
+	// principalWaitInterval is the initial delay between attempts. It doubles
+	// after each attempt up to principalWaitMaxInterval.
+	principalWaitInterval    = 250 * time.Millisecond
+	principalWaitMaxInterval = 2 * time.Second
+
+	// databaseBusyTimeout bounds how long to wait for a database in
+	// transition, e.g. while it is recovering after heavy activity.
+	databaseBusyTimeout = 60 * time.Second
+	// databaseBusyInterval is the initial delay between attempts. It doubles
+	// after each attempt up to databaseBusyMaxInterval.
+	databaseBusyInterval    = 1 * time.Second
+	databaseBusyMaxInterval = 8 * time.Second
+)
+
+// retryWhileNotFound runs fn until it no longer fails with ErrNotFound or
+// principalWaitTimeout has passed. On Azure SQL a principal created on one
+// connection may not be visible on another connection right away, so
+// statements referencing a principal created in the same apply can fail with a
+// not-found error for a short time.
+func retryWhileNotFound(ctx context.Context, fn func() error) error {
+	return retryWhile(ctx, ErrNotFound, principalWaitTimeout, principalWaitInterval, principalWaitMaxInterval, fn)
+}
+
+// retryWhileDatabaseBusy runs fn until it no longer fails with ErrDatabaseBusy
+// or databaseBusyTimeout has passed. A database that was just used, e.g. by
+// the resources destroyed before it, can be in transition for a few seconds,
+// during which it cannot be dropped.
+func retryWhileDatabaseBusy(ctx context.Context, fn func() error) error {
+	return retryWhile(ctx, ErrDatabaseBusy, databaseBusyTimeout, databaseBusyInterval, databaseBusyMaxInterval, fn)
+}
+
+// retryWhile runs fn until it no longer fails with an error matching kind or
+// timeout has passed, waiting interval between attempts, doubled after each
+// attempt up to maxInterval.
+func retryWhile(ctx context.Context, kind error, timeout, interval, maxInterval time.Duration, fn func() error) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		err := fn()
+		if !errors.Is(err, kind) || time.Now().Add(interval).After(deadline) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(interval):
+		}
+
+		interval *= 2
+		if interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package mssql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// fakeSQLError mimics the errors of the driver, which expose the SQL Server
+// error number through SQLErrorNumber.
+type fakeSQLError struct {
+	number int32
+}
+
+func (e fakeSQLError) Error() string {
+	return fmt.Sprintf("mssql: error %d", e.number)
+}
+
+func (e fakeSQLError) SQLErrorNumber() int32 {
+	return e.number
+}
+
+func TestRetryWhileDatabaseBusy(t *testing.T) {
+	// Database is in transition
+	busy := wrapSQLError(fakeSQLError{number: 952})
+	if !errors.Is(busy, ErrDatabaseBusy) {
+		t.Fatalf("wrapSQLError(952) = %v, want an error matching ErrDatabaseBusy", busy)
+	}
+
+	attempts := 0
+	err := retryWhileDatabaseBusy(context.Background(), func() error {
+		attempts++
+		if attempts == 1 {
+			return busy
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("retryWhileDatabaseBusy() = %v, want nil", err)
+	}
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+}
+
+func TestRetryWhileDatabaseBusyOtherError(t *testing.T) {
+	// Cannot find the object
+	notFound := wrapSQLError(fakeSQLError{number: 15151})
+	if errors.Is(notFound, ErrDatabaseBusy) {
+		t.Fatalf("wrapSQLError(15151) = %v, want an error not matching ErrDatabaseBusy", notFound)
+	}
+
+	attempts := 0
+	err := retryWhileDatabaseBusy(context.Background(), func() error {
+		attempts++
+		return notFound
+	})
+	if !errors.Is(err, notFound) {
+		t.Fatalf("retryWhileDatabaseBusy() = %v, want %v", err, notFound)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 )
 
 // DatabaseRole represents a database role.
@@ -17,6 +19,8 @@ type DatabaseRole struct {
 	OwnerName      string
 	IsFixedRole    bool
 	IsDatabaseRole bool
+	CreateDate     time.Time // With the UTC offset of the server
+	ModifyDate     time.Time
 }
 
 // GetDatabaseRole retrieves a database role by name.
@@ -28,7 +32,9 @@ func (c *Client) GetDatabaseRole(ctx context.Context, databaseName, roleName str
 			DB_ID() as database_id,
 			ISNULL(owner.name, ''),
 			dp.is_fixed_role,
-			CASE WHEN dp.type = 'R' THEN 1 ELSE 0 END
+			CASE WHEN dp.type = 'R' THEN 1 ELSE 0 END,
+			TODATETIMEOFFSET(dp.create_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET())),
+			TODATETIMEOFFSET(dp.modify_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET()))
 		FROM sys.database_principals dp
 		LEFT JOIN sys.database_principals owner ON dp.owning_principal_id = owner.principal_id
 		WHERE dp.name = @p1 AND dp.type = 'R'`
@@ -36,7 +42,6 @@ func (c *Client) GetDatabaseRole(ctx context.Context, databaseName, roleName str
 	// Try to get a direct connection to the database first (Azure SQL support)
 	db, err := c.GetDatabaseConnection(ctx, databaseName)
 	if err == nil {
-		defer db.Close()
 		row := db.QueryRowContext(ctx, query, roleName)
 		return scanDatabaseRole(row)
 	}
@@ -58,7 +63,9 @@ func (c *Client) GetDatabaseRoleByID(ctx context.Context, databaseName string, p
 			DB_ID() as database_id,
 			ISNULL(owner.name, ''),
 			dp.is_fixed_role,
-			CASE WHEN dp.type = 'R' THEN 1 ELSE 0 END
+			CASE WHEN dp.type = 'R' THEN 1 ELSE 0 END,
+			TODATETIMEOFFSET(dp.create_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET())),
+			TODATETIMEOFFSET(dp.modify_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET()))
 		FROM sys.database_principals dp
 		LEFT JOIN sys.database_principals owner ON dp.owning_principal_id = owner.principal_id
 		WHERE dp.principal_id = @p1 AND dp.type = 'R'`
@@ -66,7 +73,6 @@ func (c *Client) GetDatabaseRoleByID(ctx context.Context, databaseName string, p
 	// Try to get a direct connection to the database first (Azure SQL support)
 	db, err := c.GetDatabaseConnection(ctx, databaseName)
 	if err == nil {
-		defer db.Close()
 		row := db.QueryRowContext(ctx, query, principalID)
 		return scanDatabaseRole(row)
 	}
@@ -88,7 +94,9 @@ func (c *Client) ListDatabaseRoles(ctx context.Context, databaseName string) ([]
 			DB_ID() as database_id,
 			ISNULL(owner.name, ''),
 			dp.is_fixed_role,
-			CASE WHEN dp.type = 'R' THEN 1 ELSE 0 END
+			CASE WHEN dp.type = 'R' THEN 1 ELSE 0 END,
+			TODATETIMEOFFSET(dp.create_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET())),
+			TODATETIMEOFFSET(dp.modify_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET()))
 		FROM sys.database_principals dp
 		LEFT JOIN sys.database_principals owner ON dp.owning_principal_id = owner.principal_id
 		WHERE dp.type = 'R'
@@ -97,7 +105,6 @@ func (c *Client) ListDatabaseRoles(ctx context.Context, databaseName string) ([]
 	// Try to get a direct connection to the database first (Azure SQL support)
 	db, err := c.GetDatabaseConnection(ctx, databaseName)
 	if err == nil {
-		defer db.Close()
 		rows, err := db.QueryContext(ctx, query)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list database roles: %w", err)
@@ -116,7 +123,7 @@ func (c *Client) ListDatabaseRoles(ctx context.Context, databaseName string) ([]
 
 	// Switch to the target database
 	if _, err := conn.ExecContext(ctx, fmt.Sprintf("USE [%s]", databaseName)); err != nil {
-		return nil, fmt.Errorf("failed to switch database context: %w", err)
+		return nil, fmt.Errorf("failed to switch database context: %w", wrapSQLError(err))
 	}
 
 	rows, err := conn.QueryContext(ctx, query)
@@ -137,6 +144,8 @@ func scanDatabaseRole(row *sql.Row) (*DatabaseRole, error) {
 		&role.OwnerName,
 		&role.IsFixedRole,
 		&role.IsDatabaseRole,
+		&role.CreateDate,
+		&role.ModifyDate,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -158,6 +167,8 @@ func scanDatabaseRolesRows(rows *sql.Rows) ([]DatabaseRole, error) {
 			&role.OwnerName,
 			&role.IsFixedRole,
 			&role.IsDatabaseRole,
+			&role.CreateDate,
+			&role.ModifyDate,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan database role: %w", err)
 		}
@@ -183,8 +194,7 @@ func (c *Client) CreateDatabaseRole(ctx context.Context, opts CreateDatabaseRole
 	// Try to get a direct connection to the database first (Azure SQL support)
 	db, err := c.GetDatabaseConnection(ctx, opts.DatabaseName)
 	if err == nil {
-		defer db.Close()
-		_, err = db.ExecContext(ctx, query)
+		err = execSQL(ctx, db, query)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create database role: %w", err)
 		}
@@ -221,17 +231,13 @@ func (c *Client) UpdateDatabaseRole(ctx context.Context, opts UpdateDatabaseRole
 		// Try to get a direct connection to the database first (Azure SQL support)
 		db, err := c.GetDatabaseConnection(ctx, opts.DatabaseName)
 		if err == nil {
-			defer db.Close()
-			_, err = db.ExecContext(ctx, query)
+			err = execSQL(ctx, db, query)
 			if err != nil {
 				return nil, fmt.Errorf("failed to update database role owner: %w", err)
 			}
 		} else {
 			// Fallback to existing logic
-			if err := c.UseDatabase(ctx, opts.DatabaseName); err != nil {
-				return nil, err
-			}
-			if _, err := c.ExecContext(ctx, query); err != nil {
+			if err := c.ExecInDatabaseContext(ctx, opts.DatabaseName, query); err != nil {
 				return nil, fmt.Errorf("failed to update database role owner: %w", err)
 			}
 		}
@@ -247,18 +253,12 @@ func (c *Client) DropDatabaseRole(ctx context.Context, databaseName, roleName st
 	// Try to get a direct connection to the database first (Azure SQL support)
 	db, err := c.GetDatabaseConnection(ctx, databaseName)
 	if err == nil {
-		defer db.Close()
-		_, err = db.ExecContext(ctx, query)
+		err = execSQL(ctx, db, query)
 		return err
 	}
 
 	// Fallback to existing logic
-	if err := c.UseDatabase(ctx, databaseName); err != nil {
-		return err
-	}
-
-	_, err = c.ExecContext(ctx, query)
-	if err != nil {
+	if err := c.ExecInDatabaseContext(ctx, databaseName, query); err != nil {
 		return fmt.Errorf("failed to drop database role: %w", err)
 	}
 
@@ -291,7 +291,6 @@ func (c *Client) GetDatabaseRoleMember(ctx context.Context, databaseName, roleNa
 	// Try to get a direct connection to the database first (Azure SQL support)
 	db, err := c.GetDatabaseConnection(ctx, databaseName)
 	if err == nil {
-		defer db.Close()
 		row := db.QueryRowContext(ctx, query, roleName, memberName)
 		return scanDatabaseRoleMember(row)
 	}
@@ -322,15 +321,21 @@ func scanDatabaseRoleMember(row *sql.Row) (*DatabaseRoleMember, error) {
 	return &member, nil
 }
 
-// AddDatabaseRoleMember adds a member to a database role.
+// AddDatabaseRoleMember adds a member to a database role. The role and the
+// member are looked up first, so that a misspelled name or a principal of
+// another database is reported clearly instead of by the generic error of
+// ALTER ROLE.
 func (c *Client) AddDatabaseRoleMember(ctx context.Context, databaseName, roleName, memberName string) error {
+	if err := c.checkDatabaseRoleMember(ctx, databaseName, roleName, memberName); err != nil {
+		return err
+	}
+
 	query := fmt.Sprintf("ALTER ROLE [%s] ADD MEMBER [%s]", roleName, memberName)
 
 	// Try to get a direct connection to the database first (Azure SQL support)
 	db, err := c.GetDatabaseConnection(ctx, databaseName)
 	if err == nil {
-		defer db.Close()
-		_, err = db.ExecContext(ctx, query)
+		err = execSQL(ctx, db, query)
 		return err
 	}
 
@@ -343,15 +348,51 @@ func (c *Client) AddDatabaseRoleMember(ctx context.Context, databaseName, roleNa
 	return nil
 }
 
+// checkDatabaseRoleMember returns an error matching ErrNotFound if the role or
+// the member does not exist in the database. Both must be principals of the
+// same database.
+func (c *Client) checkDatabaseRoleMember(ctx context.Context, databaseName, roleName, memberName string) error {
+	role, err := c.GetDatabaseRole(ctx, databaseName, roleName)
+	if err != nil {
+		return fmt.Errorf("failed to look up database role: %w", err)
+	}
+	if role == nil {
+		exists, err := c.DatabasePrincipalExists(ctx, databaseName, roleName)
+		if err != nil {
+			return fmt.Errorf("failed to look up database role: %w", err)
+		}
+		if exists {
+			return fmt.Errorf("'%s' in database '%s' is not a database role", roleName, databaseName)
+		}
+		return fmt.Errorf("%w: database role '%s' not found in database '%s'", ErrNotFound, roleName, databaseName)
+	}
+
+	exists, err := c.DatabasePrincipalExists(ctx, databaseName, memberName)
+	if err != nil {
+		return fmt.Errorf("failed to look up role member: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("%w: member '%s' not found in database '%s', members must be users or roles of the role's database", ErrNotFound, memberName, databaseName)
+	}
+
+	return nil
+}
+
 // RemoveDatabaseRoleMember removes a member from a database role.
+// It does nothing if the role or the member no longer exists.
 func (c *Client) RemoveDatabaseRoleMember(ctx context.Context, databaseName, roleName, memberName string) error {
+	for _, principalName := range []string{roleName, memberName} {
+		if exists, err := c.DatabasePrincipalExists(ctx, databaseName, principalName); err != nil || !exists {
+			return err
+		}
+	}
+
 	query := fmt.Sprintf("ALTER ROLE [%s] DROP MEMBER [%s]", roleName, memberName)
 
 	// Try to get a direct connection to the database first (Azure SQL support)
 	db, err := c.GetDatabaseConnection(ctx, databaseName)
 	if err == nil {
-		defer db.Close()
-		_, err = db.ExecContext(ctx, query)
+		err = execSQL(ctx, db, query)
 		return err
 	}
 
@@ -364,6 +405,100 @@ func (c *Client) RemoveDatabaseRoleMember(ctx context.Context, databaseName, rol
 	return nil
 }
 
+// UpdateDatabaseRoleMemberships adds a member to and removes it from several
+// database roles in a single batch. The batch runs in a transaction, so a
+// failing statement leaves all memberships unchanged. Roles that no longer
+// exist are skipped when removing.
+func (c *Client) UpdateDatabaseRoleMemberships(ctx context.Context, databaseName, memberName string, add, remove []string) error {
+	if len(add) == 0 && len(remove) == 0 {
+		return nil
+	}
+
+	member := quoteIdentifier(memberName)
+	statements := []string{"SET XACT_ABORT ON;", "BEGIN TRANSACTION;"}
+	for _, role := range add {
+		statements = append(statements, fmt.Sprintf("ALTER ROLE %s ADD MEMBER %s;", quoteIdentifier(role), member))
+	}
+	for _, role := range remove {
+		statements = append(statements, fmt.Sprintf("IF DATABASE_PRINCIPAL_ID(%s) IS NOT NULL ALTER ROLE %s DROP MEMBER %s;",
+			quoteString(role), quoteIdentifier(role), member))
+	}
+	statements = append(statements, "COMMIT TRANSACTION;")
+	query := strings.Join(statements, "\n")
+
+	// Try to get a direct connection to the database first (Azure SQL support)
+	db, err := c.GetDatabaseConnection(ctx, databaseName)
+	if err == nil {
+		err = execSQL(ctx, db, query)
+	} else {
+		// Fallback to existing logic
+		err = c.ExecInDatabaseContext(ctx, databaseName, query)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update database role memberships: %w", err)
+	}
+
+	return nil
+}
+
+// ListDatabaseRoleMembers retrieves the names of all members of a database role.
+func (c *Client) ListDatabaseRoleMembers(ctx context.Context, databaseName, roleName string) ([]string, error) {
+	query := `
+		SELECT m.name
+		FROM sys.database_role_members drm
+		INNER JOIN sys.database_principals r ON drm.role_principal_id = r.principal_id
+		INNER JOIN sys.database_principals m ON drm.member_principal_id = m.principal_id
+		WHERE r.name = @p1
+		ORDER BY m.name`
+
+	var members []string
+
+	// Try to get a direct connection to the database first (Azure SQL support)
+	db, err := c.GetDatabaseConnection(ctx, databaseName)
+	if err == nil {
+		rows, err := db.QueryContext(ctx, query, roleName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list database role members: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var memberName string
+			if err := rows.Scan(&memberName); err != nil {
+				return nil, fmt.Errorf("failed to scan member name: %w", err)
+			}
+			members = append(members, memberName)
+		}
+		return members, rows.Err()
+	}
+
+	// Fallback to existing logic
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("USE [%s]", databaseName)); err != nil {
+		return nil, fmt.Errorf("failed to switch database context: %w", wrapSQLError(err))
+	}
+
+	rows, err := conn.QueryContext(ctx, query, roleName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list database role members: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var memberName string
+		if err := rows.Scan(&memberName); err != nil {
+			return nil, fmt.Errorf("failed to scan member name: %w", err)
+		}
+		members = append(members, memberName)
+	}
+	return members, rows.Err()
+}
+
 // GetUserRoles retrieves all database roles a user belongs to.
 func (c *Client) GetUserRoles(ctx context.Context, databaseName, userName string) ([]string, error) {
 	query := `
@@ -379,7 +514,6 @@ func (c *Client) GetUserRoles(ctx context.Context, databaseName, userName string
 	// Try to get a direct connection to the database first (Azure SQL support)
 	db, err := c.GetDatabaseConnection(ctx, databaseName)
 	if err == nil {
-		defer db.Close()
 		rows, err := db.QueryContext(ctx, query, userName)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get user roles: %w", err)
@@ -404,7 +538,7 @@ func (c *Client) GetUserRoles(ctx context.Context, databaseName, userName string
 	defer conn.Close()
 
 	if _, err := conn.ExecContext(ctx, fmt.Sprintf("USE [%s]", databaseName)); err != nil {
-		return nil, fmt.Errorf("failed to switch database context: %w", err)
+		return nil, fmt.Errorf("failed to switch database context: %w", wrapSQLError(err))
 	}
 
 	rows, err := conn.QueryContext(ctx, query, userName)
@@ -427,8 +561,11 @@ func (c *Client) GetUserRoles(ctx context.Context, databaseName, userName string
 type ServerRole struct {
 	PrincipalID int
 	Name        string
-	OwnerName   string
+	SID         string // Hex string with 0x prefix
+	OwnerName   string // Empty for fixed roles
 	IsFixedRole bool
+	CreateDate  time.Time // With the UTC offset of the server
+	ModifyDate  time.Time
 }
 
 // GetServerRole retrieves a server role by name.
@@ -437,8 +574,11 @@ func (c *Client) GetServerRole(ctx context.Context, roleName string) (*ServerRol
 		SELECT
 			sp.principal_id,
 			sp.name,
-			ISNULL(owner.name, ''),
-			sp.is_fixed_role
+			ISNULL(CONVERT(varchar(172), sp.sid, 1), ''),
+			CASE WHEN sp.is_fixed_role = 1 THEN '' ELSE ISNULL(owner.name, '') END,
+			sp.is_fixed_role,
+			TODATETIMEOFFSET(sp.create_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET())),
+			TODATETIMEOFFSET(sp.modify_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET()))
 		FROM sys.server_principals sp
 		LEFT JOIN sys.server_principals owner ON sp.owning_principal_id = owner.principal_id
 		WHERE sp.name = @p1 AND sp.type = 'R'`
@@ -448,8 +588,11 @@ func (c *Client) GetServerRole(ctx context.Context, roleName string) (*ServerRol
 	err := row.Scan(
 		&role.PrincipalID,
 		&role.Name,
+		&role.SID,
 		&role.OwnerName,
 		&role.IsFixedRole,
+		&role.CreateDate,
+		&role.ModifyDate,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -467,8 +610,11 @@ func (c *Client) GetServerRoleByID(ctx context.Context, principalID int) (*Serve
 		SELECT
 			sp.principal_id,
 			sp.name,
-			ISNULL(owner.name, ''),
-			sp.is_fixed_role
+			ISNULL(CONVERT(varchar(172), sp.sid, 1), ''),
+			CASE WHEN sp.is_fixed_role = 1 THEN '' ELSE ISNULL(owner.name, '') END,
+			sp.is_fixed_role,
+			TODATETIMEOFFSET(sp.create_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET())),
+			TODATETIMEOFFSET(sp.modify_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET()))
 		FROM sys.server_principals sp
 		LEFT JOIN sys.server_principals owner ON sp.owning_principal_id = owner.principal_id
 		WHERE sp.principal_id = @p1 AND sp.type = 'R'`
@@ -478,8 +624,11 @@ func (c *Client) GetServerRoleByID(ctx context.Context, principalID int) (*Serve
 	err := row.Scan(
 		&role.PrincipalID,
 		&role.Name,
+		&role.SID,
 		&role.OwnerName,
 		&role.IsFixedRole,
+		&role.CreateDate,
+		&role.ModifyDate,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -497,8 +646,11 @@ func (c *Client) ListServerRoles(ctx context.Context) ([]ServerRole, error) {
 		SELECT
 			sp.principal_id,
 			sp.name,
-			ISNULL(owner.name, ''),
-			sp.is_fixed_role
+			ISNULL(CONVERT(varchar(172), sp.sid, 1), ''),
+			CASE WHEN sp.is_fixed_role = 1 THEN '' ELSE ISNULL(owner.name, '') END,
+			sp.is_fixed_role,
+			TODATETIMEOFFSET(sp.create_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET())),
+			TODATETIMEOFFSET(sp.modify_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET()))
 		FROM sys.server_principals sp
 		LEFT JOIN sys.server_principals owner ON sp.owning_principal_id = owner.principal_id
 		WHERE sp.type = 'R'
@@ -515,8 +667,11 @@ func (c *Client) ListServerRoles(ctx context.Context) ([]ServerRole, error) {
 		if err := rows.Scan(
 			&role.PrincipalID,
 			&role.Name,
+			&role.SID,
 			&role.OwnerName,
 			&role.IsFixedRole,
+			&role.CreateDate,
+			&role.ModifyDate,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan server role: %w", err)
 		}
@@ -558,6 +713,144 @@ func (c *Client) DropServerRole(ctx context.Context, roleName string) error {
 	return nil
 }
 
+// PrincipalMemberships holds the roles a principal belongs to on the server
+// and in each database.
+type PrincipalMemberships struct {
+	ServerRoles []string
+	// DatabaseRoles holds the roles by database name, for the databases that
+	// have a user for the principal
+	DatabaseRoles map[string][]string
+}
+
+// GetPrincipalMemberships retrieves the server roles and the database roles
+// in all accessible databases a principal belongs to. In databases, the
+// principal is matched by the SID of the login, or by name if there is no
+// such login.
+func (c *Client) GetPrincipalMemberships(ctx context.Context, principalName string) (*PrincipalMemberships, error) {
+	serverRoles, err := c.GetServerRoleMemberships(ctx, principalName)
+	if err != nil {
+		return nil, err
+	}
+	memberships := &PrincipalMemberships{
+		ServerRoles:   serverRoles,
+		DatabaseRoles: make(map[string][]string),
+	}
+
+	databases, err := c.listAccessibleDatabases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, databaseName := range databases {
+		roles, found, err := c.getPrincipalDatabaseRoles(ctx, databaseName, principalName)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			memberships.DatabaseRoles[databaseName] = roles
+		}
+	}
+
+	return memberships, nil
+}
+
+// GetServerRoleMemberships retrieves the server roles a login or server role
+// belongs to.
+func (c *Client) GetServerRoleMemberships(ctx context.Context, memberName string) ([]string, error) {
+	query := `
+		SELECT r.name
+		FROM sys.server_role_members srm
+		INNER JOIN sys.server_principals r ON srm.role_principal_id = r.principal_id
+		INNER JOIN sys.server_principals m ON srm.member_principal_id = m.principal_id
+		WHERE m.name = @p1
+		ORDER BY r.name`
+	rows, err := c.QueryContext(ctx, query, memberName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server role memberships: %w", err)
+	}
+	defer rows.Close()
+
+	roles := []string{}
+	for rows.Next() {
+		var roleName string
+		if err := rows.Scan(&roleName); err != nil {
+			return nil, fmt.Errorf("failed to scan role name: %w", err)
+		}
+		roles = append(roles, roleName)
+	}
+	return roles, rows.Err()
+}
+
+// listAccessibleDatabases retrieves the names of the online databases the
+// client has access to.
+func (c *Client) listAccessibleDatabases(ctx context.Context) ([]string, error) {
+	query := `SELECT name FROM sys.databases WHERE state_desc = 'ONLINE' AND HAS_DBACCESS(name) = 1 ORDER BY name`
+	rows, err := c.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list databases: %w", err)
+	}
+	defer rows.Close()
+
+	var databases []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan database: %w", err)
+		}
+		databases = append(databases, name)
+	}
+	return databases, rows.Err()
+}
+
+// getPrincipalDatabaseRoles retrieves the roles a principal belongs to in a
+// database. It reports whether the database has a user for the principal.
+func (c *Client) getPrincipalDatabaseRoles(ctx context.Context, databaseName, principalName string) ([]string, bool, error) {
+	query := `
+		SELECT r.name
+		FROM sys.database_principals m
+		LEFT JOIN sys.database_role_members drm ON drm.member_principal_id = m.principal_id
+		LEFT JOIN sys.database_principals r ON drm.role_principal_id = r.principal_id
+		WHERE (m.sid = SUSER_SID(@p1) OR (SUSER_SID(@p1) IS NULL AND m.name = @p1))
+			AND m.type IN ('S', 'U', 'G', 'E', 'X')
+		ORDER BY r.name`
+
+	var rows *sql.Rows
+	// Try to get a direct connection to the database first (Azure SQL support)
+	db, err := c.GetDatabaseConnection(ctx, databaseName)
+	if err == nil {
+		rows, err = db.QueryContext(ctx, query, principalName)
+	} else {
+		// Fallback to existing logic
+		conn, connErr := c.db.Conn(ctx)
+		if connErr != nil {
+			return nil, false, fmt.Errorf("failed to get database connection: %w", connErr)
+		}
+		defer conn.Close()
+
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("USE [%s]", databaseName)); err != nil {
+			return nil, false, fmt.Errorf("failed to switch database context: %w", wrapSQLError(err))
+		}
+		rows, err = conn.QueryContext(ctx, query, principalName)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get database role memberships: %w", err)
+	}
+	defer rows.Close()
+
+	roles := []string{}
+	found := false
+	for rows.Next() {
+		var roleName sql.NullString
+		if err := rows.Scan(&roleName); err != nil {
+			return nil, false, fmt.Errorf("failed to scan role name: %w", err)
+		}
+		found = true
+		if roleName.Valid {
+			roles = append(roles, roleName.String)
+		}
+	}
+	return roles, found, rows.Err()
+}
+
 // ServerRoleMember represents a server role membership.
 type ServerRoleMember struct {
 	RoleID     int
@@ -609,7 +902,14 @@ func (c *Client) AddServerRoleMember(ctx context.Context, roleName, memberName s
 }
 
 // RemoveServerRoleMember removes a member from a server role.
+// It does nothing if the role or the member no longer exists.
 func (c *Client) RemoveServerRoleMember(ctx context.Context, roleName, memberName string) error {
+	for _, principalName := range []string{roleName, memberName} {
+		if exists, err := c.ServerPrincipalExists(ctx, principalName); err != nil || !exists {
+			return err
+		}
+	}
+
 	query := fmt.Sprintf("ALTER SERVER ROLE [%s] DROP MEMBER [%s]", roleName, memberName)
 	_, err := c.ExecContext(ctx, query)
 	if err != nil {
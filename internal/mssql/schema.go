@@ -7,6 +7,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 )
 
 // Schema represents a database schema.
@@ -51,6 +52,25 @@ func (c *Client) GetSchema(ctx context.Context, databaseName, schemaName string)
 	return &schema, nil
 }
 
+// SchemaExists reports whether a schema exists in a database.
+func (c *Client) SchemaExists(ctx context.Context, databaseName, schemaName string) (bool, error) {
+	query := `SELECT 1 FROM sys.schemas WHERE name = @p1`
+
+	var row *sql.Row
+	// Try to get a direct connection to the database first (Azure SQL support)
+	db, err := c.GetDatabaseConnection(ctx, databaseName)
+	if err == nil {
+		row = db.QueryRowContext(ctx, query, schemaName)
+	} else {
+		row, err = c.QueryRowInDatabaseContext(ctx, databaseName, query, schemaName)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return scanExists(row, "schema")
+}
+
 // GetSchemaByID retrieves a schema by ID.
 func (c *Client) GetSchemaByID(ctx context.Context, databaseName string, schemaID int) (*Schema, error) {
 	query := `
@@ -86,7 +106,7 @@ func (c *Client) GetSchemaByID(ctx context.Context, databaseName string, schemaI
 }
 
 // ListSchemas retrieves all schemas from a database.
-func (c *Client) ListSchemas(ctx context.Context, databaseName string) ([]Schema, error) {
+func (c *Client) ListSchemas(ctx context.Context, databaseName, ownerName string) ([]Schema, error) {
 	// Get a dedicated connection from the pool
 	conn, err := c.db.Conn(ctx)
 	if err != nil {
@@ -96,7 +116,7 @@ func (c *Client) ListSchemas(ctx context.Context, databaseName string) ([]Schema
 
 	// Switch to the target database
 	if _, err := conn.ExecContext(ctx, fmt.Sprintf("USE [%s]", databaseName)); err != nil {
-		return nil, fmt.Errorf("failed to switch database context: %w", err)
+		return nil, fmt.Errorf("failed to switch database context: %w", wrapSQLError(err))
 	}
 
 	query := `
@@ -107,9 +127,10 @@ func (c *Client) ListSchemas(ctx context.Context, databaseName string) ([]Schema
 			DB_ID()
 		FROM sys.schemas s
 		INNER JOIN sys.database_principals dp ON s.principal_id = dp.principal_id
+		WHERE @p1 = '' OR dp.name = @p1
 		ORDER BY s.name`
 
-	rows, err := conn.QueryContext(ctx, query)
+	rows, err := conn.QueryContext(ctx, query, ownerName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list schemas: %w", err)
 	}
@@ -132,18 +153,93 @@ func (c *Client) ListSchemas(ctx context.Context, databaseName string) ([]Schema
 	return schemas, rows.Err()
 }
 
+// SchemaObject is an object contained in a schema, as listed in sys.objects.
+type SchemaObject struct {
+	ObjectID int
+	Name     string
+	Type     string // e.g. U, V, P or FN
+	TypeDesc string // e.g. USER_TABLE
+}
+
+// ListSchemaObjects retrieves the objects in a schema, optionally of a single
+// type such as U (table), V (view), P (procedure) or FN (scalar function).
+// Objects that belong to another object, such as constraints and triggers,
+// and objects shipped with SQL Server are left out.
+func (c *Client) ListSchemaObjects(ctx context.Context, databaseName, schemaName, objectType string) ([]SchemaObject, error) {
+	query := `
+		SELECT
+			o.object_id,
+			o.name,
+			RTRIM(o.type),
+			o.type_desc
+		FROM sys.objects o
+		INNER JOIN sys.schemas s ON o.schema_id = s.schema_id
+		WHERE s.name = @p1
+			AND o.parent_object_id = 0
+			AND o.is_ms_shipped = 0
+			AND (@p2 = '' OR o.type = @p2)
+		ORDER BY o.name`
+	objectType = strings.ToUpper(strings.TrimSpace(objectType))
+
+	var rows *sql.Rows
+	// Try to get a direct connection to the database first (Azure SQL support)
+	db, err := c.GetDatabaseConnection(ctx, databaseName)
+	if err == nil {
+		rows, err = db.QueryContext(ctx, query, schemaName, objectType)
+	} else {
+		// Fallback to existing logic
+		conn, connErr := c.db.Conn(ctx)
+		if connErr != nil {
+			return nil, fmt.Errorf("failed to get database connection: %w", connErr)
+		}
+		defer conn.Close()
+
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("USE %s", quoteIdentifier(databaseName))); err != nil {
+			return nil, fmt.Errorf("failed to switch database context: %w", wrapSQLError(err))
+		}
+		rows, err = conn.QueryContext(ctx, query, schemaName, objectType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schema objects: %w", wrapSQLError(err))
+	}
+	defer rows.Close()
+
+	var objects []SchemaObject
+	for rows.Next() {
+		var object SchemaObject
+		if err := rows.Scan(
+			&object.ObjectID,
+			&object.Name,
+			&object.Type,
+			&object.TypeDesc,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan schema object: %w", err)
+		}
+		objects = append(objects, object)
+	}
+
+	return objects, rows.Err()
+}
+
 // CreateSchemaOptions contains options for creating a schema.
 type CreateSchemaOptions struct {
 	DatabaseName string
 	SchemaName   string
 	OwnerName    string
+	// Elements are CREATE TABLE, CREATE VIEW, GRANT, REVOKE or DENY statements
+	// run in the same batch as CREATE SCHEMA, so they are created atomically
+	// with the schema.
+	Elements []string
 }
 
 // CreateSchema creates a new schema.
 func (c *Client) CreateSchema(ctx context.Context, opts CreateSchemaOptions) (*Schema, error) {
-	query := fmt.Sprintf("CREATE SCHEMA [%s]", opts.SchemaName)
+	query := fmt.Sprintf("CREATE SCHEMA %s", quoteIdentifier(opts.SchemaName))
 	if opts.OwnerName != "" {
-		query += fmt.Sprintf(" AUTHORIZATION [%s]", opts.OwnerName)
+		query += fmt.Sprintf(" AUTHORIZATION %s", quoteIdentifier(opts.OwnerName))
+	}
+	for _, element := range opts.Elements {
+		query += "\n" + strings.TrimRight(strings.TrimSpace(element), ";")
 	}
 
 	err := c.ExecInDatabaseContext(ctx, opts.DatabaseName, query)
@@ -171,7 +267,7 @@ type UpdateSchemaOptions struct {
 // UpdateSchema updates an existing schema.
 func (c *Client) UpdateSchema(ctx context.Context, opts UpdateSchemaOptions) (*Schema, error) {
 	if opts.NewOwnerName != nil {
-		query := fmt.Sprintf("ALTER AUTHORIZATION ON SCHEMA::[%s] TO [%s]", opts.SchemaName, *opts.NewOwnerName)
+		query := fmt.Sprintf("ALTER AUTHORIZATION ON SCHEMA::%s TO %s", quoteIdentifier(opts.SchemaName), quoteIdentifier(*opts.NewOwnerName))
 		err := c.ExecInDatabaseContext(ctx, opts.DatabaseName, query)
 		if err != nil {
 			return nil, fmt.Errorf("failed to update schema owner: %w", err)
@@ -183,7 +279,7 @@ func (c *Client) UpdateSchema(ctx context.Context, opts UpdateSchemaOptions) (*S
 
 // DropSchema drops a schema.
 func (c *Client) DropSchema(ctx context.Context, databaseName, schemaName string) error {
-	query := fmt.Sprintf("DROP SCHEMA IF EXISTS [%s]", schemaName)
+	query := fmt.Sprintf("DROP SCHEMA IF EXISTS %s", quoteIdentifier(schemaName))
 	err := c.ExecInDatabaseContext(ctx, databaseName, query)
 	if err != nil {
 		return fmt.Errorf("failed to drop schema: %w", err)
@@ -191,3 +287,19 @@ func (c *Client) DropSchema(ctx context.Context, databaseName, schemaName string
 
 	return nil
 }
+
+// IsSchemaElement reports whether statement can be used as a schema element
+// of CREATE SCHEMA.
+func IsSchemaElement(statement string) bool {
+	fields := strings.Fields(strings.ToUpper(statement))
+	if len(fields) == 0 {
+		return false
+	}
+	switch fields[0] {
+	case "GRANT", "REVOKE", "DENY":
+		return true
+	case "CREATE":
+		return len(fields) > 1 && (fields[1] == "TABLE" || fields[1] == "VIEW")
+	}
+	return false
+}
@@ -6,8 +6,13 @@ package mssql
 import (
 	"context"
 	"crypto/sha256"
+	"database/sql"
 	"encoding/hex"
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 // Script represents a SQL script execution.
@@ -27,18 +32,282 @@ type ScriptState struct {
 	State map[string]string
 }
 
-// ExecuteScript executes a SQL script and returns the results as a map.
-func (c *Client) ExecuteScript(ctx context.Context, databaseName, script string) (map[string]string, error) {
-	if databaseName != "" {
-		if err := c.UseDatabase(ctx, databaseName); err != nil {
+// queryInDatabase runs a query in the context of a database and returns its rows
+// together with a function that releases the connection they are read from.
+// The USE statement and the query run on the same dedicated connection, so the
+// query cannot end up in another database. An empty database name runs the
+// query on the shared pool.
+func (c *Client) queryInDatabase(ctx context.Context, databaseName, query string) (*sql.Rows, func(), error) {
+	noop := func() {}
+	if databaseName == "" {
+		rows, err := c.QueryContext(ctx, query)
+		return rows, noop, err
+	}
+
+	// Try to get a direct connection to the database first (Azure SQL support)
+	if db, err := c.GetDatabaseConnection(ctx, databaseName); err == nil {
+		rows, err := db.QueryContext(ctx, query)
+		return rows, noop, wrapSQLError(err)
+	}
+
+	// Fallback to a dedicated connection from the pool
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return nil, noop, fmt.Errorf("failed to get database connection: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("USE [%s]", databaseName)); err != nil {
+		conn.Close()
+		return nil, noop, fmt.Errorf("failed to switch database context: %w", wrapSQLError(err))
+	}
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		conn.Close()
+		return nil, noop, wrapSQLError(err)
+	}
+	return rows, func() { conn.Close() }, nil
+}
+
+// scriptSetOptions are the session options that can be set for a script. They
+// cover the options required to create filtered indexes, indexed views and
+// computed column indexes, plus common error handling options.
+var scriptSetOptions = map[string]bool{
+	"ANSI_NULLS":              true,
+	"ANSI_NULL_DFLT_OFF":      true,
+	"ANSI_NULL_DFLT_ON":       true,
+	"ANSI_PADDING":            true,
+	"ANSI_WARNINGS":           true,
+	"ARITHABORT":              true,
+	"CONCAT_NULL_YIELDS_NULL": true,
+	"NOCOUNT":                 true,
+	"NUMERIC_ROUNDABORT":      true,
+	"QUOTED_IDENTIFIER":       true,
+	"XACT_ABORT":              true,
+}
+
+// ScriptSetOptionNames returns the names of the supported script SET options.
+func ScriptSetOptionNames() []string {
+	names := make([]string, 0, len(scriptSetOptions))
+	for name := range scriptSetOptions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseSetOption parses a SET option in the form "QUOTED_IDENTIFIER ON" and
+// returns the SET statement for it.
+func ParseSetOption(option string) (string, error) {
+	fields := strings.Fields(strings.ToUpper(option))
+	if len(fields) != 2 {
+		return "", fmt.Errorf("SET option must be in the form '<option> ON|OFF', got: %s", option)
+	}
+	if !scriptSetOptions[fields[0]] {
+		return "", fmt.Errorf("unsupported SET option '%s', must be one of: %s", fields[0], strings.Join(ScriptSetOptionNames(), ", "))
+	}
+	if fields[1] != "ON" && fields[1] != "OFF" {
+		return "", fmt.Errorf("SET option %s must be ON or OFF, got: %s", fields[0], fields[1])
+	}
+	return fmt.Sprintf("SET %s %s", fields[0], fields[1]), nil
+}
+
+// statementOptionKind is the kind of value a statement option takes.
+type statementOptionKind int
+
+const (
+	statementOptionOnOff statementOptionKind = iota
+	statementOptionCount
+	statementOptionMinutes
+)
+
+// scriptStatementOptions are the options that can be appended to a script as
+// a WITH clause. They cover the index options commonly required for DDL on
+// busy tables.
+var scriptStatementOptions = map[string]statementOptionKind{
+	"MAXDOP":         statementOptionCount,
+	"MAX_DURATION":   statementOptionMinutes,
+	"ONLINE":         statementOptionOnOff,
+	"RESUMABLE":      statementOptionOnOff,
+	"SORT_IN_TEMPDB": statementOptionOnOff,
+}
+
+// ScriptStatementOptionNames returns the names of the supported script
+// statement options.
+func ScriptStatementOptionNames() []string {
+	names := make([]string, 0, len(scriptStatementOptions))
+	for name := range scriptStatementOptions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseStatementOption parses a statement option in the form "ONLINE = ON" or
+// "MAXDOP = 4" and returns it in normalized form.
+func ParseStatementOption(option string) (string, error) {
+	name, value, ok := strings.Cut(option, "=")
+	if !ok {
+		return "", fmt.Errorf("statement option must be in the form '<option> = <value>', got: %s", option)
+	}
+	name = strings.ToUpper(strings.TrimSpace(name))
+	value = strings.ToUpper(strings.TrimSpace(value))
+
+	kind, ok := scriptStatementOptions[name]
+	if !ok {
+		return "", fmt.Errorf("unsupported statement option '%s', must be one of: %s", name, strings.Join(ScriptStatementOptionNames(), ", "))
+	}
+	switch kind {
+	case statementOptionOnOff:
+		if value != "ON" && value != "OFF" {
+			return "", fmt.Errorf("statement option %s must be ON or OFF, got: %s", name, value)
+		}
+	case statementOptionCount:
+		if n, err := strconv.Atoi(value); err != nil || n < 0 {
+			return "", fmt.Errorf("statement option %s must be a non-negative number, got: %s", name, value)
+		}
+	case statementOptionMinutes:
+		// MAX_DURATION also accepts an explicit unit
+		minutes := strings.TrimSpace(strings.TrimSuffix(value, "MINUTES"))
+		if n, err := strconv.Atoi(minutes); err != nil || n <= 0 {
+			return "", fmt.Errorf("statement option %s must be a positive number of minutes, got: %s", name, value)
+		}
+		value = minutes
+	}
+	return fmt.Sprintf("%s = %s", name, value), nil
+}
+
+var (
+	// indexStatementPattern matches the index statements that take the
+	// supported statement options in a trailing WITH clause.
+	indexStatementPattern = regexp.MustCompile(`(?is)^(CREATE\s+(UNIQUE\s+)?((NON)?CLUSTERED\s+)?INDEX|ALTER\s+INDEX\s.*\sREBUILD)\b`)
+	// withClausePattern matches a WITH clause that is already present.
+	withClausePattern = regexp.MustCompile(`(?i)\bWITH\s*\(`)
+	// filegroupClausePattern matches an ON or FILESTREAM_ON clause after the
+	// column list, which must come after the WITH clause.
+	filegroupClausePattern = regexp.MustCompile(`(?is)\)\s*(ON|FILESTREAM_ON)\s+[^()]*(\([^()]*\))?\s*$`)
+	// batchSeparatorPattern matches a GO line that separates batches.
+	batchSeparatorPattern = regexp.MustCompile(`(?im)^\s*GO\s*$`)
+)
+
+// CheckStatementOptionsScript checks that statement options can be appended
+// to a script: it must be a single CREATE INDEX or ALTER INDEX ... REBUILD
+// statement without a WITH clause or a trailing ON clause. Other scripts would
+// end up with a WITH clause the last statement does not accept.
+func CheckStatementOptionsScript(script string) error {
+	trimmed := strings.TrimSpace(strings.TrimRight(script, " \t\r\n;"))
+	if trimmed == "" {
+		return fmt.Errorf("statement options require a script to append them to")
+	}
+	if !indexStatementPattern.MatchString(trimmed) || strings.Contains(trimmed, ";") || batchSeparatorPattern.MatchString(trimmed) {
+		return fmt.Errorf("statement options can only be appended to a single CREATE INDEX or ALTER INDEX ... REBUILD statement")
+	}
+	if withClausePattern.MatchString(trimmed) {
+		return fmt.Errorf("the statement already has a WITH clause; add the options to it instead of using statement options")
+	}
+	if filegroupClausePattern.MatchString(trimmed) {
+		return fmt.Errorf("the statement ends with an ON or FILESTREAM_ON clause, which must follow the WITH clause; add the options to the statement instead of using statement options")
+	}
+	return nil
+}
+
+// AppendStatementOptions appends the given statement options to a single
+// index statement as a WITH clause, e.g. to rebuild an index online; see
+// CheckStatementOptionsScript. Trailing semicolons are removed and the clause
+// starts on a new line, so a comment at the end of the script does not
+// swallow it.
+func AppendStatementOptions(script string, options []string) (string, error) {
+	if len(options) == 0 {
+		return script, nil
+	}
+	if err := CheckStatementOptionsScript(script); err != nil {
+		return "", err
+	}
+
+	clauses := make([]string, 0, len(options))
+	for _, option := range options {
+		clause, err := ParseStatementOption(option)
+		if err != nil {
+			return "", err
+		}
+		clauses = append(clauses, clause)
+	}
+
+	trimmed := strings.TrimRight(script, " \t\r\n;")
+	return fmt.Sprintf("%s\nWITH (%s)", trimmed, strings.Join(clauses, ", ")), nil
+}
+
+// scriptConn returns a dedicated connection in the context of a database with
+// the given SET options applied. SET options only last for the session, so the
+// script must run on the same connection. Pooled connections are reset before
+// they are reused, so the options do not leak into other operations. The
+// caller must close the connection.
+func (c *Client) scriptConn(ctx context.Context, databaseName string, setOptions []string) (*sql.Conn, error) {
+	var statements []string
+	for _, option := range setOptions {
+		statement, err := ParseSetOption(option)
+		if err != nil {
 			return nil, err
 		}
+		statements = append(statements, statement)
+	}
+
+	var conn *sql.Conn
+	var err error
+	if databaseName == "" {
+		conn, err = c.db.Conn(ctx)
+	} else if db, dbErr := c.GetDatabaseConnection(ctx, databaseName); dbErr == nil {
+		// Try to get a direct connection to the database first (Azure SQL support)
+		conn, err = db.Conn(ctx)
+	} else {
+		conn, err = c.db.Conn(ctx)
+		if err == nil {
+			if _, err := conn.ExecContext(ctx, fmt.Sprintf("USE [%s]", databaseName)); err != nil {
+				conn.Close()
+				return nil, fmt.Errorf("failed to switch database context: %w", wrapSQLError(err))
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection: %w", err)
+	}
+
+	if len(statements) > 0 {
+		if _, err := conn.ExecContext(ctx, strings.Join(statements, "; ")); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to apply SET options: %w", wrapSQLError(err))
+		}
+	}
+	return conn, nil
+}
+
+// queryScript runs a script like queryInDatabase, on a dedicated connection
+// with the SET options applied if there are any.
+func (c *Client) queryScript(ctx context.Context, databaseName, script string, setOptions []string) (*sql.Rows, func(), error) {
+	if len(setOptions) == 0 {
+		return c.queryInDatabase(ctx, databaseName, script)
 	}
 
-	rows, err := c.QueryContext(ctx, script)
+	noop := func() {}
+	conn, err := c.scriptConn(ctx, databaseName, setOptions)
+	if err != nil {
+		return nil, noop, err
+	}
+	rows, err := conn.QueryContext(ctx, script)
+	if err != nil {
+		conn.Close()
+		return nil, noop, wrapSQLError(err)
+	}
+	return rows, func() { conn.Close() }, nil
+}
+
+// ExecuteScript executes a SQL script and returns the results as a map.
+// If setOptions are given, they are applied to the session before the script
+// runs; see ParseSetOption.
+func (c *Client) ExecuteScript(ctx context.Context, databaseName, script string, setOptions []string) (map[string]string, error) {
+	rows, release, err := c.queryScript(ctx, databaseName, script, setOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute script: %w", err)
 	}
+	defer release()
 	defer rows.Close()
 
 	columns, err := rows.Columns()
@@ -77,14 +346,28 @@ func (c *Client) ExecuteScript(ctx context.Context, databaseName, script string)
 }
 
 // ExecuteScriptNoResult executes a SQL script without returning results.
-func (c *Client) ExecuteScriptNoResult(ctx context.Context, databaseName, script string) error {
-	if databaseName != "" {
-		if err := c.UseDatabase(ctx, databaseName); err != nil {
-			return err
+// If setOptions are given, they are applied to the session before the script
+// runs; see ParseSetOption.
+func (c *Client) ExecuteScriptNoResult(ctx context.Context, databaseName, script string, setOptions []string) error {
+	var err error
+	if len(setOptions) > 0 {
+		var conn *sql.Conn
+		conn, err = c.scriptConn(ctx, databaseName, setOptions)
+		if err == nil {
+			defer conn.Close()
+			_, err = conn.ExecContext(ctx, script)
+			err = wrapSQLError(err)
+		}
+	} else if databaseName != "" {
+		// Try to get a direct connection to the database first (Azure SQL support)
+		if db, dbErr := c.GetDatabaseConnection(ctx, databaseName); dbErr == nil {
+			err = execSQL(ctx, db, script)
+		} else {
+			err = c.ExecInDatabaseContext(ctx, databaseName, script)
 		}
+	} else {
+		_, err = c.ExecContext(ctx, script)
 	}
-
-	_, err := c.ExecContext(ctx, script)
 	if err != nil {
 		return fmt.Errorf("failed to execute script: %w", err)
 	}
@@ -106,18 +389,36 @@ type QueryResult struct {
 
 // ExecuteQuery executes a query and returns all results.
 func (c *Client) ExecuteQuery(ctx context.Context, databaseName, query string) (*QueryResult, error) {
-	if databaseName != "" {
-		if err := c.UseDatabase(ctx, databaseName); err != nil {
-			return nil, err
-		}
+	rows, release, err := c.queryInDatabase(ctx, databaseName, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
+	defer release()
+	defer rows.Close()
 
-	rows, err := c.QueryContext(ctx, query)
+	return scanQueryResult(rows)
+}
+
+// ExecuteReadOnlyQuery executes a query on a connection with a read-only
+// application intent and returns all results. Such connections can be routed to
+// a readable secondary replica; see GetReadOnlyDatabaseConnection.
+func (c *Client) ExecuteReadOnlyQuery(ctx context.Context, databaseName, query string) (*QueryResult, error) {
+	db, err := c.GetReadOnlyDatabaseConnection(ctx, databaseName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute query: %w", err)
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", wrapSQLError(err))
 	}
 	defer rows.Close()
 
+	return scanQueryResult(rows)
+}
+
+// scanQueryResult reads all rows of a query result as strings.
+func scanQueryResult(rows *sql.Rows) (*QueryResult, error) {
 	columns, err := rows.Columns()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get columns: %w", err)
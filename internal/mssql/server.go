@@ -0,0 +1,130 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package mssql
+
+import (
+	"context"
+	"fmt"
+)
+
+// ServerProperties represents instance-level properties reported by SERVERPROPERTY.
+type ServerProperties struct {
+	Version       string
+	EngineEdition int
+	ProductLevel  string
+	Collation     string
+	MachineName   string
+	IsAzure       bool
+}
+
+// GetServerProperties retrieves the properties of the connected server.
+func (c *Client) GetServerProperties(ctx context.Context) (*ServerProperties, error) {
+	// EngineEdition 5 = Azure SQL Database, 6 = Azure Synapse, 8 = Azure SQL Managed Instance
+	query := `
+		SELECT
+			CAST(SERVERPROPERTY('ProductVersion') AS NVARCHAR(128)),
+			CAST(SERVERPROPERTY('EngineEdition') AS INT),
+			ISNULL(CAST(SERVERPROPERTY('ProductLevel') AS NVARCHAR(128)), ''),
+			CAST(SERVERPROPERTY('Collation') AS NVARCHAR(128)),
+			ISNULL(CAST(SERVERPROPERTY('MachineName') AS NVARCHAR(128)), ''),
+			CASE WHEN CAST(SERVERPROPERTY('EngineEdition') AS INT) IN (5, 6, 8) THEN 1 ELSE 0 END`
+	row := c.QueryRowContext(ctx, query)
+
+	var props ServerProperties
+	err := row.Scan(
+		&props.Version,
+		&props.EngineEdition,
+		&props.ProductLevel,
+		&props.Collation,
+		&props.MachineName,
+		&props.IsAzure,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server properties: %w", err)
+	}
+
+	return &props, nil
+}
+
+// Endpoint represents a server endpoint from sys.endpoints.
+type Endpoint struct {
+	EndpointID   int
+	Name         string
+	ProtocolDesc string
+	TypeDesc     string // e.g. TSQL, DATABASE_MIRRORING, SERVICE_BROKER
+	StateDesc    string // STARTED, STOPPED or DISABLED
+}
+
+// ListEndpoints lists the endpoints of the server, e.g. to find the name of
+// the database mirroring endpoint used by availability groups.
+func (c *Client) ListEndpoints(ctx context.Context) ([]Endpoint, error) {
+	query := `
+		SELECT endpoint_id, name, protocol_desc, type_desc, state_desc
+		FROM sys.endpoints
+		ORDER BY name`
+	rows, err := c.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var endpoints []Endpoint
+	for rows.Next() {
+		var endpoint Endpoint
+		if err := rows.Scan(
+			&endpoint.EndpointID,
+			&endpoint.Name,
+			&endpoint.ProtocolDesc,
+			&endpoint.TypeDesc,
+			&endpoint.StateDesc,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan endpoint: %w", err)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	return endpoints, rows.Err()
+}
+
+// Language represents a language of the server from sys.syslanguages.
+type Language struct {
+	LangID int
+	Name   string
+	Alias  string
+}
+
+// ListLanguages lists the languages of the server, which logins and users can
+// have as default language. The list is read once and cached by the client.
+func (c *Client) ListLanguages(ctx context.Context) ([]Language, error) {
+	c.languagesMu.Lock()
+	defer c.languagesMu.Unlock()
+	if c.languages != nil {
+		return c.languages, nil
+	}
+
+	query := `
+		SELECT langid, name, alias
+		FROM sys.syslanguages
+		ORDER BY langid`
+	rows, err := c.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list languages: %w", err)
+	}
+	defer rows.Close()
+
+	languages := []Language{}
+	for rows.Next() {
+		var language Language
+		if err := rows.Scan(&language.LangID, &language.Name, &language.Alias); err != nil {
+			return nil, fmt.Errorf("failed to scan language: %w", err)
+		}
+		languages = append(languages, language)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list languages: %w", err)
+	}
+
+	c.languages = languages
+	return languages, nil
+}
@@ -7,8 +7,10 @@ import (
 	"context"
 	"database/sql"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // guidToSID converts an Azure AD Object ID (GUID) to the binary SID format required by SQL Server.
@@ -50,8 +52,15 @@ type User struct {
 	Name              string
 	DatabaseID        int
 	DefaultSchemaName string
-	Type              string // S = SQL user, U = Windows user, E = External user (Azure AD)
+	Type              string // S = SQL user, U = Windows user, E = External user (Azure AD), X = External group, C = Certificate user
 	LoginName         string
+	// CertificateName is the certificate a user of type C is mapped to
+	CertificateName string
+	SID             string // Hex string with 0x prefix
+	// DefaultLanguageName is only set for users of contained databases
+	DefaultLanguageName string
+	CreateDate          time.Time // With the UTC offset of the server
+	ModifyDate          time.Time
 }
 
 // Request a user from a specific database.
@@ -59,7 +68,6 @@ func (c *Client) GetUser(ctx context.Context, databaseName, userName string) (*U
 	// Try to get a direct connection to the database first (Azure SQL support)
 	db, err := c.GetDatabaseConnection(ctx, databaseName)
 	if err == nil {
-		defer db.Close()
 		return c.getUserWithDB(ctx, db, userName)
 	}
 
@@ -71,10 +79,16 @@ func (c *Client) GetUser(ctx context.Context, databaseName, userName string) (*U
 			DB_ID() as database_id,
 			ISNULL(dp.default_schema_name, 'dbo'),
 			dp.type,
-			ISNULL(sp.name, '')
+			CASE WHEN dp.type = 'C' THEN '' ELSE ISNULL(sp.name, '') END,
+			ISNULL(cert.name, ''),
+			ISNULL(CONVERT(varchar(172), dp.sid, 1), ''),
+			ISNULL(dp.default_language_name, ''),
+			TODATETIMEOFFSET(dp.create_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET())),
+			TODATETIMEOFFSET(dp.modify_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET()))
 		FROM sys.database_principals dp
 		LEFT JOIN sys.server_principals sp ON dp.sid = sp.sid
-		WHERE dp.name = @p1 AND dp.type IN ('S', 'U', 'E', 'X')` // X = EXTERNAL_GROUP
+		LEFT JOIN sys.certificates cert ON dp.type = 'C' AND cert.sid = dp.sid
+		WHERE dp.name = @p1 AND dp.type IN ('S', 'U', 'E', 'X', 'C')` // X = EXTERNAL_GROUP, C = CERTIFICATE_MAPPED_USER
 
 	row, err := c.QueryRowInDatabaseContext(ctx, databaseName, query, userName)
 	if err != nil {
@@ -92,10 +106,16 @@ func (c *Client) getUserWithDB(ctx context.Context, db *sql.DB, userName string)
 			DB_ID() as database_id,
 			ISNULL(dp.default_schema_name, 'dbo'),
 			dp.type,
-			ISNULL(sp.name, '')
+			CASE WHEN dp.type = 'C' THEN '' ELSE ISNULL(sp.name, '') END,
+			ISNULL(cert.name, ''),
+			ISNULL(CONVERT(varchar(172), dp.sid, 1), ''),
+			ISNULL(dp.default_language_name, ''),
+			TODATETIMEOFFSET(dp.create_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET())),
+			TODATETIMEOFFSET(dp.modify_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET()))
 		FROM sys.database_principals dp
 		LEFT JOIN sys.server_principals sp ON dp.sid = sp.sid
-		WHERE dp.name = @p1 AND dp.type IN ('S', 'U', 'E', 'X')` // X = EXTERNAL_GROUP
+		LEFT JOIN sys.certificates cert ON dp.type = 'C' AND cert.sid = dp.sid
+		WHERE dp.name = @p1 AND dp.type IN ('S', 'U', 'E', 'X', 'C')` // X = EXTERNAL_GROUP, C = CERTIFICATE_MAPPED_USER
 
 	row := db.QueryRowContext(ctx, query, userName)
 	return scanUser(row)
@@ -110,6 +130,11 @@ func scanUser(row *sql.Row) (*User, error) {
 		&user.DefaultSchemaName,
 		&user.Type,
 		&user.LoginName,
+		&user.CertificateName,
+		&user.SID,
+		&user.DefaultLanguageName,
+		&user.CreateDate,
+		&user.ModifyDate,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -129,10 +154,16 @@ func (c *Client) GetUserByID(ctx context.Context, databaseName string, principal
 			DB_ID() as database_id,
 			ISNULL(dp.default_schema_name, 'dbo'),
 			dp.type,
-			ISNULL(sp.name, '')
+			CASE WHEN dp.type = 'C' THEN '' ELSE ISNULL(sp.name, '') END,
+			ISNULL(cert.name, ''),
+			ISNULL(CONVERT(varchar(172), dp.sid, 1), ''),
+			ISNULL(dp.default_language_name, ''),
+			TODATETIMEOFFSET(dp.create_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET())),
+			TODATETIMEOFFSET(dp.modify_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET()))
 		FROM sys.database_principals dp
 		LEFT JOIN sys.server_principals sp ON dp.sid = sp.sid
-		WHERE dp.principal_id = @p1 AND dp.type IN ('S', 'U', 'E', 'X')` // X = EXTERNAL_GROUP
+		LEFT JOIN sys.certificates cert ON dp.type = 'C' AND cert.sid = dp.sid
+		WHERE dp.principal_id = @p1 AND dp.type IN ('S', 'U', 'E', 'X', 'C')` // X = EXTERNAL_GROUP, C = CERTIFICATE_MAPPED_USER
 
 	row, err := c.QueryRowInDatabaseContext(ctx, databaseName, query, principalID)
 	if err != nil {
@@ -147,6 +178,11 @@ func (c *Client) GetUserByID(ctx context.Context, databaseName string, principal
 		&user.DefaultSchemaName,
 		&user.Type,
 		&user.LoginName,
+		&user.CertificateName,
+		&user.SID,
+		&user.DefaultLanguageName,
+		&user.CreateDate,
+		&user.ModifyDate,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -169,7 +205,7 @@ func (c *Client) ListUsers(ctx context.Context, databaseName string) ([]User, er
 
 	// Switch to the target database
 	if _, err := conn.ExecContext(ctx, fmt.Sprintf("USE [%s]", databaseName)); err != nil {
-		return nil, fmt.Errorf("failed to switch database context: %w", err)
+		return nil, fmt.Errorf("failed to switch database context: %w", wrapSQLError(err))
 	}
 
 	query := `
@@ -179,10 +215,16 @@ func (c *Client) ListUsers(ctx context.Context, databaseName string) ([]User, er
 			DB_ID() as database_id,
 			ISNULL(dp.default_schema_name, 'dbo'),
 			dp.type,
-			ISNULL(sp.name, '')
+			CASE WHEN dp.type = 'C' THEN '' ELSE ISNULL(sp.name, '') END,
+			ISNULL(cert.name, ''),
+			ISNULL(CONVERT(varchar(172), dp.sid, 1), ''),
+			ISNULL(dp.default_language_name, ''),
+			TODATETIMEOFFSET(dp.create_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET())),
+			TODATETIMEOFFSET(dp.modify_date, DATEPART(TZOFFSET, SYSDATETIMEOFFSET()))
 		FROM sys.database_principals dp
 		LEFT JOIN sys.server_principals sp ON dp.sid = sp.sid
-		WHERE dp.type IN ('S', 'U', 'E', 'X') // X = EXTERNAL_GROUP
+		LEFT JOIN sys.certificates cert ON dp.type = 'C' AND cert.sid = dp.sid
+		WHERE dp.type IN ('S', 'U', 'E', 'X', 'C') -- X = EXTERNAL_GROUP, C = CERTIFICATE_MAPPED_USER
 		ORDER BY dp.name`
 
 	rows, err := conn.QueryContext(ctx, query)
@@ -201,6 +243,11 @@ func (c *Client) ListUsers(ctx context.Context, databaseName string) ([]User, er
 			&user.DefaultSchemaName,
 			&user.Type,
 			&user.LoginName,
+			&user.CertificateName,
+			&user.SID,
+			&user.DefaultLanguageName,
+			&user.CreateDate,
+			&user.ModifyDate,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
@@ -210,27 +257,133 @@ func (c *Client) ListUsers(ctx context.Context, databaseName string) ([]User, er
 	return users, rows.Err()
 }
 
+// UserSID is the SID of a database user, as listed by ListUserSIDs.
+type UserSID struct {
+	Name string
+	// Type is S, U, E or X as for User, and TypeDesc the matching type_desc,
+	// e.g. SQL_USER.
+	Type     string
+	TypeDesc string
+	// AuthenticationType is INSTANCE for users mapped to a login, DATABASE
+	// for contained users with a password and EXTERNAL for Azure AD users.
+	AuthenticationType string
+	SID                string // Hex string with 0x prefix
+}
+
+// ListUserSIDs retrieves the SIDs of the users of a database, e.g. to create
+// them with the same SIDs in a copy of the database. The built-in dbo, guest,
+// INFORMATION_SCHEMA and sys users are left out.
+func (c *Client) ListUserSIDs(ctx context.Context, databaseName string) ([]UserSID, error) {
+	query := `
+		SELECT
+			name,
+			type,
+			type_desc,
+			ISNULL(authentication_type_desc, ''),
+			CONVERT(varchar(172), sid, 1)
+		FROM sys.database_principals
+		WHERE type IN ('S', 'U', 'E', 'X') -- X = EXTERNAL_GROUP
+			AND principal_id > 4
+			AND sid IS NOT NULL
+		ORDER BY name`
+
+	var rows *sql.Rows
+	// Try to get a direct connection to the database first (Azure SQL support)
+	db, err := c.GetDatabaseConnection(ctx, databaseName)
+	if err == nil {
+		rows, err = db.QueryContext(ctx, query)
+	} else {
+		// Fallback to USE statement for on-premises SQL Server
+		conn, connErr := c.db.Conn(ctx)
+		if connErr != nil {
+			return nil, fmt.Errorf("failed to get database connection: %w", connErr)
+		}
+		defer conn.Close()
+
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("USE %s", quoteIdentifier(databaseName))); err != nil {
+			return nil, fmt.Errorf("failed to switch database context: %w", wrapSQLError(err))
+		}
+		rows, err = conn.QueryContext(ctx, query)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user SIDs: %w", err)
+	}
+	defer rows.Close()
+
+	var users []UserSID
+	for rows.Next() {
+		var user UserSID
+		if err := rows.Scan(&user.Name, &user.Type, &user.TypeDesc, &user.AuthenticationType, &user.SID); err != nil {
+			return nil, fmt.Errorf("failed to scan user SID: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
 // CreateSQLUserOptions contains options for creating a SQL user.
 type CreateSQLUserOptions struct {
 	DatabaseName  string
 	UserName      string
 	LoginName     string
 	DefaultSchema string
+	// DefaultLanguage can only be set in contained databases
+	DefaultLanguage string
+	// Password creates a contained database user that authenticates with
+	// the password instead of a login. LoginName is ignored then.
+	Password string
+	// SID sets the SID of a contained database user, as a hex string with 0x
+	// prefix, e.g. to create the user with the same SID on every replica.
+	SID string
+	// CertificateName creates a user mapped to a certificate of the database,
+	// e.g. to sign modules with. Such a user cannot log in and has no default
+	// schema or language, so the other options are ignored.
+	CertificateName string
 }
 
-// CreateSQLUser creates a new SQL user mapped to a login.
+// CreateSQLUser creates a new SQL user mapped to a login, a contained
+// database user with a password, or a user mapped to a certificate.
 func (c *Client) CreateSQLUser(ctx context.Context, opts CreateSQLUserOptions) (*User, error) {
 	defaultSchema := opts.DefaultSchema
 	if defaultSchema == "" {
 		defaultSchema = "dbo"
 	}
 
-	query := fmt.Sprintf(
-		"CREATE USER [%s] FOR LOGIN [%s] WITH DEFAULT_SCHEMA = [%s]",
-		opts.UserName,
-		opts.LoginName,
-		defaultSchema,
-	)
+	var query string
+	if opts.CertificateName != "" {
+		query = fmt.Sprintf(
+			"CREATE USER %s FOR CERTIFICATE %s",
+			quoteIdentifier(opts.UserName),
+			quoteIdentifier(opts.CertificateName),
+		)
+		// The certificate user has no default schema to wait for
+		defaultSchema = "dbo"
+	} else if opts.Password != "" {
+		query = fmt.Sprintf(
+			"CREATE USER [%s] WITH PASSWORD = %s, DEFAULT_SCHEMA = [%s]",
+			opts.UserName,
+			quoteString(opts.Password),
+			defaultSchema,
+		)
+		if opts.SID != "" {
+			query += fmt.Sprintf(", SID = %s", opts.SID)
+		}
+	} else {
+		query = fmt.Sprintf(
+			"CREATE USER [%s] FOR LOGIN [%s] WITH DEFAULT_SCHEMA = [%s]",
+			opts.UserName,
+			opts.LoginName,
+			defaultSchema,
+		)
+	}
+	if opts.DefaultLanguage != "" && opts.CertificateName == "" {
+		query += fmt.Sprintf(", DEFAULT_LANGUAGE = %s", quoteIdentifier(opts.DefaultLanguage))
+	}
+
+	if err := c.waitForDefaultSchema(ctx, opts.DatabaseName, defaultSchema); err != nil {
+		return nil, err
+	}
 
 	err := c.ExecInDatabaseContext(ctx, opts.DatabaseName, query)
 	if err != nil {
@@ -247,23 +400,72 @@ func (c *Client) CreateSQLUser(ctx context.Context, opts CreateSQLUserOptions) (
 	return user, nil
 }
 
+// waitForDefaultSchema waits for a bounded time until the default schema of a
+// new user exists, so that a schema created in the same apply is visible
+// before the user is created. SQL Server accepts a default schema that does
+// not exist yet, so a schema that is still missing afterwards is not an error;
+// callers can check SchemaExists to warn about it.
+func (c *Client) waitForDefaultSchema(ctx context.Context, databaseName, schemaName string) error {
+	// dbo exists in every database
+	if c.NamesEqual(schemaName, "dbo") {
+		return nil
+	}
+
+	err := retryWhileNotFound(ctx, func() error {
+		exists, err := c.SchemaExists(ctx, databaseName, schemaName)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrNotFound
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to check default schema: %w", err)
+	}
+	return nil
+}
+
 // UpdateSQLUserOptions contains options for updating a SQL user.
 type UpdateSQLUserOptions struct {
 	DatabaseName  string
 	UserName      string
 	DefaultSchema *string
+	LoginName     *string // Maps the user to another login, keeping its permissions
+	Password      *string // Only for contained database users with a password
+	// DefaultLanguage can only be set in contained databases. An empty string
+	// removes the default language.
+	DefaultLanguage *string
 }
 
 // UpdateSQLUser updates an existing SQL user.
 func (c *Client) UpdateSQLUser(ctx context.Context, opts UpdateSQLUserOptions) (*User, error) {
+	var withParts []string
 	if opts.DefaultSchema != nil {
-		query := fmt.Sprintf("ALTER USER [%s] WITH DEFAULT_SCHEMA = [%s]", opts.UserName, *opts.DefaultSchema)
+		withParts = append(withParts, fmt.Sprintf("DEFAULT_SCHEMA = [%s]", *opts.DefaultSchema))
+	}
+	if opts.LoginName != nil {
+		withParts = append(withParts, fmt.Sprintf("LOGIN = %s", quoteIdentifier(*opts.LoginName)))
+	}
+	if opts.Password != nil {
+		withParts = append(withParts, fmt.Sprintf("PASSWORD = %s", quoteString(*opts.Password)))
+	}
+	if opts.DefaultLanguage != nil {
+		language := "NONE"
+		if *opts.DefaultLanguage != "" {
+			language = quoteIdentifier(*opts.DefaultLanguage)
+		}
+		withParts = append(withParts, fmt.Sprintf("DEFAULT_LANGUAGE = %s", language))
+	}
+
+	if len(withParts) > 0 {
+		query := fmt.Sprintf("ALTER USER [%s] WITH %s", opts.UserName, strings.Join(withParts, ", "))
 
 		// Try to get a direct connection to the database first (Azure SQL support)
 		db, err := c.GetDatabaseConnection(ctx, opts.DatabaseName)
 		if err == nil {
-			defer db.Close()
-			_, err = db.ExecContext(ctx, query)
+			err = execSQL(ctx, db, query)
 			if err != nil {
 				return nil, fmt.Errorf("failed to update SQL user: %w", err)
 			}
@@ -286,8 +488,7 @@ func (c *Client) DropUser(ctx context.Context, databaseName, userName string) er
 	// Try to get a direct connection to the database first (Azure SQL support)
 	db, err := c.GetDatabaseConnection(ctx, databaseName)
 	if err == nil {
-		defer db.Close()
-		_, err = db.ExecContext(ctx, query)
+		err = execSQL(ctx, db, query)
 		return err
 	}
 
@@ -300,12 +501,37 @@ func (c *Client) DropUser(ctx context.Context, databaseName, userName string) er
 	return nil
 }
 
+// GuestUserName is the name of the guest user, which exists in every database
+// and cannot be dropped. It is disabled by revoking its CONNECT permission.
+const GuestUserName = "guest"
+
+// IsGuestUserEnabled reports whether the guest user can connect to a database.
+func (c *Client) IsGuestUserEnabled(ctx context.Context, databaseName string) (bool, error) {
+	perm, err := c.GetDatabasePermission(ctx, databaseName, GuestUserName, "CONNECT")
+	if err != nil {
+		return false, fmt.Errorf("failed to get guest user state: %w", err)
+	}
+	return perm != nil && perm.StateDesc != PermissionStateDeny, nil
+}
+
+// SetGuestUserEnabled enables or disables the guest user in a database by
+// granting or revoking its CONNECT permission.
+func (c *Client) SetGuestUserEnabled(ctx context.Context, databaseName string, enabled bool) error {
+	if enabled {
+		return c.GrantDatabasePermission(ctx, databaseName, GuestUserName, "CONNECT", false)
+	}
+	return c.RevokeDatabasePermission(ctx, databaseName, GuestUserName, "CONNECT", false)
+}
+
 // CreateAzureADUserOptions contains options for creating an Azure AD user.
 type CreateAzureADUserOptions struct {
 	DatabaseName  string
 	UserName      string
 	ObjectID      string
 	DefaultSchema string
+	// Group creates the user for an Azure AD group. It only matters with an
+	// ObjectID, since FROM EXTERNAL PROVIDER detects groups itself.
+	Group bool
 }
 
 // CreateAzureADUser creates a new Azure AD user.
@@ -316,7 +542,6 @@ func (c *Client) CreateAzureADUser(ctx context.Context, opts CreateAzureADUserOp
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database %s: %w", opts.DatabaseName, err)
 	}
-	defer db.Close()
 
 	defaultSchema := opts.DefaultSchema
 	if defaultSchema == "" {
@@ -325,17 +550,23 @@ func (c *Client) CreateAzureADUser(ctx context.Context, opts CreateAzureADUserOp
 
 	var query string
 	if opts.ObjectID != "" {
-		// For managed identities: use SID-based creation
+		// For managed identities and groups: use SID-based creation, which
+		// doesn't need the server to look the name up in Azure AD
 		// Convert Azure AD Object ID (GUID) to binary SID format
 		sid, err := guidToSID(opts.ObjectID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert object ID to SID: %w", err)
 		}
 
+		principalType := "E"
+		if opts.Group {
+			principalType = "X"
+		}
 		query = fmt.Sprintf(
-			"CREATE USER [%s] WITH SID = %s, TYPE = E, DEFAULT_SCHEMA = [%s]",
+			"CREATE USER [%s] WITH SID = %s, TYPE = %s, DEFAULT_SCHEMA = [%s]",
 			opts.UserName,
 			sid,
+			principalType,
 			defaultSchema,
 		)
 	} else {
@@ -347,7 +578,11 @@ func (c *Client) CreateAzureADUser(ctx context.Context, opts CreateAzureADUserOp
 		)
 	}
 
-	_, err = db.ExecContext(ctx, query)
+	if err := c.waitForDefaultSchema(ctx, opts.DatabaseName, defaultSchema); err != nil {
+		return nil, err
+	}
+
+	err = execSQL(ctx, db, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Azure AD user: %w", err)
 	}
@@ -371,7 +606,6 @@ func (c *Client) CreateAzureADServicePrincipal(ctx context.Context, opts CreateA
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database %s: %w", opts.DatabaseName, err)
 	}
-	defer db.Close()
 
 	defaultSchema := opts.DefaultSchema
 	if defaultSchema == "" {
@@ -391,7 +625,7 @@ func (c *Client) CreateAzureADServicePrincipal(ctx context.Context, opts CreateA
 		defaultSchema,
 	)
 
-	_, err = db.ExecContext(ctx, query)
+	err = execSQL(ctx, db, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Azure AD service principal: %w", err)
 	}
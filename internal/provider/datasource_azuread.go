@@ -68,7 +68,7 @@ func (d *AzureADUserDataSource) Read(ctx context.Context, req datasource.ReadReq
 
 	user, err := d.client.GetUser(ctx, data.DatabaseName.ValueString(), data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to read Azure AD user", err.Error())
+		resp.Diagnostics.AddError("Failed to read Azure AD user", errorDetail(err))
 		return
 	}
 	if user == nil {
@@ -136,7 +136,7 @@ func (d *AzureADServicePrincipalDataSource) Read(ctx context.Context, req dataso
 
 	user, err := d.client.GetUser(ctx, data.DatabaseName.ValueString(), data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to read Azure AD service principal", err.Error())
+		resp.Diagnostics.AddError("Failed to read Azure AD service principal", errorDetail(err))
 		return
 	}
 	if user == nil {
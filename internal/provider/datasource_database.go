@@ -68,7 +68,7 @@ func (d *DatabaseDataSource) Read(ctx context.Context, req datasource.ReadReques
 
 	db, err := d.client.GetDatabase(ctx, data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to read database", err.Error())
+		resp.Diagnostics.AddError("Failed to read database", errorDetail(err))
 		return
 	}
 	if db == nil {
@@ -134,7 +134,7 @@ func (d *DatabasesDataSource) Read(ctx context.Context, req datasource.ReadReque
 
 	dbs, err := d.client.ListDatabases(ctx)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to list databases", err.Error())
+		resp.Diagnostics.AddError("Failed to list databases", errorDetail(err))
 		return
 	}
 
@@ -147,3 +147,72 @@ func (d *DatabasesDataSource) Read(ctx context.Context, req datasource.ReadReque
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
+
+// DatabaseOwner data source
+var _ datasource.DataSource = &DatabaseOwnerDataSource{}
+
+func NewDatabaseOwnerDataSource() datasource.DataSource {
+	return &DatabaseOwnerDataSource{}
+}
+
+type DatabaseOwnerDataSource struct {
+	client *mssql.Client
+}
+
+type DatabaseOwnerDataSourceModel struct {
+	ID           types.String `tfsdk:"id"`
+	DatabaseName types.String `tfsdk:"database_name"`
+	OwnerName    types.String `tfsdk:"owner_name"`
+	OwnerSID     types.String `tfsdk:"owner_sid"`
+}
+
+func (d *DatabaseOwnerDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_database_owner"
+}
+
+func (d *DatabaseOwnerDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Use this data source to get the owner of a database, the login mapped to its dbo user.",
+		Attributes: map[string]schema.Attribute{
+			"id":            schema.StringAttribute{Computed: true},
+			"database_name": schema.StringAttribute{Required: true},
+			"owner_name":    schema.StringAttribute{Computed: true},
+			"owner_sid":     schema.StringAttribute{Computed: true},
+		},
+	}
+}
+
+func (d *DatabaseOwnerDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type", fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData))
+		return
+	}
+	d.client = client
+}
+
+func (d *DatabaseOwnerDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data DatabaseOwnerDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	owner, err := d.client.GetDatabaseOwner(ctx, data.DatabaseName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read database owner", errorDetail(err))
+		return
+	}
+	if owner == nil {
+		resp.Diagnostics.AddError("Database not found", fmt.Sprintf("Database '%s' not found", data.DatabaseName.ValueString()))
+		return
+	}
+
+	data.ID = types.StringValue(strconv.Itoa(owner.DatabaseID))
+	data.OwnerName = types.StringValue(owner.OwnerName)
+	data.OwnerSID = types.StringValue(owner.OwnerSID)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
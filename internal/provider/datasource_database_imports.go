@@ -0,0 +1,196 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/muecahit94/terraform-provider-mssql/internal/mssql"
+)
+
+var _ datasource.DataSource = &DatabaseImportsDataSource{}
+
+func NewDatabaseImportsDataSource() datasource.DataSource {
+	return &DatabaseImportsDataSource{}
+}
+
+// DatabaseImportsDataSource lists the import IDs of the users, roles, role
+// memberships and permissions of a database, e.g. to generate the import
+// blocks when bringing an existing database under Terraform.
+type DatabaseImportsDataSource struct {
+	client *mssql.Client
+}
+
+type DatabaseImportModel struct {
+	ResourceType  types.String `tfsdk:"resource_type"`
+	ImportID      types.String `tfsdk:"import_id"`
+	PrincipalName types.String `tfsdk:"principal_name"`
+}
+
+type DatabaseImportsDataSourceModel struct {
+	ID            types.String          `tfsdk:"id"`
+	DatabaseName  types.String          `tfsdk:"database_name"`
+	PrincipalName types.String          `tfsdk:"principal_name"`
+	Imports       []DatabaseImportModel `tfsdk:"imports"`
+}
+
+// builtinUsers are the users every database has, which are not managed as
+// users: dbo by mssql_database_owner and guest by mssql_guest_user. System
+// users, whose names start with ##, are left out as well.
+var builtinUsers = map[string]bool{
+	"dbo":                true,
+	"guest":              true,
+	"information_schema": true,
+	"sys":                true,
+}
+
+func (d *DatabaseImportsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_database_imports"
+}
+
+func (d *DatabaseImportsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Use this data source to list the users, roles, role memberships and permissions of a database with the resource and import ID to import each of them with.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{Computed: true},
+			"database_name": schema.StringAttribute{
+				Description: "The database to list the imports of.",
+				Required:    true,
+			},
+			"principal_name": schema.StringAttribute{
+				Description: "Only list the user or role with this name, its role memberships and the permissions granted to it.",
+				Optional:    true,
+			},
+			"imports": schema.ListNestedAttribute{
+				Description: "The objects to import: users, then roles, role memberships and permissions.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"resource_type": schema.StringAttribute{
+							Description: "The resource to import the object into, e.g. mssql_sql_user.",
+							Computed:    true,
+						},
+						"import_id": schema.StringAttribute{
+							Description: "The ID to import the object into resource_type with.",
+							Computed:    true,
+						},
+						"principal_name": schema.StringAttribute{
+							Description: "The user or role, the member of a role membership or the grantee of a permission.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *DatabaseImportsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type", fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData))
+		return
+	}
+	d.client = client
+}
+
+func (d *DatabaseImportsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data DatabaseImportsDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	databaseName := data.DatabaseName.ValueString()
+	principalName := data.PrincipalName.ValueString()
+	matches := func(name string) bool {
+		return principalName == "" || d.client.NamesEqual(name, principalName)
+	}
+	data.Imports = []DatabaseImportModel{}
+	add := func(resourceType, principal string, parts ...string) {
+		data.Imports = append(data.Imports, DatabaseImportModel{
+			ResourceType:  types.StringValue(resourceType),
+			ImportID:      types.StringValue(strings.Join(parts, "/")),
+			PrincipalName: types.StringValue(principal),
+		})
+	}
+
+	users, err := d.client.ListUsers(ctx, databaseName)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to list users", errorDetail(err))
+		return
+	}
+	for _, user := range users {
+		if builtinUsers[strings.ToLower(user.Name)] || strings.HasPrefix(user.Name, "##") || !matches(user.Name) {
+			continue
+		}
+		switch user.Type {
+		case "S", "C":
+			add("mssql_sql_user", user.Name, databaseName, user.Name)
+		case "E", "X":
+			add("mssql_azuread_user", user.Name, databaseName, user.Name)
+		}
+	}
+
+	roles, err := d.client.ListDatabaseRoles(ctx, databaseName)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to list database roles", errorDetail(err))
+		return
+	}
+	for _, role := range roles {
+		if !role.IsFixedRole && !d.client.NamesEqual(role.Name, "public") && matches(role.Name) {
+			add("mssql_database_role", role.Name, databaseName, role.Name)
+		}
+	}
+	for _, role := range roles {
+		members, err := d.client.ListDatabaseRoleMembers(ctx, databaseName, role.Name)
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to list database role members", errorDetail(err))
+			return
+		}
+		for _, member := range members {
+			// dbo is always a member of db_owner
+			if d.client.NamesEqual(member, "dbo") || !(matches(role.Name) || matches(member)) {
+				continue
+			}
+			add("mssql_database_role_member", member, databaseName, role.Name, member)
+		}
+	}
+
+	grants, err := d.client.ListAllPermissions(ctx, mssql.ListAllPermissionsOptions{
+		IncludeDatabases: true,
+		DatabaseNames:    []string{databaseName},
+		PrincipalName:    principalName,
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to list permissions", errorDetail(err))
+		return
+	}
+	for _, grant := range grants {
+		// Server permissions are listed by mssql_all_permissions
+		if grant.DatabaseName == "" {
+			continue
+		}
+		permission := allPermissionModel(grant)
+		data.Imports = append(data.Imports, DatabaseImportModel{
+			ResourceType:  permission.ResourceType,
+			ImportID:      permission.ImportID,
+			PrincipalName: permission.PrincipalName,
+		})
+	}
+
+	data.ID = types.StringValue(databaseName)
+	if principalName != "" {
+		data.ID = types.StringValue(databaseName + "/" + principalName)
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -6,6 +6,7 @@ package provider
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
@@ -25,6 +26,7 @@ type DatabasePermissionsDataSource struct {
 
 type PermissionModel struct {
 	Permission      types.String `tfsdk:"permission"`
+	State           types.String `tfsdk:"state"`
 	WithGrantOption types.Bool   `tfsdk:"with_grant_option"`
 }
 
@@ -49,6 +51,7 @@ func (d *DatabasePermissionsDataSource) Schema(ctx context.Context, req datasour
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
 						"permission":        schema.StringAttribute{Computed: true},
+						"state":             schema.StringAttribute{Computed: true},
 						"with_grant_option": schema.BoolAttribute{Computed: true},
 					},
 				},
@@ -78,16 +81,206 @@ func (d *DatabasePermissionsDataSource) Read(ctx context.Context, req datasource
 
 	perms, err := d.client.ListDatabasePermissions(ctx, data.DatabaseName.ValueString(), data.PrincipalName.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to list database permissions", err.Error())
+		resp.Diagnostics.AddError("Failed to list database permissions", errorDetail(err))
 		return
 	}
 
 	for _, perm := range perms {
 		data.Permissions = append(data.Permissions, PermissionModel{
 			Permission:      types.StringValue(perm.PermissionName),
+			State:           types.StringValue(perm.StateDesc),
 			WithGrantOption: types.BoolValue(perm.WithGrantOption),
 		})
 	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
+
+var _ datasource.DataSource = &AllPermissionsDataSource{}
+
+func NewAllPermissionsDataSource() datasource.DataSource {
+	return &AllPermissionsDataSource{}
+}
+
+// AllPermissionsDataSource lists the permissions granted on the server and,
+// optionally, in databases along with the import ID of the resource that
+// manages each of them, e.g. to generate import blocks for a migration.
+type AllPermissionsDataSource struct {
+	client *mssql.Client
+}
+
+type AllPermissionModel struct {
+	ResourceType    types.String `tfsdk:"resource_type"`
+	ImportID        types.String `tfsdk:"import_id"`
+	DatabaseName    types.String `tfsdk:"database_name"`
+	SchemaName      types.String `tfsdk:"schema_name"`
+	PrincipalName   types.String `tfsdk:"principal_name"`
+	Permission      types.String `tfsdk:"permission"`
+	SecurableType   types.String `tfsdk:"securable_type"`
+	SecurableName   types.String `tfsdk:"securable_name"`
+	WithGrantOption types.Bool   `tfsdk:"with_grant_option"`
+}
+
+type AllPermissionsDataSourceModel struct {
+	IncludeDatabases types.Bool           `tfsdk:"include_databases"`
+	DatabaseNames    types.Set            `tfsdk:"database_names"`
+	PrincipalName    types.String         `tfsdk:"principal_name"`
+	Permissions      []AllPermissionModel `tfsdk:"permissions"`
+}
+
+func (d *AllPermissionsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_all_permissions"
+}
+
+func (d *AllPermissionsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Use this data source to list the permissions granted on the server and, optionally, in every database, with the import ID of the permission resource that manages each of them.",
+		Attributes: map[string]schema.Attribute{
+			"include_databases": schema.BoolAttribute{
+				Description: "Whether to list the permissions in databases as well as the server permissions. Defaults to false, since every database has to be read.",
+				Optional:    true,
+			},
+			"database_names": schema.SetAttribute{
+				Description: "The databases to list the permissions of when include_databases is set. Defaults to every online database the provider has access to.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"principal_name": schema.StringAttribute{
+				Description: "Only list the permissions granted to this login, user or role.",
+				Optional:    true,
+			},
+			"permissions": schema.ListNestedAttribute{
+				Description: "The permissions granted. DENY permissions and the permissions of system principals are not listed.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"resource_type": schema.StringAttribute{
+							Description: "The resource that manages the permission: mssql_server_permission, mssql_database_permission or mssql_schema_permission.",
+							Computed:    true,
+						},
+						"import_id": schema.StringAttribute{
+							Description: "The ID to import the permission into resource_type with.",
+							Computed:    true,
+						},
+						"database_name": schema.StringAttribute{
+							Description: "The database of the permission. Null for server permissions.",
+							Computed:    true,
+						},
+						"schema_name": schema.StringAttribute{
+							Description: "The schema of a schema permission.",
+							Computed:    true,
+						},
+						"principal_name": schema.StringAttribute{
+							Description: "The principal the permission is granted to.",
+							Computed:    true,
+						},
+						"permission": schema.StringAttribute{
+							Description: "The permission name.",
+							Computed:    true,
+						},
+						"securable_type": schema.StringAttribute{
+							Description: "The securable_type of a permission on an endpoint, certificate or key.",
+							Computed:    true,
+						},
+						"securable_name": schema.StringAttribute{
+							Description: "The securable_name of a permission on an endpoint, certificate or key.",
+							Computed:    true,
+						},
+						"with_grant_option": schema.BoolAttribute{
+							Description: "Whether the principal can grant the permission to others.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *AllPermissionsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type", fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData))
+		return
+	}
+	d.client = client
+}
+
+func (d *AllPermissionsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data AllPermissionsDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	opts := mssql.ListAllPermissionsOptions{
+		IncludeDatabases: data.IncludeDatabases.ValueBool(),
+		PrincipalName:    data.PrincipalName.ValueString(),
+	}
+	if !data.DatabaseNames.IsNull() {
+		resp.Diagnostics.Append(data.DatabaseNames.ElementsAs(ctx, &opts.DatabaseNames, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	grants, err := d.client.ListAllPermissions(ctx, opts)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to list permissions", errorDetail(err))
+		return
+	}
+
+	data.Permissions = []AllPermissionModel{}
+	for _, grant := range grants {
+		data.Permissions = append(data.Permissions, allPermissionModel(grant))
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// allPermissionModel maps a grant to the permission resource that manages it
+// and builds the import ID that resource expects.
+func allPermissionModel(grant mssql.PermissionGrant) AllPermissionModel {
+	model := AllPermissionModel{
+		DatabaseName:    types.StringNull(),
+		SchemaName:      types.StringNull(),
+		PrincipalName:   types.StringValue(grant.PrincipalName),
+		Permission:      types.StringValue(grant.PermissionName),
+		SecurableType:   types.StringNull(),
+		SecurableName:   types.StringNull(),
+		WithGrantOption: types.BoolValue(grant.WithGrantOption),
+	}
+	var parts []string
+
+	switch grant.SecurableType {
+	case "SERVER":
+		model.ResourceType = types.StringValue("mssql_server_permission")
+		parts = []string{grant.PrincipalName, grant.PermissionName}
+	case mssql.SecurableTypeEndpoint:
+		model.ResourceType = types.StringValue("mssql_server_permission")
+		model.SecurableType = types.StringValue(grant.SecurableType)
+		model.SecurableName = types.StringValue(grant.SecurableName)
+		parts = []string{grant.PrincipalName, grant.PermissionName, grant.SecurableType, grant.SecurableName}
+	case "SCHEMA":
+		model.ResourceType = types.StringValue("mssql_schema_permission")
+		model.DatabaseName = types.StringValue(grant.DatabaseName)
+		model.SchemaName = types.StringValue(grant.SecurableName)
+		parts = []string{grant.DatabaseName, grant.SecurableName, grant.PrincipalName, grant.PermissionName}
+	case "DATABASE":
+		model.ResourceType = types.StringValue("mssql_database_permission")
+		model.DatabaseName = types.StringValue(grant.DatabaseName)
+		parts = []string{grant.DatabaseName, grant.PrincipalName, grant.PermissionName}
+	default:
+		model.ResourceType = types.StringValue("mssql_database_permission")
+		model.DatabaseName = types.StringValue(grant.DatabaseName)
+		model.SecurableType = types.StringValue(grant.SecurableType)
+		model.SecurableName = types.StringValue(grant.SecurableName)
+		parts = []string{grant.DatabaseName, grant.PrincipalName, grant.PermissionName, grant.SecurableType, grant.SecurableName}
+	}
+
+	model.ImportID = types.StringValue(strings.Join(parts, "/"))
+	return model
+}
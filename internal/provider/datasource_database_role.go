@@ -23,11 +23,23 @@ type DatabaseRoleDataSource struct {
 	client *mssql.Client
 }
 
+// DatabaseRoleDataSourceModel adds the optional members and permissions of the
+// role to the attributes it shares with mssql_database_roles.
 type DatabaseRoleDataSourceModel struct {
+	DatabaseRoleItemModel
+	IncludeMembers     types.Bool        `tfsdk:"include_members"`
+	IncludePermissions types.Bool        `tfsdk:"include_permissions"`
+	Members            types.List        `tfsdk:"members"`
+	Permissions        []PermissionModel `tfsdk:"permissions"`
+}
+
+type DatabaseRoleItemModel struct {
 	ID           types.String `tfsdk:"id"`
 	DatabaseName types.String `tfsdk:"database_name"`
 	Name         types.String `tfsdk:"name"`
 	OwnerName    types.String `tfsdk:"owner_name"`
+	CreateDate   types.String `tfsdk:"create_date"`
+	ModifyDate   types.String `tfsdk:"modify_date"`
 }
 
 func (d *DatabaseRoleDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -42,6 +54,32 @@ func (d *DatabaseRoleDataSource) Schema(ctx context.Context, req datasource.Sche
 			"database_name": schema.StringAttribute{Required: true},
 			"name":          schema.StringAttribute{Required: true},
 			"owner_name":    schema.StringAttribute{Computed: true},
+			"create_date":   schema.StringAttribute{Computed: true},
+			"modify_date":   schema.StringAttribute{Computed: true},
+			"include_members": schema.BoolAttribute{
+				Description: "Whether to read the members of the role into members. Defaults to false.",
+				Optional:    true,
+			},
+			"include_permissions": schema.BoolAttribute{
+				Description: "Whether to read the database permissions of the role into permissions. Defaults to false.",
+				Optional:    true,
+			},
+			"members": schema.ListAttribute{
+				Description: "The names of the members of the role. Null unless include_members is true.",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+			"permissions": schema.ListNestedAttribute{
+				Description: "The database permissions granted or denied to the role. Null unless include_permissions is true.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"permission":        schema.StringAttribute{Computed: true},
+						"state":             schema.StringAttribute{Computed: true},
+						"with_grant_option": schema.BoolAttribute{Computed: true},
+					},
+				},
+			},
 		},
 	}
 }
@@ -67,7 +105,7 @@ func (d *DatabaseRoleDataSource) Read(ctx context.Context, req datasource.ReadRe
 
 	role, err := d.client.GetDatabaseRole(ctx, data.DatabaseName.ValueString(), data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to read database role", err.Error())
+		resp.Diagnostics.AddError("Failed to read database role", errorDetail(err))
 		return
 	}
 	if role == nil {
@@ -77,6 +115,41 @@ func (d *DatabaseRoleDataSource) Read(ctx context.Context, req datasource.ReadRe
 
 	data.ID = types.StringValue(fmt.Sprintf("%d/%d", role.DatabaseID, role.PrincipalID))
 	data.OwnerName = types.StringValue(role.OwnerName)
+	data.CreateDate = timestampValue(role.CreateDate)
+	data.ModifyDate = timestampValue(role.ModifyDate)
+
+	// Members and permissions take a query each, so they are only read on request
+	data.Members = types.ListNull(types.StringType)
+	if data.IncludeMembers.ValueBool() {
+		members, err := d.client.ListDatabaseRoleMembers(ctx, data.DatabaseName.ValueString(), role.Name)
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to list database role members", errorDetail(err))
+			return
+		}
+		if members == nil {
+			members = []string{}
+		}
+		list, diags := types.ListValueFrom(ctx, types.StringType, members)
+		resp.Diagnostics.Append(diags...)
+		data.Members = list
+	}
+	data.Permissions = nil
+	if data.IncludePermissions.ValueBool() {
+		perms, err := d.client.ListDatabasePermissions(ctx, data.DatabaseName.ValueString(), role.Name)
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to list database permissions", errorDetail(err))
+			return
+		}
+		data.Permissions = []PermissionModel{}
+		for _, perm := range perms {
+			data.Permissions = append(data.Permissions, PermissionModel{
+				Permission:      types.StringValue(perm.PermissionName),
+				State:           types.StringValue(perm.StateDesc),
+				WithGrantOption: types.BoolValue(perm.WithGrantOption),
+			})
+		}
+	}
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -92,8 +165,8 @@ type DatabaseRolesDataSource struct {
 }
 
 type DatabaseRolesDataSourceModel struct {
-	DatabaseName types.String                  `tfsdk:"database_name"`
-	Roles        []DatabaseRoleDataSourceModel `tfsdk:"roles"`
+	DatabaseName types.String            `tfsdk:"database_name"`
+	Roles        []DatabaseRoleItemModel `tfsdk:"roles"`
 }
 
 func (d *DatabaseRolesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -113,6 +186,8 @@ func (d *DatabaseRolesDataSource) Schema(ctx context.Context, req datasource.Sch
 						"database_name": schema.StringAttribute{Computed: true},
 						"name":          schema.StringAttribute{Computed: true},
 						"owner_name":    schema.StringAttribute{Computed: true},
+						"create_date":   schema.StringAttribute{Computed: true},
+						"modify_date":   schema.StringAttribute{Computed: true},
 					},
 				},
 			},
@@ -141,16 +216,110 @@ func (d *DatabaseRolesDataSource) Read(ctx context.Context, req datasource.ReadR
 
 	roles, err := d.client.ListDatabaseRoles(ctx, data.DatabaseName.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to list database roles", err.Error())
+		resp.Diagnostics.AddError("Failed to list database roles", errorDetail(err))
 		return
 	}
 
 	for _, role := range roles {
-		data.Roles = append(data.Roles, DatabaseRoleDataSourceModel{
+		data.Roles = append(data.Roles, DatabaseRoleItemModel{
 			ID:           types.StringValue(fmt.Sprintf("%d/%d", role.DatabaseID, role.PrincipalID)),
 			DatabaseName: data.DatabaseName,
 			Name:         types.StringValue(role.Name),
 			OwnerName:    types.StringValue(role.OwnerName),
+			CreateDate:   timestampValue(role.CreateDate),
+			ModifyDate:   timestampValue(role.ModifyDate),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// DatabaseRolePermissions data source
+var _ datasource.DataSource = &DatabaseRolePermissionsDataSource{}
+
+func NewDatabaseRolePermissionsDataSource() datasource.DataSource {
+	return &DatabaseRolePermissionsDataSource{}
+}
+
+type DatabaseRolePermissionsDataSource struct {
+	client *mssql.Client
+}
+
+type DatabaseRolePermissionsDataSourceModel struct {
+	ID           types.String      `tfsdk:"id"`
+	DatabaseName types.String      `tfsdk:"database_name"`
+	RoleName     types.String      `tfsdk:"role_name"`
+	Permissions  []PermissionModel `tfsdk:"permissions"`
+}
+
+func (d *DatabaseRolePermissionsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_database_role_permissions"
+}
+
+func (d *DatabaseRolePermissionsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Use this data source to get the database permissions granted to a database role.",
+		Attributes: map[string]schema.Attribute{
+			"id":            schema.StringAttribute{Computed: true},
+			"database_name": schema.StringAttribute{Required: true},
+			"role_name":     schema.StringAttribute{Required: true},
+			"permissions": schema.ListNestedAttribute{
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"permission":        schema.StringAttribute{Computed: true},
+						"state":             schema.StringAttribute{Computed: true},
+						"with_grant_option": schema.BoolAttribute{Computed: true},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *DatabaseRolePermissionsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type", fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData))
+		return
+	}
+	d.client = client
+}
+
+func (d *DatabaseRolePermissionsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data DatabaseRolePermissionsDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Resolve the role first so that a user with the same name is not reported.
+	role, err := d.client.GetDatabaseRole(ctx, data.DatabaseName.ValueString(), data.RoleName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read database role", errorDetail(err))
+		return
+	}
+	if role == nil {
+		resp.Diagnostics.AddError("Database role not found", fmt.Sprintf("Role '%s' not found", data.RoleName.ValueString()))
+		return
+	}
+
+	perms, err := d.client.ListDatabasePermissions(ctx, data.DatabaseName.ValueString(), role.Name)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to list database permissions", errorDetail(err))
+		return
+	}
+
+	data.ID = types.StringValue(fmt.Sprintf("%d/%d", role.DatabaseID, role.PrincipalID))
+	data.Permissions = []PermissionModel{}
+	for _, perm := range perms {
+		data.Permissions = append(data.Permissions, PermissionModel{
+			Permission:      types.StringValue(perm.PermissionName),
+			State:           types.StringValue(perm.StateDesc),
+			WithGrantOption: types.BoolValue(perm.WithGrantOption),
 		})
 	}
 
@@ -0,0 +1,361 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/muecahit94/terraform-provider-mssql/internal/mssql"
+)
+
+var _ datasource.DataSource = &PrincipalMembershipsDataSource{}
+
+func NewPrincipalMembershipsDataSource() datasource.DataSource {
+	return &PrincipalMembershipsDataSource{}
+}
+
+type PrincipalMembershipsDataSource struct {
+	client *mssql.Client
+}
+
+type PrincipalMembershipsDataSourceModel struct {
+	ID            types.String `tfsdk:"id"`
+	PrincipalName types.String `tfsdk:"principal_name"`
+	ServerRoles   types.List   `tfsdk:"server_roles"`
+	DatabaseRoles types.Map    `tfsdk:"database_roles"`
+}
+
+func (d *PrincipalMembershipsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_principal_memberships"
+}
+
+func (d *PrincipalMembershipsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Use this data source to get the server and database roles a login or user belongs to.",
+		Attributes: map[string]schema.Attribute{
+			"id":             schema.StringAttribute{Computed: true},
+			"principal_name": schema.StringAttribute{Required: true},
+			"server_roles": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+			"database_roles": schema.MapAttribute{
+				Computed:    true,
+				ElementType: types.ListType{ElemType: types.StringType},
+			},
+		},
+	}
+}
+
+func (d *PrincipalMembershipsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type", fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData))
+		return
+	}
+	d.client = client
+}
+
+func (d *PrincipalMembershipsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data PrincipalMembershipsDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	memberships, err := d.client.GetPrincipalMemberships(ctx, data.PrincipalName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read principal memberships", errorDetail(err))
+		return
+	}
+
+	serverRoles, diags := types.ListValueFrom(ctx, types.StringType, memberships.ServerRoles)
+	resp.Diagnostics.Append(diags...)
+	databaseRoles, diags := types.MapValueFrom(ctx, types.ListType{ElemType: types.StringType}, memberships.DatabaseRoles)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = data.PrincipalName
+	data.ServerRoles = serverRoles
+	data.DatabaseRoles = databaseRoles
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+var _ datasource.DataSource = &HasPermissionDataSource{}
+
+func NewHasPermissionDataSource() datasource.DataSource {
+	return &HasPermissionDataSource{}
+}
+
+// HasPermissionDataSource checks whether a principal has an effective
+// permission on a securable, e.g. to assert access in a policy check.
+type HasPermissionDataSource struct {
+	client *mssql.Client
+}
+
+type HasPermissionDataSourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	DatabaseName   types.String `tfsdk:"database_name"`
+	PrincipalName  types.String `tfsdk:"principal_name"`
+	Securable      types.String `tfsdk:"securable"`
+	SecurableClass types.String `tfsdk:"securable_class"`
+	Permission     types.String `tfsdk:"permission"`
+	HasPermission  types.Bool   `tfsdk:"has_permission"`
+}
+
+func (d *HasPermissionDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_has_permission"
+}
+
+func (d *HasPermissionDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Use this data source to check whether a principal has an effective permission on a securable, using HAS_PERMS_BY_NAME.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{Computed: true},
+			"database_name": schema.StringAttribute{
+				Description: "The database to check the permission in. Leave unset for server-level securable classes such as SERVER.",
+				Optional:    true,
+			},
+			"principal_name": schema.StringAttribute{
+				Description: "The database user, or the login for a server-level check, whose permission is checked. Defaults to the login used by the provider.",
+				Optional:    true,
+			},
+			"securable": schema.StringAttribute{
+				Description: "The name of the securable, e.g. 'app.orders' for an object. Leave unset for the SERVER class; defaults to database_name for the DATABASE class.",
+				Optional:    true,
+			},
+			"securable_class": schema.StringAttribute{
+				Description: "The securable class, e.g. SERVER, DATABASE, SCHEMA or OBJECT.",
+				Required:    true,
+			},
+			"permission": schema.StringAttribute{
+				Description: "The permission to check, e.g. SELECT. ANY checks for any permission on the securable.",
+				Required:    true,
+			},
+			"has_permission": schema.BoolAttribute{
+				Description: "Whether the principal has the permission, directly or through roles, ownership or covering permissions.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (d *HasPermissionDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type", fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData))
+		return
+	}
+	d.client = client
+}
+
+func (d *HasPermissionDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data HasPermissionDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	opts := mssql.HasPermissionOptions{
+		DatabaseName:   data.DatabaseName.ValueString(),
+		PrincipalName:  data.PrincipalName.ValueString(),
+		Securable:      data.Securable.ValueString(),
+		SecurableClass: data.SecurableClass.ValueString(),
+		Permission:     data.Permission.ValueString(),
+	}
+	has, err := d.client.HasPermission(ctx, opts)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to check permission", errorDetail(err))
+		return
+	}
+
+	data.ID = types.StringValue(strings.Join([]string{opts.DatabaseName, opts.PrincipalName, opts.SecurableClass, opts.Securable, opts.Permission}, "/"))
+	data.HasPermission = types.BoolValue(has)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+var _ datasource.DataSource = &DatabaseAccessReportDataSource{}
+
+func NewDatabaseAccessReportDataSource() datasource.DataSource {
+	return &DatabaseAccessReportDataSource{}
+}
+
+// DatabaseAccessReportDataSource reports the access a user or role holds in a
+// database, directly and through nested roles, e.g. for access reviews.
+type DatabaseAccessReportDataSource struct {
+	client *mssql.Client
+}
+
+type DatabaseAccessReportDataSourceModel struct {
+	ID            types.String                  `tfsdk:"id"`
+	DatabaseName  types.String                  `tfsdk:"database_name"`
+	PrincipalName types.String                  `tfsdk:"principal_name"`
+	Roles         []AccessReportRoleModel       `tfsdk:"roles"`
+	Permissions   []AccessReportPermissionModel `tfsdk:"permissions"`
+}
+
+type AccessReportRoleModel struct {
+	Name        types.String `tfsdk:"name"`
+	IsFixedRole types.Bool   `tfsdk:"is_fixed_role"`
+	Path        types.List   `tfsdk:"path"`
+}
+
+type AccessReportPermissionModel struct {
+	Class           types.String `tfsdk:"class"`
+	SecurableName   types.String `tfsdk:"securable_name"`
+	ColumnName      types.String `tfsdk:"column_name"`
+	Permission      types.String `tfsdk:"permission"`
+	State           types.String `tfsdk:"state"`
+	WithGrantOption types.Bool   `tfsdk:"with_grant_option"`
+	RoleName        types.String `tfsdk:"role_name"`
+	Implied         types.Bool   `tfsdk:"implied"`
+}
+
+func (d *DatabaseAccessReportDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_database_access_report"
+}
+
+func (d *DatabaseAccessReportDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Use this data source to get the access a user or role holds in a database: its permissions and those inherited through each of its roles, following nested roles.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{Computed: true},
+			"database_name": schema.StringAttribute{
+				Description: "The name of the database.",
+				Required:    true,
+			},
+			"principal_name": schema.StringAttribute{
+				Description: "The name of the user or role to report on.",
+				Required:    true,
+			},
+			"roles": schema.ListNestedAttribute{
+				Description: "The roles the principal belongs to, directly or through other roles. Users are also reported as members of public.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name":          schema.StringAttribute{Computed: true},
+						"is_fixed_role": schema.BoolAttribute{Computed: true},
+						"path": schema.ListAttribute{
+							Description: "The chain of roles from the principal to this role, ending with the role itself.",
+							Computed:    true,
+							ElementType: types.StringType,
+						},
+					},
+				},
+			},
+			"permissions": schema.ListNestedAttribute{
+				Description: "The permissions granted or denied to the principal and to each of its roles.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"class": schema.StringAttribute{
+							Description: "The securable class as used by mssql_permission, e.g. DATABASE, SCHEMA or OBJECT.",
+							Computed:    true,
+						},
+						"securable_name": schema.StringAttribute{
+							Description: "The name of the securable, schema-qualified for objects. Null for the database.",
+							Computed:    true,
+						},
+						"column_name": schema.StringAttribute{
+							Description: "The column of the object, for column permissions. Null otherwise.",
+							Computed:    true,
+						},
+						"permission":        schema.StringAttribute{Computed: true},
+						"state":             schema.StringAttribute{Description: "GRANT or DENY.", Computed: true},
+						"with_grant_option": schema.BoolAttribute{Computed: true},
+						"role_name": schema.StringAttribute{
+							Description: "The role the permission is inherited through. Null for permissions of the principal itself.",
+							Computed:    true,
+						},
+						"implied": schema.BoolAttribute{
+							Description: "Whether the permission is implied by a fixed role, such as SELECT by db_datareader, rather than granted explicitly.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *DatabaseAccessReportDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type", fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData))
+		return
+	}
+	d.client = client
+}
+
+func (d *DatabaseAccessReportDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data DatabaseAccessReportDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	report, err := d.client.GetDatabaseAccessReport(ctx, data.DatabaseName.ValueString(), data.PrincipalName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read access report", errorDetail(err))
+		return
+	}
+	if report == nil {
+		resp.Diagnostics.AddError("Principal not found", fmt.Sprintf("User or role '%s' not found in database '%s'", data.PrincipalName.ValueString(), data.DatabaseName.ValueString()))
+		return
+	}
+
+	data.ID = types.StringValue(data.DatabaseName.ValueString() + "/" + report.PrincipalName)
+	data.Roles = []AccessReportRoleModel{}
+	for _, role := range report.Roles {
+		path, diags := types.ListValueFrom(ctx, types.StringType, role.Path)
+		resp.Diagnostics.Append(diags...)
+		data.Roles = append(data.Roles, AccessReportRoleModel{
+			Name:        types.StringValue(role.Name),
+			IsFixedRole: types.BoolValue(role.IsFixedRole),
+			Path:        path,
+		})
+	}
+	data.Permissions = []AccessReportPermissionModel{}
+	for _, perm := range report.Permissions {
+		data.Permissions = append(data.Permissions, AccessReportPermissionModel{
+			Class:           types.StringValue(perm.Class),
+			SecurableName:   optionalStringValue(perm.SecurableName),
+			ColumnName:      optionalStringValue(perm.ColumnName),
+			Permission:      types.StringValue(perm.PermissionName),
+			State:           types.StringValue(perm.State),
+			WithGrantOption: types.BoolValue(perm.WithGrantOption),
+			RoleName:        optionalStringValue(perm.RoleName),
+			Implied:         types.BoolValue(perm.Implied),
+		})
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// optionalStringValue maps an empty string to null.
+func optionalStringValue(value string) types.String {
+	if value == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(value)
+}
@@ -10,11 +10,13 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/muecahit94/terraform-provider-mssql/internal/mssql"
 )
 
 var _ datasource.DataSource = &QueryDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &QueryDataSource{}
 
 func NewQueryDataSource() datasource.DataSource {
 	return &QueryDataSource{}
@@ -25,12 +27,15 @@ type QueryDataSource struct {
 }
 
 type QueryRowModel struct {
-	Values types.Map `tfsdk:"values"`
+	DatabaseName types.String `tfsdk:"database_name"`
+	Values       types.Map    `tfsdk:"values"`
 }
 
 type QueryDataSourceModel struct {
 	DatabaseName types.String `tfsdk:"database_name"`
+	Databases    types.List   `tfsdk:"databases"`
 	Query        types.String `tfsdk:"query"`
+	ReadOnly     types.Bool   `tfsdk:"read_only"`
 	Result       types.List   `tfsdk:"result"`
 }
 
@@ -46,15 +51,28 @@ func (d *QueryDataSource) Schema(ctx context.Context, req datasource.SchemaReque
 				Description: "The database to execute the query in. Empty for server-level queries.",
 				Optional:    true,
 			},
+			"databases": schema.ListAttribute{
+				Description: "Execute the query in each of these databases in turn, e.g. for reporting across databases. Each row is tagged with the database it comes from. A database the query fails in is skipped with a warning. Conflicts with database_name.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
 			"query": schema.StringAttribute{
 				Description: "The SQL query to execute. Must be a SELECT statement.",
 				Required:    true,
 			},
+			"read_only": schema.BoolAttribute{
+				Description: "Run the query on a connection with ApplicationIntent=ReadOnly, so that it is routed to a readable secondary replica where read-only routing or read scale-out is configured.",
+				Optional:    true,
+			},
 			"result": schema.ListNestedAttribute{
 				Description: "The query results.",
 				Computed:    true,
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
+						"database_name": schema.StringAttribute{
+							Description: "The database the row comes from. Null for server-level queries.",
+							Computed:    true,
+						},
 						"values": schema.MapAttribute{
 							Description: "The column values for this row.",
 							Computed:    true,
@@ -79,32 +97,75 @@ func (d *QueryDataSource) Configure(ctx context.Context, req datasource.Configur
 	d.client = client
 }
 
-func (d *QueryDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+func (d *QueryDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
 	var data QueryDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	result, err := d.client.ExecuteQuery(ctx, data.DatabaseName.ValueString(), data.Query.ValueString())
-	if err != nil {
-		resp.Diagnostics.AddError("Failed to execute query", err.Error())
+	if !data.DatabaseName.IsNull() && !data.Databases.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("databases"), "Conflicting databases",
+			"database_name and databases cannot both be set")
+	}
+}
+
+func (d *QueryDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data QueryDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	var rows []QueryRowModel
-	for _, row := range result.Rows {
-		mapValue, diags := types.MapValueFrom(ctx, types.StringType, row)
-		resp.Diagnostics.Append(diags...)
+	// A single query runs where database_name points to and fails as a whole,
+	// a query across databases skips the databases it fails in
+	databases := []string{data.DatabaseName.ValueString()}
+	acrossDatabases := !data.Databases.IsNull()
+	if acrossDatabases {
+		databases = nil
+		resp.Diagnostics.Append(data.Databases.ElementsAs(ctx, &databases, false)...)
 		if resp.Diagnostics.HasError() {
 			return
 		}
-		rows = append(rows, QueryRowModel{Values: mapValue})
+	}
+
+	var rows []QueryRowModel
+	failed := 0
+	for _, databaseName := range databases {
+		result, err := d.execute(ctx, databaseName, data.Query.ValueString(), data.ReadOnly.ValueBool())
+		if err != nil && !acrossDatabases {
+			resp.Diagnostics.AddError("Failed to execute query", errorDetail(err))
+			return
+		}
+		if err != nil {
+			resp.Diagnostics.AddWarning("Database skipped",
+				fmt.Sprintf("The query failed in database '%s', which is left out of the result: %s", databaseName, errorDetail(err)))
+			failed++
+			continue
+		}
+
+		source := data.DatabaseName
+		if acrossDatabases {
+			source = types.StringValue(databaseName)
+		}
+		for _, row := range result.Rows {
+			mapValue, diags := types.MapValueFrom(ctx, types.StringType, row)
+			resp.Diagnostics.Append(diags...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+			rows = append(rows, QueryRowModel{DatabaseName: source, Values: mapValue})
+		}
+	}
+	if failed > 0 && failed == len(databases) {
+		resp.Diagnostics.AddError("Failed to execute query", "The query failed in every database listed in databases.")
+		return
 	}
 
 	resultList, diags := types.ListValueFrom(ctx, types.ObjectType{
 		AttrTypes: map[string]attr.Type{
-			"values": types.MapType{ElemType: types.StringType},
+			"database_name": types.StringType,
+			"values":        types.MapType{ElemType: types.StringType},
 		},
 	}, rows)
 	resp.Diagnostics.Append(diags...)
@@ -115,3 +176,11 @@ func (d *QueryDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 	data.Result = resultList
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
+
+// execute runs the query in a database, on a read-only connection if requested.
+func (d *QueryDataSource) execute(ctx context.Context, databaseName, query string, readOnly bool) (*mssql.QueryResult, error) {
+	if readOnly {
+		return d.client.ExecuteReadOnlyQuery(ctx, databaseName, query)
+	}
+	return d.client.ExecuteQuery(ctx, databaseName, query)
+}
@@ -67,7 +67,7 @@ func (d *SchemaDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 
 	schema, err := d.client.GetSchema(ctx, data.DatabaseName.ValueString(), data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to read schema", err.Error())
+		resp.Diagnostics.AddError("Failed to read schema", errorDetail(err))
 		return
 	}
 	if schema == nil {
@@ -93,6 +93,7 @@ type SchemasDataSource struct {
 
 type SchemasDataSourceModel struct {
 	DatabaseName types.String            `tfsdk:"database_name"`
+	OwnerName    types.String            `tfsdk:"owner_name"`
 	Schemas      []SchemaDataSourceModel `tfsdk:"schemas"`
 }
 
@@ -105,6 +106,10 @@ func (d *SchemasDataSource) Schema(ctx context.Context, req datasource.SchemaReq
 		Description: "Use this data source to get information about all schemas in a database.",
 		Attributes: map[string]schema.Attribute{
 			"database_name": schema.StringAttribute{Required: true},
+			"owner_name": schema.StringAttribute{
+				Description: "Only list the schemas owned by this principal.",
+				Optional:    true,
+			},
 			"schemas": schema.ListNestedAttribute{
 				Computed: true,
 				NestedObject: schema.NestedAttributeObject{
@@ -139,9 +144,9 @@ func (d *SchemasDataSource) Read(ctx context.Context, req datasource.ReadRequest
 		return
 	}
 
-	schemas, err := d.client.ListSchemas(ctx, data.DatabaseName.ValueString())
+	schemas, err := d.client.ListSchemas(ctx, data.DatabaseName.ValueString(), data.OwnerName.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to list schemas", err.Error())
+		resp.Diagnostics.AddError("Failed to list schemas", errorDetail(err))
 		return
 	}
 
@@ -157,6 +162,110 @@ func (d *SchemasDataSource) Read(ctx context.Context, req datasource.ReadRequest
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
+// SchemaObjects data source
+var _ datasource.DataSource = &SchemaObjectsDataSource{}
+
+func NewSchemaObjectsDataSource() datasource.DataSource {
+	return &SchemaObjectsDataSource{}
+}
+
+// SchemaObjectsDataSource lists the objects in a schema, e.g. to grant
+// permissions on each table with for_each.
+type SchemaObjectsDataSource struct {
+	client *mssql.Client
+}
+
+type SchemaObjectsDataSourceModel struct {
+	DatabaseName types.String        `tfsdk:"database_name"`
+	SchemaName   types.String        `tfsdk:"schema_name"`
+	Type         types.String        `tfsdk:"type"`
+	Objects      []SchemaObjectModel `tfsdk:"objects"`
+}
+
+type SchemaObjectModel struct {
+	ObjectID types.Int64  `tfsdk:"object_id"`
+	Name     types.String `tfsdk:"name"`
+	Type     types.String `tfsdk:"type"`
+	TypeDesc types.String `tfsdk:"type_desc"`
+}
+
+func (d *SchemaObjectsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_schema_objects"
+}
+
+func (d *SchemaObjectsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Use this data source to list the objects in a schema, such as tables, views, procedures and functions.",
+		Attributes: map[string]schema.Attribute{
+			"database_name": schema.StringAttribute{Required: true},
+			"schema_name":   schema.StringAttribute{Required: true},
+			"type": schema.StringAttribute{
+				Description: "Only list objects of this type, as in the type column of sys.objects, e.g. U (table), V (view), P (procedure) or FN (scalar function).",
+				Optional:    true,
+			},
+			"objects": schema.ListNestedAttribute{
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"object_id": schema.Int64Attribute{Computed: true},
+						"name":      schema.StringAttribute{Computed: true},
+						"type":      schema.StringAttribute{Computed: true},
+						"type_desc": schema.StringAttribute{Computed: true},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *SchemaObjectsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type", fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData))
+		return
+	}
+	d.client = client
+}
+
+func (d *SchemaObjectsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data SchemaObjectsDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// An empty list would hide a misspelled schema name
+	exists, err := d.client.SchemaExists(ctx, data.DatabaseName.ValueString(), data.SchemaName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to list schema objects", errorDetail(err))
+		return
+	}
+	if !exists {
+		resp.Diagnostics.AddError("Schema not found", fmt.Sprintf("Schema '%s' not found", data.SchemaName.ValueString()))
+		return
+	}
+
+	objects, err := d.client.ListSchemaObjects(ctx, data.DatabaseName.ValueString(), data.SchemaName.ValueString(), data.Type.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to list schema objects", errorDetail(err))
+		return
+	}
+
+	for _, object := range objects {
+		data.Objects = append(data.Objects, SchemaObjectModel{
+			ObjectID: types.Int64Value(int64(object.ObjectID)),
+			Name:     types.StringValue(object.Name),
+			Type:     types.StringValue(object.Type),
+			TypeDesc: types.StringValue(object.TypeDesc),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
 // SchemaPermissions data source
 var _ datasource.DataSource = &SchemaPermissionsDataSource{}
 
@@ -169,10 +278,19 @@ type SchemaPermissionsDataSource struct {
 }
 
 type SchemaPermissionsDataSourceModel struct {
-	DatabaseName  types.String      `tfsdk:"database_name"`
-	SchemaName    types.String      `tfsdk:"schema_name"`
-	PrincipalName types.String      `tfsdk:"principal_name"`
-	Permissions   []PermissionModel `tfsdk:"permissions"`
+	DatabaseName  types.String            `tfsdk:"database_name"`
+	SchemaName    types.String            `tfsdk:"schema_name"`
+	PrincipalName types.String            `tfsdk:"principal_name"`
+	Effective     types.Bool              `tfsdk:"effective"`
+	Permissions   []SchemaPermissionModel `tfsdk:"permissions"`
+}
+
+// SchemaPermissionModel is a schema permission together with the principal
+// it is granted to, which differs from the requested principal for
+// permissions inherited from a role.
+type SchemaPermissionModel struct {
+	PermissionModel
+	GranteeName types.String `tfsdk:"grantee_name"`
 }
 
 func (d *SchemaPermissionsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -186,12 +304,18 @@ func (d *SchemaPermissionsDataSource) Schema(ctx context.Context, req datasource
 			"database_name":  schema.StringAttribute{Required: true},
 			"schema_name":    schema.StringAttribute{Required: true},
 			"principal_name": schema.StringAttribute{Required: true},
+			"effective": schema.BoolAttribute{
+				Description: "Whether to include the permissions the principal inherits from its roles, including nested roles and public.",
+				Optional:    true,
+			},
 			"permissions": schema.ListNestedAttribute{
 				Computed: true,
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
 						"permission":        schema.StringAttribute{Computed: true},
+						"state":             schema.StringAttribute{Computed: true},
 						"with_grant_option": schema.BoolAttribute{Computed: true},
+						"grantee_name":      schema.StringAttribute{Computed: true},
 					},
 				},
 			},
@@ -218,16 +342,24 @@ func (d *SchemaPermissionsDataSource) Read(ctx context.Context, req datasource.R
 		return
 	}
 
-	perms, err := d.client.ListSchemaPermissions(ctx, data.DatabaseName.ValueString(), data.SchemaName.ValueString(), data.PrincipalName.ValueString())
+	list := d.client.ListSchemaPermissions
+	if data.Effective.ValueBool() {
+		list = d.client.ListEffectiveSchemaPermissions
+	}
+	perms, err := list(ctx, data.DatabaseName.ValueString(), data.SchemaName.ValueString(), data.PrincipalName.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to list schema permissions", err.Error())
+		resp.Diagnostics.AddError("Failed to list schema permissions", errorDetail(err))
 		return
 	}
 
 	for _, perm := range perms {
-		data.Permissions = append(data.Permissions, PermissionModel{
-			Permission:      types.StringValue(perm.PermissionName),
-			WithGrantOption: types.BoolValue(perm.WithGrantOption),
+		data.Permissions = append(data.Permissions, SchemaPermissionModel{
+			PermissionModel: PermissionModel{
+				Permission:      types.StringValue(perm.PermissionName),
+				State:           types.StringValue(perm.StateDesc),
+				WithGrantOption: types.BoolValue(perm.WithGrantOption),
+			},
+			GranteeName: types.StringValue(perm.PrincipalName),
 		})
 	}
 
@@ -11,6 +11,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/muecahit94/terraform-provider-mssql/internal/mssql"
 )
 
@@ -25,9 +26,12 @@ type ServerRoleDataSource struct {
 }
 
 type ServerRoleDataSourceModel struct {
-	ID        types.String `tfsdk:"id"`
-	Name      types.String `tfsdk:"name"`
-	OwnerName types.String `tfsdk:"owner_name"`
+	ID         types.String `tfsdk:"id"`
+	Name       types.String `tfsdk:"name"`
+	SID        types.String `tfsdk:"sid"`
+	OwnerName  types.String `tfsdk:"owner_name"`
+	CreateDate types.String `tfsdk:"create_date"`
+	ModifyDate types.String `tfsdk:"modify_date"`
 }
 
 func (d *ServerRoleDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -38,9 +42,12 @@ func (d *ServerRoleDataSource) Schema(ctx context.Context, req datasource.Schema
 	resp.Schema = schema.Schema{
 		Description: "Use this data source to get information about a server role.",
 		Attributes: map[string]schema.Attribute{
-			"id":         schema.StringAttribute{Computed: true},
-			"name":       schema.StringAttribute{Required: true},
-			"owner_name": schema.StringAttribute{Computed: true},
+			"id":          schema.StringAttribute{Computed: true},
+			"name":        schema.StringAttribute{Required: true},
+			"sid":         schema.StringAttribute{Computed: true},
+			"owner_name":  schema.StringAttribute{Computed: true},
+			"create_date": schema.StringAttribute{Computed: true},
+			"modify_date": schema.StringAttribute{Computed: true},
 		},
 	}
 }
@@ -66,7 +73,7 @@ func (d *ServerRoleDataSource) Read(ctx context.Context, req datasource.ReadRequ
 
 	role, err := d.client.GetServerRole(ctx, data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to read server role", err.Error())
+		resp.Diagnostics.AddError("Failed to read server role", errorDetail(err))
 		return
 	}
 	if role == nil {
@@ -75,7 +82,10 @@ func (d *ServerRoleDataSource) Read(ctx context.Context, req datasource.ReadRequ
 	}
 
 	data.ID = types.StringValue(strconv.Itoa(role.PrincipalID))
+	data.SID = types.StringValue(role.SID)
 	data.OwnerName = types.StringValue(role.OwnerName)
+	data.CreateDate = timestampValue(role.CreateDate)
+	data.ModifyDate = timestampValue(role.ModifyDate)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -106,9 +116,12 @@ func (d *ServerRolesDataSource) Schema(ctx context.Context, req datasource.Schem
 				Computed: true,
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
-						"id":         schema.StringAttribute{Computed: true},
-						"name":       schema.StringAttribute{Computed: true},
-						"owner_name": schema.StringAttribute{Computed: true},
+						"id":          schema.StringAttribute{Computed: true},
+						"name":        schema.StringAttribute{Computed: true},
+						"sid":         schema.StringAttribute{Computed: true},
+						"owner_name":  schema.StringAttribute{Computed: true},
+						"create_date": schema.StringAttribute{Computed: true},
+						"modify_date": schema.StringAttribute{Computed: true},
 					},
 				},
 			},
@@ -133,15 +146,18 @@ func (d *ServerRolesDataSource) Read(ctx context.Context, req datasource.ReadReq
 
 	roles, err := d.client.ListServerRoles(ctx)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to list server roles", err.Error())
+		resp.Diagnostics.AddError("Failed to list server roles", errorDetail(err))
 		return
 	}
 
 	for _, role := range roles {
 		data.Roles = append(data.Roles, ServerRoleDataSourceModel{
-			ID:        types.StringValue(strconv.Itoa(role.PrincipalID)),
-			Name:      types.StringValue(role.Name),
-			OwnerName: types.StringValue(role.OwnerName),
+			ID:         types.StringValue(strconv.Itoa(role.PrincipalID)),
+			Name:       types.StringValue(role.Name),
+			SID:        types.StringValue(role.SID),
+			OwnerName:  types.StringValue(role.OwnerName),
+			CreateDate: timestampValue(role.CreateDate),
+			ModifyDate: timestampValue(role.ModifyDate),
 		})
 	}
 
@@ -178,6 +194,7 @@ func (d *ServerPermissionsDataSource) Schema(ctx context.Context, req datasource
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
 						"permission":        schema.StringAttribute{Computed: true},
+						"state":             schema.StringAttribute{Computed: true},
 						"with_grant_option": schema.BoolAttribute{Computed: true},
 					},
 				},
@@ -207,16 +224,315 @@ func (d *ServerPermissionsDataSource) Read(ctx context.Context, req datasource.R
 
 	perms, err := d.client.ListServerPermissions(ctx, data.PrincipalName.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to list server permissions", err.Error())
+		resp.Diagnostics.AddError("Failed to list server permissions", errorDetail(err))
 		return
 	}
 
 	for _, perm := range perms {
 		data.Permissions = append(data.Permissions, PermissionModel{
 			Permission:      types.StringValue(perm.PermissionName),
+			State:           types.StringValue(perm.StateDesc),
 			WithGrantOption: types.BoolValue(perm.WithGrantOption),
 		})
 	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
+
+// Server data source
+var _ datasource.DataSource = &ServerDataSource{}
+
+func NewServerDataSource() datasource.DataSource {
+	return &ServerDataSource{}
+}
+
+type ServerDataSource struct {
+	client *mssql.Client
+}
+
+type ServerDataSourceModel struct {
+	ID            types.String `tfsdk:"id"`
+	Version       types.String `tfsdk:"version"`
+	EngineEdition types.Int64  `tfsdk:"engine_edition"`
+	ProductLevel  types.String `tfsdk:"product_level"`
+	Collation     types.String `tfsdk:"collation"`
+	MachineName   types.String `tfsdk:"machine_name"`
+	IsAzure       types.Bool   `tfsdk:"is_azure"`
+}
+
+func (d *ServerDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_server"
+}
+
+func (d *ServerDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Use this data source to get information about the connected SQL Server instance.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The server hostname and port in format `hostname:port`.",
+				Computed:    true,
+			},
+			"version": schema.StringAttribute{
+				Description: "The product version, e.g. 16.0.4135.4.",
+				Computed:    true,
+			},
+			"engine_edition": schema.Int64Attribute{
+				Description: "The database engine edition (SERVERPROPERTY('EngineEdition')), e.g. 3 for Enterprise or 5 for Azure SQL Database.",
+				Computed:    true,
+			},
+			"product_level": schema.StringAttribute{
+				Description: "The product level, e.g. RTM.",
+				Computed:    true,
+			},
+			"collation": schema.StringAttribute{
+				Description: "The default collation of the server.",
+				Computed:    true,
+			},
+			"machine_name": schema.StringAttribute{
+				Description: "The name of the machine the server is running on.",
+				Computed:    true,
+			},
+			"is_azure": schema.BoolAttribute{
+				Description: "Whether the server is Azure SQL Database, Azure Synapse or Azure SQL Managed Instance.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (d *ServerDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type", fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData))
+		return
+	}
+	d.client = client
+}
+
+func (d *ServerDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ServerDataSourceModel
+
+	props, err := d.client.GetServerProperties(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read server properties", errorDetail(err))
+		return
+	}
+
+	data.ID = types.StringValue(fmt.Sprintf("%s:%d", d.client.Hostname(), d.client.Port()))
+	data.Version = types.StringValue(props.Version)
+	data.EngineEdition = types.Int64Value(int64(props.EngineEdition))
+	data.ProductLevel = types.StringValue(props.ProductLevel)
+	data.Collation = types.StringValue(props.Collation)
+	data.MachineName = types.StringValue(props.MachineName)
+	data.IsAzure = types.BoolValue(props.IsAzure)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Provider stats data source
+var _ datasource.DataSource = &ProviderStatsDataSource{}
+
+func NewProviderStatsDataSource() datasource.DataSource {
+	return &ProviderStatsDataSource{}
+}
+
+// ProviderStatsDataSource exposes the statistics of the connection pools of
+// the provider for troubleshooting, e.g. pool exhaustion during large applies.
+// The values are a snapshot taken when the data source is read.
+type ProviderStatsDataSource struct {
+	client *mssql.Client
+}
+
+type ProviderStatsDataSourceModel struct {
+	ID    types.String     `tfsdk:"id"`
+	Pools []PoolStatsModel `tfsdk:"pools"`
+}
+
+type PoolStatsModel struct {
+	DatabaseName       types.String `tfsdk:"database_name"`
+	ReadOnly           types.Bool   `tfsdk:"read_only"`
+	MaxOpenConnections types.Int64  `tfsdk:"max_open_connections"`
+	OpenConnections    types.Int64  `tfsdk:"open_connections"`
+	InUse              types.Int64  `tfsdk:"in_use"`
+	Idle               types.Int64  `tfsdk:"idle"`
+	WaitCount          types.Int64  `tfsdk:"wait_count"`
+	WaitDurationMs     types.Int64  `tfsdk:"wait_duration_ms"`
+}
+
+func (d *ProviderStatsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_provider_stats"
+}
+
+func (d *ProviderStatsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Use this data source to get the statistics of the connection pools of the provider, e.g. to diagnose pool exhaustion.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{Computed: true},
+			"pools": schema.ListNestedAttribute{
+				Description: "The server-level pool, followed by one pool per database and application intent.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"database_name": schema.StringAttribute{
+							Description: "The database of the pool, empty for the server-level pool.",
+							Computed:    true,
+						},
+						"read_only": schema.BoolAttribute{
+							Description: "Whether the pool connects with read-only application intent.",
+							Computed:    true,
+						},
+						"max_open_connections": schema.Int64Attribute{
+							Description: "The maximum number of open connections, 0 for unlimited.",
+							Computed:    true,
+						},
+						"open_connections": schema.Int64Attribute{
+							Description: "The number of open connections, in use or idle.",
+							Computed:    true,
+						},
+						"in_use": schema.Int64Attribute{
+							Description: "The number of connections in use.",
+							Computed:    true,
+						},
+						"idle": schema.Int64Attribute{
+							Description: "The number of idle connections.",
+							Computed:    true,
+						},
+						"wait_count": schema.Int64Attribute{
+							Description: "The total number of times an operation waited for a connection.",
+							Computed:    true,
+						},
+						"wait_duration_ms": schema.Int64Attribute{
+							Description: "The total time operations waited for a connection, in milliseconds.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *ProviderStatsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type", fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData))
+		return
+	}
+	d.client = client
+}
+
+func (d *ProviderStatsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ProviderStatsDataSourceModel
+
+	data.ID = types.StringValue(fmt.Sprintf("%s:%d", d.client.Hostname(), d.client.Port()))
+	data.Pools = []PoolStatsModel{}
+	for _, pool := range d.client.PoolStats() {
+		tflog.Debug(ctx, "Connection pool stats", map[string]interface{}{
+			"database":         pool.DatabaseName,
+			"read_only":        pool.ReadOnly,
+			"open_connections": pool.OpenConnections,
+			"in_use":           pool.InUse,
+			"idle":             pool.Idle,
+			"wait_count":       pool.WaitCount,
+			"wait_duration":    pool.WaitDuration.String(),
+		})
+		data.Pools = append(data.Pools, PoolStatsModel{
+			DatabaseName:       types.StringValue(pool.DatabaseName),
+			ReadOnly:           types.BoolValue(pool.ReadOnly),
+			MaxOpenConnections: types.Int64Value(int64(pool.MaxOpenConnections)),
+			OpenConnections:    types.Int64Value(int64(pool.OpenConnections)),
+			InUse:              types.Int64Value(int64(pool.InUse)),
+			Idle:               types.Int64Value(int64(pool.Idle)),
+			WaitCount:          types.Int64Value(pool.WaitCount),
+			WaitDurationMs:     types.Int64Value(pool.WaitDuration.Milliseconds()),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Endpoints data source
+var _ datasource.DataSource = &EndpointsDataSource{}
+
+func NewEndpointsDataSource() datasource.DataSource {
+	return &EndpointsDataSource{}
+}
+
+type EndpointsDataSource struct {
+	client *mssql.Client
+}
+
+type EndpointsDataSourceModel struct {
+	Endpoints []EndpointModel `tfsdk:"endpoints"`
+}
+
+type EndpointModel struct {
+	ID           types.String `tfsdk:"id"`
+	Name         types.String `tfsdk:"name"`
+	ProtocolDesc types.String `tfsdk:"protocol_desc"`
+	TypeDesc     types.String `tfsdk:"type_desc"`
+	StateDesc    types.String `tfsdk:"state_desc"`
+}
+
+func (d *EndpointsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_endpoints"
+}
+
+func (d *EndpointsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Use this data source to list the endpoints of the server, e.g. to find the database mirroring endpoint of an availability group.",
+		Attributes: map[string]schema.Attribute{
+			"endpoints": schema.ListNestedAttribute{
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id":            schema.StringAttribute{Computed: true},
+						"name":          schema.StringAttribute{Computed: true},
+						"protocol_desc": schema.StringAttribute{Computed: true},
+						"type_desc":     schema.StringAttribute{Computed: true},
+						"state_desc":    schema.StringAttribute{Computed: true},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *EndpointsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type", fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData))
+		return
+	}
+	d.client = client
+}
+
+func (d *EndpointsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data EndpointsDataSourceModel
+
+	endpoints, err := d.client.ListEndpoints(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to list endpoints", errorDetail(err))
+		return
+	}
+
+	for _, endpoint := range endpoints {
+		data.Endpoints = append(data.Endpoints, EndpointModel{
+			ID:           types.StringValue(strconv.Itoa(endpoint.EndpointID)),
+			Name:         types.StringValue(endpoint.Name),
+			ProtocolDesc: types.StringValue(endpoint.ProtocolDesc),
+			TypeDesc:     types.StringValue(endpoint.TypeDesc),
+			StateDesc:    types.StringValue(endpoint.StateDesc),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -24,14 +24,28 @@ type SQLLoginDataSource struct {
 	client *mssql.Client
 }
 
+// SQLLoginDataSourceModel adds the password policy state of the login to the
+// attributes it shares with mssql_sql_logins.
 type SQLLoginDataSourceModel struct {
+	SQLLoginItemModel
+	IsLocked            types.Bool   `tfsdk:"is_locked"`
+	IsExpired           types.Bool   `tfsdk:"is_expired"`
+	IsMustChange        types.Bool   `tfsdk:"is_must_change"`
+	BadPasswordCount    types.Int64  `tfsdk:"bad_password_count"`
+	PasswordLastSetTime types.String `tfsdk:"password_last_set_time"`
+}
+
+type SQLLoginItemModel struct {
 	ID                     types.String `tfsdk:"id"`
 	Name                   types.String `tfsdk:"name"`
+	SID                    types.String `tfsdk:"sid"`
 	DefaultDatabase        types.String `tfsdk:"default_database"`
 	DefaultLanguage        types.String `tfsdk:"default_language"`
 	CheckExpirationEnabled types.Bool   `tfsdk:"check_expiration_enabled"`
 	CheckPolicyEnabled     types.Bool   `tfsdk:"check_policy_enabled"`
 	IsDisabled             types.Bool   `tfsdk:"is_disabled"`
+	CreateDate             types.String `tfsdk:"create_date"`
+	ModifyDate             types.String `tfsdk:"modify_date"`
 }
 
 func (d *SQLLoginDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -44,11 +58,19 @@ func (d *SQLLoginDataSource) Schema(ctx context.Context, req datasource.SchemaRe
 		Attributes: map[string]schema.Attribute{
 			"id":                       schema.StringAttribute{Computed: true},
 			"name":                     schema.StringAttribute{Required: true},
+			"sid":                      schema.StringAttribute{Computed: true},
 			"default_database":         schema.StringAttribute{Computed: true},
 			"default_language":         schema.StringAttribute{Computed: true},
 			"check_expiration_enabled": schema.BoolAttribute{Computed: true},
 			"check_policy_enabled":     schema.BoolAttribute{Computed: true},
 			"is_disabled":              schema.BoolAttribute{Computed: true},
+			"create_date":              schema.StringAttribute{Computed: true},
+			"modify_date":              schema.StringAttribute{Computed: true},
+			"is_locked":                schema.BoolAttribute{Computed: true},
+			"is_expired":               schema.BoolAttribute{Computed: true},
+			"is_must_change":           schema.BoolAttribute{Computed: true},
+			"bad_password_count":       schema.Int64Attribute{Computed: true},
+			"password_last_set_time":   schema.StringAttribute{Computed: true},
 		},
 	}
 }
@@ -74,7 +96,7 @@ func (d *SQLLoginDataSource) Read(ctx context.Context, req datasource.ReadReques
 
 	login, err := d.client.GetSQLLogin(ctx, data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to read SQL login", err.Error())
+		resp.Diagnostics.AddError("Failed to read SQL login", errorDetail(err))
 		return
 	}
 	if login == nil {
@@ -83,11 +105,32 @@ func (d *SQLLoginDataSource) Read(ctx context.Context, req datasource.ReadReques
 	}
 
 	data.ID = types.StringValue(strconv.Itoa(login.PrincipalID))
+	data.SID = types.StringValue(login.SID)
 	data.DefaultDatabase = types.StringValue(login.DefaultDatabaseName)
 	data.DefaultLanguage = types.StringValue(login.DefaultLanguageName)
 	data.CheckExpirationEnabled = types.BoolValue(login.CheckExpirationEnabled)
 	data.CheckPolicyEnabled = types.BoolValue(login.CheckPolicyEnabled)
 	data.IsDisabled = types.BoolValue(login.IsDisabled)
+	data.CreateDate = timestampValue(login.CreateDate)
+	data.ModifyDate = timestampValue(login.ModifyDate)
+
+	props, err := d.client.GetLoginProperties(ctx, login.Name)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read SQL login properties", errorDetail(err))
+		return
+	}
+	if props == nil {
+		resp.Diagnostics.AddError("SQL login not found", fmt.Sprintf("Login '%s' not found", data.Name.ValueString()))
+		return
+	}
+	data.IsLocked = types.BoolValue(props.IsLocked)
+	data.IsExpired = types.BoolValue(props.IsExpired)
+	data.IsMustChange = types.BoolValue(props.IsMustChange)
+	data.BadPasswordCount = types.Int64Value(int64(props.BadPasswordCount))
+	data.PasswordLastSetTime = types.StringNull()
+	if props.PasswordLastSetTime.Valid {
+		data.PasswordLastSetTime = timestampValue(props.PasswordLastSetTime.Time)
+	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -103,7 +146,7 @@ type SQLLoginsDataSource struct {
 }
 
 type SQLLoginsDataSourceModel struct {
-	Logins []SQLLoginDataSourceModel `tfsdk:"logins"`
+	Logins []SQLLoginItemModel `tfsdk:"logins"`
 }
 
 func (d *SQLLoginsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -120,11 +163,14 @@ func (d *SQLLoginsDataSource) Schema(ctx context.Context, req datasource.SchemaR
 					Attributes: map[string]schema.Attribute{
 						"id":                       schema.StringAttribute{Computed: true},
 						"name":                     schema.StringAttribute{Computed: true},
+						"sid":                      schema.StringAttribute{Computed: true},
 						"default_database":         schema.StringAttribute{Computed: true},
 						"default_language":         schema.StringAttribute{Computed: true},
 						"check_expiration_enabled": schema.BoolAttribute{Computed: true},
 						"check_policy_enabled":     schema.BoolAttribute{Computed: true},
 						"is_disabled":              schema.BoolAttribute{Computed: true},
+						"create_date":              schema.StringAttribute{Computed: true},
+						"modify_date":              schema.StringAttribute{Computed: true},
 					},
 				},
 			},
@@ -149,19 +195,22 @@ func (d *SQLLoginsDataSource) Read(ctx context.Context, req datasource.ReadReque
 
 	logins, err := d.client.ListSQLLogins(ctx)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to list SQL logins", err.Error())
+		resp.Diagnostics.AddError("Failed to list SQL logins", errorDetail(err))
 		return
 	}
 
 	for _, login := range logins {
-		data.Logins = append(data.Logins, SQLLoginDataSourceModel{
+		data.Logins = append(data.Logins, SQLLoginItemModel{
 			ID:                     types.StringValue(strconv.Itoa(login.PrincipalID)),
 			Name:                   types.StringValue(login.Name),
+			SID:                    types.StringValue(login.SID),
 			DefaultDatabase:        types.StringValue(login.DefaultDatabaseName),
 			DefaultLanguage:        types.StringValue(login.DefaultLanguageName),
 			CheckExpirationEnabled: types.BoolValue(login.CheckExpirationEnabled),
 			CheckPolicyEnabled:     types.BoolValue(login.CheckPolicyEnabled),
 			IsDisabled:             types.BoolValue(login.IsDisabled),
+			CreateDate:             timestampValue(login.CreateDate),
+			ModifyDate:             timestampValue(login.ModifyDate),
 		})
 	}
 
@@ -24,11 +24,14 @@ type SQLUserDataSource struct {
 }
 
 type SQLUserDataSourceModel struct {
-	ID            types.String `tfsdk:"id"`
-	DatabaseName  types.String `tfsdk:"database_name"`
-	Name          types.String `tfsdk:"name"`
-	LoginName     types.String `tfsdk:"login_name"`
-	DefaultSchema types.String `tfsdk:"default_schema"`
+	ID              types.String `tfsdk:"id"`
+	DatabaseName    types.String `tfsdk:"database_name"`
+	Name            types.String `tfsdk:"name"`
+	LoginName       types.String `tfsdk:"login_name"`
+	DefaultSchema   types.String `tfsdk:"default_schema"`
+	DefaultLanguage types.String `tfsdk:"default_language"`
+	CreateDate      types.String `tfsdk:"create_date"`
+	ModifyDate      types.String `tfsdk:"modify_date"`
 }
 
 func (d *SQLUserDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -39,11 +42,14 @@ func (d *SQLUserDataSource) Schema(ctx context.Context, req datasource.SchemaReq
 	resp.Schema = schema.Schema{
 		Description: "Use this data source to get information about a SQL Server database user.",
 		Attributes: map[string]schema.Attribute{
-			"id":             schema.StringAttribute{Computed: true},
-			"database_name":  schema.StringAttribute{Required: true},
-			"name":           schema.StringAttribute{Required: true},
-			"login_name":     schema.StringAttribute{Computed: true},
-			"default_schema": schema.StringAttribute{Computed: true},
+			"id":               schema.StringAttribute{Computed: true},
+			"database_name":    schema.StringAttribute{Required: true},
+			"name":             schema.StringAttribute{Required: true},
+			"login_name":       schema.StringAttribute{Computed: true},
+			"default_schema":   schema.StringAttribute{Computed: true},
+			"default_language": schema.StringAttribute{Computed: true},
+			"create_date":      schema.StringAttribute{Computed: true},
+			"modify_date":      schema.StringAttribute{Computed: true},
 		},
 	}
 }
@@ -69,7 +75,7 @@ func (d *SQLUserDataSource) Read(ctx context.Context, req datasource.ReadRequest
 
 	user, err := d.client.GetUser(ctx, data.DatabaseName.ValueString(), data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to read SQL user", err.Error())
+		resp.Diagnostics.AddError("Failed to read SQL user", errorDetail(err))
 		return
 	}
 	if user == nil {
@@ -80,6 +86,9 @@ func (d *SQLUserDataSource) Read(ctx context.Context, req datasource.ReadRequest
 	data.ID = types.StringValue(fmt.Sprintf("%d/%d", user.DatabaseID, user.PrincipalID))
 	data.LoginName = types.StringValue(user.LoginName)
 	data.DefaultSchema = types.StringValue(user.DefaultSchemaName)
+	data.DefaultLanguage = types.StringValue(user.DefaultLanguageName)
+	data.CreateDate = timestampValue(user.CreateDate)
+	data.ModifyDate = timestampValue(user.ModifyDate)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -112,11 +121,14 @@ func (d *SQLUsersDataSource) Schema(ctx context.Context, req datasource.SchemaRe
 				Computed: true,
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
-						"id":             schema.StringAttribute{Computed: true},
-						"database_name":  schema.StringAttribute{Computed: true},
-						"name":           schema.StringAttribute{Computed: true},
-						"login_name":     schema.StringAttribute{Computed: true},
-						"default_schema": schema.StringAttribute{Computed: true},
+						"id":               schema.StringAttribute{Computed: true},
+						"database_name":    schema.StringAttribute{Computed: true},
+						"name":             schema.StringAttribute{Computed: true},
+						"login_name":       schema.StringAttribute{Computed: true},
+						"default_schema":   schema.StringAttribute{Computed: true},
+						"default_language": schema.StringAttribute{Computed: true},
+						"create_date":      schema.StringAttribute{Computed: true},
+						"modify_date":      schema.StringAttribute{Computed: true},
 					},
 				},
 			},
@@ -145,17 +157,129 @@ func (d *SQLUsersDataSource) Read(ctx context.Context, req datasource.ReadReques
 
 	users, err := d.client.ListUsers(ctx, data.DatabaseName.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to list SQL users", err.Error())
+		resp.Diagnostics.AddError("Failed to list SQL users", errorDetail(err))
 		return
 	}
 
 	for _, user := range users {
 		data.Users = append(data.Users, SQLUserDataSourceModel{
-			ID:            types.StringValue(fmt.Sprintf("%d/%d", user.DatabaseID, user.PrincipalID)),
-			DatabaseName:  data.DatabaseName,
-			Name:          types.StringValue(user.Name),
-			LoginName:     types.StringValue(user.LoginName),
-			DefaultSchema: types.StringValue(user.DefaultSchemaName),
+			ID:              types.StringValue(fmt.Sprintf("%d/%d", user.DatabaseID, user.PrincipalID)),
+			DatabaseName:    data.DatabaseName,
+			Name:            types.StringValue(user.Name),
+			LoginName:       types.StringValue(user.LoginName),
+			DefaultSchema:   types.StringValue(user.DefaultSchemaName),
+			DefaultLanguage: types.StringValue(user.DefaultLanguageName),
+			CreateDate:      timestampValue(user.CreateDate),
+			ModifyDate:      timestampValue(user.ModifyDate),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+var _ datasource.DataSource = &DatabaseUsersSIDDataSource{}
+
+func NewDatabaseUsersSIDDataSource() datasource.DataSource {
+	return &DatabaseUsersSIDDataSource{}
+}
+
+// DatabaseUsersSIDDataSource lists the SIDs of the users of a database, so
+// that external tooling can create them with the same SIDs elsewhere, e.g. on
+// the replicas of a contained availability group.
+type DatabaseUsersSIDDataSource struct {
+	client *mssql.Client
+}
+
+type UserSIDModel struct {
+	Name               types.String `tfsdk:"name"`
+	Type               types.String `tfsdk:"type"`
+	TypeDesc           types.String `tfsdk:"type_desc"`
+	AuthenticationType types.String `tfsdk:"authentication_type"`
+	SID                types.String `tfsdk:"sid"`
+}
+
+type DatabaseUsersSIDDataSourceModel struct {
+	DatabaseName types.String   `tfsdk:"database_name"`
+	Users        []UserSIDModel `tfsdk:"users"`
+}
+
+func (d *DatabaseUsersSIDDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_database_users_sid"
+}
+
+func (d *DatabaseUsersSIDDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Use this data source to list the users of a database with their SIDs, e.g. to create them with the same SIDs on another replica.",
+		Attributes: map[string]schema.Attribute{
+			"database_name": schema.StringAttribute{
+				Description: "The name of the database.",
+				Required:    true,
+			},
+			"users": schema.ListNestedAttribute{
+				Description: "The users of the database, except for the built-in dbo, guest, INFORMATION_SCHEMA and sys users.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Description: "The name of the user.",
+							Computed:    true,
+						},
+						"type": schema.StringAttribute{
+							Description: "The principal type: S (SQL user), U (Windows user), E (external user) or X (external group).",
+							Computed:    true,
+						},
+						"type_desc": schema.StringAttribute{
+							Description: "The description of the principal type, e.g. SQL_USER.",
+							Computed:    true,
+						},
+						"authentication_type": schema.StringAttribute{
+							Description: "INSTANCE for users mapped to a login, DATABASE for contained users with a password, EXTERNAL for Azure AD users or NONE for users without login.",
+							Computed:    true,
+						},
+						"sid": schema.StringAttribute{
+							Description: "The SID of the user as a hex string with 0x prefix.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *DatabaseUsersSIDDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type", fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData))
+		return
+	}
+	d.client = client
+}
+
+func (d *DatabaseUsersSIDDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data DatabaseUsersSIDDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	users, err := d.client.ListUserSIDs(ctx, data.DatabaseName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to list user SIDs", errorDetail(err))
+		return
+	}
+
+	data.Users = []UserSIDModel{}
+	for _, user := range users {
+		data.Users = append(data.Users, UserSIDModel{
+			Name:               types.StringValue(user.Name),
+			Type:               types.StringValue(user.Type),
+			TypeDesc:           types.StringValue(user.TypeDesc),
+			AuthenticationType: types.StringValue(user.AuthenticationType),
+			SID:                types.StringValue(user.SID),
 		})
 	}
 
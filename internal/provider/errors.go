@@ -0,0 +1,133 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/muecahit94/terraform-provider-mssql/internal/mssql"
+)
+
+// errorDetail returns the diagnostic detail for a client error, adding a hint
+// for well-known SQL Server failures.
+func errorDetail(err error) string {
+	switch {
+	case errors.Is(err, mssql.ErrPermissionDenied):
+		return err.Error() + "\n\nThe login used by the provider lacks the permission required for this operation."
+	case errors.Is(err, mssql.ErrAlreadyExists):
+		return err.Error() + "\n\nThe object already exists. Use 'terraform import' to manage it with Terraform."
+	case errors.Is(err, mssql.ErrNotFound):
+		return err.Error() + "\n\nThe object does not exist or is not visible to the login used by the provider."
+	}
+	return err.Error()
+}
+
+// ownerMatches reports whether an existing object's owner satisfies the
+// configured owner. An unset owner accepts any owner.
+func ownerMatches(client *mssql.Client, desired, actual string) bool {
+	return desired == "" || client.NamesEqual(desired, actual)
+}
+
+// warnMissingDefaultSchema adds a warning if the default schema of a new user
+// does not exist. SQL Server creates the user anyway, but objects it creates
+// without a schema go to dbo until the schema exists.
+func warnMissingDefaultSchema(ctx context.Context, client *mssql.Client, databaseName, schemaName string, diags *diag.Diagnostics) {
+	if schemaName == "" || client.NamesEqual(schemaName, "dbo") {
+		return
+	}
+	exists, err := client.SchemaExists(ctx, databaseName, schemaName)
+	if err != nil || exists {
+		return
+	}
+	diags.AddWarning("Default schema does not exist",
+		fmt.Sprintf("The default schema '%s' does not exist in database '%s'. If the schema is managed in the same configuration, "+
+			"reference it in default_schema (e.g. mssql_schema.example.name) or add depends_on so that it is created before the user. "+
+			"This is expected if the schema is owned by the user itself.", schemaName, databaseName))
+}
+
+// checkDefaultLanguage adds an error to the plan if a configured default
+// language is not a language of the server, listing the valid names, instead
+// of the less specific error SQL Server returns at apply. Languages are
+// matched by name, alias or ID, as in DEFAULT_LANGUAGE.
+func checkDefaultLanguage(ctx context.Context, client *mssql.Client, req resource.ModifyPlanRequest, diags *diag.Diagnostics) {
+	if client == nil || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var language types.String
+	diags.Append(req.Config.GetAttribute(ctx, path.Root("default_language"), &language)...)
+	if diags.HasError() || language.IsNull() || language.IsUnknown() || language.ValueString() == "" {
+		return
+	}
+
+	languages, err := client.ListLanguages(ctx)
+	if err != nil {
+		diags.AddError("Failed to list languages", errorDetail(err))
+		return
+	}
+
+	names := make([]string, len(languages))
+	for i, l := range languages {
+		if strings.EqualFold(l.Name, language.ValueString()) || strings.EqualFold(l.Alias, language.ValueString()) ||
+			strconv.Itoa(l.LangID) == language.ValueString() {
+			return
+		}
+		names[i] = l.Name
+	}
+	diags.AddAttributeError(path.Root("default_language"), "Unknown default language",
+		fmt.Sprintf("'%s' is not a language of the server. Valid languages are: %s.", language.ValueString(), strings.Join(names, ", ")))
+}
+
+// checkDbOwnerRemoval guards removing a member from db_owner. Removing the
+// user the provider is connected as is refused, like renaming its login,
+// since the provider would lose control of the database. Removing the last
+// member besides dbo only adds a warning. It returns false if the member must
+// not be removed.
+func checkDbOwnerRemoval(ctx context.Context, client *mssql.Client, databaseName, roleName, memberName string, diags *diag.Diagnostics) bool {
+	if !client.NamesEqual(roleName, "db_owner") {
+		return true
+	}
+	// The membership is already gone with the member or its database
+	exists, err := client.DatabasePrincipalExists(ctx, databaseName, memberName)
+	if err != nil {
+		diags.AddError("Failed to read database principal", errorDetail(err))
+		return false
+	}
+	if !exists {
+		return true
+	}
+
+	current, err := client.GetCurrentUserName(ctx, databaseName)
+	if err != nil {
+		diags.AddError("Failed to read current user", errorDetail(err))
+		return false
+	}
+	if client.NamesEqual(current, memberName) {
+		diags.AddError("Refusing to lock out the provider",
+			fmt.Sprintf("The provider is connected as user '%s' in database '%s'. Configure the provider with a different login before removing it from db_owner.", current, databaseName))
+		return false
+	}
+
+	members, err := client.ListDatabaseRoleMembers(ctx, databaseName, roleName)
+	if err != nil {
+		diags.AddError("Failed to read db_owner members", errorDetail(err))
+		return false
+	}
+	for _, member := range members {
+		if !client.NamesEqual(member, "dbo") && !client.NamesEqual(member, memberName) {
+			return true
+		}
+	}
+	diags.AddWarning("Last db_owner member removed",
+		fmt.Sprintf("'%s' is the last member of db_owner in database '%s' besides dbo. Only the database owner and sysadmins can administer the database afterwards.", memberName, databaseName))
+	return true
+}
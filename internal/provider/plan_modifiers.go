@@ -0,0 +1,99 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/muecahit94/terraform-provider-mssql/internal/mssql"
+)
+
+// desiredPermissionState returns the state_desc a permission resource converges to.
+func desiredPermissionState(withGrantOption bool) string {
+	return mssql.PermissionStateDesc(withGrantOption)
+}
+
+// permissionStatePlanModifier plans the permission state implied by with_grant_option.
+// When the state read from the server diverges (e.g. the permission was manually
+// DENYed), the resulting diff triggers an Update that revokes and re-grants it.
+type permissionStatePlanModifier struct{}
+
+func (m permissionStatePlanModifier) Description(ctx context.Context) string {
+	return "Plans the GRANT state implied by with_grant_option so that DENY drift is corrected."
+}
+
+func (m permissionStatePlanModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m permissionStatePlanModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	// Nothing to plan on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var withGrantOption types.Bool
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("with_grant_option"), &withGrantOption)...)
+	if resp.Diagnostics.HasError() || withGrantOption.IsUnknown() {
+		return
+	}
+
+	resp.PlanValue = types.StringValue(desiredPermissionState(withGrantOption.ValueBool()))
+}
+
+// planWithGrantOptionDefault plans the provider's default_with_grant_option
+// for a permission resource that leaves with_grant_option unset, along with
+// the permission state it implies. A configured value always takes precedence.
+func planWithGrantOptionDefault(ctx context.Context, client *mssql.Client, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var configured types.Bool
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("with_grant_option"), &configured)...)
+	if resp.Diagnostics.HasError() || !configured.IsNull() {
+		return
+	}
+
+	withGrantOption := client != nil && client.DefaultWithGrantOption()
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("with_grant_option"), withGrantOption)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("state"), desiredPermissionState(withGrantOption))...)
+}
+
+// loginLockedPlanModifier plans the lock state of a login. When unlock is set
+// and the login was found locked, it plans false, and the resulting diff
+// triggers an Update that unlocks the login. Otherwise the last known lock
+// state is kept.
+type loginLockedPlanModifier struct{}
+
+func (m loginLockedPlanModifier) Description(ctx context.Context) string {
+	return "Plans an unlocked login when unlock is set, so that a locked login is unlocked."
+}
+
+func (m loginLockedPlanModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m loginLockedPlanModifier) PlanModifyBool(ctx context.Context, req planmodifier.BoolRequest, resp *planmodifier.BoolResponse) {
+	// The lock state is only known once the login exists
+	if req.Plan.Raw.IsNull() || req.State.Raw.IsNull() {
+		return
+	}
+
+	resp.PlanValue = req.StateValue
+
+	var unlock types.Bool
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("unlock"), &unlock)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if unlock.ValueBool() && req.StateValue.ValueBool() {
+		resp.PlanValue = types.BoolValue(false)
+	}
+}
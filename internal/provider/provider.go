@@ -6,8 +6,11 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -26,10 +29,19 @@ type MSSQLProvider struct {
 
 // MSSQLProviderModel describes the provider data model.
 type MSSQLProviderModel struct {
-	Hostname  types.String    `tfsdk:"hostname"`
-	Port      types.Int64     `tfsdk:"port"`
-	SQLAuth   *SQLAuthModel   `tfsdk:"sql_auth"`
-	AzureAuth *AzureAuthModel `tfsdk:"azure_auth"`
+	Hostname               types.String    `tfsdk:"hostname"`
+	Port                   types.Int64     `tfsdk:"port"`
+	ServerName             types.String    `tfsdk:"server_name"`
+	FailoverPartner        types.String    `tfsdk:"failover_partner"`
+	MultiSubnetFailover    types.Bool      `tfsdk:"multi_subnet_failover"`
+	Encrypt                types.Bool      `tfsdk:"encrypt"`
+	TrustServerCertificate types.Bool      `tfsdk:"trust_server_certificate"`
+	Certificate            types.String    `tfsdk:"certificate"`
+	CertificateThumbprint  types.String    `tfsdk:"certificate_thumbprint"`
+	ValidateConnection     types.Bool      `tfsdk:"validate_connection"`
+	DefaultWithGrantOption types.Bool      `tfsdk:"default_with_grant_option"`
+	SQLAuth                *SQLAuthModel   `tfsdk:"sql_auth"`
+	AzureAuth              *AzureAuthModel `tfsdk:"azure_auth"`
 }
 
 // SQLAuthModel describes SQL authentication configuration.
@@ -40,9 +52,13 @@ type SQLAuthModel struct {
 
 // AzureAuthModel describes Azure AD authentication configuration.
 type AzureAuthModel struct {
-	ClientID     types.String `tfsdk:"client_id"`
-	ClientSecret types.String `tfsdk:"client_secret"`
-	TenantID     types.String `tfsdk:"tenant_id"`
+	ClientID           types.String `tfsdk:"client_id"`
+	ClientSecret       types.String `tfsdk:"client_secret"`
+	TenantID           types.String `tfsdk:"tenant_id"`
+	Environment        types.String `tfsdk:"environment"`
+	OIDCToken          types.String `tfsdk:"oidc_token"`
+	OIDCTokenFile      types.String `tfsdk:"oidc_token_file"`
+	TokenRefreshMargin types.String `tfsdk:"token_refresh_margin"`
 }
 
 // New creates a new provider instance.
@@ -73,6 +89,42 @@ func (p *MSSQLProvider) Schema(ctx context.Context, req provider.SchemaRequest,
 				Description: "TCP port of SQL endpoint. Defaults to 1433. Can also be set using MSSQL_PORT environment variable.",
 				Optional:    true,
 			},
+			"server_name": schema.StringAttribute{
+				Description: "Server name used instead of hostname in resource IDs, e.g. the logical server name when hostname is a load balancer or listener that differs between environments. Defaults to hostname.",
+				Optional:    true,
+			},
+			"failover_partner": schema.StringAttribute{
+				Description: "Host of the database mirroring failover partner, connected to on the same port when hostname cannot be reached.",
+				Optional:    true,
+			},
+			"multi_subnet_failover": schema.BoolAttribute{
+				Description: "Whether to connect to all IP addresses of an availability group listener in parallel, for fast reconnects after a failover across subnets. Defaults to true.",
+				Optional:    true,
+			},
+			"encrypt": schema.BoolAttribute{
+				Description: "Whether to encrypt the whole connection instead of only the login. Defaults to the driver default.",
+				Optional:    true,
+			},
+			"trust_server_certificate": schema.BoolAttribute{
+				Description: "Whether to accept the server certificate without validating it. Defaults to true, or to false if certificate or certificate_thumbprint is set.",
+				Optional:    true,
+			},
+			"certificate": schema.StringAttribute{
+				Description: "Path to a .pem or .der file with the certificate the server certificate is validated against, e.g. of a private CA.",
+				Optional:    true,
+			},
+			"certificate_thumbprint": schema.StringAttribute{
+				Description: "SHA-1 or SHA-256 thumbprint of the server certificate as a hex string. Only a server presenting exactly this certificate is accepted.",
+				Optional:    true,
+			},
+			"validate_connection": schema.BoolAttribute{
+				Description: "Whether to ping a pooled connection before each operation, so that connections dropped by the network while idle are replaced instead of failing the operation. Defaults to false.",
+				Optional:    true,
+			},
+			"default_with_grant_option": schema.BoolAttribute{
+				Description: "The with_grant_option of permission resources that do not set it. Defaults to false.",
+				Optional:    true,
+			},
 		},
 		Blocks: map[string]schema.Block{
 			"sql_auth": schema.SingleNestedBlock{
@@ -105,6 +157,23 @@ func (p *MSSQLProvider) Schema(ctx context.Context, req provider.SchemaRequest,
 						Description: "Azure AD tenant ID. Required only if Azure SQL Server's tenant is different than Service Principal's.",
 						Optional:    true,
 					},
+					"environment": schema.StringAttribute{
+						Description: "The Azure cloud to authenticate against: public, usgovernment or china. Defaults to public. Can also be set using ARM_ENVIRONMENT environment variable.",
+						Optional:    true,
+					},
+					"oidc_token": schema.StringAttribute{
+						Description: "OIDC ID token to authenticate the client_id with workload identity federation, e.g. in GitHub Actions or GitLab CI. Can also be set using ARM_OIDC_TOKEN environment variable.",
+						Optional:    true,
+						Sensitive:   true,
+					},
+					"oidc_token_file": schema.StringAttribute{
+						Description: "Path to a file holding the OIDC ID token. The file is read again when a new token is needed. Can also be set using ARM_OIDC_TOKEN_FILE_PATH environment variable.",
+						Optional:    true,
+					},
+					"token_refresh_margin": schema.StringAttribute{
+						Description: "How long before its expiry the Azure AD token is replaced by a new one, as a duration such as '10m'. Defaults to '5m'.",
+						Optional:    true,
+					},
 				},
 			},
 		},
@@ -124,8 +193,45 @@ func (p *MSSQLProvider) Configure(ctx context.Context, req provider.ConfigureReq
 
 	// Build client configuration
 	cfg := &mssql.Config{
-		Hostname: config.Hostname.ValueString(),
-		Port:     int(config.Port.ValueInt64()),
+		Hostname:               config.Hostname.ValueString(),
+		Port:                   int(config.Port.ValueInt64()),
+		ServerName:             config.ServerName.ValueString(),
+		FailoverPartner:        config.FailoverPartner.ValueString(),
+		ValidateConnection:     config.ValidateConnection.ValueBool(),
+		DefaultWithGrantOption: config.DefaultWithGrantOption.ValueBool(),
+	}
+	if !config.MultiSubnetFailover.IsNull() {
+		multiSubnetFailover := config.MultiSubnetFailover.ValueBool()
+		cfg.MultiSubnetFailover = &multiSubnetFailover
+	}
+
+	// Configure TLS
+	if !config.Encrypt.IsNull() {
+		encrypt := config.Encrypt.ValueBool()
+		cfg.Encrypt = &encrypt
+	}
+	if !config.TrustServerCertificate.IsNull() {
+		trust := config.TrustServerCertificate.ValueBool()
+		cfg.TrustServerCertificate = &trust
+	}
+	cfg.Certificate = config.Certificate.ValueString()
+	cfg.CertificateThumbprint = config.CertificateThumbprint.ValueString()
+	if cfg.Certificate != "" {
+		if err := mssql.CheckCertificateFile(cfg.Certificate); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("certificate"), "Invalid certificate", err.Error())
+		}
+	}
+	if cfg.CertificateThumbprint != "" {
+		if _, err := mssql.ParseCertificateThumbprint(cfg.CertificateThumbprint); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("certificate_thumbprint"), "Invalid certificate thumbprint", err.Error())
+		}
+	}
+	if (cfg.Certificate != "" || cfg.CertificateThumbprint != "") && config.TrustServerCertificate.ValueBool() {
+		resp.Diagnostics.AddAttributeError(path.Root("trust_server_certificate"), "Conflicting certificate validation",
+			"trust_server_certificate must not be true when certificate or certificate_thumbprint is set, since the server certificate would not be checked against them.")
+	}
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
 	// Configure authentication
@@ -135,10 +241,31 @@ func (p *MSSQLProvider) Configure(ctx context.Context, req provider.ConfigureReq
 			Password: config.SQLAuth.Password.ValueString(),
 		}
 	} else if config.AzureAuth != nil {
+		environment := config.AzureAuth.Environment.ValueString()
+		if environment != "" && !mssql.IsAzureEnvironment(environment) {
+			resp.Diagnostics.AddAttributeError(path.Root("azure_auth").AtName("environment"), "Unsupported Azure environment",
+				fmt.Sprintf("environment must be one of '%s', '%s' or '%s', got: %s",
+					mssql.AzureEnvironmentPublic, mssql.AzureEnvironmentUSGovernment, mssql.AzureEnvironmentChina, environment))
+			return
+		}
+		var refreshMargin time.Duration
+		if margin := config.AzureAuth.TokenRefreshMargin.ValueString(); margin != "" {
+			var err error
+			refreshMargin, err = time.ParseDuration(margin)
+			if err != nil || refreshMargin <= 0 {
+				resp.Diagnostics.AddAttributeError(path.Root("azure_auth").AtName("token_refresh_margin"), "Invalid token refresh margin",
+					fmt.Sprintf("token_refresh_margin must be a positive duration such as '10m', got: %s", margin))
+				return
+			}
+		}
 		cfg.AzureAuth = &mssql.AzureAuthConfig{
-			ClientID:     config.AzureAuth.ClientID.ValueString(),
-			ClientSecret: config.AzureAuth.ClientSecret.ValueString(),
-			TenantID:     config.AzureAuth.TenantID.ValueString(),
+			ClientID:           config.AzureAuth.ClientID.ValueString(),
+			ClientSecret:       config.AzureAuth.ClientSecret.ValueString(),
+			TenantID:           config.AzureAuth.TenantID.ValueString(),
+			Environment:        environment,
+			OIDCToken:          config.AzureAuth.OIDCToken.ValueString(),
+			OIDCTokenFile:      config.AzureAuth.OIDCTokenFile.ValueString(),
+			TokenRefreshMargin: refreshMargin,
 		}
 	}
 
@@ -168,17 +295,29 @@ func (p *MSSQLProvider) Configure(ctx context.Context, req provider.ConfigureReq
 func (p *MSSQLProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewDatabaseResource,
+		NewQueryStoreResource,
+		NewDatabaseEncryptionResource,
+		NewDatabaseOwnerResource,
 		NewSQLLoginResource,
 		NewSQLUserResource,
+		NewLoginUserResource,
+		NewGuestUserResource,
 		NewDatabaseRoleResource,
 		NewDatabaseRoleMemberResource,
 		NewDatabasePermissionResource,
+		NewPermissionResource,
 		NewSchemaResource,
 		NewSchemaPermissionResource,
+		NewSchemaPermissionsResource,
+		NewAccessResource,
 		NewServerRoleResource,
 		NewServerRoleMemberResource,
 		NewServerPermissionResource,
 		NewScriptResource,
+		NewExecResource,
+		NewAgentJobResource,
+		NewAgentScheduleResource,
+		NewAgentJobScheduleResource,
 		NewAzureADUserResource,
 		NewAzureADServicePrincipalResource,
 	}
@@ -189,19 +328,31 @@ func (p *MSSQLProvider) DataSources(ctx context.Context) []func() datasource.Dat
 	return []func() datasource.DataSource{
 		NewDatabaseDataSource,
 		NewDatabasesDataSource,
+		NewDatabaseOwnerDataSource,
 		NewSQLLoginDataSource,
 		NewSQLLoginsDataSource,
 		NewSQLUserDataSource,
 		NewSQLUsersDataSource,
+		NewDatabaseUsersSIDDataSource,
 		NewDatabaseRoleDataSource,
 		NewDatabaseRolesDataSource,
+		NewDatabaseRolePermissionsDataSource,
 		NewDatabasePermissionsDataSource,
+		NewAllPermissionsDataSource,
+		NewDatabaseImportsDataSource,
 		NewSchemaDataSource,
 		NewSchemasDataSource,
+		NewSchemaObjectsDataSource,
 		NewSchemaPermissionsDataSource,
 		NewServerRoleDataSource,
 		NewServerRolesDataSource,
 		NewServerPermissionsDataSource,
+		NewPrincipalMembershipsDataSource,
+		NewHasPermissionDataSource,
+		NewDatabaseAccessReportDataSource,
+		NewServerDataSource,
+		NewProviderStatsDataSource,
+		NewEndpointsDataSource,
 		NewAzureADUserDataSource,
 		NewAzureADServicePrincipalDataSource,
 		NewQueryDataSource,
@@ -0,0 +1,574 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/muecahit94/terraform-provider-mssql/internal/mssql"
+)
+
+var _ resource.Resource = &AccessResource{}
+var _ resource.ResourceWithImportState = &AccessResource{}
+var _ resource.ResourceWithModifyPlan = &AccessResource{}
+
+func NewAccessResource() resource.Resource {
+	return &AccessResource{}
+}
+
+// AccessResource manages all access of a database principal in one resource:
+// the server roles of its login, its database roles, and its database and
+// schema permissions.
+type AccessResource struct {
+	client *mssql.Client
+}
+
+type AccessResourceModel struct {
+	ID                  types.String `tfsdk:"id"`
+	DatabaseName        types.String `tfsdk:"database_name"`
+	PrincipalName       types.String `tfsdk:"principal_name"`
+	ServerRoles         types.Set    `tfsdk:"server_roles"`
+	DatabaseRoles       types.Set    `tfsdk:"database_roles"`
+	DatabasePermissions types.Set    `tfsdk:"database_permissions"`
+	SchemaPermissions   types.Map    `tfsdk:"schema_permissions"`
+	Ignore              types.Set    `tfsdk:"ignore"`
+}
+
+// accessPrincipal is the database principal whose access is managed.
+type accessPrincipal struct {
+	ID string
+	// LoginName is empty for database roles and users without a login
+	LoginName string
+}
+
+var schemaPermissionsType = types.SetType{ElemType: types.StringType}
+
+func (r *AccessResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_access"
+}
+
+func (r *AccessResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages the complete access of a database user or role: server roles, database roles, and database and schema permissions.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The principal ID in format 'database_id/principal_id'.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"database_name": schema.StringAttribute{
+				Description: "The name of the database.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"principal_name": schema.StringAttribute{
+				Description: "The name of the database user or role.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"server_roles": schema.SetAttribute{
+				Description: "The server roles of the login the user is mapped to. If set, membership is authoritative: server roles added outside of this set are removed.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"database_roles": schema.SetAttribute{
+				Description: "The database roles of the principal. If set, membership is authoritative: roles added outside of this set are removed.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"database_permissions": schema.SetAttribute{
+				Description: "The database-level permissions granted to the principal. If set, permissions are authoritative: permissions granted outside of this set are revoked.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"schema_permissions": schema.MapAttribute{
+				Description: "The permissions granted to the principal by schema name. Permissions granted outside of these sets on the listed schemas are revoked.",
+				Optional:    true,
+				ElementType: schemaPermissionsType,
+			},
+			"ignore": schema.SetAttribute{
+				Description: "Roles and permissions that are managed outside of this resource, e.g. by another tool. They are neither added nor removed, and are not reported as drift.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+		},
+	}
+}
+
+func (r *AccessResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Resource Configure Type", fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData))
+		return
+	}
+	r.client = client
+}
+
+// ModifyPlan lists the roles and database permissions to be changed in the plan.
+func (r *AccessResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	previewSetChanges(ctx, req, resp, r.client.NameKey, "server_roles", "database_roles")
+	previewSetChanges(ctx, req, resp, permissionKey, "database_permissions")
+}
+
+// principal looks up the user or role whose access is managed. It returns nil
+// if the principal does not exist.
+func (r *AccessResource) principal(ctx context.Context, databaseName, principalName string) (*accessPrincipal, error) {
+	user, err := r.client.GetUser(ctx, databaseName, principalName)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		return &accessPrincipal{ID: fmt.Sprintf("%d/%d", user.DatabaseID, user.PrincipalID), LoginName: user.LoginName}, nil
+	}
+
+	role, err := r.client.GetDatabaseRole(ctx, databaseName, principalName)
+	if err != nil || role == nil {
+		return nil, err
+	}
+	return &accessPrincipal{ID: fmt.Sprintf("%d/%d", role.DatabaseID, role.PrincipalID)}, nil
+}
+
+// grantedDatabasePermissions returns the database-level permissions granted
+// to the principal. CONNECT, which every user gets on creation, is only
+// returned if it is in configured, so that it is not revoked unless managed.
+func (r *AccessResource) grantedDatabasePermissions(ctx context.Context, databaseName, principalName string, configured []string) ([]string, error) {
+	perms, err := r.client.ListDatabasePermissions(ctx, databaseName, principalName)
+	if err != nil {
+		return nil, err
+	}
+	manageConnect := false
+	for _, permission := range configured {
+		if strings.EqualFold(permission, "CONNECT") {
+			manageConnect = true
+		}
+	}
+
+	var granted []string
+	for _, perm := range perms {
+		if perm.StateDesc == mssql.PermissionStateDeny || (perm.PermissionName == "CONNECT" && !manageConnect) {
+			continue
+		}
+		granted = append(granted, perm.PermissionName)
+	}
+	return granted, nil
+}
+
+// grantedSchemaPermissions returns the permissions granted to the principal
+// on a schema.
+func (r *AccessResource) grantedSchemaPermissions(ctx context.Context, databaseName, schemaName, principalName string) ([]string, error) {
+	perms, err := r.client.ListSchemaPermissions(ctx, databaseName, schemaName, principalName)
+	if err != nil {
+		return nil, err
+	}
+	var granted []string
+	for _, perm := range perms {
+		if perm.StateDesc != mssql.PermissionStateDeny {
+			granted = append(granted, perm.PermissionName)
+		}
+	}
+	return granted, nil
+}
+
+// schemaPermissions returns the configured permissions by schema name.
+func schemaPermissions(ctx context.Context, value types.Map, diags *diag.Diagnostics) map[string][]string {
+	permissions := make(map[string][]string)
+	if !value.IsNull() && !value.IsUnknown() {
+		diags.Append(value.ElementsAs(ctx, &permissions, false)...)
+	}
+	return permissions
+}
+
+// reconcile brings the access of the principal in line with the
+// configuration. Permissions are granted before roles are added, and roles are
+// not added while any permission change has failed, so that the principal's
+// access is never wider than intended. Schemas that were dropped from
+// schema_permissions since previous have their permissions revoked. Unset
+// attributes and ignored names are not managed. Failing items don't stop the others from being
+// applied; if any fail, data is read back from the server.
+func (r *AccessResource) reconcile(ctx context.Context, data *AccessResourceModel, previous *AccessResourceModel, principal *accessPrincipal) diag.Diagnostics {
+	var diags diag.Diagnostics
+	databaseName := data.DatabaseName.ValueString()
+	principalName := data.PrincipalName.ValueString()
+	ignored := setElements(ctx, data.Ignore, &diags)
+
+	if !data.DatabasePermissions.IsNull() {
+		var desired []string
+		diags.Append(data.DatabasePermissions.ElementsAs(ctx, &desired, false)...)
+		if diags.HasError() {
+			return diags
+		}
+		current, err := r.grantedDatabasePermissions(ctx, databaseName, principalName, desired)
+		if err != nil {
+			diags.AddError("Failed to read database permissions", errorDetail(err))
+			return diags
+		}
+
+		grant, revoke := diffNames(withoutIgnored(current, ignored, permissionKey), withoutIgnored(desired, ignored, permissionKey), permissionKey)
+		applyEach(revoke, "Failed to revoke database permission", "revoke", func(permission string) error {
+			return r.client.RevokeDatabasePermission(ctx, databaseName, principalName, permission, false)
+		}, &diags)
+		applyEach(grant, "Failed to grant database permission", "grant", func(permission string) error {
+			return r.client.GrantDatabasePermission(ctx, databaseName, principalName, permission, false)
+		}, &diags)
+	}
+
+	if !data.SchemaPermissions.IsNull() {
+		desired := schemaPermissions(ctx, data.SchemaPermissions, &diags)
+		schemas := make(map[string]bool)
+		for schemaName := range desired {
+			schemas[schemaName] = true
+		}
+		if previous != nil {
+			for schemaName := range schemaPermissions(ctx, previous.SchemaPermissions, &diags) {
+				schemas[schemaName] = true
+			}
+		}
+		if diags.HasError() {
+			return diags
+		}
+
+		for _, schemaName := range sortedKeys(schemas) {
+			current, err := r.grantedSchemaPermissions(ctx, databaseName, schemaName, principalName)
+			if err != nil {
+				diags.AddError("Failed to read schema permissions", errorDetail(err))
+				continue
+			}
+
+			grant, revoke := diffNames(withoutIgnored(current, ignored, permissionKey), withoutIgnored(desired[schemaName], ignored, permissionKey), permissionKey)
+			applyEach(revoke, "Failed to revoke schema permission", "revoke", func(permission string) error {
+				return r.client.RevokeSchemaPermission(ctx, databaseName, schemaName, principalName, permission, false)
+			}, &diags)
+			applyEach(grant, "Failed to grant schema permission", "grant", func(permission string) error {
+				return r.client.GrantSchemaPermission(ctx, databaseName, schemaName, principalName, permission, false)
+			}, &diags)
+		}
+	}
+
+	// Roles would be added without all of the intended permissions
+	addRoles := !diags.HasError()
+
+	if !data.DatabaseRoles.IsNull() {
+		var desired []string
+		diags.Append(data.DatabaseRoles.ElementsAs(ctx, &desired, false)...)
+		if diags.HasError() {
+			return diags
+		}
+		current, err := r.client.GetUserRoles(ctx, databaseName, principalName)
+		if err != nil {
+			diags.AddError("Failed to read database roles", errorDetail(err))
+			return diags
+		}
+
+		add, remove := diffNames(withoutIgnored(current, ignored, r.client.NameKey), withoutIgnored(desired, ignored, r.client.NameKey), r.client.NameKey)
+		applyEach(remove, "Failed to remove database role", "remove from role", func(role string) error {
+			if !checkDbOwnerRemoval(ctx, r.client, databaseName, role, principalName, &diags) {
+				return nil
+			}
+			return r.client.RemoveDatabaseRoleMember(ctx, databaseName, role, principalName)
+		}, &diags)
+		if addRoles {
+			applyEach(add, "Failed to add database role", "add to role", func(role string) error {
+				return r.client.AddDatabaseRoleMember(ctx, databaseName, role, principalName)
+			}, &diags)
+		}
+	}
+
+	if !data.ServerRoles.IsNull() {
+		var desired []string
+		diags.Append(data.ServerRoles.ElementsAs(ctx, &desired, false)...)
+		if diags.HasError() {
+			return diags
+		}
+		if principal.LoginName == "" {
+			if len(desired) > 0 {
+				diags.AddAttributeError(path.Root("server_roles"), "Principal has no login",
+					fmt.Sprintf("Server roles can only be managed for a user mapped to a login, but '%s' has no login.", principalName))
+			}
+		} else {
+			current, err := r.client.GetServerRoleMemberships(ctx, principal.LoginName)
+			if err != nil {
+				diags.AddError("Failed to read server roles", errorDetail(err))
+				return diags
+			}
+
+			add, remove := diffNames(withoutIgnored(current, ignored, r.client.NameKey), withoutIgnored(desired, ignored, r.client.NameKey), r.client.NameKey)
+			applyEach(remove, "Failed to remove server role", "remove from server role", func(role string) error {
+				return r.client.RemoveServerRoleMember(ctx, role, principal.LoginName)
+			}, &diags)
+			if addRoles {
+				applyEach(add, "Failed to add server role", "add to server role", func(role string) error {
+					return r.client.AddServerRoleMember(ctx, role, principal.LoginName)
+				}, &diags)
+			}
+		}
+	}
+
+	if diags.HasError() {
+		diags.Append(r.refresh(ctx, data, principal)...)
+	}
+	return diags
+}
+
+// refresh reads the managed access of the principal into data.
+func (r *AccessResource) refresh(ctx context.Context, data *AccessResourceModel, principal *accessPrincipal) diag.Diagnostics {
+	var diags diag.Diagnostics
+	databaseName := data.DatabaseName.ValueString()
+	principalName := data.PrincipalName.ValueString()
+	ignored := setElements(ctx, data.Ignore, &diags)
+
+	if !data.DatabasePermissions.IsNull() {
+		var configured []string
+		diags.Append(data.DatabasePermissions.ElementsAs(ctx, &configured, false)...)
+		if diags.HasError() {
+			return diags
+		}
+		granted, err := r.grantedDatabasePermissions(ctx, databaseName, principalName, configured)
+		if err != nil {
+			diags.AddError("Failed to read database permissions", errorDetail(err))
+			return diags
+		}
+		granted = managedNames(ctx, data.DatabasePermissions, granted, ignored, permissionKey, &diags)
+		data.DatabasePermissions = authoritativeSet(ctx, data.DatabasePermissions, granted, permissionKey, &diags)
+	}
+
+	if !data.SchemaPermissions.IsNull() {
+		var configured map[string]types.Set
+		diags.Append(data.SchemaPermissions.ElementsAs(ctx, &configured, false)...)
+		if diags.HasError() {
+			return diags
+		}
+		values := make(map[string]attr.Value, len(configured))
+		for schemaName, permissions := range configured {
+			granted, err := r.grantedSchemaPermissions(ctx, databaseName, schemaName, principalName)
+			if err != nil {
+				diags.AddError("Failed to read schema permissions", errorDetail(err))
+				return diags
+			}
+			granted = managedNames(ctx, permissions, granted, ignored, permissionKey, &diags)
+			values[schemaName] = authoritativeSet(ctx, permissions, granted, permissionKey, &diags)
+		}
+		value, d := types.MapValue(schemaPermissionsType, values)
+		diags.Append(d...)
+		data.SchemaPermissions = value
+	}
+
+	if !data.DatabaseRoles.IsNull() {
+		roles, err := r.client.GetUserRoles(ctx, databaseName, principalName)
+		if err != nil {
+			diags.AddError("Failed to read database roles", errorDetail(err))
+			return diags
+		}
+		roles = managedNames(ctx, data.DatabaseRoles, roles, ignored, r.client.NameKey, &diags)
+		data.DatabaseRoles = authoritativeSet(ctx, data.DatabaseRoles, roles, r.client.NameKey, &diags)
+	}
+
+	if !data.ServerRoles.IsNull() && principal.LoginName != "" {
+		roles, err := r.client.GetServerRoleMemberships(ctx, principal.LoginName)
+		if err != nil {
+			diags.AddError("Failed to read server roles", errorDetail(err))
+			return diags
+		}
+		roles = managedNames(ctx, data.ServerRoles, roles, ignored, r.client.NameKey, &diags)
+		data.ServerRoles = authoritativeSet(ctx, data.ServerRoles, roles, r.client.NameKey, &diags)
+	}
+	return diags
+}
+
+func (r *AccessResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data AccessResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Creating access", map[string]interface{}{"database": data.DatabaseName.ValueString(), "principal": data.PrincipalName.ValueString()})
+
+	principal, err := r.principal(ctx, data.DatabaseName.ValueString(), data.PrincipalName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read principal", errorDetail(err))
+		return
+	}
+	if principal == nil {
+		resp.Diagnostics.AddError("Principal not found",
+			fmt.Sprintf("No user or role '%s' found in database '%s'", data.PrincipalName.ValueString(), data.DatabaseName.ValueString()))
+		return
+	}
+
+	data.ID = types.StringValue(principal.ID)
+	resp.Diagnostics.Append(r.reconcile(ctx, &data, nil, principal)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AccessResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data AccessResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	principal, err := r.principal(ctx, data.DatabaseName.ValueString(), data.PrincipalName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read principal", errorDetail(err))
+		return
+	}
+	if principal == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	data.ID = types.StringValue(principal.ID)
+	resp.Diagnostics.Append(r.refresh(ctx, &data, principal)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AccessResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state AccessResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	principal, err := r.principal(ctx, data.DatabaseName.ValueString(), data.PrincipalName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read principal", errorDetail(err))
+		return
+	}
+	if principal == nil {
+		resp.Diagnostics.AddError("Principal not found",
+			fmt.Sprintf("No user or role '%s' found in database '%s'", data.PrincipalName.ValueString(), data.DatabaseName.ValueString()))
+		return
+	}
+
+	resp.Diagnostics.Append(r.reconcile(ctx, &data, &state, principal)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AccessResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data AccessResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	principal, err := r.principal(ctx, data.DatabaseName.ValueString(), data.PrincipalName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read principal", errorDetail(err))
+		return
+	}
+	// The access of a dropped principal went with it
+	if principal == nil {
+		return
+	}
+
+	// Reconciling against empty sets removes all managed roles and
+	// permissions; ignored ones are left in place
+	empty := data
+	for _, set := range []*types.Set{&empty.ServerRoles, &empty.DatabaseRoles, &empty.DatabasePermissions} {
+		if !set.IsNull() {
+			*set = stringSetValue(nil)
+		}
+	}
+	if !empty.SchemaPermissions.IsNull() {
+		empty.SchemaPermissions = types.MapValueMust(schemaPermissionsType, map[string]attr.Value{})
+	}
+	resp.Diagnostics.Append(r.reconcile(ctx, &empty, &data, principal)...)
+}
+
+func (r *AccessResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts := strings.Split(req.ID, "/")
+	if len(parts) != 2 {
+		resp.Diagnostics.AddError("Invalid import ID", "Import ID must be in format 'database_name/principal_name'")
+		return
+	}
+	databaseName, principalName := parts[0], parts[1]
+
+	principal, err := r.principal(ctx, databaseName, principalName)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to import access", errorDetail(err))
+		return
+	}
+	if principal == nil {
+		resp.Diagnostics.AddError("Principal not found", fmt.Sprintf("No user or role '%s' found in database '%s'", principalName, databaseName))
+		return
+	}
+
+	// Import everything the principal currently holds, so that the next plan
+	// shows what the configuration would add or remove
+	data := AccessResourceModel{
+		ID:                  types.StringValue(principal.ID),
+		DatabaseName:        types.StringValue(databaseName),
+		PrincipalName:       types.StringValue(principalName),
+		ServerRoles:         types.SetNull(types.StringType),
+		DatabaseRoles:       stringSetValue(nil),
+		DatabasePermissions: stringSetValue(nil),
+		SchemaPermissions:   types.MapNull(schemaPermissionsType),
+		Ignore:              types.SetNull(types.StringType),
+	}
+	if principal.LoginName != "" {
+		data.ServerRoles = stringSetValue(nil)
+	}
+	resp.Diagnostics.Append(r.refresh(ctx, &data, principal)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Schema permissions are imported for every schema the principal holds
+	// permissions on
+
+	schemas, err := r.client.ListSchemas(ctx, databaseName, "")
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to import access", errorDetail(err))
+		return
+	}
+	values := make(map[string]attr.Value)
+	for _, s := range schemas {
+		granted, err := r.grantedSchemaPermissions(ctx, databaseName, s.Name, principalName)
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to import access", errorDetail(err))
+			return
+		}
+		if len(granted) > 0 {
+			values[s.Name] = stringSetValue(granted)
+		}
+	}
+	data.SchemaPermissions = types.MapValueMust(schemaPermissionsType, values)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// sortedKeys returns the keys of a set of names in sorted order.
+func sortedKeys(names map[string]bool) []string {
+	keys := make([]string, 0, len(names))
+	for name := range names {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,382 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/muecahit94/terraform-provider-mssql/internal/mssql"
+)
+
+var _ resource.Resource = &AgentJobResource{}
+var _ resource.ResourceWithImportState = &AgentJobResource{}
+
+func NewAgentJobResource() resource.Resource {
+	return &AgentJobResource{}
+}
+
+// AgentJobResource manages a SQL Server Agent job with Transact-SQL steps.
+// Jobs live in msdb, so the resource is server-scoped.
+type AgentJobResource struct {
+	client *mssql.Client
+}
+
+type AgentJobResourceModel struct {
+	ID             types.String        `tfsdk:"id"`
+	Name           types.String        `tfsdk:"name"`
+	Enabled        types.Bool          `tfsdk:"enabled"`
+	OwnerLoginName types.String        `tfsdk:"owner_login_name"`
+	Steps          []AgentJobStepModel `tfsdk:"steps"`
+}
+
+type AgentJobStepModel struct {
+	Name         types.String `tfsdk:"name"`
+	Command      types.String `tfsdk:"command"`
+	DatabaseName types.String `tfsdk:"database_name"`
+	OnSuccess    types.String `tfsdk:"on_success"`
+	OnFail       types.String `tfsdk:"on_fail"`
+}
+
+func (r *AgentJobResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_agent_job"
+}
+
+func (r *AgentJobResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages a SQL Server Agent job with Transact-SQL steps. Not available in Azure SQL Database.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The job ID.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "The name of the job.",
+				Required:    true,
+			},
+			"enabled": schema.BoolAttribute{
+				Description: "Whether the job is enabled. Defaults to true.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+			},
+			"owner_login_name": schema.StringAttribute{
+				Description: "The login that owns the job. Defaults to the login used by the provider.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"steps": schema.ListNestedAttribute{
+				Description: "The steps of the job, run in order.",
+				Required:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Description: "The name of the step.",
+							Required:    true,
+						},
+						"command": schema.StringAttribute{
+							Description: "The Transact-SQL command run by the step.",
+							Required:    true,
+						},
+						"database_name": schema.StringAttribute{
+							Description: "The database the command runs in. Defaults to master.",
+							Optional:    true,
+						},
+						"on_success": schema.StringAttribute{
+							Description: "The action when the step succeeds: GO_TO_NEXT_STEP, QUIT_WITH_SUCCESS or QUIT_WITH_FAILURE. Defaults to GO_TO_NEXT_STEP, or QUIT_WITH_SUCCESS for the last step.",
+							Optional:    true,
+						},
+						"on_fail": schema.StringAttribute{
+							Description: "The action when the step fails: GO_TO_NEXT_STEP, QUIT_WITH_SUCCESS or QUIT_WITH_FAILURE. Defaults to QUIT_WITH_FAILURE.",
+							Optional:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *AgentJobResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Resource Configure Type", fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData))
+		return
+	}
+	r.client = client
+}
+
+func (r *AgentJobResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data AgentJobResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Creating agent job", map[string]interface{}{
+		"name": data.Name.ValueString(),
+	})
+
+	opts := mssql.CreateAgentJobOptions{
+		Name:    data.Name.ValueString(),
+		Enabled: data.Enabled.ValueBool(),
+		Steps:   agentJobSteps(data.Steps),
+	}
+	if !data.OwnerLoginName.IsNull() && !data.OwnerLoginName.IsUnknown() {
+		opts.OwnerLoginName = data.OwnerLoginName.ValueString()
+	}
+
+	job, err := r.client.CreateAgentJob(ctx, opts)
+	if err != nil {
+		summary := "Failed to create agent job"
+		if errors.Is(err, mssql.ErrAgentUnavailable) {
+			summary = "SQL Server Agent not available"
+		}
+		resp.Diagnostics.AddError(summary, errorDetail(err))
+		// The job exists from here on, so it is saved to the state and its
+		// steps are added again on the next apply
+		if job != nil {
+			data.ID = types.StringValue(job.JobID)
+			if data.OwnerLoginName.IsUnknown() {
+				data.OwnerLoginName = types.StringNull()
+			}
+			resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		}
+		return
+	}
+	if job == nil {
+		resp.Diagnostics.AddError("Failed to create agent job", fmt.Sprintf("Agent job '%s' not found after creation", opts.Name))
+		return
+	}
+
+	setAgentJobState(r.client, &data, job)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AgentJobResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data AgentJobResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	job, err := r.client.GetAgentJob(ctx, data.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read agent job", errorDetail(err))
+		return
+	}
+	if job == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	setAgentJobState(r.client, &data, job)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AgentJobResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state AgentJobResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Updating agent job", map[string]interface{}{
+		"id":   data.ID.ValueString(),
+		"name": data.Name.ValueString(),
+	})
+
+	opts := mssql.UpdateAgentJobOptions{
+		JobID:        data.ID.ValueString(),
+		Name:         data.Name.ValueString(),
+		Enabled:      data.Enabled.ValueBool(),
+		ReplaceSteps: !agentJobStepsEqual(r.client, data.Steps, state.Steps),
+		Steps:        agentJobSteps(data.Steps),
+	}
+	if !data.OwnerLoginName.IsNull() && !data.OwnerLoginName.IsUnknown() {
+		opts.OwnerLoginName = data.OwnerLoginName.ValueString()
+	}
+
+	job, err := r.client.UpdateAgentJob(ctx, opts)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to update agent job", errorDetail(err))
+		return
+	}
+	if job == nil {
+		resp.Diagnostics.AddError("Agent job not found", fmt.Sprintf("Agent job '%s' not found", data.Name.ValueString()))
+		return
+	}
+
+	setAgentJobState(r.client, &data, job)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AgentJobResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data AgentJobResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	job, err := r.client.GetAgentJob(ctx, data.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read agent job", errorDetail(err))
+		return
+	}
+	// The job is already gone
+	if job == nil {
+		return
+	}
+
+	tflog.Debug(ctx, "Deleting agent job", map[string]interface{}{
+		"name": job.Name,
+	})
+
+	if err := r.client.DeleteAgentJob(ctx, job.JobID); err != nil {
+		resp.Diagnostics.AddError("Failed to delete agent job", errorDetail(err))
+		return
+	}
+}
+
+func (r *AgentJobResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if err := r.client.CheckAgentAvailable(ctx); err != nil {
+		resp.Diagnostics.AddError("SQL Server Agent not available", errorDetail(err))
+		return
+	}
+
+	job, err := r.client.GetAgentJobByName(ctx, req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to import agent job", errorDetail(err))
+		return
+	}
+	if job == nil {
+		resp.Diagnostics.AddError("Agent job not found", fmt.Sprintf("Agent job '%s' not found", req.ID))
+		return
+	}
+
+	var data AgentJobResourceModel
+	setAgentJobState(r.client, &data, job)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// agentJobSteps converts the configured steps, filling in the defaults of the
+// unset attributes.
+func agentJobSteps(models []AgentJobStepModel) []mssql.AgentJobStep {
+	steps := make([]mssql.AgentJobStep, len(models))
+	for i, model := range models {
+		steps[i] = mssql.AgentJobStep{
+			Name:            model.Name.ValueString(),
+			Command:         model.Command.ValueString(),
+			DatabaseName:    model.DatabaseName.ValueString(),
+			OnSuccessAction: model.OnSuccess.ValueString(),
+			OnFailAction:    model.OnFail.ValueString(),
+		}
+		defaults := agentJobStepDefaults(i, len(models))
+		if model.DatabaseName.IsNull() {
+			steps[i].DatabaseName = defaults.DatabaseName
+		}
+		if model.OnSuccess.IsNull() {
+			steps[i].OnSuccessAction = defaults.OnSuccessAction
+		}
+		if model.OnFail.IsNull() {
+			steps[i].OnFailAction = defaults.OnFailAction
+		}
+	}
+	return steps
+}
+
+// agentJobStepDefaults returns the values used for the unset attributes of
+// the step at index i. Steps go on to the next step on success, so that all
+// steps of a job run, and the last step ends the job.
+func agentJobStepDefaults(i, count int) mssql.AgentJobStep {
+	defaults := mssql.AgentJobStep{
+		DatabaseName:    "master",
+		OnSuccessAction: mssql.AgentStepActionGoToNextStep,
+		OnFailAction:    mssql.AgentStepActionQuitWithFailure,
+	}
+	if i == count-1 {
+		defaults.OnSuccessAction = mssql.AgentStepActionQuitWithSuccess
+	}
+	return defaults
+}
+
+// agentJobStepsEqual reports whether two lists of steps result in the same
+// steps on the server.
+func agentJobStepsEqual(client *mssql.Client, a, b []AgentJobStepModel) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	stepsA, stepsB := agentJobSteps(a), agentJobSteps(b)
+	for i := range stepsA {
+		if stepsA[i].Name != stepsB[i].Name ||
+			stepsA[i].Command != stepsB[i].Command ||
+			!client.NamesEqual(stepsA[i].DatabaseName, stepsB[i].DatabaseName) ||
+			!strings.EqualFold(stepsA[i].OnSuccessAction, stepsB[i].OnSuccessAction) ||
+			!strings.EqualFold(stepsA[i].OnFailAction, stepsB[i].OnFailAction) {
+			return false
+		}
+	}
+	return true
+}
+
+// setAgentJobState copies the server values into the model. Step attributes
+// that are unset and match their default stay unset, and the configured
+// spelling of case-insensitive values is kept.
+func setAgentJobState(client *mssql.Client, data *AgentJobResourceModel, job *mssql.AgentJob) {
+	data.ID = types.StringValue(job.JobID)
+	data.Name = types.StringValue(job.Name)
+	data.Enabled = types.BoolValue(job.Enabled)
+	if !client.NamesEqual(data.OwnerLoginName.ValueString(), job.OwnerLoginName) {
+		data.OwnerLoginName = types.StringValue(job.OwnerLoginName)
+	}
+
+	steps := make([]AgentJobStepModel, len(job.Steps))
+	for i, step := range job.Steps {
+		var current AgentJobStepModel
+		if i < len(data.Steps) {
+			current = data.Steps[i]
+		}
+		defaults := agentJobStepDefaults(i, len(job.Steps))
+		steps[i] = AgentJobStepModel{
+			Name:         types.StringValue(step.Name),
+			Command:      types.StringValue(step.Command),
+			DatabaseName: agentJobStepValue(current.DatabaseName, step.DatabaseName, defaults.DatabaseName, client.NamesEqual),
+			OnSuccess:    agentJobStepValue(current.OnSuccess, step.OnSuccessAction, defaults.OnSuccessAction, strings.EqualFold),
+			OnFail:       agentJobStepValue(current.OnFail, step.OnFailAction, defaults.OnFailAction, strings.EqualFold),
+		}
+	}
+	data.Steps = steps
+}
+
+// agentJobStepValue returns the state of a step attribute: null if it is
+// unset and the server has the default, the current value if it matches the
+// server by equal, and the server value otherwise.
+func agentJobStepValue(current types.String, actual, defaultValue string, equal func(a, b string) bool) types.String {
+	if current.IsNull() && equal(actual, defaultValue) {
+		return types.StringNull()
+	}
+	if equal(current.ValueString(), actual) {
+		return current
+	}
+	return types.StringValue(actual)
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/muecahit94/terraform-provider-mssql/internal/mssql"
+)
+
+var _ resource.Resource = &AgentJobScheduleResource{}
+var _ resource.ResourceWithImportState = &AgentJobScheduleResource{}
+
+func NewAgentJobScheduleResource() resource.Resource {
+	return &AgentJobScheduleResource{}
+}
+
+// AgentJobScheduleResource attaches an Agent schedule to an Agent job. A
+// schedule can be attached to several jobs.
+type AgentJobScheduleResource struct {
+	client *mssql.Client
+}
+
+type AgentJobScheduleResourceModel struct {
+	ID         types.String `tfsdk:"id"`
+	JobID      types.String `tfsdk:"job_id"`
+	ScheduleID types.String `tfsdk:"schedule_id"`
+}
+
+func (r *AgentJobScheduleResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_agent_job_schedule"
+}
+
+func (r *AgentJobScheduleResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Attaches a SQL Server Agent schedule to an Agent job.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"job_id": schema.StringAttribute{
+				Description: "The ID of the job, e.g. mssql_agent_job.example.id.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"schedule_id": schema.StringAttribute{
+				Description: "The ID of the schedule, e.g. mssql_agent_schedule.example.id.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+	}
+}
+
+func (r *AgentJobScheduleResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Resource Configure Type", fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData))
+		return
+	}
+	r.client = client
+}
+
+func (r *AgentJobScheduleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data AgentJobScheduleResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	scheduleID := agentScheduleIDOf(data.ScheduleID, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Attaching agent schedule", map[string]interface{}{
+		"job_id":      data.JobID.ValueString(),
+		"schedule_id": scheduleID,
+	})
+
+	if err := r.client.AttachAgentSchedule(ctx, data.JobID.ValueString(), scheduleID); err != nil {
+		resp.Diagnostics.AddError("Failed to attach agent schedule", errorDetail(err))
+		return
+	}
+
+	data.ID = types.StringValue(fmt.Sprintf("%s/%d", data.JobID.ValueString(), scheduleID))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AgentJobScheduleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data AgentJobScheduleResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	scheduleID := agentScheduleIDOf(data.ScheduleID, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	attached, err := r.client.AgentJobHasSchedule(ctx, data.JobID.ValueString(), scheduleID)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read agent job schedule", errorDetail(err))
+		return
+	}
+	if !attached {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AgentJobScheduleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.AddError("Update Not Supported", "Agent job schedules do not support updates.")
+}
+
+func (r *AgentJobScheduleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data AgentJobScheduleResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	scheduleID := agentScheduleIDOf(data.ScheduleID, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The job or the schedule is already gone
+	attached, err := r.client.AgentJobHasSchedule(ctx, data.JobID.ValueString(), scheduleID)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read agent job schedule", errorDetail(err))
+		return
+	}
+	if !attached {
+		return
+	}
+
+	if err := r.client.DetachAgentSchedule(ctx, data.JobID.ValueString(), scheduleID); err != nil {
+		resp.Diagnostics.AddError("Failed to detach agent schedule", errorDetail(err))
+		return
+	}
+}
+
+func (r *AgentJobScheduleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts := strings.Split(req.ID, "/")
+	if len(parts) != 2 {
+		resp.Diagnostics.AddError("Invalid import ID", "Import ID must be in format 'job_name/schedule_name'")
+		return
+	}
+
+	job, err := r.client.GetAgentJobByName(ctx, parts[0])
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to import agent job schedule", errorDetail(err))
+		return
+	}
+	if job == nil {
+		resp.Diagnostics.AddError("Agent job not found", fmt.Sprintf("Agent job '%s' not found", parts[0]))
+		return
+	}
+	schedule, err := r.client.GetAgentScheduleByName(ctx, parts[1])
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to import agent job schedule", errorDetail(err))
+		return
+	}
+	if schedule == nil {
+		resp.Diagnostics.AddError("Agent schedule not found", fmt.Sprintf("Agent schedule '%s' not found", parts[1]))
+		return
+	}
+
+	attached, err := r.client.AgentJobHasSchedule(ctx, job.JobID, schedule.ScheduleID)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to import agent job schedule", errorDetail(err))
+		return
+	}
+	if !attached {
+		resp.Diagnostics.AddError("Agent job schedule not found", fmt.Sprintf("Schedule '%s' is not attached to job '%s'", parts[1], parts[0]))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), fmt.Sprintf("%s/%d", job.JobID, schedule.ScheduleID))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("job_id"), job.JobID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("schedule_id"), strconv.Itoa(schedule.ScheduleID))...)
+}
+
+// agentScheduleIDOf parses the ID of a schedule.
+func agentScheduleIDOf(value types.String, diags *diag.Diagnostics) int {
+	scheduleID, err := strconv.Atoi(value.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("schedule_id"), "Invalid agent schedule ID", fmt.Sprintf("Agent schedule ID '%s' is not a number", value.ValueString()))
+	}
+	return scheduleID
+}
@@ -0,0 +1,314 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/muecahit94/terraform-provider-mssql/internal/mssql"
+)
+
+var _ resource.Resource = &AgentScheduleResource{}
+var _ resource.ResourceWithImportState = &AgentScheduleResource{}
+
+func NewAgentScheduleResource() resource.Resource {
+	return &AgentScheduleResource{}
+}
+
+// AgentScheduleResource manages a daily or weekly SQL Server Agent schedule,
+// which is attached to jobs with mssql_agent_job_schedule.
+type AgentScheduleResource struct {
+	client *mssql.Client
+}
+
+type AgentScheduleResourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	Name           types.String `tfsdk:"name"`
+	Enabled        types.Bool   `tfsdk:"enabled"`
+	Frequency      types.String `tfsdk:"frequency"`
+	Interval       types.Int64  `tfsdk:"interval"`
+	Weekdays       types.Set    `tfsdk:"weekdays"`
+	SubdayUnit     types.String `tfsdk:"subday_unit"`
+	SubdayInterval types.Int64  `tfsdk:"subday_interval"`
+	StartTime      types.String `tfsdk:"start_time"`
+	EndTime        types.String `tfsdk:"end_time"`
+}
+
+func (r *AgentScheduleResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_agent_schedule"
+}
+
+func (r *AgentScheduleResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages a daily or weekly SQL Server Agent schedule. Not available in Azure SQL Database.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The schedule ID.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "The name of the schedule.",
+				Required:    true,
+			},
+			"enabled": schema.BoolAttribute{
+				Description: "Whether the schedule is enabled. Defaults to true.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+			},
+			"frequency": schema.StringAttribute{
+				Description: "How often the schedule runs: DAILY or WEEKLY.",
+				Required:    true,
+			},
+			"interval": schema.Int64Attribute{
+				Description: "The number of days between runs of a daily schedule, or of weeks between runs of a weekly schedule. Defaults to 1.",
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(1),
+			},
+			"weekdays": schema.SetAttribute{
+				Description: "The days a weekly schedule runs on, e.g. MONDAY. Required for WEEKLY.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"subday_unit": schema.StringAttribute{
+				Description: "The unit of subday_interval: ONCE, SECONDS, MINUTES or HOURS. ONCE runs once a day at start_time. Defaults to ONCE.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(mssql.AgentScheduleSubdayOnce),
+			},
+			"subday_interval": schema.Int64Attribute{
+				Description: "The number of subday_unit between runs within a day. Defaults to 0, as required for ONCE.",
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(0),
+			},
+			"start_time": schema.StringAttribute{
+				Description: "The time of day the schedule starts, as HH:MM:SS. Defaults to 00:00:00.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("00:00:00"),
+			},
+			"end_time": schema.StringAttribute{
+				Description: "The time of day the schedule stops repeating, as HH:MM:SS. Defaults to 23:59:59.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("23:59:59"),
+			},
+		},
+	}
+}
+
+func (r *AgentScheduleResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Resource Configure Type", fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData))
+		return
+	}
+	r.client = client
+}
+
+func (r *AgentScheduleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data AgentScheduleResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Creating agent schedule", map[string]interface{}{
+		"name":      data.Name.ValueString(),
+		"frequency": data.Frequency.ValueString(),
+	})
+
+	schedule, err := r.client.CreateAgentSchedule(ctx, agentScheduleOf(ctx, &data))
+	if err != nil {
+		summary := "Failed to create agent schedule"
+		if errors.Is(err, mssql.ErrAgentUnavailable) {
+			summary = "SQL Server Agent not available"
+		}
+		resp.Diagnostics.AddError(summary, errorDetail(err))
+		return
+	}
+	if schedule == nil {
+		resp.Diagnostics.AddError("Failed to create agent schedule", fmt.Sprintf("Agent schedule '%s' not found after creation", data.Name.ValueString()))
+		return
+	}
+
+	setAgentScheduleState(ctx, &data, schedule)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AgentScheduleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data AgentScheduleResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	scheduleID, err := strconv.Atoi(data.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid agent schedule ID", fmt.Sprintf("Agent schedule ID '%s' is not a number", data.ID.ValueString()))
+		return
+	}
+	schedule, err := r.client.GetAgentSchedule(ctx, scheduleID)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read agent schedule", errorDetail(err))
+		return
+	}
+	if schedule == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	setAgentScheduleState(ctx, &data, schedule)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AgentScheduleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data AgentScheduleResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	schedule := agentScheduleOf(ctx, &data)
+	scheduleID, err := strconv.Atoi(data.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid agent schedule ID", fmt.Sprintf("Agent schedule ID '%s' is not a number", data.ID.ValueString()))
+		return
+	}
+	schedule.ScheduleID = scheduleID
+
+	updated, err := r.client.UpdateAgentSchedule(ctx, schedule)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to update agent schedule", errorDetail(err))
+		return
+	}
+	if updated == nil {
+		resp.Diagnostics.AddError("Agent schedule not found", fmt.Sprintf("Agent schedule '%s' not found", data.Name.ValueString()))
+		return
+	}
+
+	setAgentScheduleState(ctx, &data, updated)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AgentScheduleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data AgentScheduleResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	scheduleID, err := strconv.Atoi(data.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid agent schedule ID", fmt.Sprintf("Agent schedule ID '%s' is not a number", data.ID.ValueString()))
+		return
+	}
+	schedule, err := r.client.GetAgentSchedule(ctx, scheduleID)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read agent schedule", errorDetail(err))
+		return
+	}
+	// The schedule is already gone
+	if schedule == nil {
+		return
+	}
+
+	tflog.Debug(ctx, "Deleting agent schedule", map[string]interface{}{
+		"name": schedule.Name,
+	})
+
+	if err := r.client.DeleteAgentSchedule(ctx, scheduleID); err != nil {
+		resp.Diagnostics.AddError("Failed to delete agent schedule", errorDetail(err))
+		return
+	}
+}
+
+func (r *AgentScheduleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if err := r.client.CheckAgentAvailable(ctx); err != nil {
+		resp.Diagnostics.AddError("SQL Server Agent not available", errorDetail(err))
+		return
+	}
+
+	schedule, err := r.client.GetAgentScheduleByName(ctx, req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to import agent schedule", errorDetail(err))
+		return
+	}
+	if schedule == nil {
+		resp.Diagnostics.AddError("Agent schedule not found", fmt.Sprintf("Agent schedule '%s' not found", req.ID))
+		return
+	}
+
+	data := AgentScheduleResourceModel{Weekdays: types.SetNull(types.StringType)}
+	setAgentScheduleState(ctx, &data, schedule)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// agentScheduleOf converts the planned schedule.
+func agentScheduleOf(ctx context.Context, data *AgentScheduleResourceModel) mssql.AgentSchedule {
+	var weekdays []string
+	data.Weekdays.ElementsAs(ctx, &weekdays, false)
+
+	return mssql.AgentSchedule{
+		Name:           data.Name.ValueString(),
+		Enabled:        data.Enabled.ValueBool(),
+		Frequency:      data.Frequency.ValueString(),
+		Interval:       int(data.Interval.ValueInt64()),
+		Weekdays:       weekdays,
+		SubdayUnit:     data.SubdayUnit.ValueString(),
+		SubdayInterval: int(data.SubdayInterval.ValueInt64()),
+		StartTime:      data.StartTime.ValueString(),
+		EndTime:        data.EndTime.ValueString(),
+	}
+}
+
+// setAgentScheduleState copies the server values into the model, keeping the
+// configured spelling of values that match case-insensitively.
+func setAgentScheduleState(ctx context.Context, data *AgentScheduleResourceModel, schedule *mssql.AgentSchedule) {
+	data.ID = types.StringValue(strconv.Itoa(schedule.ScheduleID))
+	data.Name = types.StringValue(schedule.Name)
+	data.Enabled = types.BoolValue(schedule.Enabled)
+	if !strings.EqualFold(data.Frequency.ValueString(), schedule.Frequency) {
+		data.Frequency = types.StringValue(schedule.Frequency)
+	}
+	data.Interval = types.Int64Value(int64(schedule.Interval))
+	if !strings.EqualFold(data.SubdayUnit.ValueString(), schedule.SubdayUnit) {
+		data.SubdayUnit = types.StringValue(schedule.SubdayUnit)
+	}
+	data.SubdayInterval = types.Int64Value(int64(schedule.SubdayInterval))
+	data.StartTime = types.StringValue(schedule.StartTime)
+	data.EndTime = types.StringValue(schedule.EndTime)
+
+	// Weekdays only apply to weekly schedules, so they are kept as configured
+	// otherwise
+	if strings.EqualFold(schedule.Frequency, mssql.AgentScheduleFrequencyWeekly) {
+		var configured []string
+		data.Weekdays.ElementsAs(ctx, &configured, false)
+		add, remove := diffNames(configured, schedule.Weekdays, strings.ToUpper)
+		if len(add) > 0 || len(remove) > 0 {
+			data.Weekdays = stringSetValue(schedule.Weekdays)
+		}
+	}
+}
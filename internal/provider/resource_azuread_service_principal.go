@@ -35,6 +35,7 @@ type AzureADServicePrincipalResourceModel struct {
 	Name          types.String `tfsdk:"name"`
 	ClientID      types.String `tfsdk:"client_id"`
 	DefaultSchema types.String `tfsdk:"default_schema"`
+	SID           types.String `tfsdk:"sid"`
 }
 
 func (r *AzureADServicePrincipalResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -76,6 +77,13 @@ func (r *AzureADServicePrincipalResource) Schema(ctx context.Context, req resour
 				Computed: true,
 				Default:  stringdefault.StaticString("dbo"),
 			},
+			"sid": schema.StringAttribute{
+				Description: "The SID of the user as a hex string with 0x prefix, derived from the client ID.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
 		},
 	}
 }
@@ -106,11 +114,12 @@ func (r *AzureADServicePrincipalResource) Create(ctx context.Context, req resour
 		DefaultSchema: data.DefaultSchema.ValueString(),
 	})
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to create Azure AD service principal", err.Error())
+		resp.Diagnostics.AddError("Failed to create Azure AD service principal", errorDetail(err))
 		return
 	}
 
 	data.ID = types.StringValue(fmt.Sprintf("%d/%d", user.DatabaseID, user.PrincipalID))
+	data.SID = types.StringValue(user.SID)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -123,7 +132,7 @@ func (r *AzureADServicePrincipalResource) Read(ctx context.Context, req resource
 
 	user, err := r.client.GetUser(ctx, data.DatabaseName.ValueString(), data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to read Azure AD service principal", err.Error())
+		resp.Diagnostics.AddError("Failed to read Azure AD service principal", errorDetail(err))
 		return
 	}
 	if user == nil {
@@ -132,6 +141,7 @@ func (r *AzureADServicePrincipalResource) Read(ctx context.Context, req resource
 	}
 
 	data.DefaultSchema = types.StringValue(user.DefaultSchemaName)
+	data.SID = types.StringValue(user.SID)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -151,7 +161,7 @@ func (r *AzureADServicePrincipalResource) Update(ctx context.Context, req resour
 			DefaultSchema: &schema,
 		})
 		if err != nil {
-			resp.Diagnostics.AddError("Failed to update Azure AD service principal", err.Error())
+			resp.Diagnostics.AddError("Failed to update Azure AD service principal", errorDetail(err))
 			return
 		}
 	}
@@ -168,7 +178,7 @@ func (r *AzureADServicePrincipalResource) Delete(ctx context.Context, req resour
 
 	err := r.client.DropUser(ctx, data.DatabaseName.ValueString(), data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to delete Azure AD service principal", err.Error())
+		resp.Diagnostics.AddError("Failed to delete Azure AD service principal", errorDetail(err))
 		return
 	}
 }
@@ -182,7 +192,7 @@ func (r *AzureADServicePrincipalResource) ImportState(ctx context.Context, req r
 
 	user, err := r.client.GetUser(ctx, parts[0], parts[1])
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to import Azure AD service principal", err.Error())
+		resp.Diagnostics.AddError("Failed to import Azure AD service principal", errorDetail(err))
 		return
 	}
 	if user == nil {
@@ -195,4 +205,5 @@ func (r *AzureADServicePrincipalResource) ImportState(ctx context.Context, req r
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), user.Name)...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("client_id"), "")...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("default_schema"), user.DefaultSchemaName)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("sid"), user.SID)...)
 }
@@ -6,6 +6,7 @@ package provider
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"sort"
 	"strings"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
@@ -23,7 +25,9 @@ import (
 
 var _ resource.Resource = &AzureADUserResource{}
 var _ resource.ResourceWithImportState = &AzureADUserResource{}
+var _ resource.ResourceWithModifyPlan = &AzureADUserResource{}
 var _ resource.ResourceWithMoveState = &AzureADUserResource{}
+var _ resource.ResourceWithValidateConfig = &AzureADUserResource{}
 
 func NewAzureADUserResource() resource.Resource {
 	return &AzureADUserResource{}
@@ -38,8 +42,10 @@ type AzureADUserResourceModel struct {
 	DatabaseName  types.String `tfsdk:"database_name"`
 	Name          types.String `tfsdk:"name"`
 	ObjectID      types.String `tfsdk:"object_id"`
+	IsGroup       types.Bool   `tfsdk:"is_group"`
 	DefaultSchema types.String `tfsdk:"default_schema"`
 	Roles         types.Set    `tfsdk:"roles"`
+	SID           types.String `tfsdk:"sid"`
 }
 
 func (r *AzureADUserResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -78,6 +84,15 @@ func (r *AzureADUserResource) Schema(ctx context.Context, req resource.SchemaReq
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"is_group": schema.BoolAttribute{
+				Description: "Whether the principal is an Azure AD group. Together with object_id, the group is created by its SID without a directory lookup. Read from the server when not set. Changing this forces a new resource.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+					boolplanmodifier.RequiresReplaceIfConfigured(),
+				},
+			},
 			"default_schema": schema.StringAttribute{
 				Optional: true,
 				Computed: true,
@@ -89,6 +104,13 @@ func (r *AzureADUserResource) Schema(ctx context.Context, req resource.SchemaReq
 				Computed:    true,
 				ElementType: types.StringType,
 			},
+			"sid": schema.StringAttribute{
+				Description: "The SID of the user as a hex string with 0x prefix, derived from the Azure AD object ID or client ID.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
 		},
 	}
 }
@@ -105,6 +127,26 @@ func (r *AzureADUserResource) Configure(ctx context.Context, req resource.Config
 	r.client = client
 }
 
+// ValidateConfig checks the object ID before planning, since it is converted
+// to the SID of the user without asking Azure AD.
+func (r *AzureADUserResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data AzureADUserResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.ObjectID.IsNull() || data.ObjectID.IsUnknown() || data.ObjectID.ValueString() == "" {
+		return
+	}
+
+	if !objectIDPattern.MatchString(data.ObjectID.ValueString()) {
+		resp.Diagnostics.AddAttributeError(path.Root("object_id"), "Invalid object ID",
+			fmt.Sprintf("object_id must be a GUID, e.g. 00000000-0000-0000-0000-000000000000, got: %s", data.ObjectID.ValueString()))
+	}
+}
+
+// ModifyPlan lists the roles to be changed in the plan.
+func (r *AzureADUserResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	previewSetChanges(ctx, req, resp, r.client.NameKey, "roles")
+}
+
 func (r *AzureADUserResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data AzureADUserResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -114,16 +156,18 @@ func (r *AzureADUserResource) Create(ctx context.Context, req resource.CreateReq
 
 	objectID := data.ObjectID.ValueString()
 
-	_, err := r.client.CreateAzureADUser(ctx, mssql.CreateAzureADUserOptions{
+	user, err := r.client.CreateAzureADUser(ctx, mssql.CreateAzureADUserOptions{
 		DatabaseName:  data.DatabaseName.ValueString(),
 		UserName:      data.Name.ValueString(),
 		ObjectID:      objectID,
 		DefaultSchema: data.DefaultSchema.ValueString(),
+		Group:         data.IsGroup.ValueBool(),
 	})
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to create Azure AD user", err.Error())
+		resp.Diagnostics.AddError("Failed to create Azure AD user", errorDetail(err))
 		return
 	}
+	warnMissingDefaultSchema(ctx, r.client, data.DatabaseName.ValueString(), data.DefaultSchema.ValueString(), &resp.Diagnostics)
 
 	// Assign roles if specified
 	var roles []string
@@ -143,6 +187,8 @@ func (r *AzureADUserResource) Create(ctx context.Context, req resource.CreateReq
 
 	data.ID = types.StringValue(fmt.Sprintf("sqlserver://%s:%d/%s/%s", r.client.Hostname(), r.client.Port(), data.DatabaseName.ValueString(), data.Name.ValueString()))
 	data.ObjectID = types.StringValue(objectID)
+	data.IsGroup = types.BoolValue(user.Type == "X")
+	data.SID = types.StringValue(user.SID)
 
 	// Set roles in state
 	if len(roles) > 0 {
@@ -167,7 +213,7 @@ func (r *AzureADUserResource) Read(ctx context.Context, req resource.ReadRequest
 
 	user, err := r.client.GetUser(ctx, data.DatabaseName.ValueString(), data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to read Azure AD user", err.Error())
+		resp.Diagnostics.AddError("Failed to read Azure AD user", errorDetail(err))
 		return
 	}
 	if user == nil {
@@ -178,11 +224,13 @@ func (r *AzureADUserResource) Read(ctx context.Context, req resource.ReadRequest
 	// Update ID with proper URL format
 	data.ID = types.StringValue(fmt.Sprintf("sqlserver://%s:%d/%s/%s", r.client.Hostname(), r.client.Port(), data.DatabaseName.ValueString(), data.Name.ValueString()))
 	data.DefaultSchema = types.StringValue(user.DefaultSchemaName)
+	data.IsGroup = types.BoolValue(user.Type == "X")
+	data.SID = types.StringValue(user.SID)
 
 	// Read user's roles
 	roles, err := r.client.GetUserRoles(ctx, data.DatabaseName.ValueString(), data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to read user roles", err.Error())
+		resp.Diagnostics.AddError("Failed to read user roles", errorDetail(err))
 		return
 	}
 	roleValues := make([]attr.Value, len(roles))
@@ -210,7 +258,7 @@ func (r *AzureADUserResource) Update(ctx context.Context, req resource.UpdateReq
 			DefaultSchema: &schema,
 		})
 		if err != nil {
-			resp.Diagnostics.AddError("Failed to update Azure AD user", err.Error())
+			resp.Diagnostics.AddError("Failed to update Azure AD user", errorDetail(err))
 			return
 		}
 	}
@@ -248,6 +296,9 @@ func (r *AzureADUserResource) Update(ctx context.Context, req resource.UpdateReq
 		// Remove old roles
 		for _, role := range currentRoles {
 			if !desiredSet[role] {
+				if !checkDbOwnerRemoval(ctx, r.client, data.DatabaseName.ValueString(), role, data.Name.ValueString(), &resp.Diagnostics) {
+					return
+				}
 				err := r.client.RemoveDatabaseRoleMember(ctx, data.DatabaseName.ValueString(), role, data.Name.ValueString())
 				if err != nil {
 					resp.Diagnostics.AddError("Failed to remove role", fmt.Sprintf("Failed to remove user from role '%s': %s", role, err.Error()))
@@ -277,7 +328,7 @@ func (r *AzureADUserResource) Delete(ctx context.Context, req resource.DeleteReq
 
 	err := r.client.DropUser(ctx, data.DatabaseName.ValueString(), data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to delete Azure AD user", err.Error())
+		resp.Diagnostics.AddError("Failed to delete Azure AD user", errorDetail(err))
 		return
 	}
 }
@@ -291,7 +342,7 @@ func (r *AzureADUserResource) ImportState(ctx context.Context, req resource.Impo
 
 	user, err := r.client.GetUser(ctx, parts[0], parts[1])
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to import Azure AD user", err.Error())
+		resp.Diagnostics.AddError("Failed to import Azure AD user", errorDetail(err))
 		return
 	}
 	if user == nil {
@@ -305,7 +356,9 @@ func (r *AzureADUserResource) ImportState(ctx context.Context, req resource.Impo
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("database_name"), parts[0])...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), user.Name)...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("object_id"), "")...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("is_group"), user.Type == "X")...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("default_schema"), user.DefaultSchemaName)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("sid"), user.SID)...)
 }
 
 // MoveState implements resource.ResourceWithMoveState.
@@ -390,7 +443,7 @@ func (r *AzureADUserResource) MoveState(ctx context.Context) []resource.StateMov
 				if err != nil {
 					resp.Diagnostics.AddError(
 						"Unable to Unmarshal Source State",
-						err.Error(),
+						errorDetail(err),
 					)
 					return
 				}
@@ -399,7 +452,7 @@ func (r *AzureADUserResource) MoveState(ctx context.Context) []resource.StateMov
 				if err := rawStateValue.As(&rawState); err != nil {
 					resp.Diagnostics.AddError(
 						"Unable to Convert Source State",
-						err.Error(),
+						errorDetail(err),
 					)
 					return
 				}
@@ -483,7 +536,9 @@ func (r *AzureADUserResource) MoveState(ctx context.Context) []resource.StateMov
 					Name:          types.StringPointerValue(username),
 					ObjectID:      objectIDValue,
 					DefaultSchema: types.StringPointerValue(defaultSchema),
+					IsGroup:       types.BoolValue(false),
 					Roles:         rolesSet,
+					SID:           types.StringNull(),
 				}
 
 				resp.Diagnostics.Append(resp.TargetState.Set(ctx, targetStateData)...)
@@ -491,3 +546,6 @@ func (r *AzureADUserResource) MoveState(ctx context.Context) []resource.StateMov
 		},
 	}
 }
+
+// objectIDPattern matches an Azure AD object ID in GUID format.
+var objectIDPattern = regexp.MustCompile(`^[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}$`)
@@ -7,11 +7,16 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -34,10 +39,31 @@ type DatabaseResource struct {
 
 // DatabaseResourceModel describes the resource data model.
 type DatabaseResourceModel struct {
-	ID   types.String `tfsdk:"id"`
-	Name types.String `tfsdk:"name"`
+	ID               types.String `tfsdk:"id"`
+	Name             types.String `tfsdk:"name"`
+	AdoptExisting    types.Bool   `tfsdk:"adopt_existing"`
+	Filegroups       types.Set    `tfsdk:"filegroups"`
+	DefaultFilegroup types.String `tfsdk:"default_filegroup"`
+
+	AutoClose            types.Bool `tfsdk:"auto_close"`
+	AutoShrink           types.Bool `tfsdk:"auto_shrink"`
+	AutoCreateStatistics types.Bool `tfsdk:"auto_create_statistics"`
+	AutoUpdateStatistics types.Bool `tfsdk:"auto_update_statistics"`
+
+	SourceDatabase types.String  `tfsdk:"source_database"`
+	Timeouts       *timeoutModel `tfsdk:"timeouts"`
 }
 
+// timeoutModel describes the timeouts block of a resource whose creation
+// can take long, e.g. copying a database.
+type timeoutModel struct {
+	Create types.String `tfsdk:"create"`
+}
+
+// defaultDatabaseCopyTimeout bounds how long creating a database as a copy
+// waits for the copy unless timeouts.create is set.
+const defaultDatabaseCopyTimeout = 60 * time.Minute
+
 // Metadata returns the resource type name.
 func (r *DatabaseResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_database"
@@ -56,12 +82,81 @@ func (r *DatabaseResource) Schema(ctx context.Context, req resource.SchemaReques
 				},
 			},
 			"name": schema.StringAttribute{
-				Description: "The name of the database.",
+				Description: "The name of the database. Changing this renames the database in place.",
 				Required:    true,
+			},
+			"source_database": schema.StringAttribute{
+				Description: "The database to create the database as a copy of with CREATE DATABASE ... AS COPY OF. Only supported in Azure SQL Database. Changing this forces a new resource.",
+				Optional:    true,
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
+			"adopt_existing": schema.BoolAttribute{
+				Description: "Whether to adopt an existing database with the same name into the Terraform state on create instead of creating it.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"filegroups": schema.SetAttribute{
+				Description: "Additional filegroups of the database. Each filegroup is created with one data file next to the primary data file. Changing this forces a new resource.",
+				Optional:    true,
+				ElementType: types.StringType,
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.RequiresReplace(),
+				},
+			},
+			"default_filegroup": schema.StringAttribute{
+				Description: "The filegroup new tables and indexes are created in. Defaults to the current default filegroup, usually PRIMARY.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"auto_close": schema.BoolAttribute{
+				Description: "Whether the database is shut down after the last user disconnects (AUTO_CLOSE). Defaults to the current setting.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"auto_shrink": schema.BoolAttribute{
+				Description: "Whether the database files are shrunk periodically (AUTO_SHRINK). Defaults to the current setting.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"auto_create_statistics": schema.BoolAttribute{
+				Description: "Whether missing statistics are created for query optimization (AUTO_CREATE_STATISTICS). Defaults to the current setting.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"auto_update_statistics": schema.BoolAttribute{
+				Description: "Whether out-of-date statistics are updated for query optimization (AUTO_UPDATE_STATISTICS). Defaults to the current setting.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": schema.SingleNestedBlock{
+				Description: "Timeouts for creating the database.",
+				Attributes: map[string]schema.Attribute{
+					"create": schema.StringAttribute{
+						Description: "How long to wait for a copy of source_database, as a duration such as '90m'. Defaults to 60m.",
+						Optional:    true,
+					},
+				},
+			},
 		},
 	}
 }
@@ -97,15 +192,74 @@ func (r *DatabaseResource) Create(ctx context.Context, req resource.CreateReques
 		"name": data.Name.ValueString(),
 	})
 
-	db, err := r.client.CreateDatabase(ctx, data.Name.ValueString())
+	if data.AdoptExisting.ValueBool() {
+		existing, err := r.client.GetDatabase(ctx, data.Name.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to read existing database", errorDetail(err))
+			return
+		}
+		if existing != nil {
+			tflog.Info(ctx, "Adopting existing database", map[string]interface{}{"name": existing.Name})
+			data.ID = types.StringValue(strconv.Itoa(existing.ID))
+			data.Name = types.StringValue(existing.Name)
+			r.applyFilegroups(ctx, &data, &resp.Diagnostics)
+			r.applyOptions(ctx, &data, &resp.Diagnostics)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+			resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+			return
+		}
+	}
+
+	var db *mssql.Database
+	var err error
+	if !data.SourceDatabase.IsNull() {
+		db, err = r.copyDatabase(ctx, &data)
+		// A copy that has started but not completed is saved to the state,
+		// so that Terraform replaces it on the next apply
+		if err != nil && db != nil {
+			resp.Diagnostics.AddError("Failed to copy database", errorDetail(err))
+			data.ID = types.StringValue(strconv.Itoa(db.ID))
+			if data.DefaultFilegroup.IsUnknown() {
+				data.DefaultFilegroup = types.StringNull()
+			}
+			for _, option := range databaseOptionAttributes(&data) {
+				if option.value.IsUnknown() {
+					*option.value = types.BoolNull()
+				}
+			}
+			resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+			return
+		}
+	} else {
+		db, err = r.client.CreateDatabase(ctx, data.Name.ValueString())
+	}
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to create database", err.Error())
+		resp.Diagnostics.AddError("Failed to create database", errorDetail(err))
 		return
 	}
 
 	data.ID = types.StringValue(strconv.Itoa(db.ID))
 	data.Name = types.StringValue(db.Name)
 
+	// The database exists from here on, so it is saved to the state even if
+	// its filegroups or options fail
+	r.applyFilegroups(ctx, &data, &resp.Diagnostics)
+	r.applyOptions(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		if data.DefaultFilegroup.IsUnknown() {
+			data.DefaultFilegroup = types.StringNull()
+		}
+		for _, option := range databaseOptionAttributes(&data) {
+			if option.value.IsUnknown() {
+				*option.value = types.BoolNull()
+			}
+		}
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
 	tflog.Debug(ctx, "Created database", map[string]interface{}{
 		"id":   data.ID.ValueString(),
 		"name": data.Name.ValueString(),
@@ -114,6 +268,29 @@ func (r *DatabaseResource) Create(ctx context.Context, req resource.CreateReques
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
+// copyDatabase creates the database as a copy of source_database and waits
+// for the copy within timeouts.create.
+func (r *DatabaseResource) copyDatabase(ctx context.Context, data *DatabaseResourceModel) (*mssql.Database, error) {
+	timeout := defaultDatabaseCopyTimeout
+	if data.Timeouts != nil && !data.Timeouts.Create.IsNull() {
+		parsed, err := time.ParseDuration(data.Timeouts.Create.ValueString())
+		if err != nil {
+			return nil, fmt.Errorf("invalid create timeout '%s': %w", data.Timeouts.Create.ValueString(), err)
+		}
+		timeout = parsed
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	tflog.Debug(ctx, "Copying database", map[string]interface{}{
+		"name":    data.Name.ValueString(),
+		"source":  data.SourceDatabase.ValueString(),
+		"timeout": timeout.String(),
+	})
+
+	return r.client.CopyDatabase(ctx, data.Name.ValueString(), data.SourceDatabase.ValueString())
+}
+
 // Read refreshes the Terraform state with the latest data.
 func (r *DatabaseResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	var data DatabaseResourceModel
@@ -131,7 +308,7 @@ func (r *DatabaseResource) Read(ctx context.Context, req resource.ReadRequest, r
 	if parseErr == nil {
 		db, err = r.client.GetDatabaseByID(ctx, id)
 		if err != nil {
-			resp.Diagnostics.AddError("Failed to read database", err.Error())
+			resp.Diagnostics.AddError("Failed to read database", errorDetail(err))
 			return
 		}
 	}
@@ -140,7 +317,7 @@ func (r *DatabaseResource) Read(ctx context.Context, req resource.ReadRequest, r
 	if db == nil && !data.Name.IsNull() {
 		db, err = r.client.GetDatabase(ctx, data.Name.ValueString())
 		if err != nil {
-			resp.Diagnostics.AddError("Failed to read database", err.Error())
+			resp.Diagnostics.AddError("Failed to read database", errorDetail(err))
 			return
 		}
 	}
@@ -155,16 +332,72 @@ func (r *DatabaseResource) Read(ctx context.Context, req resource.ReadRequest, r
 	data.ID = types.StringValue(strconv.Itoa(db.ID))
 	data.Name = types.StringValue(db.Name)
 
+	filegroups, err := r.client.ListFilegroups(ctx, db.Name)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read filegroups", errorDetail(err))
+		return
+	}
+	// Filegroup names are usually case-insensitive, so the configured spelling is kept
+	if defaultFilegroup := defaultFilegroupValue(filegroups); !r.client.NamesEqual(defaultFilegroup.ValueString(), data.DefaultFilegroup.ValueString()) {
+		data.DefaultFilegroup = defaultFilegroup
+	}
+	// Only the configured filegroups are tracked, so that filegroups added
+	// outside of Terraform don't plan a replacement of the database
+	if !data.Filegroups.IsNull() {
+		var configured, found []string
+		resp.Diagnostics.Append(data.Filegroups.ElementsAs(ctx, &configured, false)...)
+		for _, name := range configured {
+			if r.findFilegroup(filegroups, name) != nil {
+				found = append(found, name)
+			}
+		}
+		data.Filegroups = stringSetValue(found)
+	}
+
+	options, err := r.client.GetDatabaseOptions(ctx, db.Name)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read database options", errorDetail(err))
+		return
+	}
+	if options != nil {
+		for _, option := range databaseOptionAttributes(&data) {
+			*option.value = types.BoolValue(option.current(options))
+		}
+	}
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 // Update updates the resource and sets the updated Terraform state on success.
 func (r *DatabaseResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// Database name changes require replacement, so this should not be called
-	resp.Diagnostics.AddError(
-		"Update Not Supported",
-		"Database resources do not support updates. Changes to the name require replacement.",
-	)
+	// Filegroup changes require replacement, so only the name, adopt_existing,
+	// the default filegroup and the options can change here
+	var data, state DatabaseResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.Name.ValueString() != state.Name.ValueString() {
+		r.rename(ctx, &state, data.Name.ValueString(), &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		data.ID = state.ID
+	}
+
+	r.applyFilegroups(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	r.applyOptions(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 // Delete deletes the resource and removes the Terraform state on success.
@@ -182,7 +415,7 @@ func (r *DatabaseResource) Delete(ctx context.Context, req resource.DeleteReques
 
 	err := r.client.DropDatabase(ctx, data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to delete database", err.Error())
+		resp.Diagnostics.AddError("Failed to delete database", errorDetail(err))
 		return
 	}
 
@@ -191,12 +424,39 @@ func (r *DatabaseResource) Delete(ctx context.Context, req resource.DeleteReques
 	})
 }
 
+// rename renames the database in state, found by its ID, to newName.
+func (r *DatabaseResource) rename(ctx context.Context, state *DatabaseResourceModel, newName string, diags *diag.Diagnostics) {
+	id, err := strconv.Atoi(state.ID.ValueString())
+	if err != nil {
+		diags.AddError("Failed to rename database", fmt.Sprintf("Invalid database ID '%s': %s", state.ID.ValueString(), err))
+		return
+	}
+
+	tflog.Debug(ctx, "Renaming database", map[string]interface{}{
+		"name":     state.Name.ValueString(),
+		"new_name": newName,
+	})
+
+	db, err := r.client.RenameDatabase(ctx, id, newName)
+	if err != nil {
+		diags.AddError("Failed to rename database", errorDetail(err))
+		return
+	}
+	if db == nil {
+		diags.AddError("Database not found", fmt.Sprintf("Database '%s' not found", state.Name.ValueString()))
+		return
+	}
+
+	state.ID = types.StringValue(strconv.Itoa(db.ID))
+	state.Name = types.StringValue(db.Name)
+}
+
 // ImportState imports an existing resource into Terraform.
 func (r *DatabaseResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	// Import by name
 	db, err := r.client.GetDatabase(ctx, req.ID)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to import database", err.Error())
+		resp.Diagnostics.AddError("Failed to import database", errorDetail(err))
 		return
 	}
 
@@ -207,4 +467,133 @@ func (r *DatabaseResource) ImportState(ctx context.Context, req resource.ImportS
 
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), strconv.Itoa(db.ID))...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), db.Name)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("adopt_existing"), false)...)
+}
+
+// applyFilegroups creates the configured filegroups that don't exist yet and
+// sets the default filegroup. The default filegroup read from the server is
+// stored in data.
+func (r *DatabaseResource) applyFilegroups(ctx context.Context, data *DatabaseResourceModel, diags *diag.Diagnostics) {
+	databaseName := data.Name.ValueString()
+	filegroups, err := r.client.ListFilegroups(ctx, databaseName)
+	if err != nil {
+		diags.AddError("Failed to read filegroups", errorDetail(err))
+		return
+	}
+
+	var names []string
+	if !data.Filegroups.IsNull() && !data.Filegroups.IsUnknown() {
+		diags.Append(data.Filegroups.ElementsAs(ctx, &names, false)...)
+	}
+	for _, name := range names {
+		if r.findFilegroup(filegroups, name) != nil {
+			continue
+		}
+		tflog.Debug(ctx, "Adding filegroup", map[string]interface{}{
+			"database":  databaseName,
+			"filegroup": name,
+		})
+		if err := r.client.AddFilegroup(ctx, databaseName, name); err != nil {
+			diags.AddError("Failed to add filegroup", errorDetail(err))
+			return
+		}
+		filegroups = append(filegroups, mssql.Filegroup{Name: name})
+	}
+
+	if !data.DefaultFilegroup.IsNull() && !data.DefaultFilegroup.IsUnknown() {
+		filegroup := r.findFilegroup(filegroups, data.DefaultFilegroup.ValueString())
+		if filegroup == nil {
+			diags.AddAttributeError(path.Root("default_filegroup"), "Filegroup not found",
+				fmt.Sprintf("Filegroup '%s' does not exist in database '%s'. Add it to filegroups.", data.DefaultFilegroup.ValueString(), databaseName))
+			return
+		}
+		// Setting the default again fails, so it is only set if it changes
+		if !filegroup.IsDefault {
+			if err := r.client.SetDefaultFilegroup(ctx, databaseName, filegroup.Name); err != nil {
+				diags.AddError("Failed to set default filegroup", errorDetail(err))
+				return
+			}
+		}
+		return
+	}
+
+	data.DefaultFilegroup = defaultFilegroupValue(filegroups)
+}
+
+// databaseOption ties an option set with ALTER DATABASE SET to its attribute
+// and its value in mssql.DatabaseOptions.
+type databaseOption struct {
+	name    string
+	value   *types.Bool
+	current func(*mssql.DatabaseOptions) bool
+}
+
+// databaseOptionAttributes returns the options of the database resource.
+func databaseOptionAttributes(data *DatabaseResourceModel) []databaseOption {
+	return []databaseOption{
+		{mssql.DatabaseOptionAutoClose, &data.AutoClose, func(o *mssql.DatabaseOptions) bool { return o.AutoClose }},
+		{mssql.DatabaseOptionAutoShrink, &data.AutoShrink, func(o *mssql.DatabaseOptions) bool { return o.AutoShrink }},
+		{mssql.DatabaseOptionAutoCreateStatistics, &data.AutoCreateStatistics, func(o *mssql.DatabaseOptions) bool { return o.AutoCreateStatistics }},
+		{mssql.DatabaseOptionAutoUpdateStatistics, &data.AutoUpdateStatistics, func(o *mssql.DatabaseOptions) bool { return o.AutoUpdateStatistics }},
+	}
+}
+
+// applyOptions sets the configured options that differ from the server. The
+// options left unset are read from the server and stored in data.
+func (r *DatabaseResource) applyOptions(ctx context.Context, data *DatabaseResourceModel, diags *diag.Diagnostics) {
+	if diags.HasError() {
+		return
+	}
+
+	databaseName := data.Name.ValueString()
+	options, err := r.client.GetDatabaseOptions(ctx, databaseName)
+	if err != nil {
+		diags.AddError("Failed to read database options", errorDetail(err))
+		return
+	}
+	if options == nil {
+		diags.AddError("Database not found", fmt.Sprintf("Database '%s' not found", databaseName))
+		return
+	}
+
+	for _, option := range databaseOptionAttributes(data) {
+		current := option.current(options)
+		if option.value.IsUnknown() || option.value.IsNull() {
+			*option.value = types.BoolValue(current)
+			continue
+		}
+		if option.value.ValueBool() == current {
+			continue
+		}
+		tflog.Debug(ctx, "Setting database option", map[string]interface{}{
+			"database": databaseName,
+			"option":   option.name,
+			"on":       option.value.ValueBool(),
+		})
+		if err := r.client.SetDatabaseOption(ctx, databaseName, option.name, option.value.ValueBool()); err != nil {
+			diags.AddError("Failed to set database option", errorDetail(err))
+			return
+		}
+	}
+}
+
+// findFilegroup looks a filegroup up by name, compared like the server
+// compares names.
+func (r *DatabaseResource) findFilegroup(filegroups []mssql.Filegroup, name string) *mssql.Filegroup {
+	for i := range filegroups {
+		if r.client.NamesEqual(filegroups[i].Name, name) {
+			return &filegroups[i]
+		}
+	}
+	return nil
+}
+
+// defaultFilegroupValue returns the name of the default filegroup.
+func defaultFilegroupValue(filegroups []mssql.Filegroup) types.String {
+	for _, filegroup := range filegroups {
+		if filegroup.IsDefault {
+			return types.StringValue(filegroup.Name)
+		}
+	}
+	return types.StringNull()
 }
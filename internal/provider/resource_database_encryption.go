@@ -0,0 +1,227 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/muecahit94/terraform-provider-mssql/internal/mssql"
+)
+
+var _ resource.Resource = &DatabaseEncryptionResource{}
+var _ resource.ResourceWithImportState = &DatabaseEncryptionResource{}
+
+func NewDatabaseEncryptionResource() resource.Resource {
+	return &DatabaseEncryptionResource{}
+}
+
+type DatabaseEncryptionResource struct {
+	client *mssql.Client
+}
+
+type DatabaseEncryptionResourceModel struct {
+	ID              types.String `tfsdk:"id"`
+	DatabaseName    types.String `tfsdk:"database_name"`
+	Enabled         types.Bool   `tfsdk:"enabled"`
+	Encryptor       types.String `tfsdk:"encryptor"`
+	EncryptionState types.String `tfsdk:"encryption_state"`
+	ServiceManaged  types.Bool   `tfsdk:"service_managed"`
+}
+
+func (r *DatabaseEncryptionResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_database_encryption"
+}
+
+func (r *DatabaseEncryptionResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages Transparent Data Encryption (TDE) of a database.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The name of the database.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"database_name": schema.StringAttribute{
+				Description: "The name of the database.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"enabled": schema.BoolAttribute{
+				Description: "Whether TDE is turned on.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+			},
+			"encryptor": schema.StringAttribute{
+				Description: "The name of the server certificate in master that protects the database encryption key. Required to enable TDE on SQL Server; ignored on Azure SQL, where TDE is service-managed.",
+				Optional:    true,
+			},
+			"encryption_state": schema.StringAttribute{
+				Description: "The encryption state reported by sys.dm_database_encryption_keys, e.g. ENCRYPTED or ENCRYPTION_IN_PROGRESS.",
+				Computed:    true,
+			},
+			"service_managed": schema.BoolAttribute{
+				Description: "Whether TDE is service-managed (Azure SQL).",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (r *DatabaseEncryptionResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Resource Configure Type", fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData))
+		return
+	}
+	r.client = client
+}
+
+// setDatabaseEncryptionState copies the server values into the model. The
+// encryptor is only tracked for SQL Server; on Azure SQL the configured value is kept.
+func setDatabaseEncryptionState(data *DatabaseEncryptionResourceModel, enc *mssql.DatabaseEncryption) {
+	data.ID = types.StringValue(enc.DatabaseName)
+	data.Enabled = types.BoolValue(enc.Enabled)
+	data.EncryptionState = types.StringValue(enc.EncryptionState)
+	data.ServiceManaged = types.BoolValue(enc.ServiceManaged)
+	if !enc.ServiceManaged && enc.EncryptorName != "" {
+		data.Encryptor = types.StringValue(enc.EncryptorName)
+	}
+}
+
+func (r *DatabaseEncryptionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data DatabaseEncryptionResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Configuring database encryption", map[string]interface{}{
+		"database": data.DatabaseName.ValueString(),
+		"enabled":  data.Enabled.ValueBool(),
+	})
+
+	enc, err := r.client.SetDatabaseEncryption(ctx, mssql.SetDatabaseEncryptionOptions{
+		DatabaseName:  data.DatabaseName.ValueString(),
+		Enabled:       data.Enabled.ValueBool(),
+		EncryptorName: data.Encryptor.ValueString(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to configure database encryption", errorDetail(err))
+		return
+	}
+
+	setDatabaseEncryptionState(&data, enc)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *DatabaseEncryptionResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data DatabaseEncryptionResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	enc, err := r.client.GetDatabaseEncryption(ctx, data.DatabaseName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read database encryption", errorDetail(err))
+		return
+	}
+	if enc == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	setDatabaseEncryptionState(&data, enc)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *DatabaseEncryptionResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data DatabaseEncryptionResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	enc, err := r.client.SetDatabaseEncryption(ctx, mssql.SetDatabaseEncryptionOptions{
+		DatabaseName:  data.DatabaseName.ValueString(),
+		Enabled:       data.Enabled.ValueBool(),
+		EncryptorName: data.Encryptor.ValueString(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to configure database encryption", errorDetail(err))
+		return
+	}
+
+	setDatabaseEncryptionState(&data, enc)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *DatabaseEncryptionResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data DatabaseEncryptionResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Turning off database encryption", map[string]interface{}{
+		"database": data.DatabaseName.ValueString(),
+	})
+
+	enc, err := r.client.GetDatabaseEncryption(ctx, data.DatabaseName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read database encryption", errorDetail(err))
+		return
+	}
+	// Nothing to do if the database is already gone
+	if enc == nil {
+		return
+	}
+
+	_, err = r.client.SetDatabaseEncryption(ctx, mssql.SetDatabaseEncryptionOptions{
+		DatabaseName: data.DatabaseName.ValueString(),
+		Enabled:      false,
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to turn off database encryption", errorDetail(err))
+		return
+	}
+}
+
+func (r *DatabaseEncryptionResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	enc, err := r.client.GetDatabaseEncryption(ctx, req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to import database encryption", errorDetail(err))
+		return
+	}
+	if enc == nil {
+		resp.Diagnostics.AddError("Database not found", fmt.Sprintf("Database '%s' not found", req.ID))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), enc.DatabaseName)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("database_name"), enc.DatabaseName)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("enabled"), enc.Enabled)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("encryption_state"), enc.EncryptionState)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("service_managed"), enc.ServiceManaged)...)
+	if !enc.ServiceManaged && enc.EncryptorName != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("encryptor"), enc.EncryptorName)...)
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/muecahit94/terraform-provider-mssql/internal/mssql"
+)
+
+var _ resource.Resource = &DatabaseOwnerResource{}
+var _ resource.ResourceWithImportState = &DatabaseOwnerResource{}
+
+func NewDatabaseOwnerResource() resource.Resource {
+	return &DatabaseOwnerResource{}
+}
+
+// DatabaseOwnerResource manages the owner of a database, the login mapped to
+// its dbo user. A database always has an owner, so destroying the resource
+// hands the database back to the login the provider is connected as.
+type DatabaseOwnerResource struct {
+	client *mssql.Client
+}
+
+type DatabaseOwnerResourceModel struct {
+	ID           types.String `tfsdk:"id"`
+	DatabaseName types.String `tfsdk:"database_name"`
+	OwnerName    types.String `tfsdk:"owner_name"`
+	OwnerSID     types.String `tfsdk:"owner_sid"`
+}
+
+func (r *DatabaseOwnerResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_database_owner"
+}
+
+func (r *DatabaseOwnerResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages the owner of a database, the login mapped to its dbo user.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The database ID.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"database_name": schema.StringAttribute{
+				Description: "The name of the database.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"owner_name": schema.StringAttribute{
+				Description: "The name of the login that owns the database. The login must not be mapped to a user in the database.",
+				Required:    true,
+			},
+			"owner_sid": schema.StringAttribute{
+				Description: "The SID of the owner as a hex string.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (r *DatabaseOwnerResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *DatabaseOwnerResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data DatabaseOwnerResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.setOwner(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *DatabaseOwnerResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data DatabaseOwnerResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	owner, err := r.client.GetDatabaseOwner(ctx, data.DatabaseName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read database owner", errorDetail(err))
+		return
+	}
+	if owner == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	data.ID = types.StringValue(strconv.Itoa(owner.DatabaseID))
+	data.OwnerSID = types.StringValue(owner.OwnerSID)
+	// Login names are usually case-insensitive, so the configured spelling is kept
+	if !r.client.NamesEqual(owner.OwnerName, data.OwnerName.ValueString()) {
+		data.OwnerName = types.StringValue(owner.OwnerName)
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *DatabaseOwnerResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data DatabaseOwnerResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.setOwner(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *DatabaseOwnerResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data DatabaseOwnerResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	owner, err := r.client.GetDatabaseOwner(ctx, data.DatabaseName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read database owner", errorDetail(err))
+		return
+	}
+	// The database is already gone
+	if owner == nil {
+		return
+	}
+
+	// Hand the database back to the provider's login, which owns the
+	// databases it creates, so that the previous owner can be dropped
+	connected, err := r.client.GetCurrentLoginName(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to reset database owner", errorDetail(err))
+		return
+	}
+	if r.client.NamesEqual(owner.OwnerName, connected) {
+		return
+	}
+
+	tflog.Debug(ctx, "Resetting database owner", map[string]interface{}{
+		"database": data.DatabaseName.ValueString(),
+		"owner":    connected,
+	})
+
+	if _, err := r.client.SetDatabaseOwner(ctx, data.DatabaseName.ValueString(), connected); err != nil {
+		resp.Diagnostics.AddError("Failed to reset database owner", errorDetail(err))
+		return
+	}
+}
+
+func (r *DatabaseOwnerResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	owner, err := r.client.GetDatabaseOwner(ctx, req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to import database owner", errorDetail(err))
+		return
+	}
+	if owner == nil {
+		resp.Diagnostics.AddError("Database not found", fmt.Sprintf("Database '%s' not found", req.ID))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), strconv.Itoa(owner.DatabaseID))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("database_name"), owner.DatabaseName)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("owner_name"), owner.OwnerName)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("owner_sid"), owner.OwnerSID)...)
+}
+
+// setOwner makes the configured login the owner of the database and updates
+// the computed attributes.
+func (r *DatabaseOwnerResource) setOwner(ctx context.Context, data *DatabaseOwnerResourceModel, diags *diag.Diagnostics) {
+	tflog.Debug(ctx, "Setting database owner", map[string]interface{}{
+		"database": data.DatabaseName.ValueString(),
+		"owner":    data.OwnerName.ValueString(),
+	})
+
+	owner, err := r.client.SetDatabaseOwner(ctx, data.DatabaseName.ValueString(), data.OwnerName.ValueString())
+	if err != nil {
+		diags.AddError("Failed to set database owner", errorDetail(err))
+		return
+	}
+	if owner == nil {
+		diags.AddError("Database not found", fmt.Sprintf("Database '%s' not found", data.DatabaseName.ValueString()))
+		return
+	}
+
+	data.ID = types.StringValue(strconv.Itoa(owner.DatabaseID))
+	data.OwnerSID = types.StringValue(owner.OwnerSID)
+}
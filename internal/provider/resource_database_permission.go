@@ -20,6 +20,8 @@ import (
 
 var _ resource.Resource = &DatabasePermissionResource{}
 var _ resource.ResourceWithImportState = &DatabasePermissionResource{}
+var _ resource.ResourceWithModifyPlan = &DatabasePermissionResource{}
+var _ resource.ResourceWithValidateConfig = &DatabasePermissionResource{}
 
 func NewDatabasePermissionResource() resource.Resource {
 	return &DatabasePermissionResource{}
@@ -34,7 +36,11 @@ type DatabasePermissionResourceModel struct {
 	DatabaseName    types.String `tfsdk:"database_name"`
 	PrincipalName   types.String `tfsdk:"principal_name"`
 	Permission      types.String `tfsdk:"permission"`
+	SecurableType   types.String `tfsdk:"securable_type"`
+	SecurableName   types.String `tfsdk:"securable_name"`
 	WithGrantOption types.Bool   `tfsdk:"with_grant_option"`
+	Cascade         types.Bool   `tfsdk:"cascade"`
+	State           types.String `tfsdk:"state"`
 }
 
 func (r *DatabasePermissionResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -46,7 +52,7 @@ func (r *DatabasePermissionResource) Schema(ctx context.Context, req resource.Sc
 		Description: "Manages a database-level permission grant.",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
-				Description: "The permission ID in format 'database_name/principal_name/permission'.",
+				Description: "The permission ID in format 'database_name/principal_name/permission', followed by '/securable_type/securable_name' for permissions on a securable.",
 				Computed:    true,
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
@@ -73,12 +79,38 @@ func (r *DatabasePermissionResource) Schema(ctx context.Context, req resource.Sc
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
+			"securable_type": schema.StringAttribute{
+				Description: "The type of database securable the permission is granted on: DATABASE, CERTIFICATE, SYMMETRIC_KEY, ASYMMETRIC_KEY, USER or ROLE. If omitted, the permission is granted on the database itself; DATABASE names it explicitly.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"securable_name": schema.StringAttribute{
+				Description: "The name of the securable, e.g. the certificate name. Required when securable_type is set. For DATABASE, it must be database_name.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
 			"with_grant_option": schema.BoolAttribute{
-				Description: "Whether the principal can grant this permission to others.",
+				Description: "Whether the principal can grant this permission to others. Defaults to the provider's default_with_grant_option.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"cascade": schema.BoolAttribute{
+				Description: "Whether revoking the permission also revokes the permissions the principal granted onwards. If false, the revoke fails while such grants exist; without them, CASCADE is added automatically, since SQL Server requires it to revoke a permission held with the grant option. Defaults to false.",
 				Optional:    true,
 				Computed:    true,
 				Default:     booldefault.StaticBool(false),
 			},
+			"state": schema.StringAttribute{
+				Description: "The current state of the permission (GRANT, GRANT_WITH_GRANT_OPTION or DENY). If the permission is found DENYed, it is revoked and granted again.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					permissionStatePlanModifier{},
+				},
+			},
 		},
 	}
 }
@@ -95,6 +127,74 @@ func (r *DatabasePermissionResource) Configure(ctx context.Context, req resource
 	r.client = client
 }
 
+func (r *DatabasePermissionResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planWithGrantOptionDefault(ctx, r.client, req, resp)
+}
+
+func (r *DatabasePermissionResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data DatabasePermissionResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.SecurableType.IsUnknown() || data.SecurableName.IsUnknown() {
+		return
+	}
+
+	if !data.SecurableType.IsNull() && !mssql.IsDatabaseSecurableType(data.SecurableType.ValueString()) {
+		resp.Diagnostics.AddAttributeError(path.Root("securable_type"), "Unsupported securable type",
+			fmt.Sprintf("securable_type must be one of '%s', '%s', '%s', '%s', '%s' or '%s', got: %s",
+				mssql.SecurableTypeDatabase, mssql.SecurableTypeCertificate, mssql.SecurableTypeSymmetricKey, mssql.SecurableTypeAsymmetricKey,
+				mssql.SecurableTypeUser, mssql.SecurableTypeRole, data.SecurableType.ValueString()))
+	}
+	if data.SecurableType.IsNull() != data.SecurableName.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("securable_name"), "Incomplete securable",
+			"securable_type and securable_name must be set together")
+	}
+	// SQL Server only grants permissions on the current database
+	if strings.EqualFold(data.SecurableType.ValueString(), mssql.SecurableTypeDatabase) && !data.DatabaseName.IsUnknown() &&
+		!r.client.NamesEqual(data.SecurableName.ValueString(), data.DatabaseName.ValueString()) {
+		resp.Diagnostics.AddAttributeError(path.Root("securable_name"), "Database securable in another database",
+			fmt.Sprintf("Permissions on database '%s' must be granted in that database; set database_name to '%s'. SQL Server does not grant permissions on a database other than the one the statement runs in.",
+				data.SecurableName.ValueString(), data.SecurableName.ValueString()))
+	}
+}
+
+// onSecurable reports whether the permission targets a securable rather than the database.
+func (m *DatabasePermissionResourceModel) onSecurable() bool {
+	return !m.SecurableType.IsNull()
+}
+
+func (m *DatabasePermissionResourceModel) id() string {
+	id := fmt.Sprintf("%s/%s/%s", m.DatabaseName.ValueString(), m.PrincipalName.ValueString(), strings.ToUpper(m.Permission.ValueString()))
+	if m.onSecurable() {
+		id += fmt.Sprintf("/%s/%s", strings.ToUpper(m.SecurableType.ValueString()), m.SecurableName.ValueString())
+	}
+	return id
+}
+
+func (r *DatabasePermissionResource) getPermission(ctx context.Context, data *DatabasePermissionResourceModel) (*mssql.DatabasePermission, error) {
+	if data.onSecurable() {
+		return r.client.GetDatabaseSecurablePermission(ctx, data.DatabaseName.ValueString(), data.SecurableType.ValueString(), data.SecurableName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString())
+	}
+	return r.client.GetDatabasePermission(ctx, data.DatabaseName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString())
+}
+
+func (r *DatabasePermissionResource) grantPermission(ctx context.Context, data *DatabasePermissionResourceModel) error {
+	if data.onSecurable() {
+		return r.client.GrantDatabaseSecurablePermission(ctx, data.DatabaseName.ValueString(), data.SecurableType.ValueString(), data.SecurableName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString(), data.WithGrantOption.ValueBool())
+	}
+	return r.client.GrantDatabasePermission(ctx, data.DatabaseName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString(), data.WithGrantOption.ValueBool())
+}
+
+func (r *DatabasePermissionResource) revokePermission(ctx context.Context, data *DatabasePermissionResourceModel) error {
+	if data.onSecurable() {
+		return r.client.RevokeDatabaseSecurablePermission(ctx, data.DatabaseName.ValueString(), data.SecurableType.ValueString(), data.SecurableName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString(), data.Cascade.ValueBool())
+	}
+	return r.client.RevokeDatabasePermission(ctx, data.DatabaseName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString(), data.Cascade.ValueBool())
+}
+
 func (r *DatabasePermissionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data DatabasePermissionResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -102,13 +202,14 @@ func (r *DatabasePermissionResource) Create(ctx context.Context, req resource.Cr
 		return
 	}
 
-	err := r.client.GrantDatabasePermission(ctx, data.DatabaseName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString(), data.WithGrantOption.ValueBool())
+	err := r.grantPermission(ctx, &data)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to grant database permission", err.Error())
+		resp.Diagnostics.AddError("Failed to grant database permission", errorDetail(err))
 		return
 	}
 
-	data.ID = types.StringValue(fmt.Sprintf("%s/%s/%s", data.DatabaseName.ValueString(), data.PrincipalName.ValueString(), strings.ToUpper(data.Permission.ValueString())))
+	data.ID = types.StringValue(data.id())
+	data.State = types.StringValue(desiredPermissionState(data.WithGrantOption.ValueBool()))
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -119,9 +220,9 @@ func (r *DatabasePermissionResource) Read(ctx context.Context, req resource.Read
 		return
 	}
 
-	perm, err := r.client.GetDatabasePermission(ctx, data.DatabaseName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString())
+	perm, err := r.getPermission(ctx, &data)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to read database permission", err.Error())
+		resp.Diagnostics.AddError("Failed to read database permission", errorDetail(err))
 		return
 	}
 	if perm == nil {
@@ -129,8 +230,21 @@ func (r *DatabasePermissionResource) Read(ctx context.Context, req resource.Read
 		return
 	}
 
-	data.Permission = types.StringValue(perm.PermissionName)
-	data.WithGrantOption = types.BoolValue(perm.WithGrantOption)
+	// Keep the configured spelling, e.g. lower case or extra spaces
+	if mssql.NormalizePermissionName(data.Permission.ValueString()) != perm.PermissionName {
+		data.Permission = types.StringValue(perm.PermissionName)
+	}
+	// Only update WithGrantOption if this is a real permission (DatabaseID > 0).
+	// Permissions covered by CONTROL are virtual and have DatabaseID = 0, so we
+	// preserve the Terraform-configured value to avoid drift.
+	// CONTROL without the grant option does not cover a configured grant
+	// option, so its state is reported and the grant option is granted.
+	if perm.DatabaseID > 0 || (data.WithGrantOption.ValueBool() && !perm.WithGrantOption) {
+		data.WithGrantOption = types.BoolValue(perm.WithGrantOption)
+		data.State = types.StringValue(perm.StateDesc)
+	} else {
+		data.State = types.StringValue(desiredPermissionState(data.WithGrantOption.ValueBool()))
+	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -142,18 +256,20 @@ func (r *DatabasePermissionResource) Update(ctx context.Context, req resource.Up
 		return
 	}
 
-	// If with_grant_option changed, we need to revoke and re-grant
-	if !data.WithGrantOption.Equal(state.WithGrantOption) {
-		if err := r.client.RevokeDatabasePermission(ctx, data.DatabaseName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString()); err != nil {
-			resp.Diagnostics.AddError("Failed to revoke database permission", err.Error())
+	// If with_grant_option changed or the permission was DENYed outside of
+	// Terraform, we need to revoke and re-grant
+	if !data.WithGrantOption.Equal(state.WithGrantOption) || !data.State.Equal(state.State) {
+		if err := r.revokePermission(ctx, &data); err != nil {
+			resp.Diagnostics.AddError("Failed to revoke database permission", errorDetail(err))
 			return
 		}
-		if err := r.client.GrantDatabasePermission(ctx, data.DatabaseName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString(), data.WithGrantOption.ValueBool()); err != nil {
-			resp.Diagnostics.AddError("Failed to grant database permission", err.Error())
+		if err := r.grantPermission(ctx, &data); err != nil {
+			resp.Diagnostics.AddError("Failed to grant database permission", errorDetail(err))
 			return
 		}
 	}
 
+	data.State = types.StringValue(desiredPermissionState(data.WithGrantOption.ValueBool()))
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -164,33 +280,59 @@ func (r *DatabasePermissionResource) Delete(ctx context.Context, req resource.De
 		return
 	}
 
-	err := r.client.RevokeDatabasePermission(ctx, data.DatabaseName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString())
+	err := r.revokePermission(ctx, &data)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to revoke database permission", err.Error())
+		resp.Diagnostics.AddError("Failed to revoke database permission", errorDetail(err))
 		return
 	}
 }
 
 func (r *DatabasePermissionResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	parts := strings.Split(req.ID, "/")
-	if len(parts) != 3 {
-		resp.Diagnostics.AddError("Invalid import ID", "Import ID must be in format 'database_name/principal_name/permission'")
+	data, ok := parseDatabasePermissionID(req.ID)
+	if !ok {
+		resp.Diagnostics.AddError("Invalid import ID", "Import ID must be in format 'database_name/principal_name/permission' or 'database_name/principal_name/permission/securable_type/securable_name'")
 		return
 	}
 
-	perm, err := r.client.GetDatabasePermission(ctx, parts[0], parts[1], parts[2])
+	perm, err := r.getPermission(ctx, &data)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to import database permission", err.Error())
+		resp.Diagnostics.AddError("Failed to import database permission", errorDetail(err))
 		return
 	}
 	if perm == nil {
-		resp.Diagnostics.AddError("Database permission not found", fmt.Sprintf("Permission '%s' not found for '%s'", parts[2], parts[1]))
+		resp.Diagnostics.AddError("Database permission not found", fmt.Sprintf("Permission '%s' not found for '%s'", data.Permission.ValueString(), data.PrincipalName.ValueString()))
 		return
 	}
 
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
-	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("database_name"), parts[0])...)
-	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("principal_name"), parts[1])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("database_name"), data.DatabaseName)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("principal_name"), data.PrincipalName)...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("permission"), perm.PermissionName)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("securable_type"), data.SecurableType)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("securable_name"), data.SecurableName)...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("with_grant_option"), perm.WithGrantOption)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cascade"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("state"), perm.StateDesc)...)
+}
+
+// parseDatabasePermissionID parses an import ID of the form
+// database_name/principal_name/permission, optionally followed by
+// /securable_type/securable_name.
+func parseDatabasePermissionID(id string) (DatabasePermissionResourceModel, bool) {
+	data := DatabasePermissionResourceModel{
+		SecurableType: types.StringNull(),
+		SecurableName: types.StringNull(),
+	}
+	parts := strings.Split(id, "/")
+	switch {
+	case len(parts) == 5 && mssql.IsDatabaseSecurableType(parts[3]) && parts[4] != "":
+		data.SecurableType = types.StringValue(strings.ToUpper(parts[3]))
+		data.SecurableName = types.StringValue(parts[4])
+	case len(parts) != 3:
+		return data, false
+	}
+	data.DatabaseName = types.StringValue(parts[0])
+	data.PrincipalName = types.StringValue(parts[1])
+	data.Permission = types.StringValue(parts[2])
+	return data, true
 }
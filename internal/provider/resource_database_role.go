@@ -5,9 +5,11 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -20,6 +22,7 @@ import (
 
 var _ resource.Resource = &DatabaseRoleResource{}
 var _ resource.ResourceWithImportState = &DatabaseRoleResource{}
+var _ resource.ResourceWithModifyPlan = &DatabaseRoleResource{}
 
 func NewDatabaseRoleResource() resource.Resource {
 	return &DatabaseRoleResource{}
@@ -34,6 +37,9 @@ type DatabaseRoleResourceModel struct {
 	DatabaseName types.String `tfsdk:"database_name"`
 	Name         types.String `tfsdk:"name"`
 	OwnerName    types.String `tfsdk:"owner_name"`
+	Members      types.Set    `tfsdk:"members"`
+	Permissions  types.Set    `tfsdk:"permissions"`
+	Ignore       types.Set    `tfsdk:"ignore"`
 }
 
 func (r *DatabaseRoleResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -69,6 +75,24 @@ func (r *DatabaseRoleResource) Schema(ctx context.Context, req resource.SchemaRe
 				Description: "The owner of the role.",
 				Optional:    true,
 				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"members": schema.SetAttribute{
+				Description: "The members of the role. If set, membership is authoritative: members added outside of this set are removed.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"permissions": schema.SetAttribute{
+				Description: "The database-level permissions granted to the role. If set, permissions are authoritative: permissions granted outside of this set are revoked.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"ignore": schema.SetAttribute{
+				Description: "Members and permissions that are managed outside of this resource, e.g. by another tool. They are neither added nor removed, and are not reported as drift.",
+				Optional:    true,
+				ElementType: types.StringType,
 			},
 		},
 	}
@@ -86,6 +110,118 @@ func (r *DatabaseRoleResource) Configure(ctx context.Context, req resource.Confi
 	r.client = client
 }
 
+// ModifyPlan lists the members and permissions to be changed in the plan.
+func (r *DatabaseRoleResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	previewSetChanges(ctx, req, resp, r.client.NameKey, "members")
+	previewSetChanges(ctx, req, resp, permissionKey, "permissions")
+}
+
+// grantedPermissions returns the database-level permissions granted to the role.
+func (r *DatabaseRoleResource) grantedPermissions(ctx context.Context, data *DatabaseRoleResourceModel) ([]string, error) {
+	perms, err := r.client.ListDatabasePermissions(ctx, data.DatabaseName.ValueString(), data.Name.ValueString())
+	if err != nil {
+		return nil, err
+	}
+	var granted []string
+	for _, perm := range perms {
+		if perm.StateDesc != mssql.PermissionStateDeny {
+			granted = append(granted, perm.PermissionName)
+		}
+	}
+	return granted, nil
+}
+
+// reconcile brings the permissions and members of the role in line with the
+// configuration. Permissions are granted before members are added, so members
+// never hold the role without its permissions. Unset attributes and ignored
+// names are not managed.
+// Failing items don't stop the others from being applied; if any fail, data is
+// read back from the server so that it reflects what was applied.
+func (r *DatabaseRoleResource) reconcile(ctx context.Context, data *DatabaseRoleResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	databaseName := data.DatabaseName.ValueString()
+	roleName := data.Name.ValueString()
+	ignored := setElements(ctx, data.Ignore, &diags)
+
+	if !data.Permissions.IsNull() {
+		var desired []string
+		diags.Append(data.Permissions.ElementsAs(ctx, &desired, false)...)
+		if diags.HasError() {
+			return diags
+		}
+		current, err := r.grantedPermissions(ctx, data)
+		if err != nil {
+			diags.AddError("Failed to read database role permissions", errorDetail(err))
+			return diags
+		}
+
+		grant, revoke := diffNames(withoutIgnored(current, ignored, permissionKey), withoutIgnored(desired, ignored, permissionKey), permissionKey)
+		applyEach(revoke, "Failed to revoke database permission", "revoke", func(permission string) error {
+			return r.client.RevokeDatabasePermission(ctx, databaseName, roleName, permission, false)
+		}, &diags)
+		applyEach(grant, "Failed to grant database permission", "grant", func(permission string) error {
+			return r.client.GrantDatabasePermission(ctx, databaseName, roleName, permission, false)
+		}, &diags)
+	}
+
+	if !data.Members.IsNull() {
+		var desired []string
+		diags.Append(data.Members.ElementsAs(ctx, &desired, false)...)
+		if diags.HasError() {
+			return diags
+		}
+		current, err := r.client.ListDatabaseRoleMembers(ctx, databaseName, roleName)
+		if err != nil {
+			diags.AddError("Failed to read database role members", errorDetail(err))
+			return diags
+		}
+
+		add, remove := diffNames(withoutIgnored(current, ignored, r.client.NameKey), withoutIgnored(desired, ignored, r.client.NameKey), r.client.NameKey)
+		applyEach(remove, "Failed to remove role member", "remove", func(member string) error {
+			if !checkDbOwnerRemoval(ctx, r.client, databaseName, roleName, member, &diags) {
+				return nil
+			}
+			return r.client.RemoveDatabaseRoleMember(ctx, databaseName, roleName, member)
+		}, &diags)
+		// New members would get the role without all of its permissions
+		if !diags.HasError() {
+			applyEach(add, "Failed to add role member", "add", func(member string) error {
+				return r.client.AddDatabaseRoleMember(ctx, databaseName, roleName, member)
+			}, &diags)
+		}
+	}
+
+	if diags.HasError() {
+		diags.Append(r.refresh(ctx, data)...)
+	}
+	return diags
+}
+
+// refresh reads the managed permissions and members of the role into data.
+func (r *DatabaseRoleResource) refresh(ctx context.Context, data *DatabaseRoleResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ignored := setElements(ctx, data.Ignore, &diags)
+	if !data.Permissions.IsNull() {
+		granted, err := r.grantedPermissions(ctx, data)
+		if err != nil {
+			diags.AddError("Failed to read database role permissions", errorDetail(err))
+			return diags
+		}
+		granted = managedNames(ctx, data.Permissions, granted, ignored, permissionKey, &diags)
+		data.Permissions = authoritativeSet(ctx, data.Permissions, granted, permissionKey, &diags)
+	}
+	if !data.Members.IsNull() {
+		members, err := r.client.ListDatabaseRoleMembers(ctx, data.DatabaseName.ValueString(), data.Name.ValueString())
+		if err != nil {
+			diags.AddError("Failed to read database role members", errorDetail(err))
+			return diags
+		}
+		members = managedNames(ctx, data.Members, members, ignored, r.client.NameKey, &diags)
+		data.Members = authoritativeSet(ctx, data.Members, members, r.client.NameKey, &diags)
+	}
+	return diags
+}
+
 func (r *DatabaseRoleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data DatabaseRoleResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -102,13 +238,27 @@ func (r *DatabaseRoleResource) Create(ctx context.Context, req resource.CreateRe
 	}
 
 	role, err := r.client.CreateDatabaseRole(ctx, opts)
+	if errors.Is(err, mssql.ErrAlreadyExists) {
+		// Adopt the existing role if it matches the desired owner
+		existing, getErr := r.client.GetDatabaseRole(ctx, opts.DatabaseName, opts.RoleName)
+		if getErr != nil {
+			resp.Diagnostics.AddError("Failed to read existing database role", errorDetail(getErr))
+			return
+		}
+		if existing != nil && ownerMatches(r.client, opts.OwnerName, existing.OwnerName) {
+			resp.Diagnostics.AddWarning("Database role already exists",
+				fmt.Sprintf("Role '%s' already exists in database '%s' and has been adopted into the Terraform state.", opts.RoleName, opts.DatabaseName))
+			role, err = existing, nil
+		}
+	}
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to create database role", err.Error())
+		resp.Diagnostics.AddError("Failed to create database role", errorDetail(err))
 		return
 	}
 
 	data.ID = types.StringValue(fmt.Sprintf("%d/%d", role.DatabaseID, role.PrincipalID))
 	data.OwnerName = types.StringValue(role.OwnerName)
+	resp.Diagnostics.Append(r.reconcile(ctx, &data)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -121,7 +271,7 @@ func (r *DatabaseRoleResource) Read(ctx context.Context, req resource.ReadReques
 
 	role, err := r.client.GetDatabaseRole(ctx, data.DatabaseName.ValueString(), data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to read database role", err.Error())
+		resp.Diagnostics.AddError("Failed to read database role", errorDetail(err))
 		return
 	}
 	if role == nil {
@@ -130,6 +280,12 @@ func (r *DatabaseRoleResource) Read(ctx context.Context, req resource.ReadReques
 	}
 
 	data.OwnerName = types.StringValue(role.OwnerName)
+
+	resp.Diagnostics.Append(r.refresh(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -149,11 +305,12 @@ func (r *DatabaseRoleResource) Update(ctx context.Context, req resource.UpdateRe
 			NewOwnerName: &owner,
 		})
 		if err != nil {
-			resp.Diagnostics.AddError("Failed to update database role", err.Error())
+			resp.Diagnostics.AddError("Failed to update database role", errorDetail(err))
 			return
 		}
 	}
 
+	resp.Diagnostics.Append(r.reconcile(ctx, &data)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -164,9 +321,29 @@ func (r *DatabaseRoleResource) Delete(ctx context.Context, req resource.DeleteRe
 		return
 	}
 
+	// A role with members cannot be dropped, so remove the managed members
+	// first. Ignored members are left to whoever manages them.
+	var members []string
+	if !data.Members.IsNull() {
+		resp.Diagnostics.Append(data.Members.ElementsAs(ctx, &members, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	members = withoutIgnored(members, setElements(ctx, data.Ignore, &resp.Diagnostics), r.client.NameKey)
+	applyEach(members, "Failed to remove role member", "remove", func(member string) error {
+		if !checkDbOwnerRemoval(ctx, r.client, data.DatabaseName.ValueString(), data.Name.ValueString(), member, &resp.Diagnostics) {
+			return nil
+		}
+		return r.client.RemoveDatabaseRoleMember(ctx, data.DatabaseName.ValueString(), data.Name.ValueString(), member)
+	}, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	err := r.client.DropDatabaseRole(ctx, data.DatabaseName.ValueString(), data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to delete database role", err.Error())
+		resp.Diagnostics.AddError("Failed to delete database role", errorDetail(err))
 		return
 	}
 }
@@ -174,13 +351,15 @@ func (r *DatabaseRoleResource) Delete(ctx context.Context, req resource.DeleteRe
 func (r *DatabaseRoleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	parts := strings.Split(req.ID, "/")
 	if len(parts) != 2 {
-		resp.Diagnostics.AddError("Invalid import ID", "Import ID must be in format 'database_name/role_name'")
+		resp.Diagnostics.AddError("Invalid import ID", "Import ID must be in format 'database_name/role_name' or 'database_name/principal_id'")
 		return
 	}
 
-	role, err := r.client.GetDatabaseRole(ctx, parts[0], parts[1])
+	role, err := lookupByNameOrID(parts[1],
+		func(id int) (*mssql.DatabaseRole, error) { return r.client.GetDatabaseRoleByID(ctx, parts[0], id) },
+		func(name string) (*mssql.DatabaseRole, error) { return r.client.GetDatabaseRole(ctx, parts[0], name) })
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to import database role", err.Error())
+		resp.Diagnostics.AddError("Failed to import database role", errorDetail(err))
 		return
 	}
 	if role == nil {
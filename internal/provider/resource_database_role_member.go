@@ -96,7 +96,7 @@ func (r *DatabaseRoleMemberResource) Create(ctx context.Context, req resource.Cr
 
 	err := r.client.AddDatabaseRoleMember(ctx, data.DatabaseName.ValueString(), data.RoleName.ValueString(), data.MemberName.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to add database role member", err.Error())
+		resp.Diagnostics.AddError("Failed to add database role member", errorDetail(err))
 		return
 	}
 
@@ -113,7 +113,7 @@ func (r *DatabaseRoleMemberResource) Read(ctx context.Context, req resource.Read
 
 	member, err := r.client.GetDatabaseRoleMember(ctx, data.DatabaseName.ValueString(), data.RoleName.ValueString(), data.MemberName.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to read database role member", err.Error())
+		resp.Diagnostics.AddError("Failed to read database role member", errorDetail(err))
 		return
 	}
 	if member == nil {
@@ -135,9 +135,13 @@ func (r *DatabaseRoleMemberResource) Delete(ctx context.Context, req resource.De
 		return
 	}
 
+	if !checkDbOwnerRemoval(ctx, r.client, data.DatabaseName.ValueString(), data.RoleName.ValueString(), data.MemberName.ValueString(), &resp.Diagnostics) {
+		return
+	}
+
 	err := r.client.RemoveDatabaseRoleMember(ctx, data.DatabaseName.ValueString(), data.RoleName.ValueString(), data.MemberName.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to remove database role member", err.Error())
+		resp.Diagnostics.AddError("Failed to remove database role member", errorDetail(err))
 		return
 	}
 }
@@ -151,7 +155,7 @@ func (r *DatabaseRoleMemberResource) ImportState(ctx context.Context, req resour
 
 	member, err := r.client.GetDatabaseRoleMember(ctx, parts[0], parts[1], parts[2])
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to import database role member", err.Error())
+		resp.Diagnostics.AddError("Failed to import database role member", errorDetail(err))
 		return
 	}
 	if member == nil {
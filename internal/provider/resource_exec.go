@@ -0,0 +1,236 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/muecahit94/terraform-provider-mssql/internal/mssql"
+)
+
+var _ resource.Resource = &ExecResource{}
+var _ resource.ResourceWithValidateConfig = &ExecResource{}
+var _ resource.ResourceWithModifyPlan = &ExecResource{}
+
+// When the statement of an mssql_exec resource runs.
+const (
+	// execRunOnCreate runs the statement on create and whenever its
+	// configuration changes.
+	execRunOnCreate = "create"
+	// execRunOnApply runs the statement on every apply.
+	execRunOnApply = "apply"
+	// execRunOnDestroy runs the statement only when the resource is destroyed.
+	execRunOnDestroy = "destroy"
+)
+
+func NewExecResource() resource.Resource {
+	return &ExecResource{}
+}
+
+// ExecResource runs a statement for its side effects, e.g. DBCC or other
+// maintenance commands. Unlike mssql_script it has nothing to read or undo.
+// run_on controls when the statement runs: on create and whenever the
+// configuration changes, on every apply, or only on destroy.
+type ExecResource struct {
+	client *mssql.Client
+}
+
+type ExecResourceModel struct {
+	ID           types.String `tfsdk:"id"`
+	DatabaseName types.String `tfsdk:"database_name"`
+	Statement    types.String `tfsdk:"statement"`
+	Triggers     types.Map    `tfsdk:"triggers"`
+	RunOn        types.String `tfsdk:"run_on"`
+	ExecutedAt   types.String `tfsdk:"executed_at"`
+}
+
+func (r *ExecResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_exec"
+}
+
+func (r *ExecResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Executes a SQL statement on create and whenever its configuration changes, without reading or undoing it.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"database_name": schema.StringAttribute{
+				Description: "The database to execute the statement in. Empty for server-level statements.",
+				Optional:    true,
+			},
+			"statement": schema.StringAttribute{
+				Description: "The SQL statement to execute.",
+				Required:    true,
+			},
+			"triggers": schema.MapAttribute{
+				Description: "Arbitrary values that execute the statement again when they change. Only supported with run_on 'create'.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"run_on": schema.StringAttribute{
+				Description: "When the statement runs: 'create' on create and whenever the configuration changes, 'apply' on every apply, or 'destroy' only when the resource is destroyed. Defaults to 'create'.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(execRunOnCreate),
+			},
+			"executed_at": schema.StringAttribute{
+				Description: "The time the statement last ran, empty if it has not run yet.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (r *ExecResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Resource Configure Type", fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData))
+		return
+	}
+	r.client = client
+}
+
+func (r *ExecResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data ExecResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.RunOn.IsNull() || data.RunOn.IsUnknown() {
+		return
+	}
+
+	switch data.RunOn.ValueString() {
+	case execRunOnCreate:
+	case execRunOnApply, execRunOnDestroy:
+		// Triggers decide when the statement runs again after create, which
+		// is fixed for the other modes
+		if !data.Triggers.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("triggers"), "Conflicting execution modes",
+				fmt.Sprintf("triggers cannot be used with run_on '%s', only with '%s'", data.RunOn.ValueString(), execRunOnCreate))
+		}
+	default:
+		resp.Diagnostics.AddAttributeError(path.Root("run_on"), "Invalid run_on",
+			fmt.Sprintf("run_on must be one of '%s', '%s' or '%s', got: %s", execRunOnCreate, execRunOnApply, execRunOnDestroy, data.RunOn.ValueString()))
+	}
+}
+
+// ModifyPlan plans an update on every apply for run_on 'apply', so that the
+// statement runs even if the configuration is unchanged.
+func (r *ExecResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var runOn types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("run_on"), &runOn)...)
+	if resp.Diagnostics.HasError() || runOn.ValueString() != execRunOnApply {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("executed_at"), types.StringUnknown())...)
+}
+
+func (r *ExecResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data ExecResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ExecutedAt = types.StringNull()
+	if data.RunOn.ValueString() != execRunOnDestroy {
+		if err := r.execute(ctx, &data); err != nil {
+			resp.Diagnostics.AddError("Failed to execute statement", errorDetail(err))
+			return
+		}
+	}
+
+	data.ID = types.StringValue(mssql.GenerateScriptID(data.Statement.ValueString(), data.DatabaseName.ValueString()))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read keeps the state as is, as there is nothing on the server to compare the
+// statement with.
+func (r *ExecResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data ExecResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ExecResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state ExecResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ExecutedAt = state.ExecutedAt
+	var run bool
+	switch data.RunOn.ValueString() {
+	case execRunOnApply:
+		run = true
+	case execRunOnCreate:
+		// Also run a statement that has not run yet, e.g. when switching
+		// from run_on 'destroy'
+		run = state.ExecutedAt.IsNull() ||
+			!data.Statement.Equal(state.Statement) ||
+			!data.DatabaseName.Equal(state.DatabaseName) ||
+			!data.Triggers.Equal(state.Triggers)
+	}
+	if run {
+		if err := r.execute(ctx, &data); err != nil {
+			resp.Diagnostics.AddError("Failed to execute statement", errorDetail(err))
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete runs the statement for run_on 'destroy'. Otherwise it only removes the
+// resource from the state, as a statement run for its side effects cannot be
+// undone.
+func (r *ExecResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data ExecResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.RunOn.ValueString() != execRunOnDestroy {
+		return
+	}
+
+	if err := r.execute(ctx, &data); err != nil {
+		resp.Diagnostics.AddError("Failed to execute statement", errorDetail(err))
+		return
+	}
+}
+
+// execute runs the statement and records when it ran.
+func (r *ExecResource) execute(ctx context.Context, data *ExecResourceModel) error {
+	tflog.Debug(ctx, "Executing statement", map[string]interface{}{
+		"database": data.DatabaseName.ValueString(),
+		"run_on":   data.RunOn.ValueString(),
+	})
+	if err := r.client.ExecuteScriptNoResult(ctx, data.DatabaseName.ValueString(), data.Statement.ValueString(), nil); err != nil {
+		return err
+	}
+	data.ExecutedAt = timestampValue(time.Now().UTC())
+	return nil
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/muecahit94/terraform-provider-mssql/internal/mssql"
+)
+
+var _ resource.Resource = &GuestUserResource{}
+var _ resource.ResourceWithImportState = &GuestUserResource{}
+var _ resource.ResourceWithValidateConfig = &GuestUserResource{}
+
+func NewGuestUserResource() resource.Resource {
+	return &GuestUserResource{}
+}
+
+// GuestUserResource manages whether the guest user can connect to a database.
+// The guest user cannot be created or dropped, so the resource only grants or
+// revokes its CONNECT permission.
+type GuestUserResource struct {
+	client *mssql.Client
+}
+
+type GuestUserResourceModel struct {
+	ID           types.String `tfsdk:"id"`
+	DatabaseName types.String `tfsdk:"database_name"`
+	Enabled      types.Bool   `tfsdk:"enabled"`
+}
+
+func (r *GuestUserResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_guest_user"
+}
+
+func (r *GuestUserResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages whether the guest user can connect to a database.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The database ID.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"database_name": schema.StringAttribute{
+				Description: "The name of the database.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"enabled": schema.BoolAttribute{
+				Description: "Whether the guest user has the CONNECT permission.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+		},
+	}
+}
+
+func (r *GuestUserResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *GuestUserResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data GuestUserResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.DatabaseName.IsUnknown() || data.Enabled.IsUnknown() {
+		return
+	}
+
+	if requiresGuestUser(data.DatabaseName.ValueString()) && !data.Enabled.ValueBool() {
+		resp.Diagnostics.AddAttributeError(path.Root("enabled"), "Guest user required",
+			fmt.Sprintf("The guest user cannot be disabled in the %s database.", data.DatabaseName.ValueString()))
+	}
+}
+
+func (r *GuestUserResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data GuestUserResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	database, err := r.client.GetDatabase(ctx, data.DatabaseName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read database", errorDetail(err))
+		return
+	}
+	if database == nil {
+		resp.Diagnostics.AddError("Database not found", fmt.Sprintf("Database '%s' not found", data.DatabaseName.ValueString()))
+		return
+	}
+
+	tflog.Debug(ctx, "Setting guest user state", map[string]interface{}{
+		"database": data.DatabaseName.ValueString(),
+		"enabled":  data.Enabled.ValueBool(),
+	})
+
+	if err := r.client.SetGuestUserEnabled(ctx, data.DatabaseName.ValueString(), data.Enabled.ValueBool()); err != nil {
+		resp.Diagnostics.AddError("Failed to set guest user state", errorDetail(err))
+		return
+	}
+
+	data.ID = types.StringValue(fmt.Sprintf("%d", database.ID))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *GuestUserResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data GuestUserResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	database, err := r.client.GetDatabase(ctx, data.DatabaseName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read database", errorDetail(err))
+		return
+	}
+	if database == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	enabled, err := r.client.IsGuestUserEnabled(ctx, data.DatabaseName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read guest user state", errorDetail(err))
+		return
+	}
+
+	data.ID = types.StringValue(fmt.Sprintf("%d", database.ID))
+	data.Enabled = types.BoolValue(enabled)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *GuestUserResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data GuestUserResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.SetGuestUserEnabled(ctx, data.DatabaseName.ValueString(), data.Enabled.ValueBool()); err != nil {
+		resp.Diagnostics.AddError("Failed to set guest user state", errorDetail(err))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *GuestUserResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data GuestUserResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The guest user is required in master and tempdb and is left enabled there
+	if requiresGuestUser(data.DatabaseName.ValueString()) {
+		return
+	}
+
+	// Disabled is the default for user databases
+	err := r.client.SetGuestUserEnabled(ctx, data.DatabaseName.ValueString(), false)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to disable guest user", errorDetail(err))
+		return
+	}
+}
+
+func (r *GuestUserResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	database, err := r.client.GetDatabase(ctx, req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to import guest user", errorDetail(err))
+		return
+	}
+	if database == nil {
+		resp.Diagnostics.AddError("Database not found", fmt.Sprintf("Database '%s' not found", req.ID))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), fmt.Sprintf("%d", database.ID))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("database_name"), database.Name)...)
+}
+
+// requiresGuestUser reports whether a database needs the guest user to be
+// enabled, which is the case for master and tempdb.
+func requiresGuestUser(databaseName string) bool {
+	return strings.EqualFold(databaseName, "master") || strings.EqualFold(databaseName, "tempdb")
+}
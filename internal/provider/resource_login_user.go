@@ -0,0 +1,465 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/muecahit94/terraform-provider-mssql/internal/mssql"
+)
+
+var _ resource.Resource = &LoginUserResource{}
+var _ resource.ResourceWithImportState = &LoginUserResource{}
+var _ resource.ResourceWithModifyPlan = &LoginUserResource{}
+
+func NewLoginUserResource() resource.Resource {
+	return &LoginUserResource{}
+}
+
+// LoginUserResource manages a SQL login together with a user mapped to it in
+// one database.
+type LoginUserResource struct {
+	client *mssql.Client
+}
+
+type LoginUserResourceModel struct {
+	ID              types.String `tfsdk:"id"`
+	LoginName       types.String `tfsdk:"login_name"`
+	Password        types.String `tfsdk:"password"`
+	DefaultDatabase types.String `tfsdk:"default_database"`
+	DatabaseName    types.String `tfsdk:"database_name"`
+	UserName        types.String `tfsdk:"user_name"`
+	DefaultSchema   types.String `tfsdk:"default_schema"`
+	Roles           types.Set    `tfsdk:"roles"`
+	SID             types.String `tfsdk:"sid"`
+}
+
+func (r *LoginUserResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_login_user"
+}
+
+func (r *LoginUserResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages a SQL Server login together with a database user mapped to it.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The ID in format `login_principal_id/database_id/user_principal_id`.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"login_name": schema.StringAttribute{
+				Description: "The name of the login. Changing this renames the login in place.",
+				Required:    true,
+			},
+			"password": schema.StringAttribute{
+				Description: "The password for the login.",
+				Required:    true,
+				Sensitive:   true,
+			},
+			"default_database": schema.StringAttribute{
+				Description: "The default database for the login.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("master"),
+			},
+			"database_name": schema.StringAttribute{
+				Description: "The name of the database to create the user in.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"user_name": schema.StringAttribute{
+				Description: "The name of the user. Defaults to the login name.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"default_schema": schema.StringAttribute{
+				Description: "The default schema for the user.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("dbo"),
+			},
+			"roles": schema.SetAttribute{
+				Description: "Set of database roles to assign to the user.",
+				Optional:    true,
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+			"sid": schema.StringAttribute{
+				Description: "The SID of the login and the user as a hex string with 0x prefix.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *LoginUserResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = client
+}
+
+// ModifyPlan lists the roles to be changed in the plan.
+func (r *LoginUserResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	previewSetChanges(ctx, req, resp, r.client.NameKey, "roles")
+}
+
+func (r *LoginUserResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data LoginUserResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.UserName.IsUnknown() || data.UserName.IsNull() {
+		data.UserName = data.LoginName
+	}
+
+	tflog.Debug(ctx, "Creating SQL login and user", map[string]interface{}{
+		"login":    data.LoginName.ValueString(),
+		"database": data.DatabaseName.ValueString(),
+		"user":     data.UserName.ValueString(),
+	})
+
+	login, err := r.client.CreateSQLLogin(ctx, mssql.CreateSQLLoginOptions{
+		Name:               data.LoginName.ValueString(),
+		Password:           data.Password.ValueString(),
+		DefaultDatabase:    data.DefaultDatabase.ValueString(),
+		CheckPolicyEnabled: true,
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to create SQL login", errorDetail(err))
+		return
+	}
+
+	var roles []string
+	if !data.Roles.IsNull() && !data.Roles.IsUnknown() {
+		resp.Diagnostics.Append(data.Roles.ElementsAs(ctx, &roles, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	user, err := r.createUser(ctx, data, roles)
+	if err != nil {
+		// Don't leave a login behind that isn't tracked in the state
+		if dropErr := r.client.DropSQLLogin(ctx, login.Name); dropErr != nil {
+			tflog.Warn(ctx, "Failed to drop SQL login after the user could not be created", map[string]interface{}{
+				"login": login.Name,
+				"error": dropErr.Error(),
+			})
+		}
+		resp.Diagnostics.AddError("Failed to create SQL user", errorDetail(err))
+		return
+	}
+
+	data.ID = types.StringValue(loginUserID(login.PrincipalID, user))
+	data.Roles = stringSetValue(roles)
+	data.SID = types.StringValue(user.SID)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *LoginUserResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data LoginUserResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	loginID, _ := strconv.Atoi(strings.Split(data.ID.ValueString(), "/")[0])
+	login, err := r.client.GetSQLLoginByID(ctx, loginID)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read SQL login", errorDetail(err))
+		return
+	}
+	if login == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	data.LoginName = types.StringValue(login.Name)
+	data.DefaultDatabase = types.StringValue(login.DefaultDatabaseName)
+
+	user, err := r.client.GetUser(ctx, data.DatabaseName.ValueString(), data.UserName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read SQL user", errorDetail(err))
+		return
+	}
+	// The user was dropped outside of Terraform. Clearing its attributes
+	// plans an update, which creates the user again.
+	if user == nil {
+		data.DefaultSchema = types.StringNull()
+		data.Roles = stringSetValue(nil)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
+	roles, err := r.client.GetUserRoles(ctx, data.DatabaseName.ValueString(), user.Name)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read user roles", errorDetail(err))
+		return
+	}
+
+	data.ID = types.StringValue(loginUserID(login.PrincipalID, user))
+	data.UserName = types.StringValue(user.Name)
+	data.DefaultSchema = types.StringValue(user.DefaultSchemaName)
+	data.Roles = authoritativeSet(ctx, data.Roles, roles, r.client.NameKey, &resp.Diagnostics)
+	data.SID = types.StringValue(user.SID)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *LoginUserResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data LoginUserResourceModel
+	var state LoginUserResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Updating SQL login and user", map[string]interface{}{
+		"login":    data.LoginName.ValueString(),
+		"database": data.DatabaseName.ValueString(),
+		"user":     data.UserName.ValueString(),
+	})
+
+	// Look the login up by principal ID, so that it is found under its current name
+	loginID, _ := strconv.Atoi(strings.Split(state.ID.ValueString(), "/")[0])
+	current, err := r.client.GetSQLLoginByID(ctx, loginID)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read SQL login", errorDetail(err))
+		return
+	}
+	if current == nil {
+		resp.Diagnostics.AddError("SQL login not found", fmt.Sprintf("Login '%s' (principal ID %d) does not exist anymore.", state.LoginName.ValueString(), loginID))
+		return
+	}
+
+	opts := mssql.UpdateSQLLoginOptions{
+		Name: current.Name,
+	}
+	if data.LoginName.ValueString() != current.Name {
+		name := data.LoginName.ValueString()
+		opts.NewName = &name
+	}
+	if !data.Password.Equal(state.Password) {
+		password := data.Password.ValueString()
+		opts.Password = &password
+	}
+	if !data.DefaultDatabase.Equal(state.DefaultDatabase) {
+		db := data.DefaultDatabase.ValueString()
+		opts.DefaultDatabase = &db
+	}
+	login, err := r.client.UpdateSQLLogin(ctx, opts)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to update SQL login", errorDetail(err))
+		return
+	}
+	if login == nil {
+		resp.Diagnostics.AddError("SQL login not found", fmt.Sprintf("Login '%s' does not exist anymore.", data.LoginName.ValueString()))
+		return
+	}
+
+	var roles, currentRoles []string
+	if !data.Roles.IsNull() && !data.Roles.IsUnknown() {
+		resp.Diagnostics.Append(data.Roles.ElementsAs(ctx, &roles, false)...)
+	}
+	resp.Diagnostics.Append(state.Roles.ElementsAs(ctx, &currentRoles, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	user, err := r.client.GetUser(ctx, data.DatabaseName.ValueString(), data.UserName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read SQL user", errorDetail(err))
+		return
+	}
+	if user == nil {
+		user, err = r.createUser(ctx, data, roles)
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to create SQL user", errorDetail(err))
+			return
+		}
+	} else {
+		if !data.DefaultSchema.Equal(state.DefaultSchema) {
+			schema := data.DefaultSchema.ValueString()
+			user, err = r.client.UpdateSQLUser(ctx, mssql.UpdateSQLUserOptions{
+				DatabaseName:  data.DatabaseName.ValueString(),
+				UserName:      data.UserName.ValueString(),
+				DefaultSchema: &schema,
+			})
+			if err != nil {
+				resp.Diagnostics.AddError("Failed to update SQL user", errorDetail(err))
+				return
+			}
+		}
+
+		add, remove := diffNames(currentRoles, roles, r.client.NameKey)
+		for _, role := range remove {
+			if !checkDbOwnerRemoval(ctx, r.client, data.DatabaseName.ValueString(), role, data.UserName.ValueString(), &resp.Diagnostics) {
+				return
+			}
+		}
+		err = r.client.UpdateDatabaseRoleMemberships(ctx, data.DatabaseName.ValueString(), data.UserName.ValueString(), add, remove)
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to update roles", errorDetail(err))
+			return
+		}
+	}
+
+	data.ID = types.StringValue(loginUserID(login.PrincipalID, user))
+	data.Roles = stringSetValue(roles)
+	data.SID = types.StringValue(user.SID)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *LoginUserResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data LoginUserResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Deleting SQL user and login", map[string]interface{}{
+		"login":    data.LoginName.ValueString(),
+		"database": data.DatabaseName.ValueString(),
+		"user":     data.UserName.ValueString(),
+	})
+
+	// The user is dropped first, so that it is not left orphaned
+	err := r.client.DropUser(ctx, data.DatabaseName.ValueString(), data.UserName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to delete SQL user", errorDetail(err))
+		return
+	}
+
+	err = r.client.DropSQLLogin(ctx, data.LoginName.ValueString())
+	// The login is already gone
+	if errors.Is(err, mssql.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to delete SQL login", errorDetail(err))
+		return
+	}
+}
+
+func (r *LoginUserResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Import format: database_name/user_name
+	parts := strings.Split(req.ID, "/")
+	if len(parts) != 2 {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			"Import ID must be in format 'database_name/user_name'",
+		)
+		return
+	}
+
+	databaseName := parts[0]
+	userName := parts[1]
+
+	user, err := r.client.GetUser(ctx, databaseName, userName)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to import SQL user", errorDetail(err))
+		return
+	}
+	if user == nil {
+		resp.Diagnostics.AddError("SQL user not found", fmt.Sprintf("User '%s' not found in database '%s'", userName, databaseName))
+		return
+	}
+	if user.LoginName == "" {
+		resp.Diagnostics.AddError("SQL user not mapped to a login", fmt.Sprintf("User '%s' in database '%s' is not mapped to a login", userName, databaseName))
+		return
+	}
+
+	login, err := r.client.GetSQLLogin(ctx, user.LoginName)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to import SQL login", errorDetail(err))
+		return
+	}
+	if login == nil || login.Type != mssql.LoginTypeSQL {
+		resp.Diagnostics.AddError("Not a SQL login", fmt.Sprintf("User '%s' is mapped to login '%s', which is not a SQL login", userName, user.LoginName))
+		return
+	}
+
+	roles, err := r.client.GetUserRoles(ctx, databaseName, user.Name)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to import user roles", errorDetail(err))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), loginUserID(login.PrincipalID, user))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("login_name"), login.Name)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("database_name"), databaseName)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("user_name"), user.Name)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("default_database"), login.DefaultDatabaseName)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("default_schema"), user.DefaultSchemaName)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("roles"), stringSetValue(roles))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("sid"), user.SID)...)
+}
+
+// createUser creates the user mapped to the login and adds it to its roles.
+func (r *LoginUserResource) createUser(ctx context.Context, data LoginUserResourceModel, roles []string) (*mssql.User, error) {
+	user, err := r.client.CreateSQLUser(ctx, mssql.CreateSQLUserOptions{
+		DatabaseName:  data.DatabaseName.ValueString(),
+		UserName:      data.UserName.ValueString(),
+		LoginName:     data.LoginName.ValueString(),
+		DefaultSchema: data.DefaultSchema.ValueString(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.client.UpdateDatabaseRoleMemberships(ctx, data.DatabaseName.ValueString(), user.Name, roles, nil)
+	if err != nil {
+		// Don't leave a user behind that isn't tracked in the state. It is
+		// dropped before the caller drops the login it is mapped to.
+		if dropErr := r.client.DropUser(ctx, data.DatabaseName.ValueString(), user.Name); dropErr != nil {
+			tflog.Warn(ctx, "Failed to drop SQL user after its roles could not be granted", map[string]interface{}{
+				"database": data.DatabaseName.ValueString(),
+				"user":     user.Name,
+				"error":    dropErr.Error(),
+			})
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// loginUserID builds the ID of a login and the user mapped to it.
+func loginUserID(loginPrincipalID int, user *mssql.User) string {
+	return fmt.Sprintf("%d/%d/%d", loginPrincipalID, user.DatabaseID, user.PrincipalID)
+}
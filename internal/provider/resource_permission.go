@@ -0,0 +1,328 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/muecahit94/terraform-provider-mssql/internal/mssql"
+)
+
+var _ resource.Resource = &PermissionResource{}
+var _ resource.ResourceWithImportState = &PermissionResource{}
+var _ resource.ResourceWithModifyPlan = &PermissionResource{}
+var _ resource.ResourceWithValidateConfig = &PermissionResource{}
+
+func NewPermissionResource() resource.Resource {
+	return &PermissionResource{}
+}
+
+// PermissionResource grants or denies a permission on a securable of any
+// class of a database, e.g. an object, type or XML schema collection, for the
+// securables that have no resource of their own.
+type PermissionResource struct {
+	client *mssql.Client
+}
+
+type PermissionResourceModel struct {
+	ID              types.String `tfsdk:"id"`
+	DatabaseName    types.String `tfsdk:"database_name"`
+	Class           types.String `tfsdk:"class"`
+	SecurableName   types.String `tfsdk:"securable_name"`
+	PrincipalName   types.String `tfsdk:"principal_name"`
+	Permission      types.String `tfsdk:"permission"`
+	State           types.String `tfsdk:"state"`
+	WithGrantOption types.Bool   `tfsdk:"with_grant_option"`
+	Cascade         types.Bool   `tfsdk:"cascade"`
+}
+
+func (r *PermissionResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_permission"
+}
+
+func (r *PermissionResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Grants or denies a permission on a securable of any class of a database, e.g. OBJECT, TYPE or XML_SCHEMA_COLLECTION.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The permission ID in format 'database_name/principal_name/permission/class/securable_name'.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"database_name": schema.StringAttribute{
+				Description: "The name of the database holding the securable.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"class": schema.StringAttribute{
+				Description: fmt.Sprintf("The securable class, one of %s. Spaces may be used instead of underscores.", strings.Join(mssql.PermissionClassNames(), ", ")),
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"securable_name": schema.StringAttribute{
+				Description: "The name of the securable. OBJECT, TYPE and XML_SCHEMA_COLLECTION names may be schema-qualified, e.g. 'dbo.orders'. For DATABASE, it must be database_name.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"principal_name": schema.StringAttribute{
+				Description: "The name of the database principal (user or role) the permission is granted or denied to.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"permission": schema.StringAttribute{
+				Description: "The permission, e.g. SELECT, EXECUTE, REFERENCES or CONTROL.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"state": schema.StringAttribute{
+				Description: "Whether the permission is granted (GRANT) or denied (DENY). Defaults to GRANT. If the permission is found in another state, it is revoked and granted or denied again.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(mssql.PermissionStateGrant),
+			},
+			"with_grant_option": schema.BoolAttribute{
+				Description: "Whether the principal can grant this permission to others. Only valid with state GRANT. Defaults to the provider's default_with_grant_option for grants.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"cascade": schema.BoolAttribute{
+				Description: "Whether revoking the permission also revokes the permissions the principal granted onwards. If false, the revoke fails while such grants exist; without them, CASCADE is added automatically, since SQL Server requires it to revoke a permission held with the grant option. Defaults to false.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+		},
+	}
+}
+
+func (r *PermissionResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Resource Configure Type", fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData))
+		return
+	}
+	r.client = client
+}
+
+func (r *PermissionResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data PermissionResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.Class.IsUnknown() && !mssql.IsPermissionClass(data.Class.ValueString()) {
+		resp.Diagnostics.AddAttributeError(path.Root("class"), "Unsupported securable class",
+			fmt.Sprintf("class must be one of %s, got: %s", strings.Join(mssql.PermissionClassNames(), ", "), data.Class.ValueString()))
+	}
+	if !data.State.IsNull() && !data.State.IsUnknown() {
+		state := data.State.ValueString()
+		if state != mssql.PermissionStateGrant && state != mssql.PermissionStateDeny {
+			resp.Diagnostics.AddAttributeError(path.Root("state"), "Invalid permission state",
+				fmt.Sprintf("state must be '%s' or '%s', got: %s", mssql.PermissionStateGrant, mssql.PermissionStateDeny, state))
+		} else if state == mssql.PermissionStateDeny && data.WithGrantOption.ValueBool() {
+			resp.Diagnostics.AddAttributeError(path.Root("with_grant_option"), "Grant option on a denied permission",
+				"with_grant_option can only be set for granted permissions")
+		}
+	}
+	// SQL Server only grants permissions on the current database
+	if mssql.PermissionClassName(data.Class.ValueString()) == mssql.SecurableTypeDatabase && !data.DatabaseName.IsUnknown() && !data.SecurableName.IsUnknown() &&
+		!r.client.NamesEqual(data.SecurableName.ValueString(), data.DatabaseName.ValueString()) {
+		resp.Diagnostics.AddAttributeError(path.Root("securable_name"), "Database securable in another database",
+			fmt.Sprintf("Permissions on database '%s' must be granted in that database; set database_name to '%s'.",
+				data.SecurableName.ValueString(), data.SecurableName.ValueString()))
+	}
+}
+
+// ModifyPlan plans the provider's default_with_grant_option for a grant that
+// leaves with_grant_option unset. A denied permission never has the grant
+// option.
+func (r *PermissionResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var configured types.Bool
+	var state types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("with_grant_option"), &configured)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("state"), &state)...)
+	if resp.Diagnostics.HasError() || !configured.IsNull() || state.IsUnknown() {
+		return
+	}
+
+	withGrantOption := state.ValueString() == mssql.PermissionStateGrant && r.client != nil && r.client.DefaultWithGrantOption()
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("with_grant_option"), withGrantOption)...)
+}
+
+func (m *PermissionResourceModel) id() string {
+	return strings.Join([]string{
+		m.DatabaseName.ValueString(),
+		m.PrincipalName.ValueString(),
+		mssql.NormalizePermissionName(m.Permission.ValueString()),
+		mssql.PermissionClassName(m.Class.ValueString()),
+		m.SecurableName.ValueString(),
+	}, "/")
+}
+
+// apply grants or denies the permission as configured.
+func (r *PermissionResource) apply(ctx context.Context, data *PermissionResourceModel) error {
+	if data.State.ValueString() == mssql.PermissionStateDeny {
+		return r.client.DenyPermission(ctx, data.DatabaseName.ValueString(), data.Class.ValueString(), data.SecurableName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString())
+	}
+	return r.client.GrantPermission(ctx, data.DatabaseName.ValueString(), data.Class.ValueString(), data.SecurableName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString(), data.WithGrantOption.ValueBool())
+}
+
+func (r *PermissionResource) revoke(ctx context.Context, data *PermissionResourceModel) error {
+	return r.client.RevokePermission(ctx, data.DatabaseName.ValueString(), data.Class.ValueString(), data.SecurableName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString(), data.Cascade.ValueBool())
+}
+
+func (r *PermissionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data PermissionResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.apply(ctx, &data); err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("Failed to %s permission", strings.ToLower(data.State.ValueString())), errorDetail(err))
+		return
+	}
+
+	data.ID = types.StringValue(data.id())
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *PermissionResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data PermissionResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	perm, err := r.client.GetPermission(ctx, data.DatabaseName.ValueString(), data.Class.ValueString(), data.SecurableName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read permission", errorDetail(err))
+		return
+	}
+	if perm == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Keep the configured spelling, e.g. lower case or extra spaces
+	if mssql.NormalizePermissionName(data.Permission.ValueString()) != perm.PermissionName {
+		data.Permission = types.StringValue(perm.PermissionName)
+	}
+	setPermissionState(&data, perm)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *PermissionResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state PermissionResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A GRANT cannot be turned into a DENY or lose its grant option in place,
+	// so the permission is revoked and applied again
+	if !data.State.Equal(state.State) || !data.WithGrantOption.Equal(state.WithGrantOption) {
+		if err := r.revoke(ctx, &data); err != nil {
+			resp.Diagnostics.AddError("Failed to revoke permission", errorDetail(err))
+			return
+		}
+		if err := r.apply(ctx, &data); err != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("Failed to %s permission", strings.ToLower(data.State.ValueString())), errorDetail(err))
+			return
+		}
+	}
+
+	data.ID = types.StringValue(data.id())
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *PermissionResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data PermissionResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.revoke(ctx, &data); err != nil {
+		resp.Diagnostics.AddError("Failed to revoke permission", errorDetail(err))
+		return
+	}
+}
+
+func (r *PermissionResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts := strings.SplitN(req.ID, "/", 5)
+	if len(parts) != 5 || parts[4] == "" || !mssql.IsPermissionClass(parts[3]) {
+		resp.Diagnostics.AddError("Invalid import ID", "Import ID must be in format 'database_name/principal_name/permission/class/securable_name'")
+		return
+	}
+	data := PermissionResourceModel{
+		DatabaseName:  types.StringValue(parts[0]),
+		PrincipalName: types.StringValue(parts[1]),
+		Permission:    types.StringValue(parts[2]),
+		Class:         types.StringValue(mssql.PermissionClassName(parts[3])),
+		SecurableName: types.StringValue(parts[4]),
+	}
+
+	perm, err := r.client.GetPermission(ctx, data.DatabaseName.ValueString(), data.Class.ValueString(), data.SecurableName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to import permission", errorDetail(err))
+		return
+	}
+	if perm == nil {
+		resp.Diagnostics.AddError("Permission not found", fmt.Sprintf("Permission '%s' on %s '%s' not found for '%s'", data.Permission.ValueString(), data.Class.ValueString(), data.SecurableName.ValueString(), data.PrincipalName.ValueString()))
+		return
+	}
+
+	if mssql.NormalizePermissionName(data.Permission.ValueString()) != perm.PermissionName {
+		data.Permission = types.StringValue(perm.PermissionName)
+	}
+	data.Cascade = types.BoolValue(false)
+	setPermissionState(&data, perm)
+	data.ID = types.StringValue(data.id())
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// setPermissionState sets state and with_grant_option from the state_desc of
+// a permission read from the server.
+func setPermissionState(data *PermissionResourceModel, perm *mssql.DatabasePermission) {
+	if perm.StateDesc == mssql.PermissionStateDeny {
+		data.State = types.StringValue(mssql.PermissionStateDeny)
+		data.WithGrantOption = types.BoolValue(false)
+		return
+	}
+	data.State = types.StringValue(mssql.PermissionStateGrant)
+	data.WithGrantOption = types.BoolValue(perm.WithGrantOption)
+}
@@ -0,0 +1,313 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/muecahit94/terraform-provider-mssql/internal/mssql"
+)
+
+var _ resource.Resource = &QueryStoreResource{}
+var _ resource.ResourceWithImportState = &QueryStoreResource{}
+var _ resource.ResourceWithValidateConfig = &QueryStoreResource{}
+
+func NewQueryStoreResource() resource.Resource {
+	return &QueryStoreResource{}
+}
+
+type QueryStoreResource struct {
+	client *mssql.Client
+}
+
+type QueryStoreResourceModel struct {
+	ID                      types.String `tfsdk:"id"`
+	DatabaseName            types.String `tfsdk:"database_name"`
+	Enabled                 types.Bool   `tfsdk:"enabled"`
+	OperationMode           types.String `tfsdk:"operation_mode"`
+	MaxStorageSizeMB        types.Int64  `tfsdk:"max_storage_size_mb"`
+	QueryCaptureMode        types.String `tfsdk:"query_capture_mode"`
+	StaleQueryThresholdDays types.Int64  `tfsdk:"stale_query_threshold_days"`
+}
+
+func (r *QueryStoreResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_query_store"
+}
+
+func (r *QueryStoreResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages the Query Store configuration of a database.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The name of the database.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"database_name": schema.StringAttribute{
+				Description: "The name of the database.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"enabled": schema.BoolAttribute{
+				Description: "Whether Query Store is turned on.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+			},
+			"operation_mode": schema.StringAttribute{
+				Description: "The operation mode: READ_WRITE or READ_ONLY, case-insensitive.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"max_storage_size_mb": schema.Int64Attribute{
+				Description: "The maximum storage size of the Query Store in MB.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"query_capture_mode": schema.StringAttribute{
+				Description: "The query capture mode: ALL, AUTO, NONE or CUSTOM, case-insensitive.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"stale_query_threshold_days": schema.Int64Attribute{
+				Description: "The number of days to retain query information.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *QueryStoreResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Resource Configure Type", fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData))
+		return
+	}
+	r.client = client
+}
+
+func (r *QueryStoreResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data QueryStoreResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validateQueryStoreMode(data.OperationMode, "operation_mode", mssql.QueryStoreOperationModes, &resp.Diagnostics)
+	validateQueryStoreMode(data.QueryCaptureMode, "query_capture_mode", mssql.QueryStoreCaptureModes, &resp.Diagnostics)
+}
+
+// validateQueryStoreMode adds an error if a configured mode is not one of
+// modes. Modes are keywords, so they are matched case-insensitively.
+func validateQueryStoreMode(mode types.String, attribute string, modes []string, diags *diag.Diagnostics) {
+	if mode.IsNull() || mode.IsUnknown() || slices.Contains(modes, strings.ToUpper(mode.ValueString())) {
+		return
+	}
+	diags.AddAttributeError(path.Root(attribute), "Invalid Query Store mode",
+		fmt.Sprintf("%s must be one of %s, got: %s", attribute, strings.Join(modes, ", "), mode.ValueString()))
+}
+
+// queryStoreModeValue keeps the configured spelling of a mode, which SQL
+// Server reports in upper case.
+func queryStoreModeValue(configured types.String, actual string) types.String {
+	if strings.EqualFold(configured.ValueString(), actual) {
+		return configured
+	}
+	return types.StringValue(actual)
+}
+
+// apply turns Query Store on or off with the configured options and stores the result.
+func (r *QueryStoreResource) apply(ctx context.Context, data *QueryStoreResourceModel) error {
+	opts := mssql.SetQueryStoreOptionsOptions{
+		DatabaseName: data.DatabaseName.ValueString(),
+		Enabled:      data.Enabled.ValueBool(),
+	}
+	if !data.OperationMode.IsNull() && !data.OperationMode.IsUnknown() {
+		mode := data.OperationMode.ValueString()
+		opts.OperationMode = &mode
+	}
+	if !data.MaxStorageSizeMB.IsNull() && !data.MaxStorageSizeMB.IsUnknown() {
+		size := data.MaxStorageSizeMB.ValueInt64()
+		opts.MaxStorageSizeMB = &size
+	}
+	if !data.QueryCaptureMode.IsNull() && !data.QueryCaptureMode.IsUnknown() {
+		mode := data.QueryCaptureMode.ValueString()
+		opts.QueryCaptureMode = &mode
+	}
+	if !data.StaleQueryThresholdDays.IsNull() && !data.StaleQueryThresholdDays.IsUnknown() {
+		days := data.StaleQueryThresholdDays.ValueInt64()
+		opts.StaleQueryThresholdDays = &days
+	}
+
+	qs, err := r.client.SetQueryStoreOptions(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if qs == nil {
+		return fmt.Errorf("query store options for database '%s' not found", data.DatabaseName.ValueString())
+	}
+
+	data.ID = types.StringValue(data.DatabaseName.ValueString())
+	setQueryStoreState(data, qs)
+	return nil
+}
+
+// setQueryStoreState copies the server values into the model. While Query
+// Store is off the remaining options are not applied, so configured values are
+// kept to avoid a permanent diff.
+func setQueryStoreState(data *QueryStoreResourceModel, qs *mssql.QueryStoreOptions) {
+	data.Enabled = types.BoolValue(qs.Enabled)
+	if qs.Enabled || data.OperationMode.IsNull() || data.OperationMode.IsUnknown() {
+		data.OperationMode = queryStoreModeValue(data.OperationMode, qs.OperationMode)
+	}
+	if qs.Enabled || data.MaxStorageSizeMB.IsNull() || data.MaxStorageSizeMB.IsUnknown() {
+		data.MaxStorageSizeMB = types.Int64Value(qs.MaxStorageSizeMB)
+	}
+	if qs.Enabled || data.QueryCaptureMode.IsNull() || data.QueryCaptureMode.IsUnknown() {
+		data.QueryCaptureMode = queryStoreModeValue(data.QueryCaptureMode, qs.QueryCaptureMode)
+	}
+	if qs.Enabled || data.StaleQueryThresholdDays.IsNull() || data.StaleQueryThresholdDays.IsUnknown() {
+		data.StaleQueryThresholdDays = types.Int64Value(qs.StaleQueryThresholdDays)
+	}
+}
+
+func (r *QueryStoreResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data QueryStoreResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Configuring query store", map[string]interface{}{
+		"database": data.DatabaseName.ValueString(),
+		"enabled":  data.Enabled.ValueBool(),
+	})
+
+	if err := r.apply(ctx, &data); err != nil {
+		resp.Diagnostics.AddError("Failed to configure query store", errorDetail(err))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *QueryStoreResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data QueryStoreResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	db, err := r.client.GetDatabase(ctx, data.DatabaseName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read database", errorDetail(err))
+		return
+	}
+	if db == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	qs, err := r.client.GetQueryStoreOptions(ctx, data.DatabaseName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read query store options", errorDetail(err))
+		return
+	}
+	if qs == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	setQueryStoreState(&data, qs)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *QueryStoreResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data QueryStoreResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.apply(ctx, &data); err != nil {
+		resp.Diagnostics.AddError("Failed to configure query store", errorDetail(err))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *QueryStoreResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data QueryStoreResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Turning off query store", map[string]interface{}{
+		"database": data.DatabaseName.ValueString(),
+	})
+
+	_, err := r.client.SetQueryStoreOptions(ctx, mssql.SetQueryStoreOptionsOptions{
+		DatabaseName: data.DatabaseName.ValueString(),
+		Enabled:      false,
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to turn off query store", errorDetail(err))
+		return
+	}
+}
+
+func (r *QueryStoreResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	qs, err := r.client.GetQueryStoreOptions(ctx, req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to import query store", errorDetail(err))
+		return
+	}
+	if qs == nil {
+		resp.Diagnostics.AddError("Query store not found", fmt.Sprintf("Query store options for database '%s' not found", req.ID))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("database_name"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("enabled"), qs.Enabled)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("operation_mode"), qs.OperationMode)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("max_storage_size_mb"), qs.MaxStorageSizeMB)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("query_capture_mode"), qs.QueryCaptureMode)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("stale_query_threshold_days"), qs.StaleQueryThresholdDays)...)
+}
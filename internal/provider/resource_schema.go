@@ -5,6 +5,7 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -19,6 +20,8 @@ import (
 
 var _ resource.Resource = &SchemaResource{}
 var _ resource.ResourceWithImportState = &SchemaResource{}
+var _ resource.ResourceWithValidateConfig = &SchemaResource{}
+var _ resource.ResourceWithModifyPlan = &SchemaResource{}
 
 func NewSchemaResource() resource.Resource {
 	return &SchemaResource{}
@@ -29,10 +32,11 @@ type SchemaResource struct {
 }
 
 type SchemaResourceModel struct {
-	ID           types.String `tfsdk:"id"`
-	DatabaseName types.String `tfsdk:"database_name"`
-	Name         types.String `tfsdk:"name"`
-	OwnerName    types.String `tfsdk:"owner_name"`
+	ID            types.String `tfsdk:"id"`
+	DatabaseName  types.String `tfsdk:"database_name"`
+	Name          types.String `tfsdk:"name"`
+	OwnerName     types.String `tfsdk:"owner_name"`
+	CreateObjects types.List   `tfsdk:"create_objects"`
 }
 
 func (r *SchemaResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -68,6 +72,14 @@ func (r *SchemaResource) Schema(ctx context.Context, req resource.SchemaRequest,
 				Description: "The owner of the schema.",
 				Optional:    true,
 				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"create_objects": schema.ListAttribute{
+				Description: "CREATE TABLE, CREATE VIEW, GRANT, REVOKE or DENY statements run in the same batch as CREATE SCHEMA. Only applied when the schema is created.",
+				Optional:    true,
+				ElementType: types.StringType,
 			},
 		},
 	}
@@ -85,6 +97,42 @@ func (r *SchemaResource) Configure(ctx context.Context, req resource.ConfigureRe
 	r.client = client
 }
 
+func (r *SchemaResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data SchemaResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.CreateObjects.IsNull() || data.CreateObjects.IsUnknown() {
+		return
+	}
+
+	for i, element := range data.CreateObjects.Elements() {
+		statement, ok := element.(types.String)
+		if !ok || statement.IsUnknown() {
+			continue
+		}
+		if !mssql.IsSchemaElement(statement.ValueString()) {
+			resp.Diagnostics.AddAttributeError(path.Root("create_objects").AtListIndex(i), "Unsupported schema element",
+				fmt.Sprintf("create_objects only supports CREATE TABLE, CREATE VIEW, GRANT, REVOKE and DENY statements, got: %s", statement.ValueString()))
+		}
+	}
+}
+
+// ModifyPlan warns that a change to create_objects is not applied to an
+// existing schema.
+func (r *SchemaResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var planned, current types.List
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("create_objects"), &planned)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("create_objects"), &current)...)
+	if resp.Diagnostics.HasError() || planned.Equal(current) {
+		return
+	}
+	resp.Diagnostics.AddAttributeWarning(path.Root("create_objects"), "create_objects change not applied",
+		"create_objects is only run when the schema is created. The change is stored in the state, but no statements are run for the existing schema.")
+}
+
 func (r *SchemaResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data SchemaResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -92,13 +140,40 @@ func (r *SchemaResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
-	schema, err := r.client.CreateSchema(ctx, mssql.CreateSchemaOptions{
+	opts := mssql.CreateSchemaOptions{
 		DatabaseName: data.DatabaseName.ValueString(),
 		SchemaName:   data.Name.ValueString(),
 		OwnerName:    data.OwnerName.ValueString(),
-	})
+	}
+	if !data.CreateObjects.IsNull() {
+		resp.Diagnostics.Append(data.CreateObjects.ElementsAs(ctx, &opts.Elements, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	schema, err := r.client.CreateSchema(ctx, opts)
+	if errors.Is(err, mssql.ErrAlreadyExists) {
+		// Adopt the existing schema if it matches the desired owner
+		existing, getErr := r.client.GetSchema(ctx, opts.DatabaseName, opts.SchemaName)
+		if getErr != nil {
+			resp.Diagnostics.AddError("Failed to read existing schema", errorDetail(getErr))
+			return
+		}
+		if existing != nil && ownerMatches(r.client, opts.OwnerName, existing.OwnerName) {
+			// create_objects only runs together with CREATE SCHEMA
+			if len(opts.Elements) > 0 {
+				resp.Diagnostics.AddAttributeError(path.Root("create_objects"), "Cannot adopt existing schema",
+					fmt.Sprintf("Schema '%s' already exists in database '%s', so create_objects would not be run. Create the objects separately, e.g. with mssql_script, and remove create_objects to adopt the schema.", opts.SchemaName, opts.DatabaseName))
+				return
+			}
+			resp.Diagnostics.AddWarning("Schema already exists",
+				fmt.Sprintf("Schema '%s' already exists in database '%s' and has been adopted into the Terraform state.", opts.SchemaName, opts.DatabaseName))
+			schema, err = existing, nil
+		}
+	}
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to create schema", err.Error())
+		resp.Diagnostics.AddError("Failed to create schema", errorDetail(err))
 		return
 	}
 
@@ -116,7 +191,7 @@ func (r *SchemaResource) Read(ctx context.Context, req resource.ReadRequest, res
 
 	schema, err := r.client.GetSchema(ctx, data.DatabaseName.ValueString(), data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to read schema", err.Error())
+		resp.Diagnostics.AddError("Failed to read schema", errorDetail(err))
 		return
 	}
 	if schema == nil {
@@ -144,7 +219,7 @@ func (r *SchemaResource) Update(ctx context.Context, req resource.UpdateRequest,
 			NewOwnerName: &owner,
 		})
 		if err != nil {
-			resp.Diagnostics.AddError("Failed to update schema", err.Error())
+			resp.Diagnostics.AddError("Failed to update schema", errorDetail(err))
 			return
 		}
 	}
@@ -161,7 +236,7 @@ func (r *SchemaResource) Delete(ctx context.Context, req resource.DeleteRequest,
 
 	err := r.client.DropSchema(ctx, data.DatabaseName.ValueString(), data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to delete schema", err.Error())
+		resp.Diagnostics.AddError("Failed to delete schema", errorDetail(err))
 		return
 	}
 }
@@ -169,13 +244,15 @@ func (r *SchemaResource) Delete(ctx context.Context, req resource.DeleteRequest,
 func (r *SchemaResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	parts := strings.Split(req.ID, "/")
 	if len(parts) != 2 {
-		resp.Diagnostics.AddError("Invalid import ID", "Import ID must be in format 'database_name/schema_name'")
+		resp.Diagnostics.AddError("Invalid import ID", "Import ID must be in format 'database_name/schema_name' or 'database_name/schema_id'")
 		return
 	}
 
-	schema, err := r.client.GetSchema(ctx, parts[0], parts[1])
+	schema, err := lookupByNameOrID(parts[1],
+		func(id int) (*mssql.Schema, error) { return r.client.GetSchemaByID(ctx, parts[0], id) },
+		func(name string) (*mssql.Schema, error) { return r.client.GetSchema(ctx, parts[0], name) })
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to import schema", err.Error())
+		resp.Diagnostics.AddError("Failed to import schema", errorDetail(err))
 		return
 	}
 	if schema == nil {
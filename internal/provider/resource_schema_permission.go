@@ -20,6 +20,7 @@ import (
 
 var _ resource.Resource = &SchemaPermissionResource{}
 var _ resource.ResourceWithImportState = &SchemaPermissionResource{}
+var _ resource.ResourceWithModifyPlan = &SchemaPermissionResource{}
 
 func NewSchemaPermissionResource() resource.Resource {
 	return &SchemaPermissionResource{}
@@ -36,6 +37,8 @@ type SchemaPermissionResourceModel struct {
 	PrincipalName   types.String `tfsdk:"principal_name"`
 	Permission      types.String `tfsdk:"permission"`
 	WithGrantOption types.Bool   `tfsdk:"with_grant_option"`
+	Cascade         types.Bool   `tfsdk:"cascade"`
+	State           types.String `tfsdk:"state"`
 }
 
 func (r *SchemaPermissionResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -77,9 +80,22 @@ func (r *SchemaPermissionResource) Schema(ctx context.Context, req resource.Sche
 				},
 			},
 			"with_grant_option": schema.BoolAttribute{
-				Optional: true,
-				Computed: true,
-				Default:  booldefault.StaticBool(false),
+				Description: "Whether the principal can grant this permission to others. Defaults to the provider's default_with_grant_option.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"cascade": schema.BoolAttribute{
+				Description: "Whether revoking the permission or its grant option also revokes the permissions the principal granted onwards. If false, the revoke fails while such grants exist; without them, CASCADE is added automatically, since SQL Server requires it to revoke a permission held with the grant option. Defaults to false.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"state": schema.StringAttribute{
+				Description: "The current state of the permission (GRANT, GRANT_WITH_GRANT_OPTION or DENY). If the permission is found DENYed, it is revoked and granted again.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					permissionStatePlanModifier{},
+				},
 			},
 		},
 	}
@@ -97,6 +113,10 @@ func (r *SchemaPermissionResource) Configure(ctx context.Context, req resource.C
 	r.client = client
 }
 
+func (r *SchemaPermissionResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planWithGrantOptionDefault(ctx, r.client, req, resp)
+}
+
 func (r *SchemaPermissionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data SchemaPermissionResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -106,11 +126,12 @@ func (r *SchemaPermissionResource) Create(ctx context.Context, req resource.Crea
 
 	err := r.client.GrantSchemaPermission(ctx, data.DatabaseName.ValueString(), data.SchemaName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString(), data.WithGrantOption.ValueBool())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to grant schema permission", err.Error())
+		resp.Diagnostics.AddError("Failed to grant schema permission", errorDetail(err))
 		return
 	}
 
 	data.ID = types.StringValue(fmt.Sprintf("%s/%s/%s/%s", data.DatabaseName.ValueString(), data.SchemaName.ValueString(), data.PrincipalName.ValueString(), strings.ToUpper(data.Permission.ValueString())))
+	data.State = types.StringValue(desiredPermissionState(data.WithGrantOption.ValueBool()))
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -123,7 +144,7 @@ func (r *SchemaPermissionResource) Read(ctx context.Context, req resource.ReadRe
 
 	perm, err := r.client.GetSchemaPermission(ctx, data.DatabaseName.ValueString(), data.SchemaName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to read schema permission", err.Error())
+		resp.Diagnostics.AddError("Failed to read schema permission", errorDetail(err))
 		return
 	}
 	if perm == nil {
@@ -132,10 +153,16 @@ func (r *SchemaPermissionResource) Read(ctx context.Context, req resource.ReadRe
 	}
 
 	// Only update WithGrantOption if this is a real permission (DatabaseID > 0).
-	// Virtual permissions for schema owners have DatabaseID = 0 and we should
-	// preserve the Terraform-configured value to avoid drift.
-	if perm.DatabaseID > 0 {
+	// Virtual permissions for schema owners or permissions covered by CONTROL
+	// have DatabaseID = 0 and we should preserve the Terraform-configured value
+	// to avoid drift.
+	// CONTROL without the grant option does not cover a configured grant
+	// option, so its state is reported and the grant option is granted.
+	if perm.DatabaseID > 0 || (data.WithGrantOption.ValueBool() && !perm.WithGrantOption) {
 		data.WithGrantOption = types.BoolValue(perm.WithGrantOption)
+		data.State = types.StringValue(perm.StateDesc)
+	} else {
+		data.State = types.StringValue(desiredPermissionState(data.WithGrantOption.ValueBool()))
 	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -148,17 +175,33 @@ func (r *SchemaPermissionResource) Update(ctx context.Context, req resource.Upda
 		return
 	}
 
-	if !data.WithGrantOption.Equal(state.WithGrantOption) {
-		if err := r.client.RevokeSchemaPermission(ctx, data.DatabaseName.ValueString(), data.SchemaName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString()); err != nil {
-			resp.Diagnostics.AddError("Failed to revoke schema permission", err.Error())
+	databaseName, schemaName := data.DatabaseName.ValueString(), data.SchemaName.ValueString()
+	principalName, permission := data.PrincipalName.ValueString(), data.Permission.ValueString()
+	switch {
+	case state.State.ValueString() == mssql.PermissionStateDeny:
+		// The permission was DENYed outside of Terraform: revoke and re-grant
+		if err := r.client.RevokeSchemaPermission(ctx, databaseName, schemaName, principalName, permission, data.Cascade.ValueBool()); err != nil {
+			resp.Diagnostics.AddError("Failed to revoke schema permission", errorDetail(err))
+			return
+		}
+		if err := r.client.GrantSchemaPermission(ctx, databaseName, schemaName, principalName, permission, data.WithGrantOption.ValueBool()); err != nil {
+			resp.Diagnostics.AddError("Failed to grant schema permission", errorDetail(err))
+			return
+		}
+	case state.WithGrantOption.ValueBool() && !data.WithGrantOption.ValueBool():
+		// Only take away the grant option, so the permission itself is kept
+		if err := r.client.RevokeSchemaPermissionGrantOption(ctx, databaseName, schemaName, principalName, permission, data.Cascade.ValueBool()); err != nil {
+			resp.Diagnostics.AddError("Failed to revoke schema permission grant option", errorDetail(err))
 			return
 		}
-		if err := r.client.GrantSchemaPermission(ctx, data.DatabaseName.ValueString(), data.SchemaName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString(), data.WithGrantOption.ValueBool()); err != nil {
-			resp.Diagnostics.AddError("Failed to grant schema permission", err.Error())
+	case !data.WithGrantOption.Equal(state.WithGrantOption) || !data.State.Equal(state.State):
+		if err := r.client.GrantSchemaPermission(ctx, databaseName, schemaName, principalName, permission, data.WithGrantOption.ValueBool()); err != nil {
+			resp.Diagnostics.AddError("Failed to grant schema permission", errorDetail(err))
 			return
 		}
 	}
 
+	data.State = types.StringValue(desiredPermissionState(data.WithGrantOption.ValueBool()))
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -169,9 +212,9 @@ func (r *SchemaPermissionResource) Delete(ctx context.Context, req resource.Dele
 		return
 	}
 
-	err := r.client.RevokeSchemaPermission(ctx, data.DatabaseName.ValueString(), data.SchemaName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString())
+	err := r.client.RevokeSchemaPermission(ctx, data.DatabaseName.ValueString(), data.SchemaName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString(), data.Cascade.ValueBool())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to revoke schema permission", err.Error())
+		resp.Diagnostics.AddError("Failed to revoke schema permission", errorDetail(err))
 		return
 	}
 }
@@ -185,7 +228,7 @@ func (r *SchemaPermissionResource) ImportState(ctx context.Context, req resource
 
 	perm, err := r.client.GetSchemaPermission(ctx, parts[0], parts[1], parts[2], parts[3])
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to import schema permission", err.Error())
+		resp.Diagnostics.AddError("Failed to import schema permission", errorDetail(err))
 		return
 	}
 	if perm == nil {
@@ -199,4 +242,6 @@ func (r *SchemaPermissionResource) ImportState(ctx context.Context, req resource
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("principal_name"), parts[2])...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("permission"), perm.PermissionName)...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("with_grant_option"), perm.WithGrantOption)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cascade"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("state"), perm.StateDesc)...)
 }
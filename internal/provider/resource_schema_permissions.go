@@ -0,0 +1,325 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/muecahit94/terraform-provider-mssql/internal/mssql"
+)
+
+var _ resource.Resource = &SchemaPermissionsResource{}
+var _ resource.ResourceWithImportState = &SchemaPermissionsResource{}
+var _ resource.ResourceWithModifyPlan = &SchemaPermissionsResource{}
+
+func NewSchemaPermissionsResource() resource.Resource {
+	return &SchemaPermissionsResource{}
+}
+
+type SchemaPermissionsResource struct {
+	client *mssql.Client
+}
+
+type SchemaPermissionsResourceModel struct {
+	ID            types.String `tfsdk:"id"`
+	DatabaseName  types.String `tfsdk:"database_name"`
+	SchemaName    types.String `tfsdk:"schema_name"`
+	PrincipalName types.String `tfsdk:"principal_name"`
+	Permissions   types.Set    `tfsdk:"permissions"`
+	Ignore        types.Set    `tfsdk:"ignore"`
+}
+
+func (r *SchemaPermissionsResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_schema_permissions"
+}
+
+func (r *SchemaPermissionsResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages the complete set of permissions a principal holds on a schema.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The ID in format 'database_name/schema_name/principal_name'.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"database_name": schema.StringAttribute{
+				Description: "The name of the database.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"schema_name": schema.StringAttribute{
+				Description: "The name of the schema.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"principal_name": schema.StringAttribute{
+				Description: "The name of the principal (user or role).",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"permissions": schema.SetAttribute{
+				Description: "The permissions to grant on the schema. Permissions granted outside of this set are revoked.",
+				Required:    true,
+				ElementType: types.StringType,
+			},
+			"ignore": schema.SetAttribute{
+				Description: "Permissions that are managed outside of this resource, e.g. by another tool. They are neither granted nor revoked, and are not reported as drift.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+		},
+	}
+}
+
+func (r *SchemaPermissionsResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*mssql.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Resource Configure Type", fmt.Sprintf("Expected *mssql.Client, got: %T.", req.ProviderData))
+		return
+	}
+	r.client = client
+}
+
+// ModifyPlan lists the permissions to be changed in the plan.
+func (r *SchemaPermissionsResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	previewSetChanges(ctx, req, resp, permissionKey, "permissions")
+}
+
+// isSchemaOwner reports whether the principal owns the schema and therefore
+// already holds every permission on it. The returned schema is nil if it does
+// not exist.
+func (r *SchemaPermissionsResource) isSchemaOwner(ctx context.Context, data *SchemaPermissionsResourceModel) (*mssql.Schema, bool, error) {
+	s, err := r.client.GetSchema(ctx, data.DatabaseName.ValueString(), data.SchemaName.ValueString())
+	if err != nil || s == nil {
+		return s, false, err
+	}
+	return s, r.client.NamesEqual(s.OwnerName, data.PrincipalName.ValueString()), nil
+}
+
+func (r *SchemaPermissionsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data SchemaPermissionsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var permissions []string
+	resp.Diagnostics.Append(data.Permissions.ElementsAs(ctx, &permissions, false)...)
+	permissions = withoutIgnored(permissions, setElements(ctx, data.Ignore, &resp.Diagnostics), permissionKey)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, isOwner, err := r.isSchemaOwner(ctx, &data)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read schema", errorDetail(err))
+		return
+	}
+
+	// The schema owner implicitly holds every permission on it
+	if !isOwner {
+		applyEach(permissions, "Failed to grant schema permission", "grant", func(permission string) error {
+			return r.client.GrantSchemaPermission(ctx, data.DatabaseName.ValueString(), data.SchemaName.ValueString(), data.PrincipalName.ValueString(), permission, false)
+		}, &resp.Diagnostics)
+	}
+
+	data.ID = types.StringValue(fmt.Sprintf("%s/%s/%s", data.DatabaseName.ValueString(), data.SchemaName.ValueString(), data.PrincipalName.ValueString()))
+	r.refreshAfterFailure(ctx, &data, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SchemaPermissionsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data SchemaPermissionsResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	found := r.refresh(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !found {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// refresh reads the permissions held on the schema into data. It reports
+// whether the schema still exists.
+func (r *SchemaPermissionsResource) refresh(ctx context.Context, data *SchemaPermissionsResourceModel, diags *diag.Diagnostics) bool {
+	s, isOwner, err := r.isSchemaOwner(ctx, data)
+	if err != nil {
+		diags.AddError("Failed to read schema", errorDetail(err))
+		return false
+	}
+	if s == nil {
+		return false
+	}
+
+	perms, err := r.client.ListSchemaPermissions(ctx, data.DatabaseName.ValueString(), data.SchemaName.ValueString(), data.PrincipalName.ValueString())
+	if err != nil {
+		diags.AddError("Failed to read schema permissions", errorDetail(err))
+		return false
+	}
+
+	granted := make(map[string]bool)
+	for _, perm := range perms {
+		if perm.StateDesc != mssql.PermissionStateDeny {
+			granted[perm.PermissionName] = true
+		}
+	}
+	// Owners and holders of CONTROL implicitly have every permission on the schema
+	implied := isOwner || granted["CONTROL"]
+
+	var current []string
+	if !data.Permissions.IsNull() {
+		diags.Append(data.Permissions.ElementsAs(ctx, &current, false)...)
+		if diags.HasError() {
+			return false
+		}
+	}
+
+	// Ignored permissions are kept as configured, whether held or not
+	ignored := keySet(setElements(ctx, data.Ignore, diags), permissionKey)
+
+	// Keep the configured spelling of permissions that are still held
+	var permissions []string
+	for _, permission := range current {
+		name := strings.ToUpper(permission)
+		if granted[name] || implied || ignored[name] {
+			permissions = append(permissions, permission)
+			delete(granted, name)
+		}
+	}
+	// Permissions granted outside of Terraform show up as drift
+	for name := range granted {
+		if !ignored[name] {
+			permissions = append(permissions, name)
+		}
+	}
+
+	sort.Strings(permissions)
+	permissionValues := make([]attr.Value, len(permissions))
+	for i, permission := range permissions {
+		permissionValues[i] = types.StringValue(permission)
+	}
+	data.Permissions, _ = types.SetValue(types.StringType, permissionValues)
+	return true
+}
+
+// refreshAfterFailure reads back the permissions that are actually held when
+// applying some of them failed, so that the state reflects what succeeded.
+func (r *SchemaPermissionsResource) refreshAfterFailure(ctx context.Context, data *SchemaPermissionsResourceModel, diags *diag.Diagnostics) {
+	if !diags.HasError() {
+		return
+	}
+	r.refresh(ctx, data, diags)
+}
+
+func (r *SchemaPermissionsResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state SchemaPermissionsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var desiredPermissions, currentPermissions []string
+	resp.Diagnostics.Append(data.Permissions.ElementsAs(ctx, &desiredPermissions, false)...)
+	resp.Diagnostics.Append(state.Permissions.ElementsAs(ctx, &currentPermissions, false)...)
+	ignored := setElements(ctx, data.Ignore, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	desiredPermissions = withoutIgnored(desiredPermissions, ignored, permissionKey)
+	currentPermissions = withoutIgnored(currentPermissions, ignored, permissionKey)
+
+	_, isOwner, err := r.isSchemaOwner(ctx, &data)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read schema", errorDetail(err))
+		return
+	}
+
+	// The owner implicitly holds every permission on the schema, and SQL
+	// Server rejects GRANT and REVOKE against it, so there is nothing to apply
+	if !isOwner {
+		grant, revoke := diffNames(currentPermissions, desiredPermissions, permissionKey)
+		applyEach(revoke, "Failed to revoke schema permission", "revoke", func(permission string) error {
+			return r.client.RevokeSchemaPermission(ctx, data.DatabaseName.ValueString(), data.SchemaName.ValueString(), data.PrincipalName.ValueString(), permission, false)
+		}, &resp.Diagnostics)
+		applyEach(grant, "Failed to grant schema permission", "grant", func(permission string) error {
+			return r.client.GrantSchemaPermission(ctx, data.DatabaseName.ValueString(), data.SchemaName.ValueString(), data.PrincipalName.ValueString(), permission, false)
+		}, &resp.Diagnostics)
+	}
+
+	r.refreshAfterFailure(ctx, &data, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *SchemaPermissionsResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data SchemaPermissionsResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Ignored permissions are left to whoever manages them
+	var permissions []string
+	resp.Diagnostics.Append(data.Permissions.ElementsAs(ctx, &permissions, false)...)
+	permissions = withoutIgnored(permissions, setElements(ctx, data.Ignore, &resp.Diagnostics), permissionKey)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The owner holds its permissions through ownership, which cannot be revoked
+	_, isOwner, err := r.isSchemaOwner(ctx, &data)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read schema", errorDetail(err))
+		return
+	}
+	if isOwner {
+		return
+	}
+
+	applyEach(permissions, "Failed to revoke schema permission", "revoke", func(permission string) error {
+		return r.client.RevokeSchemaPermission(ctx, data.DatabaseName.ValueString(), data.SchemaName.ValueString(), data.PrincipalName.ValueString(), permission, false)
+	}, &resp.Diagnostics)
+}
+
+func (r *SchemaPermissionsResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts := strings.Split(req.ID, "/")
+	if len(parts) != 3 {
+		resp.Diagnostics.AddError("Invalid import ID", "Import ID must be in format 'database_name/schema_name/principal_name'")
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("database_name"), parts[0])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("schema_name"), parts[1])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("principal_name"), parts[2])...)
+}
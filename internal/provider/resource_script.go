@@ -7,6 +7,8 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
@@ -16,6 +18,7 @@ import (
 )
 
 var _ resource.Resource = &ScriptResource{}
+var _ resource.ResourceWithValidateConfig = &ScriptResource{}
 
 func NewScriptResource() resource.Resource {
 	return &ScriptResource{}
@@ -26,13 +29,15 @@ type ScriptResource struct {
 }
 
 type ScriptResourceModel struct {
-	ID           types.String `tfsdk:"id"`
-	DatabaseName types.String `tfsdk:"database_name"`
-	CreateScript types.String `tfsdk:"create_script"`
-	ReadScript   types.String `tfsdk:"read_script"`
-	UpdateScript types.String `tfsdk:"update_script"`
-	DeleteScript types.String `tfsdk:"delete_script"`
-	State        types.Map    `tfsdk:"state"`
+	ID               types.String `tfsdk:"id"`
+	DatabaseName     types.String `tfsdk:"database_name"`
+	CreateScript     types.String `tfsdk:"create_script"`
+	ReadScript       types.String `tfsdk:"read_script"`
+	UpdateScript     types.String `tfsdk:"update_script"`
+	DeleteScript     types.String `tfsdk:"delete_script"`
+	SetOptions       types.List   `tfsdk:"set_options"`
+	StatementOptions types.List   `tfsdk:"statement_options"`
+	State            types.Map    `tfsdk:"state"`
 }
 
 func (r *ScriptResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -72,6 +77,16 @@ func (r *ScriptResource) Schema(ctx context.Context, req resource.SchemaRequest,
 				Description: "SQL script to execute on resource deletion.",
 				Required:    true,
 			},
+			"set_options": schema.ListAttribute{
+				Description: "SET options applied to the session before each script runs, e.g. 'QUOTED_IDENTIFIER ON'.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"statement_options": schema.ListAttribute{
+				Description: "Options appended to the create and update scripts as a WITH clause, e.g. 'ONLINE = ON' or 'MAXDOP = 4'. The scripts must then each be a single CREATE INDEX or ALTER INDEX ... REBUILD statement.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
 			"state": schema.MapAttribute{
 				Description: "The state returned from the read script.",
 				Computed:    true,
@@ -93,16 +108,84 @@ func (r *ScriptResource) Configure(ctx context.Context, req resource.ConfigureRe
 	r.client = client
 }
 
+func (r *ScriptResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data ScriptResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validateScriptOptions(data.SetOptions, "set_options", "Invalid SET option", mssql.ParseSetOption, &resp.Diagnostics)
+	validateScriptOptions(data.StatementOptions, "statement_options", "Invalid statement option", mssql.ParseStatementOption, &resp.Diagnostics)
+
+	// Statement options are appended to the scripts, which must each be a
+	// single index statement that accepts them
+	if data.StatementOptions.IsNull() || len(data.StatementOptions.Elements()) == 0 {
+		return
+	}
+	for attribute, script := range map[string]types.String{"create_script": data.CreateScript, "update_script": data.UpdateScript} {
+		if script.IsNull() || script.IsUnknown() {
+			continue
+		}
+		if err := mssql.CheckStatementOptionsScript(script.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root(attribute), "Script does not accept statement options", err.Error())
+		}
+	}
+}
+
+// validateScriptOptions checks each known element of a list of options with
+// parse and reports the failures on the element.
+func validateScriptOptions(options types.List, attribute, summary string, parse func(string) (string, error), diags *diag.Diagnostics) {
+	if options.IsNull() || options.IsUnknown() {
+		return
+	}
+
+	for i, element := range options.Elements() {
+		option, ok := element.(types.String)
+		if !ok || option.IsUnknown() {
+			continue
+		}
+		if _, err := parse(option.ValueString()); err != nil {
+			diags.AddAttributeError(path.Root(attribute).AtListIndex(i), summary, err.Error())
+		}
+	}
+}
+
+// setOptions returns the configured SET options.
+func (m *ScriptResourceModel) setOptions(ctx context.Context, diags *diag.Diagnostics) []string {
+	var options []string
+	if !m.SetOptions.IsNull() {
+		diags.Append(m.SetOptions.ElementsAs(ctx, &options, false)...)
+	}
+	return options
+}
+
+// withStatementOptions returns script with the configured statement options
+// appended; see mssql.AppendStatementOptions.
+func (m *ScriptResourceModel) withStatementOptions(ctx context.Context, script string, diags *diag.Diagnostics) string {
+	var options []string
+	if !m.StatementOptions.IsNull() {
+		diags.Append(m.StatementOptions.ElementsAs(ctx, &options, false)...)
+	}
+	script, err := mssql.AppendStatementOptions(script, options)
+	if err != nil {
+		diags.AddAttributeError(path.Root("statement_options"), "Invalid statement option", err.Error())
+	}
+	return script
+}
+
 func (r *ScriptResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data ScriptResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	setOptions := data.setOptions(ctx, &resp.Diagnostics)
+	createScript := data.withStatementOptions(ctx, data.CreateScript.ValueString(), &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	err := r.client.ExecuteScriptNoResult(ctx, data.DatabaseName.ValueString(), data.CreateScript.ValueString())
+	err := r.client.ExecuteScriptNoResult(ctx, data.DatabaseName.ValueString(), createScript, setOptions)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to execute create script", err.Error())
+		resp.Diagnostics.AddError("Failed to execute create script", errorDetail(err))
 		return
 	}
 
@@ -110,9 +193,9 @@ func (r *ScriptResource) Create(ctx context.Context, req resource.CreateRequest,
 
 	// Execute read script if provided
 	if !data.ReadScript.IsNull() && data.ReadScript.ValueString() != "" {
-		state, err := r.client.ExecuteScript(ctx, data.DatabaseName.ValueString(), data.ReadScript.ValueString())
+		state, err := r.client.ExecuteScript(ctx, data.DatabaseName.ValueString(), data.ReadScript.ValueString(), setOptions)
 		if err != nil {
-			resp.Diagnostics.AddError("Failed to execute read script", err.Error())
+			resp.Diagnostics.AddError("Failed to execute read script", errorDetail(err))
 			return
 		}
 		stateMap, diags := types.MapValueFrom(ctx, types.StringType, state)
@@ -128,14 +211,15 @@ func (r *ScriptResource) Create(ctx context.Context, req resource.CreateRequest,
 func (r *ScriptResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	var data ScriptResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	setOptions := data.setOptions(ctx, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
 	if !data.ReadScript.IsNull() && data.ReadScript.ValueString() != "" {
-		state, err := r.client.ExecuteScript(ctx, data.DatabaseName.ValueString(), data.ReadScript.ValueString())
+		state, err := r.client.ExecuteScript(ctx, data.DatabaseName.ValueString(), data.ReadScript.ValueString(), setOptions)
 		if err != nil {
-			resp.Diagnostics.AddError("Failed to execute read script", err.Error())
+			resp.Diagnostics.AddError("Failed to execute read script", errorDetail(err))
 			return
 		}
 		stateMap, diags := types.MapValueFrom(ctx, types.StringType, state)
@@ -149,23 +233,28 @@ func (r *ScriptResource) Read(ctx context.Context, req resource.ReadRequest, res
 func (r *ScriptResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	var data ScriptResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	setOptions := data.setOptions(ctx, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
 	if !data.UpdateScript.IsNull() && data.UpdateScript.ValueString() != "" {
-		err := r.client.ExecuteScriptNoResult(ctx, data.DatabaseName.ValueString(), data.UpdateScript.ValueString())
+		updateScript := data.withStatementOptions(ctx, data.UpdateScript.ValueString(), &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		err := r.client.ExecuteScriptNoResult(ctx, data.DatabaseName.ValueString(), updateScript, setOptions)
 		if err != nil {
-			resp.Diagnostics.AddError("Failed to execute update script", err.Error())
+			resp.Diagnostics.AddError("Failed to execute update script", errorDetail(err))
 			return
 		}
 	}
 
 	// Execute read script if provided
 	if !data.ReadScript.IsNull() && data.ReadScript.ValueString() != "" {
-		state, err := r.client.ExecuteScript(ctx, data.DatabaseName.ValueString(), data.ReadScript.ValueString())
+		state, err := r.client.ExecuteScript(ctx, data.DatabaseName.ValueString(), data.ReadScript.ValueString(), setOptions)
 		if err != nil {
-			resp.Diagnostics.AddError("Failed to execute read script", err.Error())
+			resp.Diagnostics.AddError("Failed to execute read script", errorDetail(err))
 			return
 		}
 		stateMap, diags := types.MapValueFrom(ctx, types.StringType, state)
@@ -179,13 +268,14 @@ func (r *ScriptResource) Update(ctx context.Context, req resource.UpdateRequest,
 func (r *ScriptResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var data ScriptResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	setOptions := data.setOptions(ctx, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	err := r.client.ExecuteScriptNoResult(ctx, data.DatabaseName.ValueString(), data.DeleteScript.ValueString())
+	err := r.client.ExecuteScriptNoResult(ctx, data.DatabaseName.ValueString(), data.DeleteScript.ValueString(), setOptions)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to execute delete script", err.Error())
+		resp.Diagnostics.AddError("Failed to execute delete script", errorDetail(err))
 		return
 	}
 }
@@ -20,6 +20,8 @@ import (
 
 var _ resource.Resource = &ServerPermissionResource{}
 var _ resource.ResourceWithImportState = &ServerPermissionResource{}
+var _ resource.ResourceWithModifyPlan = &ServerPermissionResource{}
+var _ resource.ResourceWithValidateConfig = &ServerPermissionResource{}
 
 func NewServerPermissionResource() resource.Resource {
 	return &ServerPermissionResource{}
@@ -33,7 +35,11 @@ type ServerPermissionResourceModel struct {
 	ID              types.String `tfsdk:"id"`
 	PrincipalName   types.String `tfsdk:"principal_name"`
 	Permission      types.String `tfsdk:"permission"`
+	SecurableType   types.String `tfsdk:"securable_type"`
+	SecurableName   types.String `tfsdk:"securable_name"`
 	WithGrantOption types.Bool   `tfsdk:"with_grant_option"`
+	Cascade         types.Bool   `tfsdk:"cascade"`
+	State           types.String `tfsdk:"state"`
 }
 
 func (r *ServerPermissionResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -62,10 +68,37 @@ func (r *ServerPermissionResource) Schema(ctx context.Context, req resource.Sche
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
+			"securable_type": schema.StringAttribute{
+				Description: "The type of server securable the permission is granted on. Currently only ENDPOINT is supported. If omitted, the permission is granted on the server itself.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"securable_name": schema.StringAttribute{
+				Description: "The name of the securable, e.g. the endpoint name. Required when securable_type is set.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
 			"with_grant_option": schema.BoolAttribute{
-				Optional: true,
-				Computed: true,
-				Default:  booldefault.StaticBool(false),
+				Description: "Whether the principal can grant this permission to others. Defaults to the provider's default_with_grant_option.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"cascade": schema.BoolAttribute{
+				Description: "Whether revoking the permission also revokes the permissions the principal granted onwards. If false, the revoke fails while such grants exist; without them, CASCADE is added automatically, since SQL Server requires it to revoke a permission held with the grant option. Defaults to false.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"state": schema.StringAttribute{
+				Description: "The current state of the permission (GRANT, GRANT_WITH_GRANT_OPTION or DENY). If the permission is found DENYed, it is revoked and granted again.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					permissionStatePlanModifier{},
+				},
 			},
 		},
 	}
@@ -83,6 +116,69 @@ func (r *ServerPermissionResource) Configure(ctx context.Context, req resource.C
 	r.client = client
 }
 
+func (r *ServerPermissionResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planWithGrantOptionDefault(ctx, r.client, req, resp)
+}
+
+func (r *ServerPermissionResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data ServerPermissionResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.SecurableType.IsUnknown() || data.SecurableName.IsUnknown() {
+		return
+	}
+
+	if !data.SecurableType.IsNull() && !strings.EqualFold(data.SecurableType.ValueString(), mssql.SecurableTypeEndpoint) {
+		resp.Diagnostics.AddAttributeError(path.Root("securable_type"), "Unsupported securable type",
+			fmt.Sprintf("securable_type must be '%s', got: %s", mssql.SecurableTypeEndpoint, data.SecurableType.ValueString()))
+	}
+	if data.SecurableType.IsNull() != data.SecurableName.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("securable_name"), "Incomplete securable",
+			"securable_type and securable_name must be set together")
+	}
+	if data.SecurableType.IsNull() && !data.Permission.IsUnknown() && !mssql.IsServerPermission(data.Permission.ValueString()) {
+		resp.Diagnostics.AddAttributeError(path.Root("permission"), "Unknown server permission",
+			fmt.Sprintf("'%s' is not a permission that can be granted on the server, e.g. CONNECT SQL, VIEW SERVER STATE or ALTER ANY LOGIN. The valid names are listed by sys.fn_builtin_permissions('SERVER').", data.Permission.ValueString()))
+	}
+}
+
+// isEndpoint reports whether the permission targets an endpoint rather than the server.
+func (m *ServerPermissionResourceModel) isEndpoint() bool {
+	return strings.EqualFold(m.SecurableType.ValueString(), mssql.SecurableTypeEndpoint)
+}
+
+func (m *ServerPermissionResourceModel) id() string {
+	id := fmt.Sprintf("%s/%s", m.PrincipalName.ValueString(), mssql.NormalizePermissionName(m.Permission.ValueString()))
+	if m.isEndpoint() {
+		id += fmt.Sprintf("/%s/%s", mssql.SecurableTypeEndpoint, m.SecurableName.ValueString())
+	}
+	return id
+}
+
+func (r *ServerPermissionResource) getPermission(ctx context.Context, data *ServerPermissionResourceModel) (*mssql.ServerPermission, error) {
+	if data.isEndpoint() {
+		return r.client.GetEndpointPermission(ctx, data.SecurableName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString())
+	}
+	return r.client.GetServerPermission(ctx, data.PrincipalName.ValueString(), data.Permission.ValueString())
+}
+
+func (r *ServerPermissionResource) grantPermission(ctx context.Context, data *ServerPermissionResourceModel) error {
+	if data.isEndpoint() {
+		return r.client.GrantEndpointPermission(ctx, data.SecurableName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString(), data.WithGrantOption.ValueBool())
+	}
+	return r.client.GrantServerPermission(ctx, data.PrincipalName.ValueString(), data.Permission.ValueString(), data.WithGrantOption.ValueBool())
+}
+
+func (r *ServerPermissionResource) revokePermission(ctx context.Context, data *ServerPermissionResourceModel) error {
+	if data.isEndpoint() {
+		return r.client.RevokeEndpointPermission(ctx, data.SecurableName.ValueString(), data.PrincipalName.ValueString(), data.Permission.ValueString(), data.Cascade.ValueBool())
+	}
+	return r.client.RevokeServerPermission(ctx, data.PrincipalName.ValueString(), data.Permission.ValueString(), data.Cascade.ValueBool())
+}
+
 func (r *ServerPermissionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data ServerPermissionResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -90,13 +186,14 @@ func (r *ServerPermissionResource) Create(ctx context.Context, req resource.Crea
 		return
 	}
 
-	err := r.client.GrantServerPermission(ctx, data.PrincipalName.ValueString(), data.Permission.ValueString(), data.WithGrantOption.ValueBool())
+	err := r.grantPermission(ctx, &data)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to grant server permission", err.Error())
+		resp.Diagnostics.AddError("Failed to grant server permission", errorDetail(err))
 		return
 	}
 
-	data.ID = types.StringValue(fmt.Sprintf("%s/%s", data.PrincipalName.ValueString(), strings.ToUpper(data.Permission.ValueString())))
+	data.ID = types.StringValue(data.id())
+	data.State = types.StringValue(desiredPermissionState(data.WithGrantOption.ValueBool()))
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -107,9 +204,9 @@ func (r *ServerPermissionResource) Read(ctx context.Context, req resource.ReadRe
 		return
 	}
 
-	perm, err := r.client.GetServerPermission(ctx, data.PrincipalName.ValueString(), data.Permission.ValueString())
+	perm, err := r.getPermission(ctx, &data)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to read server permission", err.Error())
+		resp.Diagnostics.AddError("Failed to read server permission", errorDetail(err))
 		return
 	}
 	if perm == nil {
@@ -117,8 +214,12 @@ func (r *ServerPermissionResource) Read(ctx context.Context, req resource.ReadRe
 		return
 	}
 
-	data.Permission = types.StringValue(perm.PermissionName)
+	// Keep the configured spelling, e.g. lower case or extra spaces
+	if mssql.NormalizePermissionName(data.Permission.ValueString()) != perm.PermissionName {
+		data.Permission = types.StringValue(perm.PermissionName)
+	}
 	data.WithGrantOption = types.BoolValue(perm.WithGrantOption)
+	data.State = types.StringValue(perm.StateDesc)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -130,17 +231,20 @@ func (r *ServerPermissionResource) Update(ctx context.Context, req resource.Upda
 		return
 	}
 
-	if !data.WithGrantOption.Equal(state.WithGrantOption) {
-		if err := r.client.RevokeServerPermission(ctx, data.PrincipalName.ValueString(), data.Permission.ValueString()); err != nil {
-			resp.Diagnostics.AddError("Failed to revoke server permission", err.Error())
+	// Revoke and re-grant if with_grant_option changed or the permission was
+	// DENYed outside of Terraform
+	if !data.WithGrantOption.Equal(state.WithGrantOption) || !data.State.Equal(state.State) {
+		if err := r.revokePermission(ctx, &data); err != nil {
+			resp.Diagnostics.AddError("Failed to revoke server permission", errorDetail(err))
 			return
 		}
-		if err := r.client.GrantServerPermission(ctx, data.PrincipalName.ValueString(), data.Permission.ValueString(), data.WithGrantOption.ValueBool()); err != nil {
-			resp.Diagnostics.AddError("Failed to grant server permission", err.Error())
+		if err := r.grantPermission(ctx, &data); err != nil {
+			resp.Diagnostics.AddError("Failed to grant server permission", errorDetail(err))
 			return
 		}
 	}
 
+	data.State = types.StringValue(desiredPermissionState(data.WithGrantOption.ValueBool()))
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -151,32 +255,62 @@ func (r *ServerPermissionResource) Delete(ctx context.Context, req resource.Dele
 		return
 	}
 
-	err := r.client.RevokeServerPermission(ctx, data.PrincipalName.ValueString(), data.Permission.ValueString())
+	err := r.revokePermission(ctx, &data)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to revoke server permission", err.Error())
+		resp.Diagnostics.AddError("Failed to revoke server permission", errorDetail(err))
 		return
 	}
 }
 
 func (r *ServerPermissionResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	parts := strings.Split(req.ID, "/")
-	if len(parts) != 2 {
-		resp.Diagnostics.AddError("Invalid import ID", "Import ID must be in format 'principal_name/permission'")
+	data, ok := parseServerPermissionID(req.ID)
+	if !ok {
+		resp.Diagnostics.AddError("Invalid import ID", "Import ID must be in format 'principal_name/permission' or 'principal_name/permission/ENDPOINT/endpoint_name'")
 		return
 	}
+	principalName, permission := data.PrincipalName.ValueString(), data.Permission.ValueString()
 
-	perm, err := r.client.GetServerPermission(ctx, parts[0], parts[1])
+	perm, err := r.getPermission(ctx, &data)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to import server permission", err.Error())
+		resp.Diagnostics.AddError("Failed to import server permission", errorDetail(err))
 		return
 	}
 	if perm == nil {
-		resp.Diagnostics.AddError("Server permission not found", fmt.Sprintf("Permission '%s' not found for '%s'", parts[1], parts[0]))
+		resp.Diagnostics.AddError("Server permission not found", fmt.Sprintf("Permission '%s' not found for '%s'", permission, principalName))
 		return
 	}
 
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
-	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("principal_name"), parts[0])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("principal_name"), principalName)...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("permission"), perm.PermissionName)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("securable_type"), data.SecurableType)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("securable_name"), data.SecurableName)...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("with_grant_option"), perm.WithGrantOption)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cascade"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("state"), perm.StateDesc)...)
+}
+
+// parseServerPermissionID parses an import ID of the form
+// principal_name/permission or principal_name/permission/ENDPOINT/endpoint_name.
+// It is parsed from the right, as permission names never contain a slash but
+// principal names may.
+func parseServerPermissionID(id string) (ServerPermissionResourceModel, bool) {
+	data := ServerPermissionResourceModel{
+		SecurableType: types.StringNull(),
+		SecurableName: types.StringNull(),
+	}
+	parts := strings.Split(id, "/")
+	if n := len(parts); n >= 4 && strings.EqualFold(parts[n-2], mssql.SecurableTypeEndpoint) {
+		// Keep the spelling of the ID, which is the one configured
+		data.SecurableType = types.StringValue(parts[n-2])
+		data.SecurableName = types.StringValue(parts[n-1])
+		parts = parts[:n-2]
+	}
+	n := len(parts)
+	if n < 2 || parts[n-1] == "" || strings.Join(parts[:n-1], "/") == "" {
+		return data, false
+	}
+	data.PrincipalName = types.StringValue(strings.Join(parts[:n-1], "/"))
+	data.Permission = types.StringValue(parts[n-1])
+	return data, true
 }
@@ -5,6 +5,7 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -81,12 +82,26 @@ func (r *ServerRoleResource) Create(ctx context.Context, req resource.CreateRequ
 		return
 	}
 
-	role, err := r.client.CreateServerRole(ctx, mssql.CreateServerRoleOptions{
+	opts := mssql.CreateServerRoleOptions{
 		RoleName:  data.Name.ValueString(),
 		OwnerName: data.OwnerName.ValueString(),
-	})
+	}
+	role, err := r.client.CreateServerRole(ctx, opts)
+	if errors.Is(err, mssql.ErrAlreadyExists) {
+		// Adopt the existing role if it matches the desired owner
+		existing, getErr := r.client.GetServerRole(ctx, opts.RoleName)
+		if getErr != nil {
+			resp.Diagnostics.AddError("Failed to read existing server role", errorDetail(getErr))
+			return
+		}
+		if existing != nil && ownerMatches(r.client, opts.OwnerName, existing.OwnerName) {
+			resp.Diagnostics.AddWarning("Server role already exists",
+				fmt.Sprintf("Role '%s' already exists and has been adopted into the Terraform state.", opts.RoleName))
+			role, err = existing, nil
+		}
+	}
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to create server role", err.Error())
+		resp.Diagnostics.AddError("Failed to create server role", errorDetail(err))
 		return
 	}
 
@@ -105,7 +120,7 @@ func (r *ServerRoleResource) Read(ctx context.Context, req resource.ReadRequest,
 	id, _ := strconv.Atoi(data.ID.ValueString())
 	role, err := r.client.GetServerRoleByID(ctx, id)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to read server role", err.Error())
+		resp.Diagnostics.AddError("Failed to read server role", errorDetail(err))
 		return
 	}
 	if role == nil {
@@ -129,9 +144,31 @@ func (r *ServerRoleResource) Delete(ctx context.Context, req resource.DeleteRequ
 		return
 	}
 
-	err := r.client.DropServerRole(ctx, data.Name.ValueString())
+	id, _ := strconv.Atoi(data.ID.ValueString())
+	role, err := r.client.GetServerRoleByID(ctx, id)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read server role", errorDetail(err))
+		return
+	}
+	// The role is already gone
+	if role == nil {
+		return
+	}
+
+	// Fixed roles cannot be dropped; they are only removed from the state
+	if role.IsFixedRole {
+		resp.Diagnostics.AddWarning("Server role not dropped",
+			fmt.Sprintf("Role '%s' is a fixed server role and cannot be dropped. It has been removed from the Terraform state and left unchanged on the server.", role.Name))
+		return
+	}
+
+	err = r.client.DropServerRole(ctx, role.Name)
+	// The role is already gone
+	if errors.Is(err, mssql.ErrNotFound) {
+		return
+	}
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to delete server role", err.Error())
+		resp.Diagnostics.AddError("Failed to delete server role", errorDetail(err))
 		return
 	}
 }
@@ -139,7 +176,7 @@ func (r *ServerRoleResource) Delete(ctx context.Context, req resource.DeleteRequ
 func (r *ServerRoleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	role, err := r.client.GetServerRole(ctx, req.ID)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to import server role", err.Error())
+		resp.Diagnostics.AddError("Failed to import server role", errorDetail(err))
 		return
 	}
 	if role == nil {
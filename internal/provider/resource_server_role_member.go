@@ -83,9 +83,22 @@ func (r *ServerRoleMemberResource) Create(ctx context.Context, req resource.Crea
 		return
 	}
 
-	err := r.client.AddServerRoleMember(ctx, data.RoleName.ValueString(), data.MemberName.ValueString())
+	// User-defined server roles can be nested, but fixed roles can't be
+	// members of any role, which SQL Server reports less clearly
+	memberRole, err := r.client.GetServerRole(ctx, data.MemberName.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to add server role member", err.Error())
+		resp.Diagnostics.AddError("Failed to read server role", errorDetail(err))
+		return
+	}
+	if memberRole != nil && memberRole.IsFixedRole {
+		resp.Diagnostics.AddAttributeError(path.Root("member_name"), "Fixed server role as member",
+			fmt.Sprintf("'%s' is a fixed server role and cannot be a member of another role.", memberRole.Name))
+		return
+	}
+
+	err = r.client.AddServerRoleMember(ctx, data.RoleName.ValueString(), data.MemberName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to add server role member", errorDetail(err))
 		return
 	}
 
@@ -102,7 +115,7 @@ func (r *ServerRoleMemberResource) Read(ctx context.Context, req resource.ReadRe
 
 	member, err := r.client.GetServerRoleMember(ctx, data.RoleName.ValueString(), data.MemberName.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to read server role member", err.Error())
+		resp.Diagnostics.AddError("Failed to read server role member", errorDetail(err))
 		return
 	}
 	if member == nil {
@@ -126,7 +139,7 @@ func (r *ServerRoleMemberResource) Delete(ctx context.Context, req resource.Dele
 
 	err := r.client.RemoveServerRoleMember(ctx, data.RoleName.ValueString(), data.MemberName.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to remove server role member", err.Error())
+		resp.Diagnostics.AddError("Failed to remove server role member", errorDetail(err))
 		return
 	}
 }
@@ -140,7 +153,7 @@ func (r *ServerRoleMemberResource) ImportState(ctx context.Context, req resource
 
 	member, err := r.client.GetServerRoleMember(ctx, parts[0], parts[1])
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to import server role member", err.Error())
+		resp.Diagnostics.AddError("Failed to import server role member", errorDetail(err))
 		return
 	}
 	if member == nil {
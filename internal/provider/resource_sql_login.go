@@ -5,9 +5,11 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -15,6 +17,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/muecahit94/terraform-provider-mssql/internal/mssql"
@@ -22,6 +25,8 @@ import (
 
 var _ resource.Resource = &SQLLoginResource{}
 var _ resource.ResourceWithImportState = &SQLLoginResource{}
+var _ resource.ResourceWithValidateConfig = &SQLLoginResource{}
+var _ resource.ResourceWithModifyPlan = &SQLLoginResource{}
 
 func NewSQLLoginResource() resource.Resource {
 	return &SQLLoginResource{}
@@ -32,14 +37,20 @@ type SQLLoginResource struct {
 }
 
 type SQLLoginResourceModel struct {
-	ID                     types.String `tfsdk:"id"`
-	Name                   types.String `tfsdk:"name"`
-	Password               types.String `tfsdk:"password"`
-	DefaultDatabase        types.String `tfsdk:"default_database"`
-	DefaultLanguage        types.String `tfsdk:"default_language"`
-	CheckExpirationEnabled types.Bool   `tfsdk:"check_expiration_enabled"`
-	CheckPolicyEnabled     types.Bool   `tfsdk:"check_policy_enabled"`
-	IsDisabled             types.Bool   `tfsdk:"is_disabled"`
+	ID                         types.String `tfsdk:"id"`
+	Name                       types.String `tfsdk:"name"`
+	Password                   types.String `tfsdk:"password"`
+	PasswordWO                 types.String `tfsdk:"password_wo"`
+	PasswordVersion            types.String `tfsdk:"password_version"`
+	DefaultDatabase            types.String `tfsdk:"default_database"`
+	DefaultLanguage            types.String `tfsdk:"default_language"`
+	CheckExpirationEnabled     types.Bool   `tfsdk:"check_expiration_enabled"`
+	CheckPolicyEnabled         types.Bool   `tfsdk:"check_policy_enabled"`
+	IsDisabled                 types.Bool   `tfsdk:"is_disabled"`
+	Unlock                     types.Bool   `tfsdk:"unlock"`
+	IsLocked                   types.Bool   `tfsdk:"is_locked"`
+	CredentialName             types.String `tfsdk:"credential_name"`
+	CheckDefaultDatabaseAccess types.Bool   `tfsdk:"check_default_database_access"`
 }
 
 func (r *SQLLoginResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -58,16 +69,23 @@ func (r *SQLLoginResource) Schema(ctx context.Context, req resource.SchemaReques
 				},
 			},
 			"name": schema.StringAttribute{
-				Description: "The name of the login.",
+				Description: "The name of the login. Changing this renames the login in place, keeping its SID.",
 				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
 			},
 			"password": schema.StringAttribute{
-				Description: "The password for the login.",
-				Required:    true,
+				Description: "The password for the login. Either password or password_wo must be set.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"password_wo": schema.StringAttribute{
+				Description: "The password for the login as a write-only attribute, which is never stored in the state. Changes are only applied when password_version changes. Requires Terraform 1.11 or later.",
+				Optional:    true,
 				Sensitive:   true,
+				WriteOnly:   true,
+			},
+			"password_version": schema.StringAttribute{
+				Description: "An arbitrary value, e.g. a version or timestamp, that sets the password again when it changes. Use it to rotate password_wo, or to reset a password changed outside of Terraform.",
+				Optional:    true,
 			},
 			"default_database": schema.StringAttribute{
 				Description: "The default database for the login.",
@@ -98,6 +116,29 @@ func (r *SQLLoginResource) Schema(ctx context.Context, req resource.SchemaReques
 				Computed:    true,
 				Default:     booldefault.StaticBool(false),
 			},
+			"unlock": schema.BoolAttribute{
+				Description: "Whether to unlock the login when it is locked out by the password policy. Unlocking resets the password to the configured one.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"is_locked": schema.BoolAttribute{
+				Description: "Whether the login is locked out by the password policy.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					loginLockedPlanModifier{},
+				},
+			},
+			"credential_name": schema.StringAttribute{
+				Description: "The name of a server credential to map to the login.",
+				Optional:    true,
+			},
+			"check_default_database_access": schema.BoolAttribute{
+				Description: "Whether to warn when the login cannot connect to its default database after it is created or the default database changes, because it has no user there, the guest user is disabled and it is not a member of sysadmin. Defaults to false.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
 		},
 	}
 }
@@ -119,6 +160,24 @@ func (r *SQLLoginResource) Configure(ctx context.Context, req resource.Configure
 	r.client = client
 }
 
+func (r *SQLLoginResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data SQLLoginResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.Password.IsUnknown() || data.PasswordWO.IsUnknown() {
+		return
+	}
+
+	if data.Password.IsNull() == data.PasswordWO.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("password"), "Invalid password configuration",
+			"Exactly one of password or password_wo must be set.")
+	}
+}
+
+// ModifyPlan checks the default language against the languages of the server.
+func (r *SQLLoginResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	checkDefaultLanguage(ctx, r.client, req, &resp.Diagnostics)
+}
+
 func (r *SQLLoginResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data SQLLoginResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -131,37 +190,41 @@ func (r *SQLLoginResource) Create(ctx context.Context, req resource.CreateReques
 		"name": data.Name.ValueString(),
 	})
 
+	password, diags := loginPassword(ctx, req.Config, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	opts := mssql.CreateSQLLoginOptions{
 		Name:                   data.Name.ValueString(),
-		Password:               data.Password.ValueString(),
+		Password:               password,
 		DefaultDatabase:        data.DefaultDatabase.ValueString(),
 		DefaultLanguage:        data.DefaultLanguage.ValueString(),
 		CheckExpirationEnabled: data.CheckExpirationEnabled.ValueBool(),
 		CheckPolicyEnabled:     data.CheckPolicyEnabled.ValueBool(),
+		CredentialName:         data.CredentialName.ValueString(),
+		IsDisabled:             data.IsDisabled.ValueBool(),
 	}
 
-	login, err := r.client.CreateSQLLogin(ctx, opts)
-	if err != nil {
-		resp.Diagnostics.AddError("Failed to create SQL login", err.Error())
+	resp.Diagnostics.Append(r.checkDefaultDatabase(ctx, opts.DefaultDatabase)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Handle disabled state
-	if data.IsDisabled.ValueBool() {
-		disabled := true
-		_, err := r.client.UpdateSQLLogin(ctx, mssql.UpdateSQLLoginOptions{
-			Name:       data.Name.ValueString(),
-			IsDisabled: &disabled,
-		})
-		if err != nil {
-			resp.Diagnostics.AddError("Failed to disable SQL login", err.Error())
-			return
-		}
+	login, err := r.client.CreateSQLLogin(ctx, opts)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to create SQL login", errorDetail(err))
+		return
 	}
 
 	data.ID = types.StringValue(strconv.Itoa(login.PrincipalID))
 	data.DefaultLanguage = types.StringValue(login.DefaultLanguageName)
+	data.IsLocked = types.BoolValue(login.IsLocked)
 
+	if data.CheckDefaultDatabaseAccess.ValueBool() {
+		resp.Diagnostics.Append(r.checkDefaultDatabaseAccess(ctx, login.Name, opts.DefaultDatabase)...)
+	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -181,7 +244,7 @@ func (r *SQLLoginResource) Read(ctx context.Context, req resource.ReadRequest, r
 	if parseErr == nil {
 		login, err = r.client.GetSQLLoginByID(ctx, id)
 		if err != nil {
-			resp.Diagnostics.AddError("Failed to read SQL login", err.Error())
+			resp.Diagnostics.AddError("Failed to read SQL login", errorDetail(err))
 			return
 		}
 	}
@@ -190,7 +253,7 @@ func (r *SQLLoginResource) Read(ctx context.Context, req resource.ReadRequest, r
 	if login == nil && !data.Name.IsNull() {
 		login, err = r.client.GetSQLLogin(ctx, data.Name.ValueString())
 		if err != nil {
-			resp.Diagnostics.AddError("Failed to read SQL login", err.Error())
+			resp.Diagnostics.AddError("Failed to read SQL login", errorDetail(err))
 			return
 		}
 	}
@@ -208,6 +271,20 @@ func (r *SQLLoginResource) Read(ctx context.Context, req resource.ReadRequest, r
 	data.CheckExpirationEnabled = types.BoolValue(login.CheckExpirationEnabled)
 	data.CheckPolicyEnabled = types.BoolValue(login.CheckPolicyEnabled)
 	data.IsDisabled = types.BoolValue(login.IsDisabled)
+	data.IsLocked = types.BoolValue(login.IsLocked)
+	data.CredentialName = credentialNameValue(login.CredentialName)
+
+	// SQL Server keeps the default database of a login when that database is
+	// dropped, and the login can no longer connect without naming a database
+	db, err := r.client.GetDatabase(ctx, login.DefaultDatabaseName)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read default database", errorDetail(err))
+		return
+	}
+	if db == nil {
+		resp.Diagnostics.AddWarning("Default database not found",
+			fmt.Sprintf("The default database '%s' of login '%s' does not exist anymore. Connections without an explicit database will fail until default_database is changed or the database is recreated.", login.DefaultDatabaseName, login.Name))
+	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -227,17 +304,48 @@ func (r *SQLLoginResource) Update(ctx context.Context, req resource.UpdateReques
 		"name": data.Name.ValueString(),
 	})
 
+	// Look the login up by principal ID, so that it is found under its current name
+	id, _ := strconv.Atoi(state.ID.ValueString())
+	current, err := r.client.GetSQLLoginByID(ctx, id)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read SQL login", errorDetail(err))
+		return
+	}
+	if current == nil {
+		resp.Diagnostics.AddError("SQL login not found", fmt.Sprintf("Login '%s' (principal ID %s) does not exist anymore.", state.Name.ValueString(), state.ID.ValueString()))
+		return
+	}
+
 	opts := mssql.UpdateSQLLoginOptions{
-		Name: data.Name.ValueString(),
+		Name: current.Name,
+	}
+
+	// Renaming keeps the SID, so users mapped to the login stay mapped
+	if data.Name.ValueString() != current.Name {
+		name := data.Name.ValueString()
+		opts.NewName = &name
+	}
+
+	password, diags := loginPassword(ctx, req.Config, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	// Check what changed - only update if values actually differ
-	if !data.Password.Equal(state.Password) && !data.Password.IsNull() && data.Password.ValueString() != "" {
-		password := data.Password.ValueString()
+	// Check what changed - only update if values actually differ. A changed
+	// password_version sets the password again, which is the only way to
+	// change password_wo, as it is not in the state to compare against.
+	passwordChanged := !data.Password.Equal(state.Password) && !data.Password.IsNull()
+	rotated := !data.PasswordVersion.Equal(state.PasswordVersion) && !data.PasswordVersion.IsNull()
+	if (passwordChanged || rotated) && password != "" {
 		opts.Password = &password
 	}
 	if !data.DefaultDatabase.Equal(state.DefaultDatabase) {
 		db := data.DefaultDatabase.ValueString()
+		resp.Diagnostics.Append(r.checkDefaultDatabase(ctx, db)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
 		opts.DefaultDatabase = &db
 	}
 	// Only update language if explicitly changed and not empty
@@ -258,22 +366,58 @@ func (r *SQLLoginResource) Update(ctx context.Context, req resource.UpdateReques
 		opts.IsDisabled = &disabled
 	}
 
-	// Skip update if nothing changed
-	if opts.Password == nil && opts.DefaultDatabase == nil && opts.DefaultLanguage == nil &&
-		opts.CheckExpirationEnabled == nil && opts.CheckPolicyEnabled == nil && opts.IsDisabled == nil {
-		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
-		return
+	if !data.CredentialName.Equal(state.CredentialName) {
+		credential := data.CredentialName.ValueString()
+		opts.CredentialName = &credential
 	}
 
-	login, err := r.client.UpdateSQLLogin(ctx, opts)
-	if err != nil {
-		resp.Diagnostics.AddError("Failed to update SQL login", err.Error())
-		return
+	// SQL Server only unlocks a login together with a password, so the
+	// configured password is set again if it did not change
+	if data.Unlock.ValueBool() && state.IsLocked.ValueBool() {
+		if opts.Password == nil {
+			if password == "" {
+				resp.Diagnostics.AddAttributeError(path.Root("password"), "Password required to unlock",
+					fmt.Sprintf("Login '%s' is locked and can only be unlocked together with a password.", current.Name))
+				return
+			}
+			opts.Password = &password
+		}
+		opts.Unlock = true
 	}
 
-	// Update state with actual values from the server to resolve "known after apply" values
-	data.DefaultLanguage = types.StringValue(login.DefaultLanguageName)
+	// Renaming or disabling the login the provider is connected as would lock
+	// it out of the server for all following connections
+	if opts.NewName != nil || (opts.IsDisabled != nil && *opts.IsDisabled) {
+		connected, err := r.client.GetCurrentLoginName(ctx)
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to update SQL login", errorDetail(err))
+			return
+		}
+		if r.client.NamesEqual(connected, current.Name) {
+			resp.Diagnostics.AddError("Refusing to lock out the provider",
+				fmt.Sprintf("The provider is connected as login '%s'. Configure the provider with a different login before renaming or disabling it.", connected))
+			return
+		}
+	}
+
+	// Only update the login if something changed
+	if opts.NewName != nil || opts.Password != nil || opts.DefaultDatabase != nil || opts.DefaultLanguage != nil ||
+		opts.CheckExpirationEnabled != nil || opts.CheckPolicyEnabled != nil || opts.IsDisabled != nil ||
+		opts.CredentialName != nil || opts.Unlock {
+		login, err := r.client.UpdateSQLLogin(ctx, opts)
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to update SQL login", errorDetail(err))
+			return
+		}
+
+		// Update state with actual values from the server to resolve "known after apply" values
+		data.DefaultLanguage = types.StringValue(login.DefaultLanguageName)
+	}
 
+	// Check again when the default database changes or the check is turned on
+	if data.CheckDefaultDatabaseAccess.ValueBool() && (opts.DefaultDatabase != nil || !state.CheckDefaultDatabaseAccess.ValueBool()) {
+		resp.Diagnostics.Append(r.checkDefaultDatabaseAccess(ctx, data.Name.ValueString(), data.DefaultDatabase.ValueString())...)
+	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -285,13 +429,24 @@ func (r *SQLLoginResource) Delete(ctx context.Context, req resource.DeleteReques
 		return
 	}
 
+	// The sa login cannot be dropped; it is only removed from the state
+	if data.ID.ValueString() == strconv.Itoa(mssql.SALoginPrincipalID) {
+		resp.Diagnostics.AddWarning("SQL login not dropped",
+			fmt.Sprintf("Login '%s' is the built-in sa login and cannot be dropped. It has been removed from the Terraform state and left unchanged on the server.", data.Name.ValueString()))
+		return
+	}
+
 	tflog.Debug(ctx, "Deleting SQL login", map[string]interface{}{
 		"name": data.Name.ValueString(),
 	})
 
 	err := r.client.DropSQLLogin(ctx, data.Name.ValueString())
+	// The login is already gone
+	if errors.Is(err, mssql.ErrNotFound) {
+		return
+	}
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to delete SQL login", err.Error())
+		resp.Diagnostics.AddError("Failed to delete SQL login", errorDetail(err))
 		return
 	}
 }
@@ -299,7 +454,7 @@ func (r *SQLLoginResource) Delete(ctx context.Context, req resource.DeleteReques
 func (r *SQLLoginResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	login, err := r.client.GetSQLLogin(ctx, req.ID)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to import SQL login", err.Error())
+		resp.Diagnostics.AddError("Failed to import SQL login", errorDetail(err))
 		return
 	}
 
@@ -307,6 +462,10 @@ func (r *SQLLoginResource) ImportState(ctx context.Context, req resource.ImportS
 		resp.Diagnostics.AddError("SQL login not found", fmt.Sprintf("Login '%s' not found", req.ID))
 		return
 	}
+	if login.Type != mssql.LoginTypeSQL {
+		resp.Diagnostics.AddError("Not a SQL login", fmt.Sprintf("Login '%s' is a Windows or external login, which mssql_sql_login cannot manage", req.ID))
+		return
+	}
 
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), strconv.Itoa(login.PrincipalID))...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), login.Name)...)
@@ -316,4 +475,67 @@ func (r *SQLLoginResource) ImportState(ctx context.Context, req resource.ImportS
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("check_expiration_enabled"), login.CheckExpirationEnabled)...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("check_policy_enabled"), login.CheckPolicyEnabled)...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("is_disabled"), login.IsDisabled)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("unlock"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("is_locked"), login.IsLocked)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("credential_name"), credentialNameValue(login.CredentialName))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("check_default_database_access"), false)...)
+}
+
+// loginPassword returns the configured password of a login. A write-only
+// password is only available in the configuration, not in the plan.
+func loginPassword(ctx context.Context, config tfsdk.Config, data SQLLoginResourceModel) (string, diag.Diagnostics) {
+	if !data.Password.IsNull() {
+		return data.Password.ValueString(), nil
+	}
+
+	var password types.String
+	diags := config.GetAttribute(ctx, path.Root("password_wo"), &password)
+	return password.ValueString(), diags
+}
+
+// checkDefaultDatabase reports an error naming the default database if it does
+// not exist, instead of the less specific error SQL Server returns.
+func (r *SQLLoginResource) checkDefaultDatabase(ctx context.Context, name string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if name == "" {
+		return diags
+	}
+
+	db, err := r.client.GetDatabase(ctx, name)
+	if err != nil {
+		diags.AddError("Failed to read default database", errorDetail(err))
+		return diags
+	}
+	if db == nil {
+		diags.AddAttributeError(path.Root("default_database"), "Default database not found",
+			fmt.Sprintf("The default database '%s' does not exist. Create it first, or reference the mssql_database resource so that it is created before the login.", name))
+	}
+	return diags
+}
+
+// checkDefaultDatabaseAccess warns if a login cannot connect to its default
+// database. Connections that do not name a database then fail with a login
+// error, which is easily mistaken for a wrong password. The database itself is
+// already known to exist; see checkDefaultDatabase.
+func (r *SQLLoginResource) checkDefaultDatabaseAccess(ctx context.Context, loginName, databaseName string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	access, err := r.client.LoginHasDatabaseAccess(ctx, loginName, databaseName)
+	if err != nil {
+		diags.AddWarning("Failed to check default database access", errorDetail(err))
+		return diags
+	}
+	if !access {
+		diags.AddAttributeWarning(path.Root("default_database"), "Default database not accessible",
+			fmt.Sprintf("Login '%s' cannot connect to its default database '%s': it has no user there, the guest user is disabled and it is not a member of sysadmin. Connections without an explicit database will fail. This is expected if a user for the login is created later in the same apply.", loginName, databaseName))
+	}
+	return diags
+}
+
+// credentialNameValue maps an unset credential to null so that configurations
+// without credential_name don't show a diff.
+func credentialNameValue(name string) types.String {
+	if name == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(name)
 }
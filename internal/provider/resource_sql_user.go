@@ -6,10 +6,13 @@ package provider
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -23,6 +26,8 @@ import (
 
 var _ resource.Resource = &SQLUserResource{}
 var _ resource.ResourceWithImportState = &SQLUserResource{}
+var _ resource.ResourceWithModifyPlan = &SQLUserResource{}
+var _ resource.ResourceWithValidateConfig = &SQLUserResource{}
 
 func NewSQLUserResource() resource.Resource {
 	return &SQLUserResource{}
@@ -33,12 +38,16 @@ type SQLUserResource struct {
 }
 
 type SQLUserResourceModel struct {
-	ID            types.String `tfsdk:"id"`
-	DatabaseName  types.String `tfsdk:"database_name"`
-	Name          types.String `tfsdk:"name"`
-	LoginName     types.String `tfsdk:"login_name"`
-	DefaultSchema types.String `tfsdk:"default_schema"`
-	Roles         types.Set    `tfsdk:"roles"`
+	ID              types.String `tfsdk:"id"`
+	DatabaseName    types.String `tfsdk:"database_name"`
+	Name            types.String `tfsdk:"name"`
+	LoginName       types.String `tfsdk:"login_name"`
+	Password        types.String `tfsdk:"password"`
+	CertificateName types.String `tfsdk:"certificate_name"`
+	SID             types.String `tfsdk:"sid"`
+	DefaultSchema   types.String `tfsdk:"default_schema"`
+	DefaultLanguage types.String `tfsdk:"default_language"`
+	Roles           types.Set    `tfsdk:"roles"`
 }
 
 func (r *SQLUserResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -47,7 +56,7 @@ func (r *SQLUserResource) Metadata(ctx context.Context, req resource.MetadataReq
 
 func (r *SQLUserResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		Description: "Manages a SQL Server database user mapped to a login.",
+		Description: "Manages a SQL Server database user mapped to a login or a certificate, or a contained database user with a password.",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Description: "The user ID in format 'database_id/principal_id'.",
@@ -71,9 +80,27 @@ func (r *SQLUserResource) Schema(ctx context.Context, req resource.SchemaRequest
 				},
 			},
 			"login_name": schema.StringAttribute{
-				Description: "The name of the login to map this user to.",
-				Required:    true,
+				Description: "The name of the login to map this user to. Changing this maps the user to the new login in place. Exactly one of login_name, password and certificate_name must be set.",
+				Optional:    true,
+			},
+			"password": schema.StringAttribute{
+				Description: "The password of a contained database user, which authenticates without a login. Only supported in contained databases.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"certificate_name": schema.StringAttribute{
+				Description: "The name of a certificate of the database to map this user to, e.g. to sign modules with. The user cannot log in and has no default schema or language. Changing this forces a new resource.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"sid": schema.StringAttribute{
+				Description: "The SID of the user as a hex string with 0x prefix. Can only be set for contained database users with a password, e.g. to create them with the same SID on every availability group replica. Users mapped to a login have the SID of the login. Changing this forces a new resource.",
+				Optional:    true,
+				Computed:    true,
 				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
@@ -83,8 +110,12 @@ func (r *SQLUserResource) Schema(ctx context.Context, req resource.SchemaRequest
 				Computed:    true,
 				Default:     stringdefault.StaticString("dbo"),
 			},
+			"default_language": schema.StringAttribute{
+				Description: "The default language for the user. Only supported in contained databases; it is ignored with a warning in other databases.",
+				Optional:    true,
+			},
 			"roles": schema.SetAttribute{
-				Description: "List of database roles to assign to this user.",
+				Description: "List of database roles to assign to this user. Roles can also be given by principal ID as `id:<principal_id>`, which keeps working when the role is renamed.",
 				Optional:    true,
 				Computed:    true,
 				ElementType: types.StringType,
@@ -110,6 +141,67 @@ func (r *SQLUserResource) Configure(ctx context.Context, req resource.ConfigureR
 	r.client = client
 }
 
+// ModifyPlan lists the roles to be changed in the plan and checks the default
+// language against the languages of the server.
+func (r *SQLUserResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	previewSetChanges(ctx, req, resp, r.client.NameKey, "roles")
+	checkDefaultLanguage(ctx, r.client, req, &resp.Diagnostics)
+}
+
+func (r *SQLUserResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data SQLUserResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sources := []types.String{data.LoginName, data.Password, data.CertificateName}
+	set, unknown := 0, false
+	for _, source := range sources {
+		unknown = unknown || source.IsUnknown()
+		if !source.IsNull() {
+			set++
+		}
+	}
+	if !unknown && set != 1 {
+		resp.Diagnostics.AddAttributeError(path.Root("login_name"), "Invalid user type",
+			"Exactly one of login_name, password and certificate_name must be set: login_name maps the user to a login, password creates a contained database user and certificate_name maps the user to a certificate.")
+	}
+	if !data.CertificateName.IsNull() {
+		if !data.DefaultSchema.IsNull() && !data.DefaultSchema.IsUnknown() && !strings.EqualFold(data.DefaultSchema.ValueString(), "dbo") {
+			resp.Diagnostics.AddAttributeError(path.Root("default_schema"), "Default schema not supported",
+				"A user mapped to a certificate cannot have a default schema.")
+		}
+		if !data.DefaultLanguage.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("default_language"), "Default language not supported",
+				"A user mapped to a certificate cannot have a default language.")
+		}
+	}
+	if !data.SID.IsNull() && !data.SID.IsUnknown() {
+		if !data.LoginName.IsNull() || !data.CertificateName.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("sid"), "SID not supported",
+				"A user mapped to a login or a certificate has the SID of the login or certificate. sid can only be set together with password.")
+		} else if !sidPattern.MatchString(data.SID.ValueString()) {
+			resp.Diagnostics.AddAttributeError(path.Root("sid"), "Invalid SID",
+				fmt.Sprintf("sid must be a hex string with 0x prefix, e.g. 0x0105000000000009030000004A8E4E4A, got: %s", data.SID.ValueString()))
+		}
+	}
+
+	if data.Roles.IsNull() || data.Roles.IsUnknown() {
+		return
+	}
+
+	for _, element := range data.Roles.Elements() {
+		role, ok := element.(types.String)
+		if !ok || role.IsUnknown() {
+			continue
+		}
+		if _, _, err := roleEntryID(role.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("roles").AtSetValue(role), "Invalid role ID", err.Error())
+		}
+	}
+}
+
 func (r *SQLUserResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data SQLUserResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -124,17 +216,27 @@ func (r *SQLUserResource) Create(ctx context.Context, req resource.CreateRequest
 	})
 
 	opts := mssql.CreateSQLUserOptions{
-		DatabaseName:  data.DatabaseName.ValueString(),
-		UserName:      data.Name.ValueString(),
-		LoginName:     data.LoginName.ValueString(),
-		DefaultSchema: data.DefaultSchema.ValueString(),
+		DatabaseName:    data.DatabaseName.ValueString(),
+		UserName:        data.Name.ValueString(),
+		LoginName:       data.LoginName.ValueString(),
+		DefaultSchema:   data.DefaultSchema.ValueString(),
+		Password:        data.Password.ValueString(),
+		SID:             data.SID.ValueString(),
+		CertificateName: data.CertificateName.ValueString(),
+	}
+	if data.DefaultLanguage.ValueString() != "" && r.supportsDefaultLanguage(ctx, opts.DatabaseName, &resp.Diagnostics) {
+		opts.DefaultLanguage = data.DefaultLanguage.ValueString()
+	}
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
 	user, err := r.client.CreateSQLUser(ctx, opts)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to create SQL user", err.Error())
+		resp.Diagnostics.AddError("Failed to create SQL user", errorDetail(err))
 		return
 	}
+	warnMissingDefaultSchema(ctx, r.client, data.DatabaseName.ValueString(), data.DefaultSchema.ValueString(), &resp.Diagnostics)
 
 	// Assign roles if specified
 	var roles []string
@@ -143,17 +245,25 @@ func (r *SQLUserResource) Create(ctx context.Context, req resource.CreateRequest
 		if resp.Diagnostics.HasError() {
 			return
 		}
-		for _, role := range roles {
-			err := r.client.AddDatabaseRoleMember(ctx, data.DatabaseName.ValueString(), role, data.Name.ValueString())
-			if err != nil {
-				resp.Diagnostics.AddError("Failed to assign role", fmt.Sprintf("Failed to add user to role '%s': %s", role, err.Error()))
-				return
-			}
+		names, err := r.requireRoleNames(ctx, data.DatabaseName.ValueString(), roles)
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to assign roles", errorDetail(err))
+			return
+		}
+		tflog.Debug(ctx, "Assigning roles to SQL user", map[string]interface{}{
+			"name":  data.Name.ValueString(),
+			"roles": len(names),
+		})
+		err = r.client.UpdateDatabaseRoleMemberships(ctx, data.DatabaseName.ValueString(), data.Name.ValueString(), names, nil)
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to assign roles", errorDetail(err))
+			return
 		}
 	}
 
 	data.ID = types.StringValue(fmt.Sprintf("%d/%d", user.DatabaseID, user.PrincipalID))
 	data.DefaultSchema = types.StringValue(user.DefaultSchemaName)
+	data.SID = sidValue(data.SID, user.SID)
 
 	// Set roles in state
 	if len(roles) > 0 {
@@ -180,7 +290,7 @@ func (r *SQLUserResource) Read(ctx context.Context, req resource.ReadRequest, re
 	// Always lookup by name - handles ID changes gracefully
 	user, err := r.client.GetUser(ctx, data.DatabaseName.ValueString(), data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to read SQL user", err.Error())
+		resp.Diagnostics.AddError("Failed to read SQL user", errorDetail(err))
 		return
 	}
 
@@ -192,14 +302,48 @@ func (r *SQLUserResource) Read(ctx context.Context, req resource.ReadRequest, re
 	// Update state with current values (including potentially changed ID)
 	data.ID = types.StringValue(fmt.Sprintf("%d/%d", user.DatabaseID, user.PrincipalID))
 	data.DefaultSchema = types.StringValue(user.DefaultSchemaName)
-	data.LoginName = types.StringValue(user.LoginName)
+	data.SID = sidValue(data.SID, user.SID)
+	// Contained database users have no login
+	if user.LoginName != "" || !data.LoginName.IsNull() {
+		data.LoginName = types.StringValue(user.LoginName)
+	}
+	// Certificate names are usually case-insensitive, so the configured
+	// spelling is kept
+	if (user.CertificateName != "" || !data.CertificateName.IsNull()) && !r.client.NamesEqual(user.CertificateName, data.CertificateName.ValueString()) {
+		data.CertificateName = types.StringValue(user.CertificateName)
+	}
+	// Outside of contained databases users have no default language, so the
+	// configured one is kept
+	if user.DefaultLanguageName != "" {
+		data.DefaultLanguage = types.StringValue(user.DefaultLanguageName)
+	}
 
 	// Read user's roles
 	roles, err := r.client.GetUserRoles(ctx, data.DatabaseName.ValueString(), data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to read user roles", err.Error())
+		resp.Diagnostics.AddError("Failed to read user roles", errorDetail(err))
+		return
+	}
+
+	// Keep roles that are given by ID in that form, so that renaming them doesn't show a diff
+	var entries []string
+	resp.Diagnostics.Append(data.Roles.ElementsAs(ctx, &entries, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resolved, err := r.resolveRoleNames(ctx, data.DatabaseName.ValueString(), entries)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read user roles", errorDetail(err))
 		return
 	}
+	for i, role := range roles {
+		for entry, name := range resolved {
+			if entry != name && r.client.NamesEqual(role, name) {
+				roles[i] = entry
+			}
+		}
+	}
+
 	roleValues := make([]attr.Value, len(roles))
 	for i, role := range roles {
 		roleValues[i] = types.StringValue(role)
@@ -234,10 +378,26 @@ func (r *SQLUserResource) Update(ctx context.Context, req resource.UpdateRequest
 		schema := data.DefaultSchema.ValueString()
 		opts.DefaultSchema = &schema
 	}
+	// A renamed login keeps its SID, so remapping to it is a no-op on the server
+	if !data.LoginName.Equal(state.LoginName) && !data.LoginName.IsNull() {
+		login := data.LoginName.ValueString()
+		opts.LoginName = &login
+	}
+	if !data.Password.Equal(state.Password) && !data.Password.IsNull() {
+		password := data.Password.ValueString()
+		opts.Password = &password
+	}
+	if !data.DefaultLanguage.Equal(state.DefaultLanguage) && r.supportsDefaultLanguage(ctx, opts.DatabaseName, &resp.Diagnostics) {
+		language := data.DefaultLanguage.ValueString()
+		opts.DefaultLanguage = &language
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	_, err := r.client.UpdateSQLUser(ctx, opts)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to update SQL user", err.Error())
+		resp.Diagnostics.AddError("Failed to update SQL user", errorDetail(err))
 		return
 	}
 
@@ -250,37 +410,40 @@ func (r *SQLUserResource) Update(ctx context.Context, req resource.UpdateRequest
 			return
 		}
 
-		// Find roles to add and remove
-		currentSet := make(map[string]bool)
-		for _, role := range currentRoles {
-			currentSet[role] = true
+		// Roles given by ID are compared by their current names. Roles that
+		// were dropped in the meantime have no membership left to remove.
+		current, err := r.resolveRoleNames(ctx, data.DatabaseName.ValueString(), currentRoles)
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to update roles", errorDetail(err))
+			return
 		}
-		desiredSet := make(map[string]bool)
-		for _, role := range desiredRoles {
-			desiredSet[role] = true
+		desired, err := r.requireRoleNames(ctx, data.DatabaseName.ValueString(), desiredRoles)
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to update roles", errorDetail(err))
+			return
 		}
-
-		// Add new roles
-		for _, role := range desiredRoles {
-			if !currentSet[role] {
-				err := r.client.AddDatabaseRoleMember(ctx, data.DatabaseName.ValueString(), role, data.Name.ValueString())
-				if err != nil {
-					resp.Diagnostics.AddError("Failed to add role", fmt.Sprintf("Failed to add user to role '%s': %s", role, err.Error()))
-					return
-				}
-			}
+		var currentNames []string
+		for _, name := range current {
+			currentNames = append(currentNames, name)
 		}
 
-		// Remove old roles
-		for _, role := range currentRoles {
-			if !desiredSet[role] {
-				err := r.client.RemoveDatabaseRoleMember(ctx, data.DatabaseName.ValueString(), role, data.Name.ValueString())
-				if err != nil {
-					resp.Diagnostics.AddError("Failed to remove role", fmt.Sprintf("Failed to remove user from role '%s': %s", role, err.Error()))
-					return
-				}
+		// Apply all membership changes in one batch instead of one round trip per role
+		add, remove := diffNames(currentNames, desired, r.client.NameKey)
+		tflog.Debug(ctx, "Updating SQL user roles", map[string]interface{}{
+			"name":   data.Name.ValueString(),
+			"add":    len(add),
+			"remove": len(remove),
+		})
+		for _, role := range remove {
+			if !checkDbOwnerRemoval(ctx, r.client, data.DatabaseName.ValueString(), role, data.Name.ValueString(), &resp.Diagnostics) {
+				return
 			}
 		}
+		err = r.client.UpdateDatabaseRoleMemberships(ctx, data.DatabaseName.ValueString(), data.Name.ValueString(), add, remove)
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to update roles", errorDetail(err))
+			return
+		}
 
 		// Update state with sorted roles
 		sort.Strings(desiredRoles)
@@ -309,18 +472,18 @@ func (r *SQLUserResource) Delete(ctx context.Context, req resource.DeleteRequest
 
 	err := r.client.DropUser(ctx, data.DatabaseName.ValueString(), data.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to delete SQL user", err.Error())
+		resp.Diagnostics.AddError("Failed to delete SQL user", errorDetail(err))
 		return
 	}
 }
 
 func (r *SQLUserResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Import format: database_name/user_name
+	// Import format: database_name/user_name or database_name/principal_id
 	parts := strings.Split(req.ID, "/")
 	if len(parts) != 2 {
 		resp.Diagnostics.AddError(
 			"Invalid import ID",
-			"Import ID must be in format 'database_name/user_name'",
+			"Import ID must be in format 'database_name/user_name' or 'database_name/principal_id'",
 		)
 		return
 	}
@@ -328,9 +491,11 @@ func (r *SQLUserResource) ImportState(ctx context.Context, req resource.ImportSt
 	databaseName := parts[0]
 	userName := parts[1]
 
-	user, err := r.client.GetUser(ctx, databaseName, userName)
+	user, err := lookupByNameOrID(userName,
+		func(id int) (*mssql.User, error) { return r.client.GetUserByID(ctx, databaseName, id) },
+		func(name string) (*mssql.User, error) { return r.client.GetUser(ctx, databaseName, name) })
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to import SQL user", err.Error())
+		resp.Diagnostics.AddError("Failed to import SQL user", errorDetail(err))
 		return
 	}
 
@@ -342,6 +507,101 @@ func (r *SQLUserResource) ImportState(ctx context.Context, req resource.ImportSt
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), fmt.Sprintf("%d/%d", user.DatabaseID, user.PrincipalID))...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("database_name"), databaseName)...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), user.Name)...)
-	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("login_name"), user.LoginName)...)
+	if user.LoginName != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("login_name"), user.LoginName)...)
+	}
+	if user.CertificateName != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("certificate_name"), user.CertificateName)...)
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("sid"), user.SID)...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("default_schema"), user.DefaultSchemaName)...)
+	if user.DefaultLanguageName != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("default_language"), user.DefaultLanguageName)...)
+	}
+}
+
+// supportsDefaultLanguage reports whether users of a database can have a
+// default language, which is only the case in contained databases. It adds a
+// warning if they can't.
+func (r *SQLUserResource) supportsDefaultLanguage(ctx context.Context, databaseName string, diags *diag.Diagnostics) bool {
+	contained, err := r.client.IsContainedDatabase(ctx, databaseName)
+	if err != nil {
+		diags.AddError("Failed to read database containment", errorDetail(err))
+		return false
+	}
+	if !contained {
+		diags.AddAttributeWarning(path.Root("default_language"), "Default language ignored",
+			fmt.Sprintf("Database '%s' is not a contained database, so its users cannot have a default language. Users get the default language of their login instead.", databaseName))
+	}
+	return contained
+}
+
+// roleIDPrefix marks entries of the roles set that refer to a role by principal ID.
+const roleIDPrefix = "id:"
+
+// roleEntryID parses an entry of the roles set. It reports whether the entry
+// refers to a role by principal ID, and the ID if so.
+func roleEntryID(entry string) (int, bool, error) {
+	if !strings.HasPrefix(entry, roleIDPrefix) {
+		return 0, false, nil
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(entry, roleIDPrefix))
+	if err != nil || id <= 0 {
+		return 0, true, fmt.Errorf("role '%s' is not a valid principal ID, expected %s<principal_id>", entry, roleIDPrefix)
+	}
+	return id, true, nil
+}
+
+// resolveRoleNames maps the entries of the roles set to role names. Entries
+// given by ID are looked up in the database and left out if the role is gone.
+func (r *SQLUserResource) resolveRoleNames(ctx context.Context, databaseName string, entries []string) (map[string]string, error) {
+	names := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		id, isID, err := roleEntryID(entry)
+		if err != nil {
+			return nil, err
+		}
+		if !isID {
+			names[entry] = entry
+			continue
+		}
+		role, err := r.client.GetDatabaseRoleByID(ctx, databaseName, id)
+		if err != nil {
+			return nil, err
+		}
+		if role != nil {
+			names[entry] = role.Name
+		}
+	}
+	return names, nil
+}
+
+// requireRoleNames is like resolveRoleNames, but fails if a role given by ID
+// does not exist.
+func (r *SQLUserResource) requireRoleNames(ctx context.Context, databaseName string, entries []string) ([]string, error) {
+	resolved, err := r.resolveRoleNames(ctx, databaseName, entries)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name, ok := resolved[entry]
+		if !ok {
+			return nil, fmt.Errorf("role '%s' does not exist in database '%s'", entry, databaseName)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// sidPattern matches a SID given as a hex string with 0x prefix.
+var sidPattern = regexp.MustCompile(`^0[xX]([0-9A-Fa-f]{2})+$`)
+
+// sidValue keeps the configured spelling of a SID, which SQL Server reports in
+// upper case.
+func sidValue(configured types.String, actual string) types.String {
+	if strings.EqualFold(configured.ValueString(), actual) {
+		return configured
+	}
+	return types.StringValue(actual)
 }
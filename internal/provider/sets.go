@@ -0,0 +1,200 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// permissionKey is the key permission names are compared by. Permissions are
+// keywords, so they are case-insensitive on every server collation, unlike
+// principal names; see mssql.Client.NameKey.
+var permissionKey = strings.ToUpper
+
+// diffNames compares two lists of names by key and returns the desired names
+// missing from current and the current names not desired.
+func diffNames(current, desired []string, key func(string) string) (add, remove []string) {
+	currentSet := make(map[string]bool)
+	for _, name := range current {
+		currentSet[key(name)] = true
+	}
+	desiredSet := make(map[string]bool)
+	for _, name := range desired {
+		desiredSet[key(name)] = true
+	}
+
+	for _, name := range desired {
+		if !currentSet[key(name)] {
+			add = append(add, name)
+		}
+	}
+	for _, name := range current {
+		if !desiredSet[key(name)] {
+			remove = append(remove, name)
+		}
+	}
+	return add, remove
+}
+
+// applyEach calls apply for each name and collects the failures as errors
+// instead of stopping at the first one, so that a single failing item does not
+// keep the others from being applied.
+func applyEach(names []string, summary, action string, apply func(name string) error, diags *diag.Diagnostics) {
+	for _, name := range names {
+		if err := apply(name); err != nil {
+			diags.AddError(summary, fmt.Sprintf("Failed to %s '%s': %s", action, name, err.Error()))
+		}
+	}
+}
+
+// authoritativeSet builds the state of an authoritative set attribute from the
+// names found on the server, compared by key. The configured spelling of names
+// that are still present is kept, and names added outside of Terraform show up
+// as drift.
+func authoritativeSet(ctx context.Context, configured types.Set, actual []string, key func(string) string, diags *diag.Diagnostics) types.Set {
+	var current []string
+	diags.Append(configured.ElementsAs(ctx, &current, false)...)
+
+	found := make(map[string]string)
+	for _, name := range actual {
+		found[key(name)] = name
+	}
+
+	var names []string
+	for _, name := range current {
+		k := key(name)
+		if _, ok := found[k]; ok {
+			names = append(names, name)
+			delete(found, k)
+		}
+	}
+	for _, name := range found {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	values := make([]attr.Value, len(names))
+	for i, name := range names {
+		values[i] = types.StringValue(name)
+	}
+	set, d := types.SetValue(types.StringType, values)
+	diags.Append(d...)
+	return set
+}
+
+// setElements returns the strings of a set attribute, or nil if it is null or
+// unknown.
+func setElements(ctx context.Context, set types.Set, diags *diag.Diagnostics) []string {
+	var names []string
+	if !set.IsNull() && !set.IsUnknown() {
+		diags.Append(set.ElementsAs(ctx, &names, false)...)
+	}
+	return names
+}
+
+// keySet returns the keys of names.
+func keySet(names []string, key func(string) string) map[string]bool {
+	keys := make(map[string]bool, len(names))
+	for _, name := range names {
+		keys[key(name)] = true
+	}
+	return keys
+}
+
+// withoutIgnored returns the names that are not among the ignored names,
+// compared by key. Authoritative attributes leave ignored names to whoever
+// else manages them, so they are left out of both sides of a diff.
+func withoutIgnored(names, ignored []string, key func(string) string) []string {
+	if len(ignored) == 0 {
+		return names
+	}
+	skip := keySet(ignored, key)
+	var kept []string
+	for _, name := range names {
+		if !skip[key(name)] {
+			kept = append(kept, name)
+		}
+	}
+	return kept
+}
+
+// managedNames returns the names found on the server as authoritativeSet
+// should see them when some names are ignored. Ignored names found on the
+// server are left out, so that they don't show up as drift, and ignored names
+// in the configuration are kept, so that they don't drop out of the state.
+func managedNames(ctx context.Context, configured types.Set, actual, ignored []string, key func(string) string, diags *diag.Diagnostics) []string {
+	if len(ignored) == 0 {
+		return actual
+	}
+	names := withoutIgnored(actual, ignored, key)
+	skip := keySet(ignored, key)
+	for _, name := range setElements(ctx, configured, diags) {
+		if skip[key(name)] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// stringSetValue builds a sorted set of strings.
+func stringSetValue(values []string) types.Set {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	elements := make([]attr.Value, len(sorted))
+	for i, value := range sorted {
+		elements[i] = types.StringValue(value)
+	}
+	set, _ := types.SetValue(types.StringType, elements)
+	return set
+}
+
+// previewSetChanges adds a warning to the plan for each of the given set
+// attributes that lists the names to be added and removed, so that access
+// changes can be reviewed by name. Names are compared by key, so a change
+// that key ignores, such as in case only, is not listed. Unknown sets and
+// destroy plans are skipped.
+func previewSetChanges(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, key func(string) string, attributes ...string) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	for _, attribute := range attributes {
+		var planned, current types.Set
+		resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root(attribute), &planned)...)
+		if !req.State.Raw.IsNull() {
+			resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root(attribute), &current)...)
+		}
+		if resp.Diagnostics.HasError() || planned.IsNull() || planned.IsUnknown() || current.IsUnknown() {
+			continue
+		}
+
+		var desired, existing []string
+		resp.Diagnostics.Append(planned.ElementsAs(ctx, &desired, false)...)
+		resp.Diagnostics.Append(current.ElementsAs(ctx, &existing, false)...)
+		add, remove := diffNames(existing, desired, key)
+		if len(add) == 0 && len(remove) == 0 {
+			continue
+		}
+
+		var lines []string
+		if len(add) > 0 {
+			sort.Strings(add)
+			lines = append(lines, "To add: "+strings.Join(add, ", "))
+		}
+		if len(remove) > 0 {
+			sort.Strings(remove)
+			lines = append(lines, "To remove: "+strings.Join(remove, ", "))
+		}
+		resp.Diagnostics.AddAttributeWarning(path.Root(attribute), fmt.Sprintf("Planned changes to %s", attribute), strings.Join(lines, "\n"))
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright (c) 2024 muecahit94
+// SPDX-License-Identifier: MIT
+
+package provider
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// timestampValue formats a catalog view timestamp as an RFC3339 string.
+func timestampValue(t time.Time) types.String {
+	return types.StringValue(t.Format(time.RFC3339))
+}
+
+// lookupByNameOrID finds an object for import by the name or ID given in the
+// import ID. A numeric value is looked up as an ID first, which also finds
+// objects renamed since the ID was recorded, and as a name if no object has
+// that ID.
+func lookupByNameOrID[T any](nameOrID string, byID func(id int) (*T, error), byName func(name string) (*T, error)) (*T, error) {
+	if id, err := strconv.Atoi(nameOrID); err == nil {
+		obj, err := byID(id)
+		if err != nil || obj != nil {
+			return obj, err
+		}
+	}
+	return byName(nameOrID)
+}
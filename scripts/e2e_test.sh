@@ -211,6 +211,13 @@ EOF
         record_test "SQL Verify: Login exists" "FAIL"
     fi
 
+    # Check lock state
+    if terraform state show -no-color mssql_sql_login.app 2>/dev/null | grep -Eq 'is_locked +=  *false'; then
+        record_test "SQL Verify: Login is not locked" "PASS"
+    else
+        record_test "SQL Verify: Login is not locked" "FAIL"
+    fi
+
     # Check user in database
     if run_sql "SELECT 1 FROM sys.database_principals WHERE name = 'app_user'" "application_db" | grep -v "Executed in" | grep "1" -q; then
         record_test "SQL Verify: User exists in database" "PASS"
@@ -232,6 +239,76 @@ EOF
         record_test "SQL Verify: Schema exists" "FAIL"
     fi
 
+    # Check the quoted schema name and the GRANT from its create_objects batch
+    if run_sql "SELECT 1 FROM sys.database_permissions p JOIN sys.database_principals pr ON p.grantee_principal_id = pr.principal_id JOIN sys.schemas s ON p.major_id = s.schema_id WHERE s.name = 'report [archive]' AND pr.name = 'app_readers' AND p.class = 3 AND p.permission_name = 'SELECT'" "application_db" | grep -v "Executed in" | grep "1" -q; then
+        record_test "SQL Verify: Schema created with embedded grant" "PASS"
+    else
+        record_test "SQL Verify: Schema created with embedded grant" "FAIL"
+    fi
+
+    # Check the filtered index created by mssql_script with set_options
+    if run_sql "SELECT 1 FROM sys.indexes WHERE name = 'ix_orders_open' AND has_filter = 1" "application_db" | grep -v "Executed in" | grep "1" -q; then
+        record_test "SQL Verify: Script with SET options" "PASS"
+    else
+        record_test "SQL Verify: Script with SET options" "FAIL"
+    fi
+
+    # Check the index created by mssql_script with statement_options
+    if run_sql "SELECT 1 FROM sys.indexes WHERE name = 'ix_orders_shipped'" "application_db" | grep -v "Executed in" | grep "1" -q; then
+        record_test "SQL Verify: Script with statement options" "PASS"
+    else
+        record_test "SQL Verify: Script with statement options" "FAIL"
+    fi
+
+    # Check the object permissions granted and denied by mssql_permission
+    if run_sql "SELECT 1 WHERE EXISTS (SELECT 1 FROM sys.database_permissions p JOIN sys.database_principals pr ON p.grantee_principal_id = pr.principal_id WHERE pr.name = 'app_readers' AND p.class = 1 AND p.major_id = OBJECT_ID('dbo.orders') AND p.permission_name = 'SELECT' AND p.state = 'G') AND EXISTS (SELECT 1 FROM sys.database_permissions p JOIN sys.database_principals pr ON p.grantee_principal_id = pr.principal_id WHERE pr.name = 'app_readers' AND p.class = 1 AND p.major_id = OBJECT_ID('dbo.orders') AND p.permission_name = 'DELETE' AND p.state = 'D')" "application_db" | grep -v "Executed in" | grep "1" -q; then
+        record_test "SQL Verify: Object permissions by class" "PASS"
+    else
+        record_test "SQL Verify: Object permissions by class" "FAIL"
+    fi
+
+    terraform state rm mssql_permission.readers_orders_deny_delete >/dev/null 2>&1
+    if terraform import mssql_permission.readers_orders_deny_delete "application_db/app_readers/DELETE/OBJECT/dbo.orders" 2>&1 | grep -q "Import successful" && \
+        terraform plan -detailed-exitcode -target=mssql_permission.readers_orders_deny_delete >/dev/null 2>&1; then
+        record_test "Import: Denied object permission" "PASS"
+    else
+        record_test "Import: Denied object permission" "FAIL"
+    fi
+
+    # Check the row inserted by mssql_exec
+    if run_sql "SELECT 1 FROM dbo.orders WHERE id = 1" "application_db" | grep -v "Executed in" | grep "1" -q; then
+        record_test "SQL Verify: Exec statement" "PASS"
+    else
+        record_test "SQL Verify: Exec statement" "FAIL"
+    fi
+
+    # Check the multi-word server permission and its import
+    if run_sql "SELECT 1 FROM sys.server_permissions p JOIN sys.server_principals pr ON p.grantee_principal_id = pr.principal_id WHERE pr.name = 'app_login' AND p.permission_name = 'ALTER ANY LOGIN' AND p.class = 100 AND p.state = 'G'" | grep -v "Executed in" | grep "1" -q; then
+        record_test "SQL Verify: ALTER ANY LOGIN server permission" "PASS"
+    else
+        record_test "SQL Verify: ALTER ANY LOGIN server permission" "FAIL"
+    fi
+
+    terraform state rm mssql_server_permission.app_alter_any_login >/dev/null 2>&1
+    if terraform import mssql_server_permission.app_alter_any_login "app_login/ALTER ANY LOGIN" 2>&1 | grep -q "Import successful" && \
+        terraform plan -detailed-exitcode -target=mssql_server_permission.app_alter_any_login >/dev/null 2>&1; then
+        record_test "Import: ALTER ANY LOGIN server permission" "PASS"
+    else
+        record_test "Import: ALTER ANY LOGIN server permission" "FAIL"
+    fi
+
+    # Re-import a role by principal ID and verify the plan stays empty
+    log_info "Test: Import database role by principal ID..."
+    local readers_id
+    readers_id=$(terraform state show -no-color mssql_database_role.readers 2>/dev/null | grep -E '^ *id += ' | sed -E 's|.*"[0-9]+/([0-9]+)".*|\1|')
+    terraform state rm mssql_database_role.readers >/dev/null 2>&1
+    if terraform import mssql_database_role.readers "application_db/${readers_id}" 2>&1 | grep -q "Import successful" && \
+        terraform plan -detailed-exitcode -target=mssql_database_role.readers >/dev/null 2>&1; then
+        record_test "Import: Database role by principal ID" "PASS"
+    else
+        record_test "Import: Database role by principal ID" "FAIL"
+    fi
+
     # Check role membership (app_user in app_readers via OPTION 1: inline roles)
     if run_sql "SELECT 1 FROM sys.database_role_members rm JOIN sys.database_principals r ON rm.role_principal_id = r.principal_id JOIN sys.database_principals m ON rm.member_principal_id = m.principal_id WHERE r.name = 'app_readers' AND m.name = 'app_user'" "application_db" | grep -v "Executed in" | grep "1" -q; then
         record_test "SQL Verify: app_user in app_readers (Option 1: inline roles)" "PASS"
@@ -267,6 +344,56 @@ EOF
         record_test "SQL Verify: Permission granted" "FAIL"
     fi
 
+    # Check CONNECT granted to the public role
+    if run_sql "SELECT 1 FROM sys.database_permissions p JOIN sys.database_principals pr ON p.grantee_principal_id = pr.principal_id WHERE pr.name = 'public' AND p.permission_name = 'CONNECT' AND p.class = 0 AND p.state = 'G'" "application_db" | grep -v "Executed in" | grep "1" -q; then
+        record_test "SQL Verify: CONNECT granted to public" "PASS"
+    else
+        record_test "SQL Verify: CONNECT granted to public" "FAIL"
+    fi
+
+    # Check CONTROL granted on the signing certificate (class 25)
+    if run_sql "SELECT 1 FROM sys.database_permissions p JOIN sys.database_principals pr ON p.grantee_principal_id = pr.principal_id JOIN sys.certificates c ON p.major_id = c.certificate_id WHERE pr.name = 'app_readers' AND c.name = 'signing_cert' AND p.permission_name = 'CONTROL' AND p.class = 25 AND p.state = 'G'" "application_db" | grep -v "Executed in" | grep "1" -q; then
+        record_test "SQL Verify: Certificate permission granted" "PASS"
+    else
+        record_test "SQL Verify: Certificate permission granted" "FAIL"
+    fi
+
+    # Check the user mapped to the signing certificate and its import
+    if run_sql "SELECT 1 FROM sys.database_principals dp JOIN sys.certificates c ON c.sid = dp.sid WHERE dp.name = 'signing_user' AND dp.type = 'C' AND c.name = 'signing_cert'" "application_db" | grep -v "Executed in" | grep "1" -q; then
+        record_test "SQL Verify: Certificate user created" "PASS"
+    else
+        record_test "SQL Verify: Certificate user created" "FAIL"
+    fi
+
+    terraform state rm mssql_sql_user.signing >/dev/null 2>&1
+    if terraform import mssql_sql_user.signing "application_db/signing_user" 2>&1 | grep -q "Import successful" && \
+        terraform state show mssql_sql_user.signing 2>/dev/null | grep -q 'certificate_name.*"signing_cert"'; then
+        record_test "Import: Certificate user" "PASS"
+    else
+        record_test "Import: Certificate user" "FAIL"
+    fi
+
+    # Check ALTER granted on test_user (class 4)
+    if run_sql "SELECT 1 FROM sys.database_permissions p JOIN sys.database_principals pr ON p.grantee_principal_id = pr.principal_id JOIN sys.database_principals u ON p.major_id = u.principal_id WHERE pr.name = 'app_writers' AND u.name = 'test_user' AND p.permission_name = 'ALTER' AND p.class = 4 AND p.state = 'G'" "application_db" | grep -v "Executed in" | grep "1" -q; then
+        record_test "SQL Verify: User permission granted" "PASS"
+    else
+        record_test "SQL Verify: User permission granted" "FAIL"
+    fi
+
+    # Check CREATE VIEW granted on the database named explicitly (class 0)
+    if run_sql "SELECT 1 FROM sys.database_permissions p JOIN sys.database_principals pr ON p.grantee_principal_id = pr.principal_id WHERE pr.name = 'app_writers' AND p.permission_name = 'CREATE VIEW' AND p.class = 0 AND p.state = 'G'" "application_db" | grep -v "Executed in" | grep "1" -q; then
+        record_test "SQL Verify: Explicit database permission granted" "PASS"
+    else
+        record_test "SQL Verify: Explicit database permission granted" "FAIL"
+    fi
+
+    # Check app_readers has a plain SELECT grant without GRANT OPTION (state = G)
+    if run_sql "SELECT 1 FROM sys.database_permissions p JOIN sys.database_principals pr ON p.grantee_principal_id = pr.principal_id WHERE pr.name = 'app_readers' AND p.permission_name = 'SELECT' AND p.class = 0 AND p.state = 'G'" "application_db" | grep -v "Executed in" | grep "1" -q; then
+        record_test "SQL Verify: Permission state GRANT" "PASS"
+    else
+        record_test "SQL Verify: Permission state GRANT" "FAIL"
+    fi
+
     # Check test_user has SELECT on app schema with WITH GRANT OPTION (state = W)
     local test_user_perm=$(run_sql "SELECT state FROM sys.database_permissions p JOIN sys.database_principals pr ON p.grantee_principal_id = pr.principal_id JOIN sys.schemas s ON p.major_id = s.schema_id WHERE pr.name = 'test_user' AND s.name = 'app' AND p.permission_name = 'SELECT'" "application_db" 2>/dev/null)
     if echo "$test_user_perm" | grep -q "W"; then
@@ -276,6 +403,57 @@ EOF
         record_test "SQL Verify: test_user schema permission" "FAIL"
     fi
 
+    # Check the authoritative app_auditors role holds its permissions and members
+    local auditors_perms=$(run_sql "SELECT COUNT(*) FROM sys.database_permissions p JOIN sys.database_principals pr ON p.grantee_principal_id = pr.principal_id WHERE pr.name = 'app_auditors' AND p.class = 0 AND p.state = 'G' AND p.permission_name IN ('VIEW DEFINITION', 'SHOWPLAN')" "application_db" 2>/dev/null)
+    if echo "$auditors_perms" | grep -q "\b2\b"; then
+        record_test "SQL Verify: Role permissions" "PASS"
+    else
+        record_test "SQL Verify: Role permissions" "FAIL"
+    fi
+    if run_sql "SELECT 1 FROM sys.database_role_members drm JOIN sys.database_principals r ON drm.role_principal_id = r.principal_id JOIN sys.database_principals m ON drm.member_principal_id = m.principal_id WHERE r.name = 'app_auditors' AND m.name = 'test_user'" "application_db" | grep -v "Executed in" | grep "1" -q; then
+        record_test "SQL Verify: Role members" "PASS"
+    else
+        record_test "SQL Verify: Role members" "FAIL"
+    fi
+
+    # Check the owner of the application database
+    if run_sql "SELECT 1 FROM sys.databases WHERE name = 'application_db' AND SUSER_SNAME(owner_sid) = 'app_db_owner'" | grep -v "Executed in" | grep "1" -q; then
+        record_test "SQL Verify: Database owner" "PASS"
+    else
+        record_test "SQL Verify: Database owner" "FAIL"
+    fi
+
+    # Check the login created with is_disabled = true
+    if run_sql "SELECT 1 FROM sys.sql_logins WHERE name = 'pending_service_login' AND is_disabled = 1" | grep -v "Executed in" | grep "1" -q; then
+        record_test "SQL Verify: Login created disabled" "PASS"
+    else
+        record_test "SQL Verify: Login created disabled" "FAIL"
+    fi
+
+    # Check the access resource applied every kind of access to audit_user
+    local audit_access=$(run_sql "SELECT (SELECT COUNT(*) FROM sys.server_role_members srm JOIN sys.server_principals r ON srm.role_principal_id = r.principal_id JOIN sys.server_principals m ON srm.member_principal_id = m.principal_id WHERE r.name = 'dbcreator' AND m.name = 'audit_login') + (SELECT COUNT(*) FROM sys.database_role_members drm JOIN sys.database_principals r ON drm.role_principal_id = r.principal_id JOIN sys.database_principals m ON drm.member_principal_id = m.principal_id WHERE r.name = 'app_readers' AND m.name = 'audit_user') + (SELECT COUNT(*) FROM sys.database_permissions p JOIN sys.database_principals pr ON p.grantee_principal_id = pr.principal_id WHERE pr.name = 'audit_user' AND p.state = 'G' AND ((p.class = 0 AND p.permission_name = 'VIEW DEFINITION') OR (p.class = 3 AND p.major_id = SCHEMA_ID('app') AND p.permission_name IN ('SELECT', 'EXECUTE'))))" "application_db" 2>/dev/null)
+    if echo "$audit_access" | grep -q "\b5\b"; then
+        record_test "SQL Verify: Access resource" "PASS"
+    else
+        log_error "Expected audit_user to hold 1 server role, 1 database role and 3 permissions"
+        record_test "SQL Verify: Access resource" "FAIL"
+    fi
+
+    # Check the effective schema permissions include the grant inherited from app_readers
+    if terraform output -raw test_effective_report_permissions 2>/dev/null | grep -q "SELECT:app_readers"; then
+        record_test "Data Source: Effective schema permissions" "PASS"
+    else
+        record_test "Data Source: Effective schema permissions" "FAIL"
+    fi
+
+    # Check the SID of the reporting user matches its login in the resource and the data source
+    local report_sid=$(run_sql "SELECT CONVERT(varchar(172), sid, 1) FROM sys.server_principals WHERE name = 'report_login'" 2>/dev/null | grep -o "0x[0-9A-F]*")
+    if [[ -n "$report_sid" ]] && [ "$(terraform output -raw report_user_sids 2>/dev/null)" = "$report_sid,$report_sid" ]; then
+        record_test "Data Source: Database user SIDs" "PASS"
+    else
+        record_test "Data Source: Database user SIDs" "FAIL"
+    fi
+
     # Check app_user owns the app schema
     local app_schema_owner=$(run_sql "SELECT dp.name FROM sys.schemas s JOIN sys.database_principals dp ON s.principal_id = dp.principal_id WHERE s.name = 'app'" "application_db" 2>/dev/null)
     if echo "$app_schema_owner" | grep -q "app_user"; then
@@ -285,6 +463,27 @@ EOF
         record_test "SQL Verify: app_user schema owner" "FAIL"
     fi
 
+    # Check the composite login user: login, mapped user and its role
+    if run_sql "SELECT 1 FROM sys.database_principals dp JOIN sys.server_principals sp ON dp.sid = sp.sid JOIN sys.database_role_members rm ON rm.member_principal_id = dp.principal_id JOIN sys.database_principals r ON rm.role_principal_id = r.principal_id WHERE dp.name = 'report_login' AND sp.name = 'report_login' AND r.name = 'db_datareader'" "application_db" | grep -v "Executed in" | grep "1" -q; then
+        record_test "SQL Verify: Login user" "PASS"
+    else
+        record_test "SQL Verify: Login user" "FAIL"
+    fi
+
+    # Check the agent job, its owner and its steps in order with their defaults
+    if run_sql "SELECT 1 FROM msdb.dbo.sysjobs j JOIN msdb.dbo.sysjobsteps s1 ON s1.job_id = j.job_id AND s1.step_id = 1 JOIN msdb.dbo.sysjobsteps s2 ON s2.job_id = j.job_id AND s2.step_id = 2 WHERE j.name = 'application_db maintenance' AND j.enabled = 1 AND SUSER_SNAME(j.owner_sid) = 'sa' AND s1.database_name = 'application_db' AND s1.on_success_action = 3 AND s2.database_name = 'master' AND s2.on_success_action = 1" | grep -v "Executed in" | grep "1" -q; then
+        record_test "SQL Verify: Agent job" "PASS"
+    else
+        record_test "SQL Verify: Agent job" "FAIL"
+    fi
+
+    # Check the weekly schedule (Saturday = 64 + Sunday = 1) is attached to the job
+    if run_sql "SELECT 1 FROM msdb.dbo.sysschedules s JOIN msdb.dbo.sysjobschedules js ON js.schedule_id = s.schedule_id JOIN msdb.dbo.sysjobs j ON j.job_id = js.job_id WHERE s.name = 'application_db maintenance' AND j.name = 'application_db maintenance' AND s.freq_type = 8 AND s.freq_interval = 65 AND s.freq_recurrence_factor = 1 AND s.freq_subday_type = 8 AND s.freq_subday_interval = 6 AND s.active_start_time = 13000" | grep -v "Executed in" | grep "1" -q; then
+        record_test "SQL Verify: Agent job schedule" "PASS"
+    else
+        record_test "SQL Verify: Agent job schedule" "FAIL"
+    fi
+
     # Check idempotency
     log_info "Checking idempotency..."
     local plan_output
@@ -327,6 +526,138 @@ phase_data_sources() {
         record_test "Data Sources: Output verification" "FAIL"
     fi
 
+    # Verify the read-only query returned a row
+    if terraform output -raw read_only_query 2>/dev/null | grep -q "READ_"; then
+        record_test "Data Sources: Read-only query" "PASS"
+    else
+        record_test "Data Sources: Read-only query" "FAIL"
+    fi
+
+    # Verify the query ran in each database and skipped the missing one
+    if [ "$(terraform output -raw query_across_databases 2>/dev/null)" = "master=master,application_db=application_db" ]; then
+        record_test "Data Sources: Query across databases" "PASS"
+    else
+        record_test "Data Sources: Query across databases" "FAIL"
+    fi
+
+    # Verify the access report resolves permissions inherited through roles
+    local app_user_access
+    app_user_access=$(terraform output -raw app_user_access 2>/dev/null)
+    if echo "$app_user_access" | grep -q "DATABASE:SELECT:app_readers" && \
+        echo "$app_user_access" | grep -q "DATABASE:CONNECT:public" && \
+        echo "$app_user_access" | grep -q "SCHEMA:EXECUTE:-"; then
+        record_test "Data Sources: Access report with inherited permissions" "PASS"
+    else
+        record_test "Data Sources: Access report with inherited permissions" "FAIL"
+    fi
+
+    # Verify principal timestamps are RFC3339 strings
+    if terraform output -raw sysadmin_create_date 2>/dev/null | grep -Eq "^[0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9]{2}:[0-9]{2}:[0-9]{2}(Z|[+-][0-9]{2}:[0-9]{2})$"; then
+        record_test "Data Sources: Principal timestamps" "PASS"
+    else
+        record_test "Data Sources: Principal timestamps" "FAIL"
+    fi
+
+    # Verify the owner filter only lists schemas owned by sys
+    local sys_owned_schemas
+    sys_owned_schemas=$(terraform output -raw sys_owned_schemas 2>/dev/null)
+    if echo "$sys_owned_schemas" | grep -q "INFORMATION_SCHEMA" && ! echo "$sys_owned_schemas" | grep -qw "dbo"; then
+        record_test "Data Sources: Schemas filtered by owner" "PASS"
+    else
+        record_test "Data Sources: Schemas filtered by owner" "FAIL"
+    fi
+
+    # Verify the type filter only lists the tables of the dbo schema
+    local app_tables
+    app_tables=$(terraform output -raw app_tables 2>/dev/null)
+    if [ "$app_tables" = "orders:USER_TABLE" ]; then
+        record_test "Data Sources: Schema objects filtered by type" "PASS"
+    else
+        record_test "Data Sources: Schema objects filtered by type" "FAIL"
+    fi
+
+    # Verify the role memberships of sa on both scopes
+    local sa_memberships
+    sa_memberships=$(terraform output -raw sa_memberships 2>/dev/null)
+    if echo "$sa_memberships" | grep -qw "sysadmin" && echo "$sa_memberships" | grep -qw "db_owner"; then
+        record_test "Data Sources: Principal memberships" "PASS"
+    else
+        record_test "Data Sources: Principal memberships" "FAIL"
+    fi
+
+    # Verify HAS_PERMS_BY_NAME for the provider's login and an impersonated user
+    if [ "$(terraform output -raw permission_checks 2>/dev/null)" = "true,false" ]; then
+        record_test "Data Sources: Has permission" "PASS"
+    else
+        record_test "Data Sources: Has permission" "FAIL"
+    fi
+
+    # Verify the default TCP endpoint is listed and started
+    if [ "$(terraform output -raw tsql_endpoint_state 2>/dev/null)" = "STARTED" ]; then
+        record_test "Data Sources: Endpoints" "PASS"
+    else
+        record_test "Data Sources: Endpoints" "FAIL"
+    fi
+
+    # Verify the server-level pool has an open connection after the reads
+    if [ "$(terraform output -raw server_pool_open_connections 2>/dev/null)" -ge 1 ] 2>/dev/null; then
+        record_test "Data Sources: Provider stats" "PASS"
+    else
+        record_test "Data Sources: Provider stats" "FAIL"
+    fi
+
+    # Verify IDs are built with server_name instead of the connected host
+    if [ "$(terraform output -raw provider_stats_id 2>/dev/null)" = "sqlserver-e2e:1433" ]; then
+        record_test "Data Sources: Server name in IDs" "PASS"
+    else
+        record_test "Data Sources: Server name in IDs" "FAIL"
+    fi
+
+    # Verify the import IDs of permissions that exist on every server
+    all_permissions=$(terraform output -raw all_permissions_import_ids 2>/dev/null)
+    if echo "$all_permissions" | grep -q "public/VIEW ANY DATABASE" && \
+        echo "$all_permissions" | grep -q "public/CONNECT/ENDPOINT/TSQL Default TCP" && \
+        echo "$all_permissions" | grep -q "mssql_database_permission:master/guest/CONNECT"; then
+        record_test "Data Sources: All permissions" "PASS"
+    else
+        record_test "Data Sources: All permissions" "FAIL"
+    fi
+
+    # Verify the import IDs of the role of the complete example, its member and its permission
+    local app_readers_imports
+    app_readers_imports=$(terraform output -raw app_readers_imports 2>/dev/null)
+    if echo "$app_readers_imports" | grep -q "mssql_database_role:application_db/app_readers" && \
+        echo "$app_readers_imports" | grep -q "mssql_database_role_member:application_db/app_readers/app_user" && \
+        echo "$app_readers_imports" | grep -q "mssql_database_permission:application_db/app_readers/SELECT"; then
+        record_test "Data Sources: Database imports" "PASS"
+    else
+        record_test "Data Sources: Database imports" "FAIL"
+    fi
+
+    # Verify the members and permissions read with the database role
+    local app_readers_access
+    app_readers_access=$(terraform output -raw app_readers_access 2>/dev/null)
+    if echo "$app_readers_access" | grep -Eq "^members=([^;]*,)?app_user[,;]" && \
+        echo "$app_readers_access" | grep -Eq "permissions=.*GRANT[A-Z_]*:SELECT"; then
+        record_test "Data Sources: Database role members and permissions" "PASS"
+    else
+        record_test "Data Sources: Database role members and permissions" "FAIL"
+    fi
+
+    # Verify the password policy state read with LOGINPROPERTY
+    if terraform output -raw sa_password_state 2>/dev/null | grep -Eq "^locked=false,bad_password_count=[0-9]+,password_last_set_time=[0-9]{4}-[0-9]{2}-[0-9]{2}T"; then
+        record_test "Data Sources: Login password state" "PASS"
+    else
+        record_test "Data Sources: Login password state" "FAIL"
+    fi
+
+    # Verify the SIDs of sa and sysadmin, which are the same on every server
+    if [[ "$(terraform output -raw principal_sids 2>/dev/null)" == "0x01,0x03" ]]; then
+        record_test "Data Sources: Login and server role SIDs" "PASS"
+    else
+        record_test "Data Sources: Login and server role SIDs" "FAIL"
+    fi
+
     return 0
 }
 
@@ -359,6 +690,22 @@ phase_provider_example() {
         record_test "Provider Example: Resources verified" "FAIL"
     fi
 
+    # The added filegroup has a data file and is the default filegroup
+    if run_sql "SELECT 1 FROM sys.filegroups fg JOIN sys.database_files df ON df.data_space_id = fg.data_space_id WHERE fg.name = 'example_data' AND fg.is_default = 1" "example_db" | grep -v "Executed in" | grep "1" -q && \
+        terraform plan -detailed-exitcode >/dev/null 2>&1; then
+        record_test "Provider Example: Default filegroup" "PASS"
+    else
+        record_test "Provider Example: Default filegroup" "FAIL"
+    fi
+
+    # The configured AUTO_* options are set without drift
+    if run_sql "SELECT 1 FROM sys.databases WHERE name = 'example_db' AND is_auto_shrink_on = 1 AND is_auto_close_on = 0" | grep -v "Executed in" | grep "1" -q && \
+        terraform plan -detailed-exitcode >/dev/null 2>&1; then
+        record_test "Provider Example: Database options" "PASS"
+    else
+        record_test "Provider Example: Database options" "FAIL"
+    fi
+
     # Destroy
     log_info "Destroying provider example..."
     local destroy_output
@@ -370,6 +717,136 @@ phase_provider_example() {
         record_test "Provider Example: terraform destroy" "FAIL"
     fi
 
+    # A missing default database is reported before the login is created
+    log_info "Test: Missing default database..."
+    local missing_db_output
+    missing_db_output=$(terraform apply -auto-approve -target=mssql_sql_login.example -var login_default_database=missing_db 2>&1) || true
+    if echo "$missing_db_output" | grep -q "Default database not found" && \
+        ! run_sql "SELECT 1 FROM sys.sql_logins WHERE name = 'example_login'" | grep -v "Executed in" | grep "1" -q; then
+        record_test "Provider Example: Missing default database" "PASS"
+    else
+        record_test "Provider Example: Missing default database" "FAIL"
+    fi
+
+    # An unknown default language is reported at plan time with the valid names
+    log_info "Test: Unknown default language..."
+    local language_output
+    language_output=$(terraform plan -no-color -var login_default_language=klingon 2>&1) || true
+    if echo "$language_output" | grep -q "Unknown default language" && \
+        echo "$language_output" | grep -q "us_english"; then
+        record_test "Provider Example: Unknown default language" "PASS"
+    else
+        record_test "Provider Example: Unknown default language" "FAIL"
+    fi
+
+    return 0
+}
+
+# Phase 4b: sa Login
+phase_sa_login() {
+    log_header "PHASE 4b: SA LOGIN"
+
+    local example_dir="$PROJECT_ROOT/examples/testing/sa_login"
+    cd "$example_dir"
+
+    # Clean up any existing state
+    cleanup_state_files "$example_dir"
+
+    log_info "Importing the sa login..."
+    if terraform import mssql_sql_login.sa sa 2>&1 | grep -q "Import successful"; then
+        record_test "sa Login: terraform import" "PASS"
+    else
+        record_test "sa Login: terraform import" "FAIL"
+        return 1
+    fi
+
+    local apply_output
+    apply_output=$(terraform apply -auto-approve 2>&1)
+    if echo "$apply_output" | grep -q "Apply complete"; then
+        record_test "sa Login: terraform apply" "PASS"
+    else
+        echo "$apply_output" | tail -10
+        record_test "sa Login: terraform apply" "FAIL"
+    fi
+
+    # The provider is connected as sa, so disabling it must be refused
+    log_info "Test: Disabling the provider's own login is refused..."
+    apply_output=$(terraform apply -auto-approve -var sa_disabled=true 2>&1) || true
+    if echo "$apply_output" | grep -q "Refusing to lock out the provider" && \
+        run_sql "SELECT 1 FROM sys.sql_logins WHERE principal_id = 1 AND is_disabled = 0" | grep -v "Executed in" | grep "1" -q; then
+        record_test "sa Login: Lockout guard" "PASS"
+    else
+        record_test "sa Login: Lockout guard" "FAIL"
+    fi
+
+    # Destroying the resource must leave sa in place
+    log_info "Test: Destroy keeps the sa login..."
+    local destroy_output
+    destroy_output=$(terraform destroy -auto-approve 2>&1)
+    if echo "$destroy_output" | grep -q "Destroy complete" && \
+        run_sql "SELECT 1 FROM sys.sql_logins WHERE principal_id = 1" | grep -v "Executed in" | grep "1" -q; then
+        record_test "sa Login: Destroy guard" "PASS"
+    else
+        record_test "sa Login: Destroy guard" "FAIL"
+    fi
+
+    return 0
+}
+
+# Phase 4c: Fixed Server Role
+phase_fixed_server_role() {
+    log_header "PHASE 4c: FIXED SERVER ROLE"
+
+    local example_dir="$PROJECT_ROOT/examples/testing/fixed_server_role"
+    cd "$example_dir"
+
+    # Clean up any existing state
+    cleanup_state_files "$example_dir"
+
+    log_info "Importing the securityadmin role..."
+    if terraform import mssql_server_role.securityadmin securityadmin 2>&1 | grep -q "Import successful"; then
+        record_test "Fixed Server Role: terraform import" "PASS"
+    else
+        record_test "Fixed Server Role: terraform import" "FAIL"
+        return 1
+    fi
+
+    local apply_output
+    apply_output=$(terraform apply -auto-approve 2>&1)
+    if echo "$apply_output" | grep -q "Apply complete" && \
+        run_sql "SELECT 1 FROM sys.server_role_members rm JOIN sys.server_principals r ON rm.role_principal_id = r.principal_id JOIN sys.server_principals m ON rm.member_principal_id = m.principal_id WHERE r.name = 'securityadmin' AND m.name = 'security_operator'" | grep -v "Executed in" | grep "1" -q; then
+        record_test "Fixed Server Role: Member added" "PASS"
+    else
+        echo "$apply_output" | tail -10
+        record_test "Fixed Server Role: Member added" "FAIL"
+    fi
+
+    # User-defined roles nested in a fixed and in a user-defined role
+    if run_sql "SELECT COUNT(*) FROM sys.server_role_members rm JOIN sys.server_principals r ON rm.role_principal_id = r.principal_id JOIN sys.server_principals m ON rm.member_principal_id = m.principal_id WHERE m.type = 'R' AND ((r.name = 'securityadmin' AND m.name = 'security_operators') OR (r.name = 'security_operators' AND m.name = 'security_auditors'))" | grep -v "Executed in" | grep -qw "2" && \
+        terraform state show -no-color mssql_server_role_member.security_auditors 2>/dev/null | grep -q 'security_operators/security_auditors'; then
+        record_test "Fixed Server Role: Nested roles" "PASS"
+    else
+        record_test "Fixed Server Role: Nested roles" "FAIL"
+    fi
+
+    # The owner of a fixed role is empty, so a second plan shows no changes
+    if terraform plan -detailed-exitcode >/dev/null 2>&1; then
+        record_test "Fixed Server Role: No drift" "PASS"
+    else
+        record_test "Fixed Server Role: No drift" "FAIL"
+    fi
+
+    # Destroying the resources must leave securityadmin in place
+    log_info "Test: Destroy keeps the securityadmin role..."
+    local destroy_output
+    destroy_output=$(terraform destroy -auto-approve 2>&1)
+    if echo "$destroy_output" | grep -q "Destroy complete" && \
+        run_sql "SELECT 1 FROM sys.server_principals WHERE name = 'securityadmin' AND is_fixed_role = 1" | grep -v "Executed in" | grep "1" -q; then
+        record_test "Fixed Server Role: Destroy guard" "PASS"
+    else
+        record_test "Fixed Server Role: Destroy guard" "FAIL"
+    fi
+
     return 0
 }
 
@@ -397,6 +874,20 @@ phase_drift_recovery() {
         record_test "Drift Recovery: Role recreation" "FAIL"
     fi
 
+    # Test 1b: Destroy a permission whose principal was dropped outside of Terraform
+    log_info "Test: Revoke from a dropped principal..."
+    run_sql "ALTER ROLE app_readers DROP MEMBER app_user; DROP ROLE app_readers;" "application_db" >/dev/null 2>&1 || true
+
+    local destroy_output
+    destroy_output=$(terraform destroy -auto-approve -refresh=false -target=mssql_database_permission.readers_select 2>&1)
+    apply_output=$(terraform apply -auto-approve 2>&1)
+    if echo "$destroy_output" | grep -q "Destroy complete" && echo "$apply_output" | grep -q "Apply complete"; then
+        record_test "Drift Recovery: Revoke from a dropped principal" "PASS"
+    else
+        echo "$destroy_output" | tail -5
+        record_test "Drift Recovery: Revoke from a dropped principal" "FAIL"
+    fi
+
     # Test 2: Delete user and recover
     log_info "Test: User deletion recovery..."
     run_sql "DROP SCHEMA app; DROP USER app_user;" "application_db" >/dev/null 2>&1 || true
@@ -427,6 +918,38 @@ phase_drift_recovery() {
         record_test "Drift Recovery: Permission restoration" "FAIL"
     fi
 
+    # Test 3b: DENY permission and recover
+    log_info "Test: Denied permission recovery..."
+    run_sql "DENY SELECT TO app_readers" "application_db" >/dev/null 2>&1 || true
+
+    apply_output=$(terraform apply -auto-approve 2>&1)
+    if echo "$apply_output" | grep -q "Apply complete"; then
+        # The DENY (state = D) must be replaced by a plain GRANT (state = G)
+        if run_sql "SELECT 1 FROM sys.database_permissions p JOIN sys.database_principals pr ON p.grantee_principal_id = pr.principal_id WHERE pr.name = 'app_readers' AND p.permission_name = 'SELECT' AND p.class = 0 AND p.state = 'G'" "application_db" | grep -v "Executed in" | grep "1" -q; then
+            record_test "Drift Recovery: Denied permission restoration" "PASS"
+        else
+            record_test "Drift Recovery: Denied permission restoration" "FAIL"
+        fi
+    else
+        record_test "Drift Recovery: Denied permission restoration" "FAIL"
+    fi
+
+    # Test 3c: Grants and members added outside of an authoritative role are removed
+    log_info "Test: Authoritative role recovery..."
+    run_sql "GRANT CREATE TABLE TO app_auditors; ALTER ROLE app_auditors ADD MEMBER app_user;" "application_db" >/dev/null 2>&1 || true
+
+    apply_output=$(terraform apply -auto-approve 2>&1)
+    if echo "$apply_output" | grep -q "Apply complete"; then
+        local extra=$(run_sql "SELECT (SELECT COUNT(*) FROM sys.database_permissions p JOIN sys.database_principals pr ON p.grantee_principal_id = pr.principal_id WHERE pr.name = 'app_auditors' AND p.permission_name = 'CREATE TABLE') + (SELECT COUNT(*) FROM sys.database_role_members drm JOIN sys.database_principals r ON drm.role_principal_id = r.principal_id JOIN sys.database_principals m ON drm.member_principal_id = m.principal_id WHERE r.name = 'app_auditors' AND m.name = 'app_user')" "application_db" 2>/dev/null)
+        if echo "$extra" | grep -q "\b0\b"; then
+            record_test "Drift Recovery: Authoritative role" "PASS"
+        else
+            record_test "Drift Recovery: Authoritative role" "FAIL"
+        fi
+    else
+        record_test "Drift Recovery: Authoritative role" "FAIL"
+    fi
+
     # Test 4: Disable login and recover
     log_info "Test: Login modification recovery..."
     run_sql "ALTER LOGIN app_login DISABLE" >/dev/null 2>&1 || true
@@ -463,6 +986,184 @@ phase_drift_recovery() {
         record_test "Drift Recovery: Schema permission restoration" "FAIL"
     fi
 
+    # Test 6: Removing a grant option must not silently revoke onward grants
+    log_info "Test: Schema grant option removal..."
+    run_sql "EXECUTE AS USER = 'test_user'; GRANT SELECT ON SCHEMA::app TO app_writers; REVERT;" "application_db" >/dev/null 2>&1 || true
+    local onward_grant="SELECT 1 FROM sys.database_permissions p JOIN sys.database_principals pr ON p.grantee_principal_id = pr.principal_id JOIN sys.schemas s ON p.major_id = s.schema_id WHERE pr.name = 'app_writers' AND s.name = 'app' AND p.permission_name = 'SELECT'"
+
+    # Without cascade, the revoke is refused while the onward grant exists
+    apply_output=$(terraform apply -auto-approve -var test_select_grant_option=false -var test_select_cascade=false 2>&1)
+    if ! echo "$apply_output" | grep -q "Apply complete" && run_sql "$onward_grant" "application_db" | grep -v "Executed in" | grep "1" -q; then
+        record_test "Grant Option: Non-cascading revoke keeps onward grants" "PASS"
+    else
+        record_test "Grant Option: Non-cascading revoke keeps onward grants" "FAIL"
+    fi
+
+    # With cascade, only the grant option is removed and SELECT is kept (state = G)
+    apply_output=$(terraform apply -auto-approve -var test_select_grant_option=false 2>&1)
+    if echo "$apply_output" | grep -q "Apply complete"; then
+        local downgraded_perm=$(run_sql "SELECT state FROM sys.database_permissions p JOIN sys.database_principals pr ON p.grantee_principal_id = pr.principal_id JOIN sys.schemas s ON p.major_id = s.schema_id WHERE pr.name = 'test_user' AND s.name = 'app' AND p.permission_name = 'SELECT'" "application_db" 2>/dev/null)
+        if echo "$downgraded_perm" | grep -qw "G" && ! run_sql "$onward_grant" "application_db" | grep -v "Executed in" | grep "1" -q; then
+            record_test "Grant Option: Cascading grant option revoke" "PASS"
+        else
+            record_test "Grant Option: Cascading grant option revoke" "FAIL"
+        fi
+    else
+        record_test "Grant Option: Cascading grant option revoke" "FAIL"
+    fi
+
+    # Restore the grant option for the remaining phases
+    terraform apply -auto-approve >/dev/null 2>&1 || true
+
+    # Test 7: Renaming a login keeps its SID and the users mapped to it
+    log_info "Test: Login rename in place..."
+    local login_sid=$(run_sql "SELECT CONVERT(varchar(100), sid, 1) FROM sys.server_principals WHERE name = 'test_login'" 2>/dev/null | grep -o "0x[0-9A-F]*")
+    apply_output=$(terraform apply -auto-approve -var test_login_name=test_login_renamed 2>&1)
+    if echo "$apply_output" | grep -q "Apply complete! Resources: 0 added" && [[ -n "$login_sid" ]] && \
+        run_sql "SELECT 1 FROM sys.server_principals WHERE name = 'test_login_renamed' AND CONVERT(varchar(100), sid, 1) = '$login_sid'" | grep -v "Executed in" | grep "1" -q && \
+        run_sql "SELECT 1 FROM sys.database_principals WHERE name = 'test_user' AND CONVERT(varchar(100), sid, 1) = '$login_sid'" "application_db" | grep -v "Executed in" | grep "1" -q; then
+        record_test "Login Rename: SID and user mapping kept" "PASS"
+    else
+        echo "$apply_output" | tail -10
+        record_test "Login Rename: SID and user mapping kept" "FAIL"
+    fi
+
+    # Rename the login back for the remaining phases
+    terraform apply -auto-approve >/dev/null 2>&1 || true
+
+    # Test 8: A login user whose user was dropped gets it back for the same login
+    log_info "Test: Login user recreation..."
+    run_sql "DROP USER report_login" "application_db" >/dev/null 2>&1 || true
+    apply_output=$(terraform apply -auto-approve 2>&1)
+    if echo "$apply_output" | grep -q "Apply complete" && \
+        run_sql "SELECT 1 FROM sys.database_principals dp JOIN sys.server_principals sp ON dp.sid = sp.sid WHERE dp.name = 'report_login' AND sp.name = 'report_login'" "application_db" | grep -v "Executed in" | grep "1" -q; then
+        record_test "Drift Recovery: Login user recreation" "PASS"
+    else
+        record_test "Drift Recovery: Login user recreation" "FAIL"
+    fi
+
+    # Test 9: Roles given by principal ID survive a rename of the role
+    log_info "Test: User role given by ID after a role rename..."
+    run_sql "ALTER ROLE app_readers WITH NAME = app_readers_renamed" "application_db" >/dev/null 2>&1 || true
+    terraform apply -refresh-only -auto-approve -target=mssql_sql_user.app >/dev/null 2>&1 || true
+    if terraform state show mssql_sql_user.app 2>/dev/null | grep -q '"id:' && \
+        run_sql "SELECT 1 FROM sys.database_role_members rm JOIN sys.database_principals r ON rm.role_principal_id = r.principal_id JOIN sys.database_principals m ON rm.member_principal_id = m.principal_id WHERE r.name = 'app_readers_renamed' AND m.name = 'app_user'" "application_db" | grep -v "Executed in" | grep "1" -q; then
+        record_test "Role By ID: Membership kept after rename" "PASS"
+    else
+        record_test "Role By ID: Membership kept after rename" "FAIL"
+    fi
+
+    # Rename the role back for the remaining phases
+    run_sql "ALTER ROLE app_readers_renamed WITH NAME = app_readers" "application_db" >/dev/null 2>&1 || true
+    terraform apply -auto-approve >/dev/null 2>&1 || true
+
+    # Test 10: A guest user enabled outside of Terraform is disabled again
+    log_info "Test: Guest user drift recovery..."
+    run_sql "GRANT CONNECT TO guest" "application_db" >/dev/null 2>&1 || true
+    apply_output=$(terraform apply -auto-approve 2>&1)
+    if echo "$apply_output" | grep -q "Apply complete" && \
+        ! run_sql "SELECT 1 FROM sys.database_permissions WHERE grantee_principal_id = DATABASE_PRINCIPAL_ID('guest') AND permission_name = 'CONNECT' AND state IN ('G', 'W')" "application_db" | grep -v "Executed in" | grep "1" -q; then
+        record_test "Drift Recovery: Guest user disabled" "PASS"
+    else
+        record_test "Drift Recovery: Guest user disabled" "FAIL"
+    fi
+
+    # Test 11: A new password version resets a password changed outside of Terraform
+    log_info "Test: Password reset by password version..."
+    run_sql "ALTER LOGIN test_login WITH PASSWORD = 'Ch@ngedOutside123!'" >/dev/null 2>&1 || true
+    apply_output=$(terraform apply -auto-approve -var test_password_version=2 2>&1)
+    if echo "$apply_output" | grep -q "Apply complete! Resources: 0 added, 1 changed" && \
+        run_sql "SELECT 1 FROM sys.sql_logins WHERE name = 'test_login' AND PWDCOMPARE('AppP@ssw0rd123!', password_hash) = 1" | grep -v "Executed in" | grep "1" -q; then
+        record_test "Password Version: Password reset" "PASS"
+    else
+        echo "$apply_output" | tail -10
+        record_test "Password Version: Password reset" "FAIL"
+    fi
+
+    # Restore the password version for the remaining phases
+    terraform apply -auto-approve >/dev/null 2>&1 || true
+
+    # Test 12: Removing the last db_owner member warns
+    log_info "Test: Last db_owner member removal..."
+    apply_output=$(terraform apply -auto-approve -no-color -var test_db_owner=false 2>&1)
+    if echo "$apply_output" | grep -q "Last db_owner member removed" && \
+        ! run_sql "SELECT 1 FROM sys.database_role_members WHERE role_principal_id = DATABASE_PRINCIPAL_ID('db_owner') AND member_principal_id = DATABASE_PRINCIPAL_ID('test_user')" "application_db" | grep -v "Executed in" | grep "1" -q; then
+        record_test "db_owner Guard: Last member warning" "PASS"
+    else
+        echo "$apply_output" | tail -10
+        record_test "db_owner Guard: Last member warning" "FAIL"
+    fi
+
+    # Add the member back for the remaining phases
+    terraform apply -auto-approve >/dev/null 2>&1 || true
+
+    # Test 13: The provider default applies to permissions without with_grant_option
+    log_info "Test: Provider default_with_grant_option..."
+    terraform apply -auto-approve -var test_default_grant_option=true >/dev/null 2>&1 || true
+    if run_sql "SELECT 1 FROM sys.database_permissions WHERE grantee_principal_id = DATABASE_PRINCIPAL_ID('app_readers') AND permission_name = 'SELECT' AND class = 0 AND state = 'W'" "application_db" | grep -v "Executed in" | grep "1" -q && \
+        run_sql "SELECT 1 FROM sys.database_permissions WHERE grantee_principal_id = DATABASE_PRINCIPAL_ID('app_user') AND permission_name = 'EXECUTE' AND class = 3 AND state = 'G'" "application_db" | grep -v "Executed in" | grep "1" -q; then
+        record_test "Permission Defaults: default_with_grant_option" "PASS"
+    else
+        record_test "Permission Defaults: default_with_grant_option" "FAIL"
+    fi
+
+    # Restore the default for the remaining phases
+    terraform apply -auto-approve >/dev/null 2>&1 || true
+
+    # Test 14: Adding a member that does not exist in the role's database fails clearly
+    log_info "Test: Role member lookup..."
+    apply_output=$(terraform apply -auto-approve -no-color -var test_missing_role_member=test_usr 2>&1)
+    if echo "$apply_output" | grep -q "member 'test_usr' not found in database 'application_db'"; then
+        record_test "Role Member: Missing member error" "PASS"
+    else
+        echo "$apply_output" | tail -10
+        record_test "Role Member: Missing member error" "FAIL"
+    fi
+
+    # Drop the failed member from the configuration for the remaining phases
+    terraform apply -auto-approve >/dev/null 2>&1 || true
+
+    # Test 15: Database copies are refused outside of Azure SQL Database
+    log_info "Test: Database copy outside of Azure..."
+    apply_output=$(terraform apply -auto-approve -no-color -var test_copy_source=application_db 2>&1)
+    if echo "$apply_output" | grep -q "only supported in Azure SQL Database" && \
+        ! run_sql "SELECT 1 FROM sys.databases WHERE name = 'application_db_copy'" | grep -v "Executed in" | grep "1" -q; then
+        record_test "Database Copy: Refused outside of Azure" "PASS"
+    else
+        echo "$apply_output" | tail -10
+        record_test "Database Copy: Refused outside of Azure" "FAIL"
+    fi
+
+    # Test 16: run_on 'apply' runs the statement on every apply, 'destroy' only on destroy
+    log_info "Test: Exec run_on..."
+    terraform apply -auto-approve -var test_exec_run_on=apply >/dev/null 2>&1
+    terraform apply -auto-approve -var test_exec_run_on=apply >/dev/null 2>&1
+    terraform apply -auto-approve -var test_exec_run_on=destroy >/dev/null 2>&1
+    local ran_on_apply=false
+    if run_sql "SELECT 1 WHERE (SELECT MAX(id) FROM dbo.orders) = 3" "application_db" | grep -v "Executed in" | grep "1" -q; then
+        ran_on_apply=true
+    fi
+    # Dropping the resource from the configuration runs the statement once more
+    terraform apply -auto-approve >/dev/null 2>&1 || true
+    if $ran_on_apply && \
+        run_sql "SELECT 1 WHERE (SELECT MAX(id) FROM dbo.orders) = 4" "application_db" | grep -v "Executed in" | grep "1" -q; then
+        record_test "Exec: run_on apply and destroy" "PASS"
+    else
+        record_test "Exec: run_on apply and destroy" "FAIL"
+    fi
+
+    # Test 17: Changing the name renames the database in place, keeping its data
+    log_info "Test: Database rename..."
+    terraform apply -auto-approve -var test_rename_database=rename_db_a >/dev/null 2>&1
+    run_sql "CREATE TABLE dbo.kept (id INT)" "rename_db_a" >/dev/null 2>&1
+    terraform apply -auto-approve -var test_rename_database=rename_db_b >/dev/null 2>&1
+    if run_sql "SELECT 1 WHERE OBJECT_ID('dbo.kept') IS NOT NULL AND DB_ID('rename_db_a') IS NULL" "rename_db_b" | grep -v "Executed in" | grep "1" -q; then
+        record_test "Database: Rename in place" "PASS"
+    else
+        record_test "Database: Rename in place" "FAIL"
+    fi
+    terraform apply -auto-approve >/dev/null 2>&1 || true
+
     return 0
 }
 
@@ -486,6 +1187,8 @@ phase_cleanup() {
     cleanup_state_files "$PROJECT_ROOT/examples/testing/complete"
     cleanup_state_files "$PROJECT_ROOT/examples/testing/data_sources"
     cleanup_state_files "$PROJECT_ROOT/examples/testing/provider"
+    cleanup_state_files "$PROJECT_ROOT/examples/testing/sa_login"
+    cleanup_state_files "$PROJECT_ROOT/examples/testing/fixed_server_role"
 
     # Clean up tfvars
     rm -f "$PROJECT_ROOT/examples/testing/complete/terraform.tfvars"
@@ -551,6 +1254,8 @@ main() {
     phase_complete_example || true
     phase_data_sources || true
     phase_provider_example || true
+    phase_sa_login || true
+    phase_fixed_server_role || true
     phase_drift_recovery || true
     phase_cleanup
 
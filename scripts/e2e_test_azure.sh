@@ -284,6 +284,14 @@ main() {
         log_record "SQL Verify: Managed Identity User" "FAIL"
     fi
 
+    # Check the MI user was created from its object ID, without a directory lookup
+    if run_sql "$SQL_HOST" "$SQL_USER" "$SQL_PASSWORD" "$DB_NAME" \
+        "SELECT 1 FROM sys.database_principals WHERE name = '$MI_NAME' AND type = 'E' AND sid = CAST(CAST('$MI_OBJECT_ID' AS UNIQUEIDENTIFIER) AS VARBINARY(16))" | grep -v "Executed in" | grep -q "1"; then
+        log_record "SQL Verify: Managed Identity User SID" "PASS"
+    else
+        log_record "SQL Verify: Managed Identity User SID" "FAIL"
+    fi
+
     # Check Role creation
     if run_sql "$SQL_HOST" "$SQL_USER" "$SQL_PASSWORD" "$DB_NAME" \
         "SELECT 1 FROM sys.database_principals WHERE name = 'managed_identity_role' AND type = 'R'" | grep -v "Executed in" | grep -q "1"; then